@@ -0,0 +1 @@
+Added the cache.mode field to deploy a replicated Redis monitored by Redis Sentinel.
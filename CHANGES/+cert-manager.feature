@@ -0,0 +1 @@
+Added the `tls.issuer_ref` field to provision the ingress, route and pulp-web certificates through cert-manager.
@@ -0,0 +1 @@
+Added a managed rotation of the database fields encryption key, with each step tracked in the Pulp-DB-Fields-Encryption-Key-Rotation condition.
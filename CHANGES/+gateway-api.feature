@@ -0,0 +1 @@
+Added ingress_type: gateway to expose Pulp through Gateway API HTTPRoutes.
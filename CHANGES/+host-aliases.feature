@@ -0,0 +1 @@
+Added the `host_aliases` and `content_host` fields to expose Pulp through multiple hostnames and a dedicated content hostname.
@@ -0,0 +1 @@
+Added the ingress_controller field to configure Traefik, HAProxy and Contour without pulp-web.
@@ -0,0 +1 @@
+Added the `internal_tls` field to serve pulpcore-api and pulpcore-content over HTTPS inside the cluster.
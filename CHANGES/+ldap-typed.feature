@@ -0,0 +1 @@
+Added typed `ldap` fields to generate the django-auth-ldap settings and a Job to test the ldap bind and searches.
@@ -0,0 +1 @@
+Added the `network_policies` field to provision least-privilege NetworkPolicies for each Pulp component.
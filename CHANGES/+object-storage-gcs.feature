@@ -0,0 +1 @@
+Added the object_storage_gcs_secret field to configure Google Cloud Storage as the storage backend.
@@ -0,0 +1 @@
+Extra s3-*, azure-* and gcs-* keys from the object storage Secret are now rendered into the django-storages OPTIONS.
@@ -0,0 +1 @@
+Added support for keyless object storage through IAM Roles for Service Accounts (S3) and Azure Workload Identity.
@@ -0,0 +1 @@
+Added the `oidc` field to authenticate Pulp users through any OpenID Connect provider, with claim to group and role mappings.
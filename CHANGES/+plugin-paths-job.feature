@@ -0,0 +1 @@
+The plugin paths exposed through Ingress, Routes and HTTPRoutes are now discovered by a Job and cached in a ConfigMap instead of running an exec in the pulpcore pods.
@@ -0,0 +1 @@
+Added the `PulpUser`, `PulpGroup` and `PulpRoleBinding` CRDs to manage Pulp users, groups and role assignments declaratively.
@@ -0,0 +1 @@
+Added the `PulpRemote`, `PulpRepository` and `PulpDistribution` CRDs to manage Pulp remotes, repositories (and their syncs) and distributions declaratively.
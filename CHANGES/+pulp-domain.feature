@@ -0,0 +1 @@
+Added the `PulpDomain` CRD to manage Pulp domains and their storage, exposing the content path of each domain in the Ingress/Route.
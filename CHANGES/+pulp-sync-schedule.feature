@@ -0,0 +1 @@
+Added the `PulpSyncSchedule` CRD to sync repositories periodically through operator-managed CronJobs.
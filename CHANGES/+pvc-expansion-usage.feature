@@ -0,0 +1 @@
+Expand the file storage and database PVCs when their size is increased in Pulp CR and report the file storage usage in Pulp status.
@@ -0,0 +1 @@
+Added scheduled and on-demand rotation of the admin password and of the container token key pair.
@@ -0,0 +1 @@
+Added the `secrets_store` field to read the database, object storage, SSO, LDAP and signing credentials from Secrets Store CSI driver `SecretProviderClasses` instead of `Secrets`.
@@ -0,0 +1 @@
+Added the migration of the artifacts between file and object storage when the storage type changes.
@@ -0,0 +1 @@
+Added the web.nginx field to configure response headers, rate limits, API access lists and raw snippets in the pulp-web nginx.conf.
@@ -0,0 +1 @@
+The pulp-web nginx locations are now generated from the discovered plugin paths, so nodeport and loadbalancer deployments expose the same paths as Ingress and Route deployments.
@@ -0,0 +1,104 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PulpDistributionSpec defines the desired state of PulpDistribution
+// +kubebuilder:validation:XValidation:rule="self.type == oldSelf.type",message="type is immutable"
+type PulpDistributionSpec struct {
+
+	// Name of Pulp CR in which the distribution is managed
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DeploymentName string `json:"deployment_name"`
+
+	// Plugin and type of the distribution, as in the Pulp API endpoint (for example, rpm/rpm,
+	// file/file or container/container). It cannot be modified.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern:=`^[a-z0-9_]+/[a-z0-9_]+$`
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Type string `json:"type"`
+
+	// Name of the distribution
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Name string `json:"name"`
+
+	// Base path of the distribution in the content app (for example, rhel/9/baseos)
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	BasePath string `json:"base_path"`
+
+	// Name of the repository (of the same type) served by the distribution. Depending on the
+	// plugin, the latest version or the latest publication of the repository is served.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Repository string `json:"repository,omitempty"`
+
+	// Plugin specific fields of the distribution (for example, private of container
+	// distributions), sent to Pulp as they are.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	PluginFields map[string]apiextensionsv1.JSON `json:"plugin_fields,omitempty"`
+
+	// Define if the distribution should be removed from Pulp when the CR is removed (Delete) or not (Retain).
+	// Default: Delete
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum:=Delete;Retain
+	// +kubebuilder:default:=Delete
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	DeletionPolicy DeletionPolicy `json:"deletion_policy,omitempty"`
+}
+
+// PulpDistributionStatus defines the observed state of PulpDistribution
+type PulpDistributionStatus struct {
+	PulpObjectStatus `json:",inline"`
+	// URL of the content served by the distribution
+	//+operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors={"urn:alm:descriptor:org.w3:link"}
+	BaseURL string `json:"base_url,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// PulpDistribution is the Schema for the pulpdistributions API
+type PulpDistribution struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PulpDistributionSpec   `json:"spec,omitempty"`
+	Status PulpDistributionStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PulpDistributionList contains a list of PulpDistribution
+type PulpDistributionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PulpDistribution `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PulpDistribution{}, &PulpDistributionList{})
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PulpDomainSpec defines the desired state of PulpDomain
+// +kubebuilder:validation:XValidation:rule="has(self.storage_class) || has(self.storage_secret)",message="storage_class or storage_secret should be provided"
+type PulpDomainSpec struct {
+
+	// Name of Pulp CR in which the domain is managed. The domains should be enabled in Pulp
+	// (DOMAIN_ENABLED: true in custom_pulp_settings).
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DeploymentName string `json:"deployment_name"`
+
+	// Name of the domain (it is part of the api and content paths)
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern:=`^[-a-zA-Z0-9_]+$`
+	// +kubebuilder:validation:XValidation:rule="self != 'default'",message="the default domain is managed by Pulp"
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Name string `json:"name"`
+
+	// Description of the domain
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Description string `json:"description,omitempty"`
+
+	// Storage class of the domain (for example, pulpcore.app.models.storage.FileSystem,
+	// storages.backends.s3boto3.S3Boto3Storage or storages.backends.azure_storage.AzureStorage).
+	// If not provided, the storage class is defined by the format of storage_secret.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	StorageClass string `json:"storage_class,omitempty"`
+
+	// Settings of the storage class (for example, location of FileSystem domains), sent to
+	// Pulp as they are. The settings from storage_secret take precedence.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	StorageSettings map[string]apiextensionsv1.JSON `json:"storage_settings,omitempty"`
+
+	// Secret with the storage settings of the domain, in the same format of
+	// object_storage_s3_secret or object_storage_azure_secret.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret"}
+	StorageSecret string `json:"storage_secret,omitempty"`
+
+	// Redirect the content requests to the object storage (instead of streaming the content
+	// through the content app).
+	// Default: true
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=true
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	RedirectToObjectStorage *bool `json:"redirect_to_object_storage,omitempty"`
+
+	// Hide the distributions protected by a content guard from the content app listing.
+	// Default: false
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	HideGuardedDistributions bool `json:"hide_guarded_distributions,omitempty"`
+
+	// Define if the domain should be removed from Pulp when the CR is removed (Delete) or not (Retain).
+	// Pulp only removes the domains without content.
+	// Default: Delete
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum:=Delete;Retain
+	// +kubebuilder:default:=Delete
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	DeletionPolicy DeletionPolicy `json:"deletion_policy,omitempty"`
+}
+
+// PulpDomainStatus defines the observed state of PulpDomain
+type PulpDomainStatus struct {
+	PulpObjectStatus `json:",inline"`
+	// Hash of the storage class and settings sent to Pulp (Pulp does not return the secret settings)
+	StorageHash string `json:"storage_hash,omitempty"`
+	// Path of the content of the domain (exposed by the Ingress/Route)
+	//+operator-sdk:csv:customresourcedefinitions:type=status
+	ContentPath string `json:"content_path,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// PulpDomain is the Schema for the pulpdomains API
+type PulpDomain struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PulpDomainSpec   `json:"spec,omitempty"`
+	Status PulpDomainStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PulpDomainList contains a list of PulpDomain
+type PulpDomainList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PulpDomain `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PulpDomain{}, &PulpDomainList{})
+}
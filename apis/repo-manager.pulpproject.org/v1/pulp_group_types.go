@@ -0,0 +1,80 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PulpGroupSpec defines the desired state of PulpGroup
+type PulpGroupSpec struct {
+
+	// Name of Pulp CR in which the group is managed
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DeploymentName string `json:"deployment_name"`
+
+	// Name of the group
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Name string `json:"name"`
+
+	// Usernames of the members of the group. The users added to the group through the
+	// Pulp API that are not in this list are removed from it.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Users []string `json:"users,omitempty"`
+
+	// Define if the group should be removed from Pulp when the CR is removed (Delete) or not (Retain).
+	// Default: Delete
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum:=Delete;Retain
+	// +kubebuilder:default:=Delete
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	DeletionPolicy DeletionPolicy `json:"deletion_policy,omitempty"`
+}
+
+// PulpGroupStatus defines the observed state of PulpGroup
+type PulpGroupStatus struct {
+	PulpObjectStatus `json:",inline"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// PulpGroup is the Schema for the pulpgroups API
+type PulpGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PulpGroupSpec   `json:"spec,omitempty"`
+	Status PulpGroupStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PulpGroupList contains a list of PulpGroup
+type PulpGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PulpGroup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PulpGroup{}, &PulpGroupList{})
+}
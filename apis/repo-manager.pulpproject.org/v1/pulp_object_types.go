@@ -0,0 +1,68 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DeletionPolicy defines what happens with the Pulp object when its CR is removed
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete removes the object from Pulp
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyRetain keeps the object in Pulp
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+)
+
+// PulpObjectStatus defines the observed state of the objects managed through the Pulp REST API
+type PulpObjectStatus struct {
+	//+operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors={"urn:alm:descriptor:io.kubernetes.conditions"}
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Pulp href of the object
+	//+operator-sdk:csv:customresourcedefinitions:type=status
+	PulpHref string `json:"pulp_href,omitempty"`
+
+	// Generation of the CR synchronized with Pulp
+	//+operator-sdk:csv:customresourcedefinitions:type=status
+	ObservedGeneration int64 `json:"observed_generation,omitempty"`
+
+	// Last time the object was synchronized with Pulp (the objects are periodically synchronized
+	// to revert the modifications made through the Pulp API)
+	//+operator-sdk:csv:customresourcedefinitions:type=status
+	LastSyncTime *metav1.Time `json:"last_sync_time,omitempty"`
+	// Last task dispatched by Pulp to create, update or remove the object (only for the objects
+	// that Pulp modifies asynchronously, like remotes, repositories and distributions)
+	//+operator-sdk:csv:customresourcedefinitions:type=status
+	LastTask *PulpTaskStatus `json:"last_task,omitempty"`
+}
+
+// PulpTaskStatus defines the observed state of a Pulp task
+type PulpTaskStatus struct {
+	// Pulp href of the task
+	Href string `json:"href"`
+	// State of the task (waiting, running, completed, failed, canceled, etc.)
+	State string `json:"state,omitempty"`
+	// Description of the error of a failed task
+	Error string `json:"error,omitempty"`
+	// Time the task started running
+	StartedAt *metav1.Time `json:"started_at,omitempty"`
+	// Time the task finished
+	FinishedAt *metav1.Time `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PulpRemoteSpec defines the desired state of PulpRemote
+// +kubebuilder:validation:XValidation:rule="self.type == oldSelf.type",message="type is immutable"
+type PulpRemoteSpec struct {
+
+	// Name of Pulp CR in which the remote is managed
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DeploymentName string `json:"deployment_name"`
+
+	// Plugin and type of the remote, as in the Pulp API endpoint (for example, rpm/rpm,
+	// file/file or container/container). It cannot be modified.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern:=`^[a-z0-9_]+/[a-z0-9_]+$`
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Type string `json:"type"`
+
+	// Name of the remote
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Name string `json:"name"`
+
+	// URL of the external content source
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	URL string `json:"url"`
+
+	// Policy to use when downloading content (immediate, on_demand or streamed).
+	// Default: immediate
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum:=immediate;on_demand;streamed
+	// +kubebuilder:default:=immediate
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Policy string `json:"policy,omitempty"`
+
+	// If true, TLS peer validation must be performed.
+	// Default: true
+	// +kubebuilder:default:=true
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	TLSValidation *bool `json:"tls_validation,omitempty"`
+
+	// URL of the proxy server used to download the content
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	ProxyURL string `json:"proxy_url,omitempty"`
+
+	// Secret with the username and password keys used to authenticate to the external content
+	// source. The credentials are set when the remote is created and whenever the Secret is modified.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret"}
+	CredentialsSecret string `json:"credentials_secret,omitempty"`
+
+	// Plugin specific fields of the remote (for example, upstream_name and include_tags of
+	// container remotes), sent to Pulp as they are.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	PluginFields map[string]apiextensionsv1.JSON `json:"plugin_fields,omitempty"`
+
+	// Define if the remote should be removed from Pulp when the CR is removed (Delete) or not (Retain).
+	// Default: Delete
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum:=Delete;Retain
+	// +kubebuilder:default:=Delete
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	DeletionPolicy DeletionPolicy `json:"deletion_policy,omitempty"`
+}
+
+// PulpRemoteStatus defines the observed state of PulpRemote
+type PulpRemoteStatus struct {
+	PulpObjectStatus `json:",inline"`
+	// Hash of the credentials_secret content set in Pulp
+	CredentialsHash string `json:"credentials_hash,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// PulpRemote is the Schema for the pulpremotes API
+type PulpRemote struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PulpRemoteSpec   `json:"spec,omitempty"`
+	Status PulpRemoteStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PulpRemoteList contains a list of PulpRemote
+type PulpRemoteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PulpRemote `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PulpRemote{}, &PulpRemoteList{})
+}
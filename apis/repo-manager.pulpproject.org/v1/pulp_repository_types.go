@@ -0,0 +1,131 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SyncRequestAnnotation can be set (or modified) in a PulpRepository to sync it again from its remote
+const SyncRequestAnnotation = "repo-manager.pulpproject.org/sync-request"
+
+// PulpRepositorySpec defines the desired state of PulpRepository
+// +kubebuilder:validation:XValidation:rule="self.type == oldSelf.type",message="type is immutable"
+type PulpRepositorySpec struct {
+
+	// Name of Pulp CR in which the repository is managed
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DeploymentName string `json:"deployment_name"`
+
+	// Plugin and type of the repository, as in the Pulp API endpoint (for example, rpm/rpm,
+	// file/file or container/container). It cannot be modified.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern:=`^[a-z0-9_]+/[a-z0-9_]+$`
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Type string `json:"type"`
+
+	// Name of the repository
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Name string `json:"name"`
+
+	// Description of the repository
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Description string `json:"description,omitempty"`
+
+	// Number of repository versions to keep (all of them if not provided)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum:=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	RetainRepoVersions *int `json:"retain_repo_versions,omitempty"`
+
+	// Name of the remote (of the same type) from which the repository is synced. The repository
+	// is synced when it is created, when the remote is modified in this field and whenever the
+	// repo-manager.pulpproject.org/sync-request annotation is modified.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Remote string `json:"remote,omitempty"`
+
+	// Remove the content that is not in the remote when the repository is synced.
+	// Default: false
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	Mirror bool `json:"mirror,omitempty"`
+
+	// Plugin specific fields of the repository (for example, autopublish of rpm and file
+	// repositories), sent to Pulp as they are.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	PluginFields map[string]apiextensionsv1.JSON `json:"plugin_fields,omitempty"`
+
+	// Define if the repository (and its content) should be removed from Pulp when the CR is removed (Delete) or not (Retain).
+	// Default: Delete
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum:=Delete;Retain
+	// +kubebuilder:default:=Delete
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	DeletionPolicy DeletionPolicy `json:"deletion_policy,omitempty"`
+}
+
+// PulpRepositoryStatus defines the observed state of PulpRepository
+type PulpRepositoryStatus struct {
+	PulpObjectStatus `json:",inline"`
+	// Pulp href of the latest version of the repository
+	//+operator-sdk:csv:customresourcedefinitions:type=status
+	LatestVersionHref string `json:"latest_version_href,omitempty"`
+	// Last sync of the repository dispatched by the operator
+	//+operator-sdk:csv:customresourcedefinitions:type=status
+	LastSync *PulpRepositorySyncStatus `json:"last_sync,omitempty"`
+}
+
+// PulpRepositorySyncStatus defines the observed state of a repository sync
+type PulpRepositorySyncStatus struct {
+	PulpTaskStatus `json:",inline"`
+	// Name of the remote from which the repository was synced
+	Remote string `json:"remote,omitempty"`
+	// Value of the sync-request annotation when the sync was dispatched
+	Request string `json:"request,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// PulpRepository is the Schema for the pulprepositories API
+type PulpRepository struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PulpRepositorySpec   `json:"spec,omitempty"`
+	Status PulpRepositoryStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PulpRepositoryList contains a list of PulpRepository
+type PulpRepositoryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PulpRepository `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PulpRepository{}, &PulpRepositoryList{})
+}
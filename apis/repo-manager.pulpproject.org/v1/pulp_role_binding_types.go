@@ -0,0 +1,103 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PulpRoleBindingSpec defines the desired state of PulpRoleBinding
+type PulpRoleBindingSpec struct {
+
+	// Name of Pulp CR in which the role is assigned
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DeploymentName string `json:"deployment_name"`
+
+	// Name of the Pulp role (for example, core.task_viewer or file.filerepository_owner)
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Role string `json:"role"`
+
+	// Pulp href of the object in which the role is assigned (for example,
+	// /pulp/api/v3/repositories/file/file/<uuid>/). If not provided, the role is assigned
+	// to all the objects (model-level).
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	ContentObject string `json:"content_object,omitempty"`
+
+	// Usernames of the users that should have the role
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Users []string `json:"users,omitempty"`
+
+	// Names of the groups that should have the role
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Groups []string `json:"groups,omitempty"`
+
+	// Define if the role assignments should be removed from Pulp when the CR is removed (Delete) or not (Retain).
+	// Default: Delete
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum:=Delete;Retain
+	// +kubebuilder:default:=Delete
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	DeletionPolicy DeletionPolicy `json:"deletion_policy,omitempty"`
+}
+
+// PulpRoleBindingStatus defines the observed state of PulpRoleBinding
+type PulpRoleBindingStatus struct {
+	PulpObjectStatus `json:",inline"`
+
+	// Role assigned by the operator
+	Role string `json:"role,omitempty"`
+
+	// Object in which the role was assigned by the operator
+	ContentObject string `json:"content_object,omitempty"`
+
+	// Users to which the role was assigned by the operator
+	Users []string `json:"users,omitempty"`
+
+	// Groups to which the role was assigned by the operator
+	Groups []string `json:"groups,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// PulpRoleBinding is the Schema for the pulprolebindings API
+type PulpRoleBinding struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PulpRoleBindingSpec   `json:"spec,omitempty"`
+	Status PulpRoleBindingStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PulpRoleBindingList contains a list of PulpRoleBinding
+type PulpRoleBindingList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PulpRoleBinding `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PulpRoleBinding{}, &PulpRoleBindingList{})
+}
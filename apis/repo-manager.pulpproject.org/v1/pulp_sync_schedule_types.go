@@ -0,0 +1,136 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PulpSyncScheduleSpec defines the desired state of PulpSyncSchedule
+type PulpSyncScheduleSpec struct {
+
+	// Name of Pulp CR in which the repository is synced
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DeploymentName string `json:"deployment_name"`
+
+	// Plugin and type of the repository and the remote, as in the Pulp API endpoint
+	// (for example, rpm/rpm, file/file or container/container)
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern:=`^[a-z0-9_]+/[a-z0-9_]+$`
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Type string `json:"type"`
+
+	// Name of the repository synced
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Repository string `json:"repository"`
+
+	// Name of the remote from which the repository is synced. If not provided, the remote
+	// of the repository is used.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Remote string `json:"remote,omitempty"`
+
+	// Schedule of the syncs in cron format (for example, "0 2 * * *")
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Schedule string `json:"schedule"`
+
+	// Time zone of the schedule (for example, "Etc/UTC"). If not provided, the time zone
+	// of the kube-controller-manager is used.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	TimeZone *string `json:"time_zone,omitempty"`
+
+	// Suspend the next syncs (the running sync is not canceled).
+	// Default: false
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	Suspend bool `json:"suspend,omitempty"`
+
+	// Remove the content that is not in the remote when the repository is synced.
+	// Default: false
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	Mirror bool `json:"mirror,omitempty"`
+
+	// Plugin specific options of the sync (for example, sync_policy or optimize of rpm
+	// repositories), sent to Pulp as they are.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	SyncOptions map[string]apiextensionsv1.JSON `json:"sync_options,omitempty"`
+
+	// Resource requirements for the sync container (it only dispatches the sync and
+	// waits for the Pulp task).
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:resourceRequirements","urn:alm:descriptor:com.tectonic.ui:advanced"}
+	ResourceRequirements corev1.ResourceRequirements `json:"resource_requirements,omitempty"`
+}
+
+// PulpSyncScheduleStatus defines the observed state of PulpSyncSchedule
+type PulpSyncScheduleStatus struct {
+	//+operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors={"urn:alm:descriptor:io.kubernetes.conditions"}
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Name of the CronJob that runs the syncs
+	//+operator-sdk:csv:customresourcedefinitions:type=status
+	CronJob string `json:"cronjob,omitempty"`
+	// Last time a sync was scheduled
+	//+operator-sdk:csv:customresourcedefinitions:type=status
+	LastScheduleTime *metav1.Time `json:"last_schedule_time,omitempty"`
+	// Result of the last sync finished
+	//+operator-sdk:csv:customresourcedefinitions:type=status
+	LastSync *PulpScheduledSyncStatus `json:"last_sync,omitempty"`
+}
+
+// PulpScheduledSyncStatus defines the observed state of a sync run by a PulpSyncSchedule
+type PulpScheduledSyncStatus struct {
+	PulpTaskStatus `json:",inline"`
+	// Name of the Job that dispatched the sync
+	Job string `json:"job"`
+	// Duration of the sync task
+	Duration *metav1.Duration `json:"duration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// PulpSyncSchedule is the Schema for the pulpsyncschedules API
+type PulpSyncSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PulpSyncScheduleSpec   `json:"spec,omitempty"`
+	Status PulpSyncScheduleStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PulpSyncScheduleList contains a list of PulpSyncSchedule
+type PulpSyncScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PulpSyncSchedule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PulpSyncSchedule{}, &PulpSyncScheduleList{})
+}
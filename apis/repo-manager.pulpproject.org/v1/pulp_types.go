@@ -54,6 +54,22 @@ type PulpSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:hidden","urn:alm:descriptor:io.kubernetes:StorageClass"}
 	FileStorageClass string `json:"file_storage_storage_class,omitempty"`
 
+	// Percentage of the file storage in use above which the operator will set the
+	// Pulp-File-Storage-Usage condition to false and emit a warning event.
+	// Set it to 0 to disable the sampling of the file storage usage.
+	// Default: 85
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum:=0
+	// +kubebuilder:validation:Maximum:=100
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:hidden","urn:alm:descriptor:com.tectonic.ui:number"}
+	FileStorageUsageThreshold *int32 `json:"file_storage_usage_threshold,omitempty"`
+
+	// Interval between the samples of the file storage usage; for example 30m.
+	// Default: 10m
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:hidden"}
+	FileStorageUsageInterval string `json:"file_storage_usage_interval,omitempty"`
+
 	// The secret for Azure compliant object storage configuration.
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Azure secret"
@@ -66,6 +82,12 @@ type PulpSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret","urn:alm:descriptor:com.tectonic.ui:hidden"}
 	ObjectStorageS3Secret string `json:"object_storage_s3_secret,omitempty"`
 
+	// The secret for Google Cloud Storage object storage configuration.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="GCS secret"
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret","urn:alm:descriptor:com.tectonic.ui:hidden"}
+	ObjectStorageGCSSecret string `json:"object_storage_gcs_secret,omitempty"`
+
 	// PersistenVolumeClaim name that will be used by Pulp pods.
 	// If defined, the PVC must be provisioned by the user and the operator will only
 	// configure the deployment to use it
@@ -93,10 +115,16 @@ type PulpSpec struct {
 	// The ingress type to use to reach the deployed instance.
 	// Default: none (will not expose the service)
 	// +kubebuilder:validation:Optional
-	// +kubebuilder:validation:Enum:=none;Ingress;ingress;Route;route;LoadBalancer;loadbalancer;NodePort;nodeport
-	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:Route","urn:alm:descriptor:com.tectonic.ui:select:Ingress","urn:alm:descriptor:com.tectonic.ui:select:LoadBalancer","urn:alm:descriptor:com.tectonic.ui:select:NodePort"}
+	// +kubebuilder:validation:Enum:=none;Ingress;ingress;Route;route;LoadBalancer;loadbalancer;NodePort;nodeport;Gateway;gateway
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:Route","urn:alm:descriptor:com.tectonic.ui:select:Ingress","urn:alm:descriptor:com.tectonic.ui:select:LoadBalancer","urn:alm:descriptor:com.tectonic.ui:select:NodePort","urn:alm:descriptor:com.tectonic.ui:select:Gateway"}
 	IngressType string `json:"ingress_type,omitempty"`
 
+	// Gateway defines the Gateway (from Gateway API) the HTTPRoutes will be attached to
+	// when ingress_type is gateway.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:fieldDependency:ingress_type:Gateway"}
+	Gateway Gateway `json:"gateway,omitempty"`
+
 	// Annotations for the Ingress
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:fieldDependency:ingress_type:Ingress"}
@@ -116,7 +144,18 @@ type PulpSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text","urn:alm:descriptor:com.tectonic.ui:fieldDependency:ingress_type:Ingress"}
 	IsNginxIngress bool `json:"is_nginx_ingress,omitempty"`
 
-	// Ingress DNS host
+	// Ingress controller of the IngressClass provided in ingress_class_name.
+	// With nginx, traefik, haproxy (HAProxy Kubernetes Ingress Controller) and contour, the operator configures
+	// the controller (through Ingress annotations or the controller CRDs) to forward the traffic to api and content pods.
+	// With any other controller (or if not provided), `pulp-web` pods are provisioned to redirect the traffic.
+	// Defining is_nginx_ingress as true is the same as defining ingress_controller as nginx.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum:=nginx;traefik;haproxy;contour
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:nginx","urn:alm:descriptor:com.tectonic.ui:select:traefik","urn:alm:descriptor:com.tectonic.ui:select:haproxy","urn:alm:descriptor:com.tectonic.ui:select:contour","urn:alm:descriptor:com.tectonic.ui:fieldDependency:ingress_type:Ingress"}
+	IngressController string `json:"ingress_controller,omitempty"`
+
+	// Ingress DNS host.
+	// It is also used as the HTTPRoutes hostname when ingress_type is gateway.
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text","urn:alm:descriptor:com.tectonic.ui:fieldDependency:ingress_type:Ingress"}
 	IngressHost string `json:"ingress_host,omitempty"`
 
@@ -147,6 +186,36 @@ type PulpSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret","urn:alm:descriptor:com.tectonic.ui:fieldDependency:ingress_type:Route"}
 	RouteTLSSecret string `json:"route_tls_secret,omitempty"`
 
+	// Additional hostnames (aliases) used to access Pulp through the Ingress, Routes or HTTPRoutes.
+	// Each alias is exposed with the same paths as ingress_host/route_host.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	HostAliases []Hostname `json:"host_aliases,omitempty"`
+
+	// Dedicated hostname used to access the content app (for example, cdn.example.com).
+	// Only the content paths are exposed through this host and it is used to define CONTENT_ORIGIN.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	ContentHost *Hostname `json:"content_host,omitempty"`
+
+	// TLS defines the cert-manager configuration used to provision the certificates for
+	// ingress_host, route_host and pulp-web (when web.tls_termination_mechanism is passthrough).
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	TLS TLS `json:"tls,omitempty"`
+
+	// InternalTLS defines the configuration to encrypt the traffic from pulp-web, Ingress and Routes
+	// to the pulpcore-api and pulpcore-content services.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	InternalTLS InternalTLS `json:"internal_tls,omitempty"`
+
+	// NetworkPolicies defines the NetworkPolicies provisioned to restrict the traffic
+	// between the Pulp components.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	NetworkPolicies NetworkPolicies `json:"network_policies,omitempty"`
+
 	// Provide requested port value
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldDependency:ingress_type:NodePort"}
@@ -289,6 +358,19 @@ type PulpSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret","urn:alm:descriptor:com.tectonic.ui:advanced"}
 	AdminPasswordSecret string `json:"admin_password_secret,omitempty"`
 
+	// Rotation policies of the admin password, of the container token key pair and of the
+	// database fields encryption key. The rotation can also be requested through the
+	// repo-manager.pulpproject.org/rotate-admin-password, repo-manager.pulpproject.org/rotate-container-token
+	// and repo-manager.pulpproject.org/rotate-db-fields-encryption-key annotations.
+	// +kubebuilder:validation:Optional
+	SecretRotation SecretRotation `json:"secret_rotation,omitempty"`
+
+	// SecretProviderClasses (Secrets Store CSI driver) that provide the credentials from an external
+	// secret store (Vault, AWS Secrets Manager, Azure Key Vault, etc.). The objects are mounted in the
+	// pulpcore pods and read by settings.py, so the operator never reads nor copies them into a Secret.
+	// +kubebuilder:validation:Optional
+	SecretsStore SecretsStore `json:"secrets_store,omitempty"`
+
 	// Image pull secrets for container images.
 	// Default: []
 	// +kubebuilder:validation:Optional
@@ -331,6 +413,16 @@ type PulpSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:hidden"}
 	DisableMigrations bool `json:"disable_migrations,omitempty"`
 
+	// Job to copy the artifacts to the new storage backend when the storage type changes
+	StorageMigrationJob PulpJob `json:"storage_migration_job,omitempty"`
+
+	// Disable the copy of the artifacts when the storage type changes (for example, from
+	// file_storage_storage_class to object_storage_s3_secret). If disabled, the artifacts
+	// need to be copied manually to the new storage backend.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:hidden"}
+	DisableStorageMigration bool `json:"disable_storage_migration,omitempty"`
+
 	// Name of the Secret to provide Django cryptographic signing.
 	// Default: "pulp-secret-key"
 	// +kubebuilder:validation:Optional
@@ -362,6 +454,10 @@ type PulpSpec struct {
 	// +kubebuilder:validation:Optional
 	LDAP LDAP `json:"ldap,omitempty"`
 
+	// OIDC defines the OpenID Connect provider (Okta, Azure AD, Keycloak, etc.) used to authenticate Pulp users
+	// +kubebuilder:validation:Optional
+	OIDC OIDC `json:"oidc,omitempty"`
+
 	// Disable ipv6 for pulpcore and pulp-web pods
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:hidden"}
@@ -665,6 +761,75 @@ type Web struct {
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:hidden"}
 	DeploymentAnnotations map[string]string `json:"deployment_annotations,omitempty"`
+
+	// Customizations rendered into the pulp-web nginx.conf (response headers, rate limits,
+	// API access lists and raw snippets)
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	Nginx Nginx `json:"nginx,omitempty"`
+}
+
+// Nginx defines the customizations of the pulp-web nginx.conf
+type Nginx struct {
+	// Headers added to all responses from pulp-web (for example, security headers like
+	// Strict-Transport-Security or X-Content-Type-Options).
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Rate limits applied to the requests, per client IP address.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	RateLimits []NginxRateLimit `json:"rate_limits,omitempty"`
+
+	// IP addresses or CIDRs allowed to access the API (all paths not served by pulpcore-content).
+	// If provided, requests from any other address will be denied.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	APIAllowList []string `json:"api_allow_list,omitempty"`
+
+	// IP addresses or CIDRs denied to access the API (all paths not served by pulpcore-content).
+	// The deny list is evaluated before the allow list.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	APIDenyList []string `json:"api_deny_list,omitempty"`
+
+	// Raw nginx directives added to the http block.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	HTTPSnippet string `json:"http_snippet,omitempty"`
+
+	// Raw nginx directives added to the server block (for example, custom location blocks).
+	// The locations cannot use the paths already exposed by pulpcore and its plugins.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	ServerSnippet string `json:"server_snippet,omitempty"`
+}
+
+// NginxRateLimit defines a rate limit for the requests to a path
+type NginxRateLimit struct {
+	// Path prefix of the limited requests (for example, /pulp/api/v3/ or /pulp/content/).
+	// +kubebuilder:validation:Pattern:=`^/`
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Path string `json:"path"`
+
+	// Maximum rate of requests, per second (for example, 10r/s) or per minute (for example, 60r/m).
+	// +kubebuilder:validation:Pattern:=`^[0-9]+r/[sm]$`
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Rate string `json:"rate"`
+
+	// Number of requests over the rate that will be queued before being rejected.
+	// Default: 0
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum:=0
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	Burst int32 `json:"burst,omitempty"`
+
+	// Process the requests in the burst without delaying them.
+	// Default: false
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	NoDelay bool `json:"nodelay,omitempty"`
 }
 
 // Database defines desired state of postgres
@@ -853,6 +1018,75 @@ type Cache struct {
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:hidden"}
 	DeploymentAnnotations map[string]string `json:"deployment_annotations,omitempty"`
+
+	// Defines how the managed Redis instance will be deployed.
+	// "standalone" deploys a single Redis Deployment.
+	// "sentinel" deploys a replicated Redis StatefulSet monitored by Redis Sentinel pods.
+	// Any Redis compatible image (like Valkey) can be used through redis_image and sentinel.image.
+	// Default: "standalone"
+	// +kubebuilder:validation:Enum:=standalone;sentinel
+	// +kubebuilder:default:="standalone"
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:standalone","urn:alm:descriptor:com.tectonic.ui:select:sentinel","urn:alm:descriptor:com.tectonic.ui:advanced"}
+	Mode string `json:"mode,omitempty"`
+
+	// Number of Redis pods (1 master + N-1 replicas) deployed when mode is "sentinel".
+	// Default: 3
+	// +kubebuilder:validation:Minimum:=2
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:podCount","urn:alm:descriptor:com.tectonic.ui:advanced"}
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// Redis Sentinel configuration. Only used when mode is "sentinel".
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	Sentinel Sentinel `json:"sentinel,omitempty"`
+
+	// PodDisruptionBudget for the Redis pods deployed when mode is "sentinel".
+	// Default: maxUnavailable: 1
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:hidden"}
+	PDB *policy.PodDisruptionBudgetSpec `json:"pdb,omitempty"`
+}
+
+// Sentinel defines the configuration of the Redis Sentinel pods
+type Sentinel struct {
+
+	// Number of Sentinel pods.
+	// Default: 3
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:podCount","urn:alm:descriptor:com.tectonic.ui:advanced"}
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// The image name for the sentinel containers.
+	// By default, if not provided, it will use the same image from cache.redis_image.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	Image string `json:"image,omitempty"`
+
+	// Name of the master group monitored by the Sentinel pods.
+	// Default: "pulp"
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	MasterName string `json:"master_name,omitempty"`
+
+	// Number of milliseconds the master should be unreachable before Sentinel starts a failover.
+	// Default: 5000
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number","urn:alm:descriptor:com.tectonic.ui:advanced"}
+	DownAfterMilliseconds int `json:"down_after_milliseconds,omitempty"`
+
+	// Resource requirements for the Sentinel containers
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:resourceRequirements","urn:alm:descriptor:com.tectonic.ui:advanced"}
+	ResourceRequirements corev1.ResourceRequirements `json:"resource_requirements,omitempty"`
+
+	// PodDisruptionBudget for the Sentinel pods.
+	// Default: maxUnavailable: 1
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:hidden"}
+	PDB *policy.PodDisruptionBudgetSpec `json:"pdb,omitempty"`
 }
 
 // Telemetry defines the configuration for OpenTelemetry used by Pulp
@@ -918,6 +1152,291 @@ type LDAP struct {
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
 	CA string `json:"ca,omitempty"`
+
+	// URI of the ldap server (for example, ldaps://ldap.example.com:636).
+	// When defined, settings.py is generated from the ldap fields instead of the config Secret.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^ldaps?://`
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	ServerURI string `json:"server_uri,omitempty"`
+
+	// Distinguished name used to bind to the ldap server. An anonymous bind is done if not provided.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	BindDN string `json:"bind_dn,omitempty"`
+
+	// The name of the Secret with the bind_password key.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret","urn:alm:descriptor:com.tectonic.ui:advanced"}
+	BindPasswordSecret string `json:"bind_password_secret,omitempty"`
+
+	// Search used to find the users. The filter should have the %(user)s placeholder.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	UserSearch LDAPSearch `json:"user_search,omitempty"`
+
+	// Search used to find the groups.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	GroupSearch LDAPSearch `json:"group_search,omitempty"`
+
+	// Type of the ldap groups.
+	// Default: GroupOfNamesType
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum:=PosixGroupType;GroupOfNamesType;NestedGroupOfNamesType;GroupOfUniqueNamesType;NestedGroupOfUniqueNamesType;ActiveDirectoryGroupType;NestedActiveDirectoryGroupType
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:select:PosixGroupType","urn:alm:descriptor:com.tectonic.ui:select:GroupOfNamesType","urn:alm:descriptor:com.tectonic.ui:select:NestedGroupOfNamesType","urn:alm:descriptor:com.tectonic.ui:select:GroupOfUniqueNamesType","urn:alm:descriptor:com.tectonic.ui:select:NestedGroupOfUniqueNamesType","urn:alm:descriptor:com.tectonic.ui:select:ActiveDirectoryGroupType","urn:alm:descriptor:com.tectonic.ui:select:NestedActiveDirectoryGroupType"}
+	GroupType string `json:"group_type,omitempty"`
+
+	// Attribute with the group name.
+	// Default: cn
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	GroupNameAttr string `json:"group_name_attr,omitempty"`
+
+	// Mirror the ldap groups of the user into Pulp groups at every login.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	MirrorGroups bool `json:"mirror_groups,omitempty"`
+
+	// Use StartTLS to encrypt the connection with an ldap:// server.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	StartTLS bool `json:"start_tls,omitempty"`
+
+	// Map of Pulp user fields (first_name, last_name, email) to ldap attributes.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	UserAttrMap map[string]string `json:"user_attr_map,omitempty"`
+}
+
+// LDAPSearch defines an ldap search
+type LDAPSearch struct {
+
+	// Base DN of the search.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	BaseDN string `json:"base_dn,omitempty"`
+
+	// Search filter (for example, (uid=%(user)s) or (objectClass=groupOfNames)).
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Filter string `json:"filter,omitempty"`
+
+	// Scope of the search.
+	// Default: subtree
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum:=base;onelevel;subtree
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:base","urn:alm:descriptor:com.tectonic.ui:select:onelevel","urn:alm:descriptor:com.tectonic.ui:select:subtree"}
+	Scope string `json:"scope,omitempty"`
+}
+
+// OIDC defines the OpenID Connect provider used to authenticate Pulp users
+type OIDC struct {
+
+	// Issuer URL of the OpenID provider. The provider endpoints are discovered from
+	// <issuer_url>/.well-known/openid-configuration.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^https://`
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	IssuerURL string `json:"issuer_url,omitempty"`
+
+	// The name of the Secret with the client_id and client_secret keys of the client registered in the OpenID provider.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret","urn:alm:descriptor:com.tectonic.ui:advanced"}
+	ClientSecret string `json:"client_secret,omitempty"`
+
+	// Scopes requested to the OpenID provider.
+	// Default: ["openid", "profile", "email"]
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	Scopes []string `json:"scopes,omitempty"`
+
+	// Claim used as the Pulp username.
+	// Default: preferred_username
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	UsernameClaim string `json:"username_claim,omitempty"`
+
+	// Claim with the groups (or roles) of the user in the OpenID provider.
+	// Default: groups
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	GroupsClaim string `json:"groups_claim,omitempty"`
+
+	// Pulp groups, roles and superuser permission granted to the users based on the
+	// values of the groups_claim. They are synchronized at every login.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	ClaimMappings []OIDCClaimMapping `json:"claim_mappings,omitempty"`
+}
+
+// OIDCClaimMapping maps a value from the OIDC groups_claim to Pulp groups and roles
+type OIDCClaimMapping struct {
+
+	// Value of the groups_claim (for example, the group name or the group object ID in Azure AD).
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Claim string `json:"claim"`
+
+	// Pulp groups the user will be added to. The groups are created if they do not exist.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Groups []string `json:"groups,omitempty"`
+
+	// Pulp roles (for example, core.task_viewer or file.filerepository_creator) assigned to the user.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Roles []string `json:"roles,omitempty"`
+
+	// Grant superuser permission to the user.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	Superuser bool `json:"superuser,omitempty"`
+}
+
+// Hostname defines a DNS host used to expose Pulp
+type Hostname struct {
+
+	// DNS host.
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Host string `json:"host"`
+
+	// Name of the Secret with the certificate/key for this host.
+	// If not provided, the Secret from ingress_tls_secret/route_tls_secret (or the certificate
+	// issued by cert-manager) will be used.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret"}
+	TLSSecret string `json:"tls_secret,omitempty"`
+}
+
+// TLS defines the certificates provisioned through cert-manager
+type TLS struct {
+
+	// Reference to the cert-manager Issuer (or ClusterIssuer) that will sign the certificates.
+	// If not provided, the certificates should be provided through ingress_tls_secret or route_tls_secret.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	IssuerRef *IssuerRef `json:"issuer_ref,omitempty"`
+
+	// Requested duration of the certificates; for example 2160h.
+	// Default: the cert-manager default (90 days)
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	Duration string `json:"duration,omitempty"`
+
+	// How long before the expiration the certificates should be renewed; for example 360h.
+	// Default: the cert-manager default (1/3 of the duration)
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	RenewBefore string `json:"renew_before,omitempty"`
+}
+
+// InternalTLS defines the certificates used by pulpcore-api and pulpcore-content
+type InternalTLS struct {
+
+	// Serve pulpcore-api and pulpcore-content over HTTPS.
+	// Default: false
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Name of the Secret with the CA certificate and key (tls.crt and tls.key) used to sign the
+	// services certificate.
+	// If not provided, the certificate will be issued by tls.issuer_ref (if defined) or signed by
+	// a self-signed CA created by the operator.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret","urn:alm:descriptor:com.tectonic.ui:advanced"}
+	CASecret string `json:"ca_secret,omitempty"`
+}
+
+// NetworkPolicies defines the NetworkPolicies provisioned for each Pulp component
+type NetworkPolicies struct {
+
+	// Provision a NetworkPolicy for each Pulp component allowing only the traffic
+	// required by Pulp.
+	// Default: false
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Selects the namespaces of the ingress controller (or Gateway, Route router) allowed
+	// to reach pulp-web, pulpcore-api and pulpcore-content.
+	// Required in non-OpenShift clusters. If not provided in OpenShift clusters, the router
+	// namespaces (network.openshift.io/policy-group: ingress) will be allowed.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	IngressNamespaceSelector *metav1.LabelSelector `json:"ingress_namespace_selector,omitempty"`
+
+	// Selects the ingress controller pods (from the namespaces selected by ingress_namespace_selector)
+	// allowed to reach pulp-web, pulpcore-api and pulpcore-content.
+	// If not provided, all pods from the selected namespaces will be allowed.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	IngressPodSelector *metav1.LabelSelector `json:"ingress_pod_selector,omitempty"`
+
+	// Selects the namespaces allowed to scrape the telemetry metrics.
+	// If not provided, all namespaces will be allowed.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	TelemetryNamespaceSelector *metav1.LabelSelector `json:"telemetry_namespace_selector,omitempty"`
+}
+
+// IssuerRef is a reference to a cert-manager Issuer or ClusterIssuer
+type IssuerRef struct {
+
+	// Name of the Issuer.
+	Name string `json:"name"`
+
+	// Kind of the Issuer.
+	// Default: "Issuer"
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum:=Issuer;ClusterIssuer
+	Kind string `json:"kind,omitempty"`
+
+	// Group of the Issuer (for external issuers).
+	// Default: "cert-manager.io"
+	// +kubebuilder:validation:Optional
+	Group string `json:"group,omitempty"`
+}
+
+// Gateway defines the Gateway API resources used to expose Pulp when ingress_type is gateway
+type Gateway struct {
+
+	// Name of the Gateway the HTTPRoutes will be attached to.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Name string `json:"name,omitempty"`
+
+	// Namespace of the Gateway.
+	// Default: the namespace of Pulp CR
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the Gateway listener the HTTPRoutes will be attached to.
+	// Default: "" (all listeners from the Gateway)
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text","urn:alm:descriptor:com.tectonic.ui:advanced"}
+	SectionName string `json:"section_name,omitempty"`
+
+	// Scheme used by clients to reach the Gateway listener (used to define CONTENT_ORIGIN and TOKEN_SERVER).
+	// Default: "https"
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum:=http;https
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:https","urn:alm:descriptor:com.tectonic.ui:select:http","urn:alm:descriptor:com.tectonic.ui:advanced"}
+	Scheme string `json:"scheme,omitempty"`
+
+	// Annotations for the HTTPRoutes
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Labels for the HTTPRoutes
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 // PulpStatus defines the observed state of Pulp
@@ -928,6 +1447,8 @@ type PulpStatus struct {
 	ObjectStorageAzureSecret string `json:"object_storage_azure_secret,omitempty"`
 	// The secret for S3 compliant object storage configuration.
 	ObjectStorageS3Secret string `json:"object_storage_s3_secret,omitempty"`
+	// The secret for Google Cloud Storage object storage configuration.
+	ObjectStorageGCSSecret string `json:"object_storage_gcs_secret,omitempty"`
 	// Secret where the Fernet symmetric encryption key is stored.
 	DBFieldsEncryptionSecret string `json:"db_fields_encryption_secret,omitempty"`
 	// Name of pulp image deployed.
@@ -936,6 +1457,12 @@ type PulpStatus struct {
 	IngressType string `json:"ingress_type,omitempty"`
 	// IngressClassName is used to inform the operator which ingressclass should be used to provision the ingress.
 	IngressClassName string `json:"ingress_class_name,omitempty"`
+	// Ingress controller configured by the operator.
+	IngressController string `json:"ingress_controller,omitempty"`
+	// OpenID provider issuer validated by the operator.
+	OIDCIssuer string `json:"oidc_issuer,omitempty"`
+	// Hash of the ldap configuration verified by the connectivity test Job.
+	LDAPConfigHash string `json:"ldap_config_hash,omitempty"`
 	// Secret where the container token certificates are stored.
 	ContainerTokenSecret string `json:"container_token_secret,omitempty"`
 	// Secret where the administrator password can be found
@@ -954,6 +1481,164 @@ type PulpStatus struct {
 	ManagedCacheEnabled bool `json:"managed_cache_enabled,omitempty"`
 	// Type of storage in use by pulpcore pods
 	StorageType string `json:"storage_type,omitempty"`
+	// Deployment mode of the cache provisioned by pulp-operator
+	CacheMode string `json:"cache_mode,omitempty"`
+	// Name of the PVC used by pulpcore pods
+	FileStoragePVC string `json:"file_storage_pvc,omitempty"`
+	// Progress of the copy of the artifacts between storage backends
+	StorageMigration *StorageMigrationStatus `json:"storage_migration,omitempty"`
+	// Last sample of the file storage usage
+	FileStorageUsage *FileStorageUsageStatus `json:"file_storage_usage,omitempty"`
+	// Last rotation of the admin password
+	AdminPasswordRotation *SecretRotationStatus `json:"admin_password_rotation,omitempty"`
+	// Last rotation of the container token key pair
+	ContainerTokenRotation *SecretRotationStatus `json:"container_token_rotation,omitempty"`
+	// Progress of the database fields encryption key rotation
+	DBFieldsEncryptionRotation *DBFieldsEncryptionRotationStatus `json:"db_fields_encryption_rotation,omitempty"`
+}
+
+// SecretsStore defines the SecretProviderClasses used instead of the credentials from the Secrets.
+// Each SecretProviderClass should provide the objects (files) with the same name of the Secret keys
+// they replace. The other (non-sensitive) keys are still read from the Secrets.
+type SecretsStore struct {
+
+	// SecretProviderClass with the POSTGRES_PASSWORD object of the external database (database.external_db_secret).
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	ExternalDBSecret string `json:"external_db_secret,omitempty"`
+
+	// SecretProviderClass with the s3-access-key-id and s3-secret-access-key objects (object_storage_s3_secret).
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	ObjectStorageS3Secret string `json:"object_storage_s3_secret,omitempty"`
+
+	// SecretProviderClass with the azure-account-key object (object_storage_azure_secret).
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	ObjectStorageAzureSecret string `json:"object_storage_azure_secret,omitempty"`
+
+	// SecretProviderClass with the gcs-credentials object (object_storage_gcs_secret).
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	ObjectStorageGCSSecret string `json:"object_storage_gcs_secret,omitempty"`
+
+	// SecretProviderClass with the REDIS_PASSWORD object of the external cache (cache.external_cache_secret).
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	ExternalCacheSecret string `json:"external_cache_secret,omitempty"`
+
+	// SecretProviderClass with the social_auth_keycloak_secret object (sso_secret).
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	SSOSecret string `json:"sso_secret,omitempty"`
+
+	// SecretProviderClass with the bind_password object of the ldap server
+	// (ldap.bind_password_secret or the auth_ldap_bind_password key from ldap.config).
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	LDAPBindPasswordSecret string `json:"ldap_bind_password_secret,omitempty"`
+
+	// SecretProviderClass with the client_secret object of the OpenID Connect client (oidc.client_secret).
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	OIDCClientSecret string `json:"oidc_client_secret,omitempty"`
+
+	// SecretProviderClass with the signing_service.gpg object (signing_secret).
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	SigningSecret string `json:"signing_secret,omitempty"`
+
+	// Fingerprint of the gpg key provided by the signing_secret SecretProviderClass.
+	// Required with signing_secret because the operator does not read the key.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	SigningKeyFingerprint string `json:"signing_key_fingerprint,omitempty"`
+}
+
+// SecretRotation defines the rotation policies of the admin password, of the container token key pair
+// and of the database fields encryption key
+type SecretRotation struct {
+
+	// Rotation policy of the admin_password_secret.
+	// +kubebuilder:validation:Optional
+	AdminPassword RotationPolicy `json:"admin_password,omitempty"`
+
+	// Rotation policy of the container_token_secret key pair.
+	// pulp_container verifies the tokens with a single public key, so the tokens signed with
+	// the previous key are rejected after the rotation and the clients request a new one.
+	// +kubebuilder:validation:Optional
+	ContainerToken RotationPolicy `json:"container_token,omitempty"`
+
+	// Rotation policy of the db_fields_encryption_secret key.
+	// A new key is added to the Secret, the pulpcore pods are restarted, the encrypted fields
+	// are re-encrypted with the new key (pulpcore-manager rotate-db-key) and the previous key is removed.
+	// +kubebuilder:validation:Optional
+	DBFieldsEncryption RotationPolicy `json:"db_fields_encryption,omitempty"`
+}
+
+// RotationPolicy defines how often a Secret is rotated
+type RotationPolicy struct {
+
+	// Interval between the rotations; for example 720h.
+	// If not provided, the Secret is only rotated through the rotation annotation.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern:=`^([0-9]+(\.[0-9]+)?(ms|s|m|h))+$`
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	Interval string `json:"interval,omitempty"`
+}
+
+// SecretRotationStatus defines the last rotation of a Secret
+type SecretRotationStatus struct {
+	// Time of the last rotation
+	LastRotationTime *metav1.Time `json:"last_rotation_time,omitempty"`
+	// Value of the rotation annotation handled by the last rotation
+	Trigger string `json:"trigger,omitempty"`
+}
+
+// DBFieldsEncryptionRotationStatus defines the observed state of the database fields encryption key rotation
+type DBFieldsEncryptionRotationStatus struct {
+	SecretRotationStatus `json:",inline"`
+	// Current phase of the rotation (RollingOut, Rotating, Completed or Failed)
+	Phase string `json:"phase,omitempty"`
+	// Name of the Job re-encrypting the database fields
+	Job string `json:"job,omitempty"`
+	// Time the rotation started
+	StartTime *metav1.Time `json:"start_time,omitempty"`
+}
+
+// FileStorageUsageStatus defines the observed usage of the file storage volume
+type FileStorageUsageStatus struct {
+	// Size of the volume
+	Capacity string `json:"capacity,omitempty"`
+	// Space in use
+	Used string `json:"used,omitempty"`
+	// Free space
+	Available string `json:"available,omitempty"`
+	// Percentage of the volume in use
+	UsedPercent int32 `json:"used_percent,omitempty"`
+	// Time of the last sample
+	LastSampleTime *metav1.Time `json:"last_sample_time,omitempty"`
+}
+
+// StorageMigrationStatus defines the observed state of the artifacts migration
+// between storage backends
+type StorageMigrationStatus struct {
+	// Current phase of the migration (Maintenance, Copying, Completed, Failed or Canceled)
+	Phase string `json:"phase,omitempty"`
+	// Storage type the artifacts are copied from
+	Source string `json:"source,omitempty"`
+	// Storage type the artifacts are copied to
+	Destination string `json:"destination,omitempty"`
+	// Object storage Secret or PVC the artifacts are copied from
+	SourceReference string `json:"source_reference,omitempty"`
+	// Name of the Job copying the artifacts
+	Job string `json:"job,omitempty"`
+	// Last progress report from the Job
+	Progress string `json:"progress,omitempty"`
+	// Time the migration started
+	StartTime *metav1.Time `json:"start_time,omitempty"`
+	// Time the migration finished
+	CompletionTime *metav1.Time `json:"completion_time,omitempty"`
 }
 
 // +kubebuilder:object:root=true
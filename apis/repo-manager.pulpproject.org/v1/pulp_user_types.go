@@ -0,0 +1,112 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PulpUserSpec defines the desired state of PulpUser
+type PulpUserSpec struct {
+
+	// Name of Pulp CR in which the user is managed
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DeploymentName string `json:"deployment_name"`
+
+	// Username of the user
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Username string `json:"username"`
+
+	// First name of the user
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	FirstName string `json:"first_name,omitempty"`
+
+	// Last name of the user
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	LastName string `json:"last_name,omitempty"`
+
+	// Email of the user
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Email string `json:"email,omitempty"`
+
+	// Designates whether the user can log into the Django admin site.
+	// Default: false
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	IsStaff bool `json:"is_staff,omitempty"`
+
+	// Designates whether the user should be treated as active (unselect it instead of removing the user).
+	// Default: true
+	// +kubebuilder:default:=true
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	IsActive *bool `json:"is_active,omitempty"`
+
+	// Secret with the password of the user (password key). The password is set when the user is
+	// created and whenever the Secret is modified. If not provided, the user can only authenticate
+	// through an external provider (ldap, keycloak, oidc, etc.).
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret"}
+	PasswordSecret string `json:"password_secret,omitempty"`
+
+	// Define if the user should be removed from Pulp when the CR is removed (Delete) or not (Retain).
+	// Default: Delete
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum:=Delete;Retain
+	// +kubebuilder:default:=Delete
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	DeletionPolicy DeletionPolicy `json:"deletion_policy,omitempty"`
+}
+
+// PulpUserStatus defines the observed state of PulpUser
+type PulpUserStatus struct {
+	PulpObjectStatus `json:",inline"`
+
+	// Hash of the password_secret content set in Pulp
+	PasswordHash string `json:"password_hash,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// PulpUser is the Schema for the pulpusers API
+type PulpUser struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PulpUserSpec   `json:"spec,omitempty"`
+	Status PulpUserStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PulpUserList contains a list of PulpUser
+type PulpUserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PulpUser `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PulpUser{}, &PulpUserList{})
+}
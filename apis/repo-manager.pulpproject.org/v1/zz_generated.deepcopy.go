@@ -23,6 +23,7 @@ package v1
 import (
 	corev1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -141,6 +142,12 @@ func (in *Cache) DeepCopyInto(out *Cache) {
 			(*out)[key] = val
 		}
 	}
+	in.Sentinel.DeepCopyInto(&out.Sentinel)
+	if in.PDB != nil {
+		in, out := &in.PDB, &out.PDB
+		*out = new(policyv1.PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Cache.
@@ -226,6 +233,26 @@ func (in *Content) DeepCopy() *Content {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DBFieldsEncryptionRotationStatus) DeepCopyInto(out *DBFieldsEncryptionRotationStatus) {
+	*out = *in
+	in.SecretRotationStatus.DeepCopyInto(&out.SecretRotationStatus)
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DBFieldsEncryptionRotationStatus.
+func (in *DBFieldsEncryptionRotationStatus) DeepCopy() *DBFieldsEncryptionRotationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DBFieldsEncryptionRotationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Database) DeepCopyInto(out *Database) {
 	*out = *in
@@ -281,9 +308,111 @@ func (in *Database) DeepCopy() *Database {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileStorageUsageStatus) DeepCopyInto(out *FileStorageUsageStatus) {
+	*out = *in
+	if in.LastSampleTime != nil {
+		in, out := &in.LastSampleTime, &out.LastSampleTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileStorageUsageStatus.
+func (in *FileStorageUsageStatus) DeepCopy() *FileStorageUsageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FileStorageUsageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Gateway) DeepCopyInto(out *Gateway) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Gateway.
+func (in *Gateway) DeepCopy() *Gateway {
+	if in == nil {
+		return nil
+	}
+	out := new(Gateway)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Hostname) DeepCopyInto(out *Hostname) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Hostname.
+func (in *Hostname) DeepCopy() *Hostname {
+	if in == nil {
+		return nil
+	}
+	out := new(Hostname)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InternalTLS) DeepCopyInto(out *InternalTLS) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalTLS.
+func (in *InternalTLS) DeepCopy() *InternalTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(InternalTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IssuerRef) DeepCopyInto(out *IssuerRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IssuerRef.
+func (in *IssuerRef) DeepCopy() *IssuerRef {
+	if in == nil {
+		return nil
+	}
+	out := new(IssuerRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LDAP) DeepCopyInto(out *LDAP) {
 	*out = *in
+	out.UserSearch = in.UserSearch
+	out.GroupSearch = in.GroupSearch
+	if in.UserAttrMap != nil {
+		in, out := &in.UserAttrMap, &out.UserAttrMap
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LDAP.
@@ -296,6 +425,155 @@ func (in *LDAP) DeepCopy() *LDAP {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LDAPSearch) DeepCopyInto(out *LDAPSearch) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LDAPSearch.
+func (in *LDAPSearch) DeepCopy() *LDAPSearch {
+	if in == nil {
+		return nil
+	}
+	out := new(LDAPSearch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkPolicies) DeepCopyInto(out *NetworkPolicies) {
+	*out = *in
+	if in.IngressNamespaceSelector != nil {
+		in, out := &in.IngressNamespaceSelector, &out.IngressNamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IngressPodSelector != nil {
+		in, out := &in.IngressPodSelector, &out.IngressPodSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TelemetryNamespaceSelector != nil {
+		in, out := &in.TelemetryNamespaceSelector, &out.TelemetryNamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkPolicies.
+func (in *NetworkPolicies) DeepCopy() *NetworkPolicies {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkPolicies)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Nginx) DeepCopyInto(out *Nginx) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.RateLimits != nil {
+		in, out := &in.RateLimits, &out.RateLimits
+		*out = make([]NginxRateLimit, len(*in))
+		copy(*out, *in)
+	}
+	if in.APIAllowList != nil {
+		in, out := &in.APIAllowList, &out.APIAllowList
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.APIDenyList != nil {
+		in, out := &in.APIDenyList, &out.APIDenyList
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Nginx.
+func (in *Nginx) DeepCopy() *Nginx {
+	if in == nil {
+		return nil
+	}
+	out := new(Nginx)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NginxRateLimit) DeepCopyInto(out *NginxRateLimit) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NginxRateLimit.
+func (in *NginxRateLimit) DeepCopy() *NginxRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(NginxRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDC) DeepCopyInto(out *OIDC) {
+	*out = *in
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClaimMappings != nil {
+		in, out := &in.ClaimMappings, &out.ClaimMappings
+		*out = make([]OIDCClaimMapping, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDC.
+func (in *OIDC) DeepCopy() *OIDC {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCClaimMapping) DeepCopyInto(out *OIDCClaimMapping) {
+	*out = *in
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCClaimMapping.
+func (in *OIDCClaimMapping) DeepCopy() *OIDCClaimMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCClaimMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Pulp) DeepCopyInto(out *Pulp) {
 	*out = *in
@@ -448,47 +726,58 @@ func (in *PulpContainer) DeepCopy() *PulpContainer {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PulpJob) DeepCopyInto(out *PulpJob) {
+func (in *PulpDistribution) DeepCopyInto(out *PulpDistribution) {
 	*out = *in
-	in.PulpContainer.DeepCopyInto(&out.PulpContainer)
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpJob.
-func (in *PulpJob) DeepCopy() *PulpJob {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpDistribution.
+func (in *PulpDistribution) DeepCopy() *PulpDistribution {
 	if in == nil {
 		return nil
 	}
-	out := new(PulpJob)
+	out := new(PulpDistribution)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PulpDistribution) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PulpList) DeepCopyInto(out *PulpList) {
+func (in *PulpDistributionList) DeepCopyInto(out *PulpDistributionList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Pulp, len(*in))
+		*out = make([]PulpDistribution, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpList.
-func (in *PulpList) DeepCopy() *PulpList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpDistributionList.
+func (in *PulpDistributionList) DeepCopy() *PulpDistributionList {
 	if in == nil {
 		return nil
 	}
-	out := new(PulpList)
+	out := new(PulpDistributionList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PulpList) DeepCopyObject() runtime.Object {
+func (in *PulpDistributionList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -496,26 +785,64 @@ func (in *PulpList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PulpRestore) DeepCopyInto(out *PulpRestore) {
+func (in *PulpDistributionSpec) DeepCopyInto(out *PulpDistributionSpec) {
+	*out = *in
+	if in.PluginFields != nil {
+		in, out := &in.PluginFields, &out.PluginFields
+		*out = make(map[string]apiextensionsv1.JSON, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpDistributionSpec.
+func (in *PulpDistributionSpec) DeepCopy() *PulpDistributionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpDistributionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpDistributionStatus) DeepCopyInto(out *PulpDistributionStatus) {
+	*out = *in
+	in.PulpObjectStatus.DeepCopyInto(&out.PulpObjectStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpDistributionStatus.
+func (in *PulpDistributionStatus) DeepCopy() *PulpDistributionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpDistributionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpDomain) DeepCopyInto(out *PulpDomain) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpRestore.
-func (in *PulpRestore) DeepCopy() *PulpRestore {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpDomain.
+func (in *PulpDomain) DeepCopy() *PulpDomain {
 	if in == nil {
 		return nil
 	}
-	out := new(PulpRestore)
+	out := new(PulpDomain)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PulpRestore) DeepCopyObject() runtime.Object {
+func (in *PulpDomain) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -523,31 +850,31 @@ func (in *PulpRestore) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PulpRestoreList) DeepCopyInto(out *PulpRestoreList) {
+func (in *PulpDomainList) DeepCopyInto(out *PulpDomainList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]PulpRestore, len(*in))
+		*out = make([]PulpDomain, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpRestoreList.
-func (in *PulpRestoreList) DeepCopy() *PulpRestoreList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpDomainList.
+func (in *PulpDomainList) DeepCopy() *PulpDomainList {
 	if in == nil {
 		return nil
 	}
-	out := new(PulpRestoreList)
+	out := new(PulpDomainList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PulpRestoreList) DeepCopyObject() runtime.Object {
+func (in *PulpDomainList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -555,16 +882,517 @@ func (in *PulpRestoreList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PulpRestoreSpec) DeepCopyInto(out *PulpRestoreSpec) {
+func (in *PulpDomainSpec) DeepCopyInto(out *PulpDomainSpec) {
 	*out = *in
+	if in.StorageSettings != nil {
+		in, out := &in.StorageSettings, &out.StorageSettings
+		*out = make(map[string]apiextensionsv1.JSON, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.RedirectToObjectStorage != nil {
+		in, out := &in.RedirectToObjectStorage, &out.RedirectToObjectStorage
+		*out = new(bool)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpRestoreSpec.
-func (in *PulpRestoreSpec) DeepCopy() *PulpRestoreSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpDomainSpec.
+func (in *PulpDomainSpec) DeepCopy() *PulpDomainSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(PulpRestoreSpec)
+	out := new(PulpDomainSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpDomainStatus) DeepCopyInto(out *PulpDomainStatus) {
+	*out = *in
+	in.PulpObjectStatus.DeepCopyInto(&out.PulpObjectStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpDomainStatus.
+func (in *PulpDomainStatus) DeepCopy() *PulpDomainStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpDomainStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpGroup) DeepCopyInto(out *PulpGroup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpGroup.
+func (in *PulpGroup) DeepCopy() *PulpGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PulpGroup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpGroupList) DeepCopyInto(out *PulpGroupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PulpGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpGroupList.
+func (in *PulpGroupList) DeepCopy() *PulpGroupList {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpGroupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PulpGroupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpGroupSpec) DeepCopyInto(out *PulpGroupSpec) {
+	*out = *in
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpGroupSpec.
+func (in *PulpGroupSpec) DeepCopy() *PulpGroupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpGroupStatus) DeepCopyInto(out *PulpGroupStatus) {
+	*out = *in
+	in.PulpObjectStatus.DeepCopyInto(&out.PulpObjectStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpGroupStatus.
+func (in *PulpGroupStatus) DeepCopy() *PulpGroupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpGroupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpJob) DeepCopyInto(out *PulpJob) {
+	*out = *in
+	in.PulpContainer.DeepCopyInto(&out.PulpContainer)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpJob.
+func (in *PulpJob) DeepCopy() *PulpJob {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpList) DeepCopyInto(out *PulpList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Pulp, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpList.
+func (in *PulpList) DeepCopy() *PulpList {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PulpList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpObjectStatus) DeepCopyInto(out *PulpObjectStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastTask != nil {
+		in, out := &in.LastTask, &out.LastTask
+		*out = new(PulpTaskStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpObjectStatus.
+func (in *PulpObjectStatus) DeepCopy() *PulpObjectStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpObjectStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpRemote) DeepCopyInto(out *PulpRemote) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpRemote.
+func (in *PulpRemote) DeepCopy() *PulpRemote {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpRemote)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PulpRemote) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpRemoteList) DeepCopyInto(out *PulpRemoteList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PulpRemote, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpRemoteList.
+func (in *PulpRemoteList) DeepCopy() *PulpRemoteList {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpRemoteList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PulpRemoteList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpRemoteSpec) DeepCopyInto(out *PulpRemoteSpec) {
+	*out = *in
+	if in.TLSValidation != nil {
+		in, out := &in.TLSValidation, &out.TLSValidation
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PluginFields != nil {
+		in, out := &in.PluginFields, &out.PluginFields
+		*out = make(map[string]apiextensionsv1.JSON, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpRemoteSpec.
+func (in *PulpRemoteSpec) DeepCopy() *PulpRemoteSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpRemoteSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpRemoteStatus) DeepCopyInto(out *PulpRemoteStatus) {
+	*out = *in
+	in.PulpObjectStatus.DeepCopyInto(&out.PulpObjectStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpRemoteStatus.
+func (in *PulpRemoteStatus) DeepCopy() *PulpRemoteStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpRemoteStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpRepository) DeepCopyInto(out *PulpRepository) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpRepository.
+func (in *PulpRepository) DeepCopy() *PulpRepository {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpRepository)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PulpRepository) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpRepositoryList) DeepCopyInto(out *PulpRepositoryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PulpRepository, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpRepositoryList.
+func (in *PulpRepositoryList) DeepCopy() *PulpRepositoryList {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpRepositoryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PulpRepositoryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpRepositorySpec) DeepCopyInto(out *PulpRepositorySpec) {
+	*out = *in
+	if in.RetainRepoVersions != nil {
+		in, out := &in.RetainRepoVersions, &out.RetainRepoVersions
+		*out = new(int)
+		**out = **in
+	}
+	if in.PluginFields != nil {
+		in, out := &in.PluginFields, &out.PluginFields
+		*out = make(map[string]apiextensionsv1.JSON, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpRepositorySpec.
+func (in *PulpRepositorySpec) DeepCopy() *PulpRepositorySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpRepositorySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpRepositoryStatus) DeepCopyInto(out *PulpRepositoryStatus) {
+	*out = *in
+	in.PulpObjectStatus.DeepCopyInto(&out.PulpObjectStatus)
+	if in.LastSync != nil {
+		in, out := &in.LastSync, &out.LastSync
+		*out = new(PulpRepositorySyncStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpRepositoryStatus.
+func (in *PulpRepositoryStatus) DeepCopy() *PulpRepositoryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpRepositoryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpRepositorySyncStatus) DeepCopyInto(out *PulpRepositorySyncStatus) {
+	*out = *in
+	in.PulpTaskStatus.DeepCopyInto(&out.PulpTaskStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpRepositorySyncStatus.
+func (in *PulpRepositorySyncStatus) DeepCopy() *PulpRepositorySyncStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpRepositorySyncStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpRestore) DeepCopyInto(out *PulpRestore) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpRestore.
+func (in *PulpRestore) DeepCopy() *PulpRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PulpRestore) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpRestoreList) DeepCopyInto(out *PulpRestoreList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PulpRestore, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpRestoreList.
+func (in *PulpRestoreList) DeepCopy() *PulpRestoreList {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpRestoreList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PulpRestoreList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpRestoreSpec) DeepCopyInto(out *PulpRestoreSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpRestoreSpec.
+func (in *PulpRestoreSpec) DeepCopy() *PulpRestoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpRestoreSpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -572,119 +1400,665 @@ func (in *PulpRestoreSpec) DeepCopy() *PulpRestoreSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PulpRestoreStatus) DeepCopyInto(out *PulpRestoreStatus) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpRestoreStatus.
+func (in *PulpRestoreStatus) DeepCopy() *PulpRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpRoleBinding) DeepCopyInto(out *PulpRoleBinding) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpRoleBinding.
+func (in *PulpRoleBinding) DeepCopy() *PulpRoleBinding {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpRoleBinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PulpRoleBinding) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpRoleBindingList) DeepCopyInto(out *PulpRoleBindingList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PulpRoleBinding, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpRoleBindingList.
+func (in *PulpRoleBindingList) DeepCopy() *PulpRoleBindingList {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpRoleBindingList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PulpRoleBindingList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpRoleBindingSpec) DeepCopyInto(out *PulpRoleBindingSpec) {
+	*out = *in
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpRoleBindingSpec.
+func (in *PulpRoleBindingSpec) DeepCopy() *PulpRoleBindingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpRoleBindingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpRoleBindingStatus) DeepCopyInto(out *PulpRoleBindingStatus) {
+	*out = *in
+	in.PulpObjectStatus.DeepCopyInto(&out.PulpObjectStatus)
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpRoleBindingStatus.
+func (in *PulpRoleBindingStatus) DeepCopy() *PulpRoleBindingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpRoleBindingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpScheduledSyncStatus) DeepCopyInto(out *PulpScheduledSyncStatus) {
+	*out = *in
+	in.PulpTaskStatus.DeepCopyInto(&out.PulpTaskStatus)
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpScheduledSyncStatus.
+func (in *PulpScheduledSyncStatus) DeepCopy() *PulpScheduledSyncStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpScheduledSyncStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpSpec) DeepCopyInto(out *PulpSpec) {
+	*out = *in
+	if in.FileStorageUsageThreshold != nil {
+		in, out := &in.FileStorageUsageThreshold, &out.FileStorageUsageThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	in.Gateway.DeepCopyInto(&out.Gateway)
+	if in.IngressAnnotations != nil {
+		in, out := &in.IngressAnnotations, &out.IngressAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.RouteLabels != nil {
+		in, out := &in.RouteLabels, &out.RouteLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.RouteAnnotations != nil {
+		in, out := &in.RouteAnnotations, &out.RouteAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.HostAliases != nil {
+		in, out := &in.HostAliases, &out.HostAliases
+		*out = make([]Hostname, len(*in))
+		copy(*out, *in)
+	}
+	if in.ContentHost != nil {
+		in, out := &in.ContentHost, &out.ContentHost
+		*out = new(Hostname)
+		**out = **in
+	}
+	in.TLS.DeepCopyInto(&out.TLS)
+	out.InternalTLS = in.InternalTLS
+	in.NetworkPolicies.DeepCopyInto(&out.NetworkPolicies)
+	in.Api.DeepCopyInto(&out.Api)
+	in.Database.DeepCopyInto(&out.Database)
+	in.Content.DeepCopyInto(&out.Content)
+	in.Worker.DeepCopyInto(&out.Worker)
+	in.Web.DeepCopyInto(&out.Web)
+	in.Cache.DeepCopyInto(&out.Cache)
+	out.SecretRotation = in.SecretRotation
+	out.SecretsStore = in.SecretsStore
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SAAnnotations != nil {
+		in, out := &in.SAAnnotations, &out.SAAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SALabels != nil {
+		in, out := &in.SALabels, &out.SALabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.AdminPasswordJob.DeepCopyInto(&out.AdminPasswordJob)
+	in.MigrationJob.DeepCopyInto(&out.MigrationJob)
+	in.SigningJob.DeepCopyInto(&out.SigningJob)
+	in.StorageMigrationJob.DeepCopyInto(&out.StorageMigrationJob)
+	if in.AllowedContentChecksums != nil {
+		in, out := &in.AllowedContentChecksums, &out.AllowedContentChecksums
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Telemetry.DeepCopyInto(&out.Telemetry)
+	in.LDAP.DeepCopyInto(&out.LDAP)
+	in.OIDC.DeepCopyInto(&out.OIDC)
+	if in.IPv6Disabled != nil {
+		in, out := &in.IPv6Disabled, &out.IPv6Disabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpSpec.
+func (in *PulpSpec) DeepCopy() *PulpSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpStatus) DeepCopyInto(out *PulpStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StorageMigration != nil {
+		in, out := &in.StorageMigration, &out.StorageMigration
+		*out = new(StorageMigrationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FileStorageUsage != nil {
+		in, out := &in.FileStorageUsage, &out.FileStorageUsage
+		*out = new(FileStorageUsageStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdminPasswordRotation != nil {
+		in, out := &in.AdminPasswordRotation, &out.AdminPasswordRotation
+		*out = new(SecretRotationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ContainerTokenRotation != nil {
+		in, out := &in.ContainerTokenRotation, &out.ContainerTokenRotation
+		*out = new(SecretRotationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DBFieldsEncryptionRotation != nil {
+		in, out := &in.DBFieldsEncryptionRotation, &out.DBFieldsEncryptionRotation
+		*out = new(DBFieldsEncryptionRotationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpStatus.
+func (in *PulpStatus) DeepCopy() *PulpStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpSyncSchedule) DeepCopyInto(out *PulpSyncSchedule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpSyncSchedule.
+func (in *PulpSyncSchedule) DeepCopy() *PulpSyncSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpSyncSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PulpSyncSchedule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpSyncScheduleList) DeepCopyInto(out *PulpSyncScheduleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PulpSyncSchedule, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpRestoreStatus.
-func (in *PulpRestoreStatus) DeepCopy() *PulpRestoreStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpSyncScheduleList.
+func (in *PulpSyncScheduleList) DeepCopy() *PulpSyncScheduleList {
 	if in == nil {
 		return nil
 	}
-	out := new(PulpRestoreStatus)
+	out := new(PulpSyncScheduleList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PulpSyncScheduleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PulpSpec) DeepCopyInto(out *PulpSpec) {
+func (in *PulpSyncScheduleSpec) DeepCopyInto(out *PulpSyncScheduleSpec) {
 	*out = *in
-	if in.IngressAnnotations != nil {
-		in, out := &in.IngressAnnotations, &out.IngressAnnotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.TimeZone != nil {
+		in, out := &in.TimeZone, &out.TimeZone
+		*out = new(string)
+		**out = **in
 	}
-	if in.RouteLabels != nil {
-		in, out := &in.RouteLabels, &out.RouteLabels
-		*out = make(map[string]string, len(*in))
+	if in.SyncOptions != nil {
+		in, out := &in.SyncOptions, &out.SyncOptions
+		*out = make(map[string]apiextensionsv1.JSON, len(*in))
 		for key, val := range *in {
-			(*out)[key] = val
+			(*out)[key] = *val.DeepCopy()
 		}
 	}
-	if in.RouteAnnotations != nil {
-		in, out := &in.RouteAnnotations, &out.RouteAnnotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
+	in.ResourceRequirements.DeepCopyInto(&out.ResourceRequirements)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpSyncScheduleSpec.
+func (in *PulpSyncScheduleSpec) DeepCopy() *PulpSyncScheduleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpSyncScheduleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpSyncScheduleStatus) DeepCopyInto(out *PulpSyncScheduleStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	in.Api.DeepCopyInto(&out.Api)
-	in.Database.DeepCopyInto(&out.Database)
-	in.Content.DeepCopyInto(&out.Content)
-	in.Worker.DeepCopyInto(&out.Worker)
-	in.Web.DeepCopyInto(&out.Web)
-	in.Cache.DeepCopyInto(&out.Cache)
-	if in.ImagePullSecrets != nil {
-		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
 	}
-	if in.SAAnnotations != nil {
-		in, out := &in.SAAnnotations, &out.SAAnnotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.LastSync != nil {
+		in, out := &in.LastSync, &out.LastSync
+		*out = new(PulpScheduledSyncStatus)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.SALabels != nil {
-		in, out := &in.SALabels, &out.SALabels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpSyncScheduleStatus.
+func (in *PulpSyncScheduleStatus) DeepCopy() *PulpSyncScheduleStatus {
+	if in == nil {
+		return nil
 	}
-	in.AdminPasswordJob.DeepCopyInto(&out.AdminPasswordJob)
-	in.MigrationJob.DeepCopyInto(&out.MigrationJob)
-	in.SigningJob.DeepCopyInto(&out.SigningJob)
-	if in.AllowedContentChecksums != nil {
-		in, out := &in.AllowedContentChecksums, &out.AllowedContentChecksums
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	out := new(PulpSyncScheduleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpTaskStatus) DeepCopyInto(out *PulpTaskStatus) {
+	*out = *in
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
 	}
-	in.Telemetry.DeepCopyInto(&out.Telemetry)
-	out.LDAP = in.LDAP
-	if in.IPv6Disabled != nil {
-		in, out := &in.IPv6Disabled, &out.IPv6Disabled
-		*out = new(bool)
-		**out = **in
+	if in.FinishedAt != nil {
+		in, out := &in.FinishedAt, &out.FinishedAt
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpSpec.
-func (in *PulpSpec) DeepCopy() *PulpSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpTaskStatus.
+func (in *PulpTaskStatus) DeepCopy() *PulpTaskStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(PulpSpec)
+	out := new(PulpTaskStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PulpStatus) DeepCopyInto(out *PulpStatus) {
+func (in *PulpUser) DeepCopyInto(out *PulpUser) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpUser.
+func (in *PulpUser) DeepCopy() *PulpUser {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpUser)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PulpUser) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpUserList) DeepCopyInto(out *PulpUserList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PulpUser, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpStatus.
-func (in *PulpStatus) DeepCopy() *PulpStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpUserList.
+func (in *PulpUserList) DeepCopy() *PulpUserList {
 	if in == nil {
 		return nil
 	}
-	out := new(PulpStatus)
+	out := new(PulpUserList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PulpUserList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpUserSpec) DeepCopyInto(out *PulpUserSpec) {
+	*out = *in
+	if in.IsActive != nil {
+		in, out := &in.IsActive, &out.IsActive
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpUserSpec.
+func (in *PulpUserSpec) DeepCopy() *PulpUserSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpUserSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PulpUserStatus) DeepCopyInto(out *PulpUserStatus) {
+	*out = *in
+	in.PulpObjectStatus.DeepCopyInto(&out.PulpObjectStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PulpUserStatus.
+func (in *PulpUserStatus) DeepCopy() *PulpUserStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PulpUserStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RotationPolicy) DeepCopyInto(out *RotationPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RotationPolicy.
+func (in *RotationPolicy) DeepCopy() *RotationPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RotationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretRotation) DeepCopyInto(out *SecretRotation) {
+	*out = *in
+	out.AdminPassword = in.AdminPassword
+	out.ContainerToken = in.ContainerToken
+	out.DBFieldsEncryption = in.DBFieldsEncryption
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretRotation.
+func (in *SecretRotation) DeepCopy() *SecretRotation {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretRotation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretRotationStatus) DeepCopyInto(out *SecretRotationStatus) {
+	*out = *in
+	if in.LastRotationTime != nil {
+		in, out := &in.LastRotationTime, &out.LastRotationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretRotationStatus.
+func (in *SecretRotationStatus) DeepCopy() *SecretRotationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretRotationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretsStore) DeepCopyInto(out *SecretsStore) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretsStore.
+func (in *SecretsStore) DeepCopy() *SecretsStore {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretsStore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Sentinel) DeepCopyInto(out *Sentinel) {
+	*out = *in
+	in.ResourceRequirements.DeepCopyInto(&out.ResourceRequirements)
+	if in.PDB != nil {
+		in, out := &in.PDB, &out.PDB
+		*out = new(policyv1.PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Sentinel.
+func (in *Sentinel) DeepCopy() *Sentinel {
+	if in == nil {
+		return nil
+	}
+	out := new(Sentinel)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageMigrationStatus) DeepCopyInto(out *StorageMigrationStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageMigrationStatus.
+func (in *StorageMigrationStatus) DeepCopy() *StorageMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLS) DeepCopyInto(out *TLS) {
+	*out = *in
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(IssuerRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLS.
+func (in *TLS) DeepCopy() *TLS {
+	if in == nil {
+		return nil
+	}
+	out := new(TLS)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -753,6 +2127,7 @@ func (in *Web) DeepCopyInto(out *Web) {
 			(*out)[key] = val
 		}
 	}
+	in.Nginx.DeepCopyInto(&out.Nginx)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Web.
@@ -0,0 +1,258 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager_access
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers/pulpapi/pulpapitest"
+	"github.com/pulp/pulp-operator/controllers/pulpobject"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// newTestClients returns the pulpapitest clients with the status subresource of the access CRs
+func newTestClients(t *testing.T, objs ...client.Object) (client.Client, *pulpapitest.Server, pulpobject.ClientFunc) {
+	return pulpapitest.NewClients(t, []client.Object{&pulpv1.PulpUser{}, &pulpv1.PulpGroup{}, &pulpv1.PulpRoleBinding{}}, objs...)
+}
+
+func TestPulpUser(t *testing.T) {
+	user := &pulpv1.PulpUser{
+		ObjectMeta: metav1.ObjectMeta{Name: "alice", Namespace: pulpapitest.Namespace},
+		Spec: pulpv1.PulpUserSpec{
+			DeploymentName: pulpapitest.PulpName,
+			Username:       "alice",
+			Email:          "alice@example.com",
+			PasswordSecret: "alice-password",
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "alice-password", Namespace: pulpapitest.Namespace},
+		Data:       map[string][]byte{"password": []byte("first")},
+	}
+	k8sClient, stub, newPulpClient := newTestClients(t, user, secret)
+	r := &PulpUserReconciler{Client: k8sClient, RawLogger: logr.Discard(), NewPulpClient: newPulpClient}
+	conditions := func() []metav1.Condition { return user.Status.Conditions }
+
+	// create
+	pulpapitest.ReconcileReady(t, r, k8sClient, user, conditions)
+	if len(stub.Users) != 1 {
+		t.Fatalf("expected 1 user in Pulp, got %v", len(stub.Users))
+	}
+	created := stub.Users[1]
+	if created.Username != "alice" || created.Email != "alice@example.com" || !created.IsActive || created.Password != "first" {
+		t.Errorf("unexpected user created in Pulp: %+v", created)
+	}
+	if user.Status.PulpHref != created.Href || !slices.Contains(user.Finalizers, pulpobject.Finalizer) {
+		t.Errorf("unexpected status or finalizers: %+v %v", user.Status, user.Finalizers)
+	}
+
+	// no modifications: only the lookup is sent
+	patches := len(stub.RequestsWith("PATCH"))
+	pulpapitest.ReconcileReady(t, r, k8sClient, user, conditions)
+	if len(stub.RequestsWith("PATCH")) != patches {
+		t.Errorf("expected no PATCH requests for an unmodified user")
+	}
+
+	// drift correction
+	stub.Users[1].Email = "mallory@example.com"
+	stub.Users[1].IsStaff = true
+	pulpapitest.ReconcileReady(t, r, k8sClient, user, conditions)
+	if stub.Users[1].Email != "alice@example.com" || stub.Users[1].IsStaff {
+		t.Errorf("modifications made through the Pulp API were not reverted: %+v", stub.Users[1])
+	}
+
+	// password rotation
+	secret.Data["password"] = []byte("second")
+	if err := k8sClient.Update(context.TODO(), secret); err != nil {
+		t.Fatal(err)
+	}
+	pulpapitest.ReconcileReady(t, r, k8sClient, user, conditions)
+	if stub.Users[1].Password != "second" {
+		t.Errorf("expected the password from the modified Secret, got %q", stub.Users[1].Password)
+	}
+
+	// deletion
+	if err := k8sClient.Delete(context.TODO(), user); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(context.TODO(), pulpapitest.Request("alice")); err != nil {
+		t.Fatal(err)
+	}
+	if len(stub.Users) != 0 {
+		t.Errorf("expected the user to be removed from Pulp")
+	}
+	if err := k8sClient.Get(context.TODO(), pulpapitest.Request("alice").NamespacedName, user); err == nil {
+		t.Errorf("expected the PulpUser to be removed after the finalizer")
+	}
+}
+
+func TestPulpUserRetain(t *testing.T) {
+	user := &pulpv1.PulpUser{
+		ObjectMeta: metav1.ObjectMeta{Name: "bob", Namespace: pulpapitest.Namespace},
+		Spec:       pulpv1.PulpUserSpec{DeploymentName: pulpapitest.PulpName, Username: "bob", DeletionPolicy: pulpv1.DeletionPolicyRetain},
+	}
+	k8sClient, stub, newPulpClient := newTestClients(t, user)
+	r := &PulpUserReconciler{Client: k8sClient, RawLogger: logr.Discard(), NewPulpClient: newPulpClient}
+
+	// an existing user is adopted instead of created
+	stub.AddUser("bob")
+	pulpapitest.ReconcileReady(t, r, k8sClient, user, func() []metav1.Condition { return user.Status.Conditions })
+	if len(stub.RequestsWith("POST")) != 0 {
+		t.Errorf("expected the existing user to be adopted")
+	}
+
+	if err := k8sClient.Delete(context.TODO(), user); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(context.TODO(), pulpapitest.Request("bob")); err != nil {
+		t.Fatal(err)
+	}
+	if len(stub.Users) != 1 {
+		t.Errorf("expected the user to be kept in Pulp")
+	}
+}
+
+func TestPulpUserWithoutPulp(t *testing.T) {
+	user := &pulpv1.PulpUser{
+		ObjectMeta: metav1.ObjectMeta{Name: "carol", Namespace: pulpapitest.Namespace},
+		Spec:       pulpv1.PulpUserSpec{DeploymentName: "missing-pulp", Username: "carol"},
+	}
+	k8sClient, _, newPulpClient := newTestClients(t, user)
+	r := &PulpUserReconciler{Client: k8sClient, RawLogger: logr.Discard(), NewPulpClient: newPulpClient}
+
+	result, err := r.Reconcile(context.TODO(), pulpapitest.Request("carol"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	k8sClient.Get(context.TODO(), pulpapitest.Request("carol").NamespacedName, user)
+	condition := v1.FindStatusCondition(user.Status.Conditions, pulpobject.ReadyCondition)
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != "PulpNotFound" {
+		t.Errorf("expected the PulpNotFound condition, got %+v", condition)
+	}
+	if result.RequeueAfter != pulpobject.RetryPeriod {
+		t.Errorf("expected requeue after %v, got %v", pulpobject.RetryPeriod, result.RequeueAfter)
+	}
+}
+
+func TestPulpGroup(t *testing.T) {
+	group := &pulpv1.PulpGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "admins", Namespace: pulpapitest.Namespace},
+		Spec:       pulpv1.PulpGroupSpec{DeploymentName: pulpapitest.PulpName, Name: "admins", Users: []string{"alice", "bob"}},
+	}
+	k8sClient, stub, newPulpClient := newTestClients(t, group)
+	r := &PulpGroupReconciler{Client: k8sClient, RawLogger: logr.Discard(), NewPulpClient: newPulpClient}
+	conditions := func() []metav1.Condition { return group.Status.Conditions }
+	alice, bob, mallory := stub.AddUser("alice"), stub.AddUser("bob"), stub.AddUser("mallory")
+
+	pulpapitest.ReconcileReady(t, r, k8sClient, group, conditions)
+	groupID := 4
+	if stub.Groups[groupID] == nil || stub.Groups[groupID].Name != "admins" {
+		t.Fatalf("expected the admins group in Pulp, got %+v", stub.Groups)
+	}
+	if !slices.Equal(stub.Members[groupID], []int{alice.ID, bob.ID}) {
+		t.Errorf("unexpected members: %v", stub.Members[groupID])
+	}
+
+	// members added through the Pulp API are removed and removed members are added back
+	stub.Members[groupID] = []int{alice.ID, mallory.ID}
+	pulpapitest.ReconcileReady(t, r, k8sClient, group, conditions)
+	if !slices.Equal(stub.Members[groupID], []int{alice.ID, bob.ID}) {
+		t.Errorf("membership drift was not corrected: %v", stub.Members[groupID])
+	}
+
+	// a user that does not exist in Pulp is reported in the condition
+	group.Spec.Users = append(group.Spec.Users, "dave")
+	if err := k8sClient.Update(context.TODO(), group); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(context.TODO(), pulpapitest.Request("admins")); err != nil {
+		t.Fatal(err)
+	}
+	k8sClient.Get(context.TODO(), pulpapitest.Request("admins").NamespacedName, group)
+	if condition := v1.FindStatusCondition(group.Status.Conditions, pulpobject.ReadyCondition); condition.Reason != "SyncFailed" {
+		t.Errorf("expected the SyncFailed condition, got %+v", condition)
+	}
+}
+
+func TestPulpRoleBinding(t *testing.T) {
+	binding := &pulpv1.PulpRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "task-viewers", Namespace: pulpapitest.Namespace},
+		Spec: pulpv1.PulpRoleBindingSpec{
+			DeploymentName: pulpapitest.PulpName,
+			Role:           "core.task_viewer",
+			Users:          []string{"alice", "bob"},
+			Groups:         []string{"admins"},
+		},
+	}
+	k8sClient, stub, newPulpClient := newTestClients(t, binding)
+	r := &PulpRoleBindingReconciler{Client: k8sClient, RawLogger: logr.Discard(), NewPulpClient: newPulpClient}
+	conditions := func() []metav1.Condition { return binding.Status.Conditions }
+	alice, bob, admins := stub.AddUser("alice"), stub.AddUser("bob"), stub.AddGroup("admins")
+
+	pulpapitest.ReconcileReady(t, r, k8sClient, binding, conditions)
+	for _, href := range []string{alice.Href, bob.Href, admins.Href} {
+		if len(stub.Roles[href]) != 1 || stub.Roles[href][0].Role != "core.task_viewer" || stub.Roles[href][0].ContentObject != nil {
+			t.Errorf("expected the model-level core.task_viewer role in %v, got %+v", href, stub.Roles[href])
+		}
+	}
+
+	// assignments removed through the Pulp API are recreated
+	stub.Roles[alice.Href] = nil
+	pulpapitest.ReconcileReady(t, r, k8sClient, binding, conditions)
+	if len(stub.Roles[alice.Href]) != 1 {
+		t.Errorf("removed assignment was not recreated")
+	}
+
+	// users removed from spec lose the role
+	binding.Spec.Users = []string{"alice"}
+	if err := k8sClient.Update(context.TODO(), binding); err != nil {
+		t.Fatal(err)
+	}
+	pulpapitest.ReconcileReady(t, r, k8sClient, binding, conditions)
+	if len(stub.Roles[bob.Href]) != 0 || !slices.Equal(binding.Status.Users, []string{"alice"}) {
+		t.Errorf("expected the role to be removed from bob, got %+v %v", stub.Roles[bob.Href], binding.Status.Users)
+	}
+
+	// a modified content_object moves the assignments
+	repository := "/pulp/api/v3/repositories/file/file/0190e5a4-3a1c-7b5e-9d0a-6a2a4b0f1c2d/"
+	binding.Spec.ContentObject = repository
+	if err := k8sClient.Update(context.TODO(), binding); err != nil {
+		t.Fatal(err)
+	}
+	pulpapitest.ReconcileReady(t, r, k8sClient, binding, conditions)
+	if len(stub.Roles[alice.Href]) != 1 || stub.Roles[alice.Href][0].ContentObject == nil || *stub.Roles[alice.Href][0].ContentObject != repository {
+		t.Errorf("expected the role assigned in %v, got %+v", repository, stub.Roles[alice.Href])
+	}
+
+	// deletion
+	if err := k8sClient.Delete(context.TODO(), binding); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(context.TODO(), pulpapitest.Request("task-viewers")); err != nil {
+		t.Fatal(err)
+	}
+	if len(stub.Roles[alice.Href]) != 0 || len(stub.Roles[admins.Href]) != 0 {
+		t.Errorf("expected the assignments to be removed, got %+v %+v", stub.Roles[alice.Href], stub.Roles[admins.Href])
+	}
+}
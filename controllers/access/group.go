@@ -0,0 +1,153 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager_access
+
+import (
+	"context"
+	"slices"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers/pulpapi"
+	"github.com/pulp/pulp-operator/controllers/pulpobject"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// PulpGroupReconciler reconciles a PulpGroup object
+type PulpGroupReconciler struct {
+	client.Client
+	RawLogger     logr.Logger
+	Scheme        *runtime.Scheme
+	NewPulpClient pulpobject.ClientFunc
+}
+
+//+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulpgroups,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulpgroups/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulpgroups/finalizers,verbs=update
+
+// Reconcile creates or updates the group and its members in Pulp through the REST API
+func (r *PulpGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.RawLogger.WithValues("PulpGroup", req.NamespacedName)
+
+	group := &pulpv1.PulpGroup{}
+	if err := r.Get(ctx, req.NamespacedName, group); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get PulpGroup")
+		return ctrl.Result{}, err
+	}
+
+	return pulpobject.Reconcile(ctx, r.Client, r.NewPulpClient, log, pulpobject.Object{
+		Object:         group,
+		DeploymentName: group.Spec.DeploymentName,
+		DeletionPolicy: group.Spec.DeletionPolicy,
+		Status:         &group.Status.PulpObjectStatus,
+		Sync: func(ctx context.Context, api *pulpapi.Client) error {
+			return syncGroup(ctx, api, group)
+		},
+		Remove: func(ctx context.Context, api *pulpapi.Client) error {
+			current, err := findGroup(ctx, api, group)
+			if err != nil || current == nil {
+				return err
+			}
+			return api.Delete(ctx, current.Href)
+		},
+	})
+}
+
+// findGroup returns the group from .status.pulp_href (or, if it does not exist, the group with
+// the name from spec), so that a modified name is updated instead of creating a new group
+func findGroup(ctx context.Context, api *pulpapi.Client, group *pulpv1.PulpGroup) (*pulpapi.Group, error) {
+	if len(group.Status.PulpHref) > 0 {
+		current := &pulpapi.Group{}
+		err := api.Get(ctx, group.Status.PulpHref, current)
+		if err == nil {
+			return current, nil
+		}
+		if !pulpapi.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	return api.FindGroup(ctx, group.Spec.Name)
+}
+
+// syncGroup creates the group in Pulp (or updates its name) and adds/removes the members
+// so that they match the users from spec
+func syncGroup(ctx context.Context, api *pulpapi.Client, group *pulpv1.PulpGroup) error {
+	current, err := findGroup(ctx, api, group)
+	if err != nil {
+		return err
+	}
+
+	expected := pulpapi.Group{Name: group.Spec.Name}
+	switch {
+	case current == nil:
+		current = &pulpapi.Group{}
+		if err := api.Create(ctx, pulpapi.GroupsPath, expected, current); err != nil {
+			return err
+		}
+	case current.Name != expected.Name:
+		if err := api.Update(ctx, current.Href, expected, nil); err != nil {
+			return err
+		}
+	}
+	group.Status.PulpHref = current.Href
+
+	members, err := api.GroupUsers(ctx, current.Href)
+	if err != nil {
+		return err
+	}
+	usernames := []string{}
+	for _, member := range members {
+		usernames = append(usernames, member.Username)
+		if !slices.Contains(group.Spec.Users, member.Username) {
+			if err := api.RemoveGroupUser(ctx, current.Href, member.Href); err != nil {
+				return err
+			}
+		}
+	}
+	for _, username := range group.Spec.Users {
+		if !slices.Contains(usernames, username) {
+			if err := api.AddGroupUser(ctx, current.Href, username); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PulpGroupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.NewPulpClient == nil {
+		r.NewPulpClient = pulpapi.NewForPulp
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&pulpv1.PulpGroup{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(
+			&pulpv1.Pulp{},
+			handler.EnqueueRequestsFromMapFunc(pulpobject.FindObjects(r.Client, &pulpv1.PulpGroupList{}, func(o client.Object) string { return o.(*pulpv1.PulpGroup).Spec.DeploymentName })),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		Complete(r)
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager_access
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers/pulpapi"
+	"github.com/pulp/pulp-operator/controllers/pulpobject"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// PulpRoleBindingReconciler reconciles a PulpRoleBinding object
+type PulpRoleBindingReconciler struct {
+	client.Client
+	RawLogger     logr.Logger
+	Scheme        *runtime.Scheme
+	NewPulpClient pulpobject.ClientFunc
+}
+
+//+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulprolebindings,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulprolebindings/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulprolebindings/finalizers,verbs=update
+
+// Reconcile assigns the role to the users and groups in Pulp through the REST API
+func (r *PulpRoleBindingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.RawLogger.WithValues("PulpRoleBinding", req.NamespacedName)
+
+	binding := &pulpv1.PulpRoleBinding{}
+	if err := r.Get(ctx, req.NamespacedName, binding); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get PulpRoleBinding")
+		return ctrl.Result{}, err
+	}
+
+	return pulpobject.Reconcile(ctx, r.Client, r.NewPulpClient, log, pulpobject.Object{
+		Object:         binding,
+		DeploymentName: binding.Spec.DeploymentName,
+		DeletionPolicy: binding.Spec.DeletionPolicy,
+		Status:         &binding.Status.PulpObjectStatus,
+		Sync: func(ctx context.Context, api *pulpapi.Client) error {
+			return syncRoleBinding(ctx, api, binding)
+		},
+		Remove: func(ctx context.Context, api *pulpapi.Client) error {
+			return unassignRoles(ctx, api, binding, nil, nil)
+		},
+	})
+}
+
+// roleSubject is a user or a group to which a role is assigned
+type roleSubject struct {
+	kind string
+	name string
+}
+
+// href returns the href of the user or group (or an empty string if it does not exist)
+func (s roleSubject) href(ctx context.Context, api *pulpapi.Client) (string, error) {
+	if s.kind == "user" {
+		user, err := api.FindUser(ctx, s.name)
+		if err != nil || user == nil {
+			return "", err
+		}
+		return user.Href, nil
+	}
+	group, err := api.FindGroup(ctx, s.name)
+	if err != nil || group == nil {
+		return "", err
+	}
+	return group.Href, nil
+}
+
+// syncRoleBinding removes the assignments made by the operator that are no longer in spec
+// (including all of them if the role or the content_object was modified) and assigns the
+// role to the users and groups from spec that do not have it
+func syncRoleBinding(ctx context.Context, api *pulpapi.Client, binding *pulpv1.PulpRoleBinding) error {
+	status := &binding.Status
+	if status.Role != binding.Spec.Role || status.ContentObject != binding.Spec.ContentObject {
+		if err := unassignRoles(ctx, api, binding, nil, nil); err != nil {
+			return err
+		}
+	} else if err := unassignRoles(ctx, api, binding, binding.Spec.Users, binding.Spec.Groups); err != nil {
+		return err
+	}
+	status.Role, status.ContentObject = binding.Spec.Role, binding.Spec.ContentObject
+
+	subjects := []roleSubject{}
+	for _, user := range binding.Spec.Users {
+		subjects = append(subjects, roleSubject{"user", user})
+	}
+	for _, group := range binding.Spec.Groups {
+		subjects = append(subjects, roleSubject{"group", group})
+	}
+
+	for _, subject := range subjects {
+		href, err := subject.href(ctx, api)
+		if err != nil {
+			return err
+		}
+		if len(href) == 0 {
+			return fmt.Errorf("%v %v not found in Pulp", subject.kind, subject.name)
+		}
+		assignment, err := api.FindRoleAssignment(ctx, href, binding.Spec.Role, binding.Spec.ContentObject)
+		if err != nil {
+			return err
+		}
+		// the subject is kept in status before the role is assigned, so the assignment is
+		// removed if the subject is removed from spec even if the sync fails in the middle
+		if subject.kind == "user" && !slices.Contains(status.Users, subject.name) {
+			status.Users = append(status.Users, subject.name)
+		}
+		if subject.kind == "group" && !slices.Contains(status.Groups, subject.name) {
+			status.Groups = append(status.Groups, subject.name)
+		}
+		if assignment == nil {
+			if err := api.AssignRole(ctx, href, binding.Spec.Role, binding.Spec.ContentObject); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// unassignRoles removes the role assigned by the operator (kept in status) from the users and
+// groups that are not in keepUsers and keepGroups
+func unassignRoles(ctx context.Context, api *pulpapi.Client, binding *pulpv1.PulpRoleBinding, keepUsers, keepGroups []string) error {
+	status := &binding.Status
+	subjects := []roleSubject{}
+	for _, user := range status.Users {
+		if !slices.Contains(keepUsers, user) {
+			subjects = append(subjects, roleSubject{"user", user})
+		}
+	}
+	for _, group := range status.Groups {
+		if !slices.Contains(keepGroups, group) {
+			subjects = append(subjects, roleSubject{"group", group})
+		}
+	}
+
+	for _, subject := range subjects {
+		href, err := subject.href(ctx, api)
+		if err != nil {
+			return err
+		}
+		if len(href) > 0 {
+			assignment, err := api.FindRoleAssignment(ctx, href, status.Role, status.ContentObject)
+			if err != nil {
+				return err
+			}
+			if assignment != nil {
+				if err := api.Delete(ctx, assignment.Href); err != nil {
+					return err
+				}
+			}
+		}
+		if subject.kind == "user" {
+			status.Users = slices.DeleteFunc(status.Users, func(u string) bool { return u == subject.name })
+		} else {
+			status.Groups = slices.DeleteFunc(status.Groups, func(g string) bool { return g == subject.name })
+		}
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PulpRoleBindingReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.NewPulpClient == nil {
+		r.NewPulpClient = pulpapi.NewForPulp
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&pulpv1.PulpRoleBinding{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(
+			&pulpv1.Pulp{},
+			handler.EnqueueRequestsFromMapFunc(pulpobject.FindObjects(r.Client, &pulpv1.PulpRoleBindingList{}, func(o client.Object) string { return o.(*pulpv1.PulpRoleBinding).Spec.DeploymentName })),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		Complete(r)
+}
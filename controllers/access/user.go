@@ -0,0 +1,193 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager_access
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/pulpapi"
+	"github.com/pulp/pulp-operator/controllers/pulpobject"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// PulpUserReconciler reconciles a PulpUser object
+type PulpUserReconciler struct {
+	client.Client
+	RawLogger     logr.Logger
+	Scheme        *runtime.Scheme
+	NewPulpClient pulpobject.ClientFunc
+}
+
+//+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulpusers,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulpusers/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulpusers/finalizers,verbs=update
+
+// Reconcile creates or updates the user in Pulp through the REST API
+func (r *PulpUserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.RawLogger.WithValues("PulpUser", req.NamespacedName)
+
+	user := &pulpv1.PulpUser{}
+	if err := r.Get(ctx, req.NamespacedName, user); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get PulpUser")
+		return ctrl.Result{}, err
+	}
+
+	return pulpobject.Reconcile(ctx, r.Client, r.NewPulpClient, log, pulpobject.Object{
+		Object:         user,
+		DeploymentName: user.Spec.DeploymentName,
+		DeletionPolicy: user.Spec.DeletionPolicy,
+		Status:         &user.Status.PulpObjectStatus,
+		Sync: func(ctx context.Context, api *pulpapi.Client) error {
+			return r.syncUser(ctx, api, user)
+		},
+		Remove: func(ctx context.Context, api *pulpapi.Client) error {
+			current, err := findUser(ctx, api, user)
+			if err != nil || current == nil {
+				return err
+			}
+			return api.Delete(ctx, current.Href)
+		},
+	})
+}
+
+// findUser returns the user from .status.pulp_href (or, if it does not exist, the user with the
+// username from spec), so that a modified username is updated instead of creating a new user
+func findUser(ctx context.Context, api *pulpapi.Client, user *pulpv1.PulpUser) (*pulpapi.User, error) {
+	if len(user.Status.PulpHref) > 0 {
+		current := &pulpapi.User{}
+		err := api.Get(ctx, user.Status.PulpHref, current)
+		if err == nil {
+			return current, nil
+		}
+		if !pulpapi.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	return api.FindUser(ctx, user.Spec.Username)
+}
+
+// syncUser creates the user in Pulp or updates the fields modified in the CR or through the Pulp API
+func (r *PulpUserReconciler) syncUser(ctx context.Context, api *pulpapi.Client, user *pulpv1.PulpUser) error {
+	expected := pulpapi.User{
+		Username:  user.Spec.Username,
+		FirstName: user.Spec.FirstName,
+		LastName:  user.Spec.LastName,
+		Email:     user.Spec.Email,
+		IsStaff:   user.Spec.IsStaff,
+		IsActive:  user.Spec.IsActive == nil || *user.Spec.IsActive,
+	}
+
+	password, passwordHash, err := r.userPassword(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	current, err := findUser(ctx, api, user)
+	if err != nil {
+		return err
+	}
+
+	if current == nil {
+		expected.Password = password
+		created := &pulpapi.User{}
+		if err := api.Create(ctx, pulpapi.UsersPath, expected, created); err != nil {
+			return err
+		}
+		user.Status.PulpHref = created.Href
+		user.Status.PasswordHash = passwordHash
+		return nil
+	}
+
+	user.Status.PulpHref = current.Href
+	expected.Href, expected.ID = current.Href, current.ID
+	if passwordHash != user.Status.PasswordHash {
+		expected.Password = password
+	} else if *current == expected {
+		return nil
+	}
+	if err := api.Update(ctx, current.Href, expected, nil); err != nil {
+		return err
+	}
+	user.Status.PasswordHash = passwordHash
+	return nil
+}
+
+// userPassword returns the password from password_secret and its hash
+func (r *PulpUserReconciler) userPassword(ctx context.Context, user *pulpv1.PulpUser) (string, string, error) {
+	if len(user.Spec.PasswordSecret) == 0 {
+		return "", "", nil
+	}
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: user.Spec.PasswordSecret, Namespace: user.Namespace}, secret); err != nil {
+		return "", "", fmt.Errorf("failed to get %v Secret: %v", user.Spec.PasswordSecret, err)
+	}
+	password := string(secret.Data["password"])
+	if len(password) == 0 {
+		return "", "", fmt.Errorf("the %v Secret does not have the password key", user.Spec.PasswordSecret)
+	}
+	return password, controllers.CalculateHash(password), nil
+}
+
+// findUsersForSecret enqueues the PulpUsers that reference the password Secret
+func (r *PulpUserReconciler) findUsersForSecret(ctx context.Context, secret client.Object) []ctrl.Request {
+	users := &pulpv1.PulpUserList{}
+	if err := r.List(ctx, users, client.InNamespace(secret.GetNamespace())); err != nil {
+		return nil
+	}
+	requests := []ctrl.Request{}
+	for _, user := range users.Items {
+		if user.Spec.PasswordSecret == secret.GetName() {
+			requests = append(requests, ctrl.Request{NamespacedName: types.NamespacedName{Name: user.Name, Namespace: user.Namespace}})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PulpUserReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.NewPulpClient == nil {
+		r.NewPulpClient = pulpapi.NewForPulp
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&pulpv1.PulpUser{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(
+			&pulpv1.Pulp{},
+			handler.EnqueueRequestsFromMapFunc(pulpobject.FindObjects(r.Client, &pulpv1.PulpUserList{}, func(o client.Object) string { return o.(*pulpv1.PulpUser).Spec.DeploymentName })),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.findUsersForSecret),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		Complete(r)
+}
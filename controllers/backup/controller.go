@@ -79,6 +79,12 @@ func (r *RepoManagerBackupReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return ctrl.Result{}, nil
 	}
 
+	if err := r.checkDatabaseCredentials(ctx, pulpBackup); err != nil {
+		log.Error(err, "The "+getPostgresCfgSecret(pulpBackup)+" Secret should provide the username, password, host, port and database keys used by pg_dump!")
+		r.updateStatus(ctx, pulpBackup, metav1.ConditionFalse, "BackupComplete", "Missing database credentials in "+getPostgresCfgSecret(pulpBackup)+" Secret!", "FailedDatabaseCredentials")
+		return ctrl.Result{}, nil
+	}
+
 	r.updateStatus(ctx, pulpBackup, metav1.ConditionFalse, "BackupComplete", "Backup process running ...", "StartingBackupProcess")
 	r.cleanup(ctx, pulpBackup)
 
@@ -91,6 +91,14 @@ func getPostgresCfgSecret(pulpBackup *pulpv1.PulpBackup) string {
 	return postgresCfgSecret
 }
 
+// checkDatabaseCredentials verifies if the postgres configuration Secret provides the keys used to
+// build the pg_dump connection string. The backup reads the password from this Secret even if the
+// Pulp CR reads it from a SecretProviderClass (secrets_store.external_db_secret).
+func (r *RepoManagerBackupReconciler) checkDatabaseCredentials(ctx context.Context, pulpBackup *pulpv1.PulpBackup) error {
+	_, err := controllers.RetrieveSecretData(ctx, getPostgresCfgSecret(pulpBackup), pulpBackup.Namespace, true, r.Client, "username", "password", "host", "port", "database")
+	return err
+}
+
 // getDBFieldsEncryption returns the db_fields_encryption_secret
 func getDBFieldsEncryption(pulp *pulpv1.Pulp) string {
 	return pulp.Status.DBFieldsEncryptionSecret
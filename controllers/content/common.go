@@ -0,0 +1,179 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager_content
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers/pulpapi"
+	"github.com/pulp/pulp-operator/controllers/pulpobject"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// pluginObject is a remote, repository or distribution to be synchronized with Pulp
+type pluginObject struct {
+	// path is the list endpoint of the object (for example, remotes/rpm/rpm/)
+	path   string
+	name   string
+	status *pulpv1.PulpObjectStatus
+
+	// fields are compared with the object in Pulp and updated if they differ
+	fields map[string]any
+
+	// writeOnly fields (like passwords) are not returned by Pulp, so they are only sent when
+	// the object is created or when their hash differs from the one in writeOnlyHash
+	writeOnly     map[string]any
+	hash          string
+	writeOnlyHash *string
+}
+
+// sync creates the object in Pulp or updates the fields that differ from spec and returns the
+// object from Pulp. A TaskRunningError is returned while Pulp modifies the object asynchronously.
+func (o pluginObject) sync(ctx context.Context, api *pulpapi.Client) (pulpapi.Object, error) {
+	if err := pulpobject.PollTask(ctx, api, o.status.LastTask); err != nil {
+		if taskErr := (*pulpobject.TaskRunningError)(nil); !errors.As(err, &taskErr) && o.writeOnlyHash != nil {
+			// the write-only fields are sent again in the next update
+			*o.writeOnlyHash = ""
+		}
+		return nil, err
+	}
+
+	current, err := o.find(ctx, api)
+	if err != nil {
+		return nil, err
+	}
+
+	if current == nil {
+		body := maps.Clone(o.fields)
+		maps.Copy(body, o.writeOnly)
+		response := pulpapi.Object{}
+		if err := api.Create(ctx, o.path, body, &response); err != nil {
+			return nil, err
+		}
+		return o.dispatched(response)
+	}
+
+	o.status.PulpHref = current.Href()
+	update, err := changedFields(o.fields, current)
+	if err != nil {
+		return nil, err
+	}
+	if o.writeOnlyHash != nil && *o.writeOnlyHash != o.hash {
+		maps.Copy(update, o.writeOnly)
+	}
+	if len(update) == 0 {
+		return current, nil
+	}
+	response := pulpapi.Object{}
+	if err := api.Update(ctx, current.Href(), update, &response); err != nil {
+		return nil, err
+	}
+	return o.dispatched(response)
+}
+
+// dispatched records the task dispatched by a create or update request (returning a
+// TaskRunningError) or the object answered by Pulp
+func (o pluginObject) dispatched(response pulpapi.Object) (pulpapi.Object, error) {
+	if o.writeOnlyHash != nil {
+		*o.writeOnlyHash = o.hash
+	}
+	if task := response.Task(); len(task) > 0 {
+		o.status.LastTask = &pulpv1.PulpTaskStatus{Href: task, State: pulpapi.TaskWaiting}
+		return nil, &pulpobject.TaskRunningError{Href: task}
+	}
+	o.status.PulpHref = response.Href()
+	return response, nil
+}
+
+// remove deletes the object from Pulp, returning a TaskRunningError until Pulp removes it
+func (o pluginObject) remove(ctx context.Context, api *pulpapi.Client) error {
+	if err := pulpobject.PollTask(ctx, api, o.status.LastTask); err != nil && !pulpapi.TaskFinished(o.status.LastTask.State) {
+		return err
+	}
+	current, err := o.find(ctx, api)
+	if err != nil || current == nil {
+		return err
+	}
+	task, err := api.DeleteObject(ctx, current.Href())
+	if err != nil || len(task) == 0 {
+		return err
+	}
+	o.status.LastTask = &pulpv1.PulpTaskStatus{Href: task, State: pulpapi.TaskWaiting}
+	return &pulpobject.TaskRunningError{Href: task}
+}
+
+// find returns the object from .status.pulp_href (or, if it does not exist, the object with
+// the name from spec), so that a modified name is updated instead of creating a new object
+func (o pluginObject) find(ctx context.Context, api *pulpapi.Client) (pulpapi.Object, error) {
+	if len(o.status.PulpHref) > 0 {
+		current := pulpapi.Object{}
+		err := api.Get(ctx, o.status.PulpHref, &current)
+		if err == nil {
+			return current, nil
+		}
+		if !pulpapi.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	return api.FindObject(ctx, o.path, o.name)
+}
+
+// changedFields returns the fields whose values differ from the current object
+// (compared as decoded from json, like the fields of the current object)
+func changedFields(fields map[string]any, current pulpapi.Object) (map[string]any, error) {
+	content, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	expected := map[string]any{}
+	if err := json.Unmarshal(content, &expected); err != nil {
+		return nil, err
+	}
+	changed := map[string]any{}
+	for field, value := range expected {
+		if !reflect.DeepEqual(value, current[field]) {
+			changed[field] = fields[field]
+		}
+	}
+	return changed, nil
+}
+
+// withPluginFields adds the plugin_fields from spec to fields
+func withPluginFields(fields map[string]any, pluginFields map[string]apiextensionsv1.JSON) (map[string]any, error) {
+	for field, value := range pluginFields {
+		var decoded any
+		if err := json.Unmarshal(value.Raw, &decoded); err != nil {
+			return nil, fmt.Errorf("invalid value of plugin field %v: %v", field, err)
+		}
+		fields[field] = decoded
+	}
+	return fields, nil
+}
+
+// nilIfEmpty returns nil for an empty value, so the field is cleared in Pulp
+func nilIfEmpty(value string) any {
+	if len(value) == 0 {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,317 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager_content
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers/pulpapi"
+	"github.com/pulp/pulp-operator/controllers/pulpapi/pulpapitest"
+	"github.com/pulp/pulp-operator/controllers/pulpobject"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// newTestClients returns the pulpapitest clients with the status subresource of the content CRs
+func newTestClients(t *testing.T, objs ...client.Object) (client.Client, *pulpapitest.Server, pulpobject.ClientFunc) {
+	return pulpapitest.NewClients(t, []client.Object{&pulpv1.PulpRemote{}, &pulpv1.PulpRepository{}, &pulpv1.PulpDistribution{}, &pulpv1.PulpDomain{}}, objs...)
+}
+
+func TestPulpRemote(t *testing.T) {
+	remote := &pulpv1.PulpRemote{
+		ObjectMeta: metav1.ObjectMeta{Name: "quay-pulp", Namespace: pulpapitest.Namespace},
+		Spec: pulpv1.PulpRemoteSpec{
+			DeploymentName:    pulpapitest.PulpName,
+			Type:              "container/container",
+			Name:              "quay-pulp",
+			URL:               "https://quay.io",
+			Policy:            "on_demand",
+			CredentialsSecret: "quay-credentials",
+			PluginFields:      map[string]apiextensionsv1.JSON{"upstream_name": {Raw: []byte(`"pulp/pulp"`)}, "include_tags": {Raw: []byte(`["latest"]`)}},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "quay-credentials", Namespace: pulpapitest.Namespace},
+		Data:       map[string][]byte{"username": []byte("robot"), "password": []byte("first")},
+	}
+	k8sClient, stub, newPulpClient := newTestClients(t, remote, secret)
+	r := &PulpRemoteReconciler{Client: k8sClient, RawLogger: logr.Discard(), NewPulpClient: newPulpClient}
+	conditions := func() []metav1.Condition { return remote.Status.Conditions }
+
+	// create
+	pulpapitest.ReconcileReady(t, r, k8sClient, remote, conditions)
+	created := stub.Find("remotes/container/container/", "quay-pulp")
+	if created == nil || created["url"] != "https://quay.io" || created["policy"] != "on_demand" || created["tls_validation"] != true ||
+		created["upstream_name"] != "pulp/pulp" || created["username"] != "robot" || created["password"] != "first" {
+		t.Fatalf("unexpected remote created in Pulp: %+v", created)
+	}
+	if remote.Status.PulpHref != created.Href() || len(remote.Status.CredentialsHash) == 0 || !slices.Contains(remote.Finalizers, pulpobject.Finalizer) {
+		t.Errorf("unexpected status or finalizers: %+v %v", remote.Status, remote.Finalizers)
+	}
+
+	// no modifications: only the lookup is sent
+	pulpapitest.ReconcileReady(t, r, k8sClient, remote, conditions)
+	if len(stub.RequestsWith("PATCH")) != 0 {
+		t.Errorf("expected no PATCH requests for an unmodified remote")
+	}
+
+	// drift correction waits for the update task
+	created["include_tags"] = []any{"latest", "nightly"}
+	pulpapitest.ReconcileTask(t, r, k8sClient, remote, conditions)
+	if remote.Status.LastTask == nil || remote.Status.LastTask.State != pulpapi.TaskWaiting {
+		t.Errorf("expected the update task in status, got %+v", remote.Status.LastTask)
+	}
+	pulpapitest.ReconcileReady(t, r, k8sClient, remote, conditions)
+	if tags := created["include_tags"].([]any); len(tags) != 1 || remote.Status.LastTask.State != pulpapi.TaskCompleted {
+		t.Errorf("modifications made through the Pulp API were not reverted: %v %+v", tags, remote.Status.LastTask)
+	}
+
+	// credentials rotation
+	secret.Data["password"] = []byte("second")
+	if err := k8sClient.Update(context.TODO(), secret); err != nil {
+		t.Fatal(err)
+	}
+	pulpapitest.ReconcileTask(t, r, k8sClient, remote, conditions)
+	pulpapitest.ReconcileReady(t, r, k8sClient, remote, conditions)
+	if created["password"] != "second" {
+		t.Errorf("expected the password from the modified Secret, got %q", created["password"])
+	}
+
+	// deletion waits for the removal task
+	if err := k8sClient.Delete(context.TODO(), remote); err != nil {
+		t.Fatal(err)
+	}
+	pulpapitest.ReconcileTask(t, r, k8sClient, remote, conditions)
+	if stub.Find("remotes/container/container/", "quay-pulp") != nil {
+		t.Errorf("expected the remote to be removed from Pulp")
+	}
+	if _, err := r.Reconcile(context.TODO(), pulpapitest.Request("quay-pulp")); err != nil {
+		t.Fatal(err)
+	}
+	if err := k8sClient.Get(context.TODO(), pulpapitest.Request("quay-pulp").NamespacedName, remote); err == nil {
+		t.Errorf("expected the PulpRemote to be removed after the finalizer")
+	}
+}
+
+func TestPulpRepository(t *testing.T) {
+	repository := &pulpv1.PulpRepository{
+		ObjectMeta: metav1.ObjectMeta{Name: "baseos", Namespace: pulpapitest.Namespace},
+		Spec: pulpv1.PulpRepositorySpec{
+			DeploymentName: pulpapitest.PulpName,
+			Type:           "rpm/rpm",
+			Name:           "baseos",
+			Remote:         "baseos",
+			Mirror:         true,
+			PluginFields:   map[string]apiextensionsv1.JSON{"autopublish": {Raw: []byte(`true`)}},
+		},
+	}
+	k8sClient, stub, newPulpClient := newTestClients(t, repository)
+	r := &PulpRepositoryReconciler{Client: k8sClient, RawLogger: logr.Discard(), NewPulpClient: newPulpClient}
+	conditions := func() []metav1.Condition { return repository.Status.Conditions }
+
+	// the remote does not exist yet
+	condition, result := pulpapitest.ReconcileObject(t, r, k8sClient, repository, conditions)
+	if condition.Reason != "SyncFailed" || result.RequeueAfter != pulpobject.RetryPeriod {
+		t.Errorf("expected the SyncFailed condition, got %+v", condition)
+	}
+
+	// the repository is created and synced from the remote
+	remote := stub.Add("remotes/rpm/rpm/", pulpapi.Object{"name": "baseos", "url": "https://mirror.example.com/baseos/"})
+	pulpapitest.ReconcileTask(t, r, k8sClient, repository, conditions)
+	created := stub.Find("repositories/rpm/rpm/", "baseos")
+	if created == nil || created["remote"] != remote.Href() || created["autopublish"] != true || created["description"] != nil {
+		t.Fatalf("unexpected repository created in Pulp: %+v", created)
+	}
+	if sync := repository.Status.LastSync; sync == nil || sync.Remote != "baseos" || !slices.Contains(stub.RequestsWith("POST"), "POST "+created.Href()+"sync/") {
+		t.Fatalf("expected the sync to be dispatched, got %+v", sync)
+	}
+	pulpapitest.ReconcileReady(t, r, k8sClient, repository, conditions)
+	if repository.Status.LastSync.State != pulpapi.TaskCompleted || repository.Status.LatestVersionHref != created["latest_version_href"] {
+		t.Errorf("unexpected sync status: %+v %v", repository.Status.LastSync, repository.Status.LatestVersionHref)
+	}
+
+	// no new sync without a new request
+	pulpapitest.ReconcileReady(t, r, k8sClient, repository, conditions)
+	if syncs := len(stub.RequestsWith("POST")); syncs != 2 {
+		t.Errorf("expected a single sync, got %v", stub.RequestsWith("POST"))
+	}
+
+	// a failed sync requested through the annotation is reported until the next request
+	stub.SetTaskState(pulpapi.TaskFailed, "Cannot connect to host mirror.example.com")
+	repository.Annotations = map[string]string{pulpv1.SyncRequestAnnotation: "1"}
+	if err := k8sClient.Update(context.TODO(), repository); err != nil {
+		t.Fatal(err)
+	}
+	pulpapitest.ReconcileTask(t, r, k8sClient, repository, conditions)
+	for range 2 {
+		condition, _ = pulpapitest.ReconcileObject(t, r, k8sClient, repository, conditions)
+		if condition.Reason != "SyncFailed" || repository.Status.LastSync.Error != "Cannot connect to host mirror.example.com" {
+			t.Errorf("expected the failed sync to be reported, got %+v %+v", condition, repository.Status.LastSync)
+		}
+	}
+	if syncs := len(stub.RequestsWith("POST")); syncs != 3 {
+		t.Errorf("expected the failed sync not to be retried, got %v", stub.RequestsWith("POST"))
+	}
+}
+
+func TestPulpDistribution(t *testing.T) {
+	distribution := &pulpv1.PulpDistribution{
+		ObjectMeta: metav1.ObjectMeta{Name: "baseos", Namespace: pulpapitest.Namespace},
+		Spec: pulpv1.PulpDistributionSpec{
+			DeploymentName: pulpapitest.PulpName,
+			Type:           "rpm/rpm",
+			Name:           "baseos",
+			BasePath:       "rhel/9/baseos",
+			Repository:     "baseos",
+			DeletionPolicy: pulpv1.DeletionPolicyRetain,
+		},
+	}
+	k8sClient, stub, newPulpClient := newTestClients(t, distribution)
+	r := &PulpDistributionReconciler{Client: k8sClient, RawLogger: logr.Discard(), NewPulpClient: newPulpClient}
+	conditions := func() []metav1.Condition { return distribution.Status.Conditions }
+	repository := stub.Add("repositories/rpm/rpm/", pulpapi.Object{"name": "baseos"})
+
+	// the distributions are created asynchronously
+	pulpapitest.ReconcileTask(t, r, k8sClient, distribution, conditions)
+	pulpapitest.ReconcileReady(t, r, k8sClient, distribution, conditions)
+	created := stub.Find("distributions/rpm/rpm/", "baseos")
+	if created == nil || created["base_path"] != "rhel/9/baseos" || created["repository"] != repository.Href() {
+		t.Fatalf("unexpected distribution created in Pulp: %+v", created)
+	}
+	if distribution.Status.PulpHref != created.Href() || distribution.Status.BaseURL != "https://pulp.example.com/pulp/content/rhel/9/baseos/" {
+		t.Errorf("unexpected status: %+v", distribution.Status)
+	}
+
+	// the distribution is kept in Pulp with the Retain policy
+	if err := k8sClient.Delete(context.TODO(), distribution); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(context.TODO(), pulpapitest.Request("baseos")); err != nil {
+		t.Fatal(err)
+	}
+	if stub.Find("distributions/rpm/rpm/", "baseos") == nil || len(stub.RequestsWith("DELETE")) != 0 {
+		t.Errorf("expected the distribution to be kept in Pulp")
+	}
+}
+
+func TestPulpDomain(t *testing.T) {
+	domain := &pulpv1.PulpDomain{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge", Namespace: pulpapitest.Namespace},
+		Spec: pulpv1.PulpDomainSpec{
+			DeploymentName:  pulpapitest.PulpName,
+			Name:            "edge",
+			StorageSecret:   "edge-s3",
+			StorageSettings: map[string]apiextensionsv1.JSON{"location": {Raw: []byte(`"edge"`)}},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge-s3", Namespace: pulpapitest.Namespace},
+		Data: map[string][]byte{
+			"s3-bucket-name": []byte("edge"), "s3-region": []byte("us-east-1"), "s3-access-key-id": []byte("key"),
+			"s3-secret-access-key": []byte("first"), "s3-default-acl": []byte("private"), "s3-querystring-auth": []byte("false"),
+		},
+	}
+	settings := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: pulpapitest.Namespace}, Data: map[string]string{}}
+	k8sClient, stub, newPulpClient := newTestClients(t, domain, secret, settings)
+	r := &PulpDomainReconciler{Client: k8sClient, RawLogger: logr.Discard(), NewPulpClient: newPulpClient}
+	conditions := func() []metav1.Condition { return domain.Status.Conditions }
+
+	// the domains should be enabled in Pulp
+	condition, _ := pulpapitest.ReconcileObject(t, r, k8sClient, domain, conditions)
+	if condition == nil || condition.Reason != "SyncFailed" || stub.Find("domains/", "edge") != nil {
+		t.Fatalf("expected the domain not to be created without DOMAIN_ENABLED, got %+v", condition)
+	}
+	pulp := &pulpv1.Pulp{}
+	k8sClient.Get(context.TODO(), types.NamespacedName{Name: pulpapitest.PulpName, Namespace: pulpapitest.Namespace}, pulp)
+	pulp.Spec.CustomPulpSettings = "settings"
+	k8sClient.Update(context.TODO(), pulp)
+	settings.Data["domain_enabled"] = "True"
+	k8sClient.Update(context.TODO(), settings)
+
+	// create
+	pulpapitest.ReconcileReady(t, r, k8sClient, domain, conditions)
+	created := stub.Find("domains/", "edge")
+	if created == nil || created["storage_class"] != "storages.backends.s3boto3.S3Boto3Storage" || created["redirect_to_object_storage"] != true {
+		t.Fatalf("unexpected domain created in Pulp: %+v", created)
+	}
+	storageSettings := created["storage_settings"].(map[string]any)
+	if storageSettings["bucket_name"] != "edge" || storageSettings["region_name"] != "us-east-1" || storageSettings["secret_key"] != "first" ||
+		storageSettings["default_acl"] != "private" || storageSettings["querystring_auth"] != false || storageSettings["location"] != "edge" ||
+		storageSettings["signature_version"] != "s3v4" {
+		t.Errorf("unexpected storage settings: %+v", storageSettings)
+	}
+	if domain.Status.ContentPath != "/pulp/content/edge/" || len(domain.Status.StorageHash) == 0 {
+		t.Errorf("unexpected status: %+v", domain.Status)
+	}
+
+	// no modifications: only the lookup is sent
+	pulpapitest.ReconcileReady(t, r, k8sClient, domain, conditions)
+	if len(stub.RequestsWith("PATCH")) != 0 {
+		t.Errorf("expected no PATCH requests for an unmodified domain")
+	}
+
+	// the storage settings are updated with the Secret
+	secret.Data["s3-secret-access-key"] = []byte("second")
+	if err := k8sClient.Update(context.TODO(), secret); err != nil {
+		t.Fatal(err)
+	}
+	pulpapitest.ReconcileTask(t, r, k8sClient, domain, conditions)
+	pulpapitest.ReconcileReady(t, r, k8sClient, domain, conditions)
+	if storageSettings := created["storage_settings"].(map[string]any); storageSettings["secret_key"] != "second" || created["storage_class"] == nil {
+		t.Errorf("expected the storage settings from the modified Secret, got %+v", created)
+	}
+
+	// deletion waits for the removal task
+	if err := k8sClient.Delete(context.TODO(), domain); err != nil {
+		t.Fatal(err)
+	}
+	pulpapitest.ReconcileTask(t, r, k8sClient, domain, conditions)
+	if stub.Find("domains/", "edge") != nil {
+		t.Errorf("expected the domain to be removed from Pulp")
+	}
+}
+
+func TestStorageSecretSettings(t *testing.T) {
+	azure := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "azure"},
+		Data: map[string][]byte{
+			"azure-account-name": []byte("pulp"), "azure-account-key": []byte("key"), "azure-container": []byte("edge"),
+			"azure-container-path": []byte("domains/edge"), "azure-client-id": []byte("ignored"), "azure-expiration-secs": []byte("120"),
+		},
+	}
+	storageClass, settings, err := storageSecretSettings(azure)
+	if err != nil || storageClass != "storages.backends.azure_storage.AzureStorage" {
+		t.Fatalf("unexpected storage class %v: %v", storageClass, err)
+	}
+	if settings["account_name"] != "pulp" || settings["azure_container"] != "edge" || settings["location"] != "domains/edge" ||
+		settings["expiration_secs"] != float64(120) || settings["overwrite_files"] != true || settings["client_id"] != nil {
+		t.Errorf("unexpected azure storage settings: %+v", settings)
+	}
+
+	for _, data := range []map[string][]byte{{"azure-account-name": []byte("pulp")}, {"username": []byte("admin")}} {
+		if _, _, err := storageSecretSettings(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "invalid"}, Data: data}); err == nil {
+			t.Errorf("expected an error for the Secret with %v", data)
+		}
+	}
+}
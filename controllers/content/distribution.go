@@ -0,0 +1,119 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager_content
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers/pulpapi"
+	"github.com/pulp/pulp-operator/controllers/pulpobject"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// PulpDistributionReconciler reconciles a PulpDistribution object
+type PulpDistributionReconciler struct {
+	client.Client
+	RawLogger     logr.Logger
+	Scheme        *runtime.Scheme
+	NewPulpClient pulpobject.ClientFunc
+}
+
+//+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulpdistributions,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulpdistributions/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulpdistributions/finalizers,verbs=update
+
+// Reconcile creates or updates the distribution in Pulp through the REST API
+func (r *PulpDistributionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.RawLogger.WithValues("PulpDistribution", req.NamespacedName)
+
+	distribution := &pulpv1.PulpDistribution{}
+	if err := r.Get(ctx, req.NamespacedName, distribution); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get PulpDistribution")
+		return ctrl.Result{}, err
+	}
+
+	return pulpobject.Reconcile(ctx, r.Client, r.NewPulpClient, log, pulpobject.Object{
+		Object:         distribution,
+		DeploymentName: distribution.Spec.DeploymentName,
+		DeletionPolicy: distribution.Spec.DeletionPolicy,
+		Status:         &distribution.Status.PulpObjectStatus,
+		Sync: func(ctx context.Context, api *pulpapi.Client) error {
+			return syncDistribution(ctx, api, distribution)
+		},
+		Remove: func(ctx context.Context, api *pulpapi.Client) error {
+			obj := pluginObject{path: pulpapi.DistributionsPath(distribution.Spec.Type), name: distribution.Spec.Name, status: &distribution.Status.PulpObjectStatus}
+			return obj.remove(ctx, api)
+		},
+	})
+}
+
+// syncDistribution creates or updates the distribution in Pulp
+func syncDistribution(ctx context.Context, api *pulpapi.Client, distribution *pulpv1.PulpDistribution) error {
+	repositoryHref := ""
+	if len(distribution.Spec.Repository) > 0 {
+		href, err := api.FindObjectHref(ctx, pulpapi.RepositoriesPath(distribution.Spec.Type), distribution.Spec.Repository)
+		if err != nil {
+			return err
+		}
+		repositoryHref = href
+	}
+
+	fields, err := withPluginFields(map[string]any{
+		"name":       distribution.Spec.Name,
+		"base_path":  distribution.Spec.BasePath,
+		"repository": nilIfEmpty(repositoryHref),
+	}, distribution.Spec.PluginFields)
+	if err != nil {
+		return err
+	}
+
+	obj := pluginObject{path: pulpapi.DistributionsPath(distribution.Spec.Type), name: distribution.Spec.Name, status: &distribution.Status.PulpObjectStatus, fields: fields}
+	current, err := obj.sync(ctx, api)
+	if err != nil {
+		return err
+	}
+	if baseURL, ok := current["base_url"].(string); ok {
+		distribution.Status.BaseURL = baseURL
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PulpDistributionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.NewPulpClient == nil {
+		r.NewPulpClient = pulpapi.NewForPulp
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&pulpv1.PulpDistribution{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(
+			&pulpv1.Pulp{},
+			handler.EnqueueRequestsFromMapFunc(pulpobject.FindObjects(r.Client, &pulpv1.PulpDistributionList{}, func(o client.Object) string { return o.(*pulpv1.PulpDistribution).Spec.DeploymentName })),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		Complete(r)
+}
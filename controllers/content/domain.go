@@ -0,0 +1,276 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager_content
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/pulpapi"
+	"github.com/pulp/pulp-operator/controllers/pulpobject"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	s3StorageClass    = "storages.backends.s3boto3.S3Boto3Storage"
+	azureStorageClass = "storages.backends.azure_storage.AzureStorage"
+)
+
+// storageSecretFormat maps the keys of an object storage Secret (in the format of
+// object_storage_s3_secret or object_storage_azure_secret) into the storage settings of a domain
+type storageSecretFormat struct {
+	storageClass string
+	prefix       string
+	// required keys of the Secret, which identify its format
+	required []string
+	// keys mapped into a setting with a different name
+	keys map[string]string
+	// defaults are the settings used by the operator for the Pulp CR storage
+	defaults map[string]any
+	// ignored keys are not supported in the domains (they configure the pods, like
+	// the workload identity keys)
+	ignored []string
+}
+
+var storageSecretFormats = []storageSecretFormat{
+	{
+		storageClass: s3StorageClass,
+		prefix:       "s3-",
+		required:     []string{"s3-bucket-name"},
+		keys: map[string]string{
+			"s3-bucket-name":       "bucket_name",
+			"s3-access-key-id":     "access_key",
+			"s3-secret-access-key": "secret_key",
+			"s3-endpoint":          "endpoint_url",
+			"s3-region":            "region_name",
+		},
+		defaults: map[string]any{"signature_version": "s3v4", "addressing_style": "path"},
+		ignored:  []string{"s3-role-arn"},
+	},
+	{
+		storageClass: azureStorageClass,
+		prefix:       "azure-",
+		required:     []string{"azure-account-name", "azure-container"},
+		keys: map[string]string{
+			"azure-account-name":      "account_name",
+			"azure-account-key":       "account_key",
+			"azure-container":         "azure_container",
+			"azure-container-path":    "location",
+			"azure-connection-string": "connection_string",
+		},
+		defaults: map[string]any{"expiration_secs": 60, "overwrite_files": true},
+		ignored:  []string{"azure-client-id", "azure-tenant-id"},
+	},
+}
+
+// PulpDomainReconciler reconciles a PulpDomain object
+type PulpDomainReconciler struct {
+	client.Client
+	RawLogger     logr.Logger
+	Scheme        *runtime.Scheme
+	NewPulpClient pulpobject.ClientFunc
+}
+
+//+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulpdomains,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulpdomains/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulpdomains/finalizers,verbs=update
+
+// Reconcile creates or updates the domain in Pulp through the REST API
+func (r *PulpDomainReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.RawLogger.WithValues("PulpDomain", req.NamespacedName)
+
+	domain := &pulpv1.PulpDomain{}
+	if err := r.Get(ctx, req.NamespacedName, domain); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get PulpDomain")
+		return ctrl.Result{}, err
+	}
+
+	return pulpobject.Reconcile(ctx, r.Client, r.NewPulpClient, log, pulpobject.Object{
+		Object:         domain,
+		DeploymentName: domain.Spec.DeploymentName,
+		DeletionPolicy: domain.Spec.DeletionPolicy,
+		Status:         &domain.Status.PulpObjectStatus,
+		Sync: func(ctx context.Context, api *pulpapi.Client) error {
+			return r.syncDomain(ctx, api, domain)
+		},
+		Remove: func(ctx context.Context, api *pulpapi.Client) error {
+			obj := pluginObject{path: pulpapi.DomainsPath, name: domain.Spec.Name, status: &domain.Status.PulpObjectStatus}
+			return obj.remove(ctx, api)
+		},
+	})
+}
+
+// syncDomain creates or updates the domain in Pulp
+func (r *PulpDomainReconciler) syncDomain(ctx context.Context, api *pulpapi.Client, domain *pulpv1.PulpDomain) error {
+	pulp := &pulpv1.Pulp{}
+	if err := r.Get(ctx, types.NamespacedName{Name: domain.Spec.DeploymentName, Namespace: domain.Namespace}, pulp); err != nil {
+		return err
+	}
+	if !controllers.DomainEnabled(ctx, r.Client, pulp) {
+		return fmt.Errorf("the domains are not enabled in Pulp %v (DOMAIN_ENABLED: true should be set in custom_pulp_settings)", pulp.Name)
+	}
+
+	storage, hash, err := r.domainStorage(ctx, domain)
+	if err != nil {
+		return err
+	}
+	obj := pluginObject{
+		path:   pulpapi.DomainsPath,
+		name:   domain.Spec.Name,
+		status: &domain.Status.PulpObjectStatus,
+		fields: map[string]any{
+			"name":                       domain.Spec.Name,
+			"description":                nilIfEmpty(domain.Spec.Description),
+			"redirect_to_object_storage": domain.Spec.RedirectToObjectStorage == nil || *domain.Spec.RedirectToObjectStorage,
+			"hide_guarded_distributions": domain.Spec.HideGuardedDistributions,
+		},
+		// the storage settings can have credentials, which are not returned by Pulp
+		writeOnly:     storage,
+		hash:          hash,
+		writeOnlyHash: &domain.Status.StorageHash,
+	}
+	if _, err := obj.sync(ctx, api); err != nil {
+		return err
+	}
+	domain.Status.ContentPath = controllers.GetDomainContentPath(ctx, r.Client, pulp, domain.Spec.Name)
+	return nil
+}
+
+// domainStorage returns the storage_class and storage_settings fields of the domain (from spec
+// and storage_secret) and their hash
+func (r *PulpDomainReconciler) domainStorage(ctx context.Context, domain *pulpv1.PulpDomain) (map[string]any, string, error) {
+	storageClass := domain.Spec.StorageClass
+	storageSettings := map[string]any{}
+	for setting, value := range domain.Spec.StorageSettings {
+		var decoded any
+		if err := json.Unmarshal(value.Raw, &decoded); err != nil {
+			return nil, "", fmt.Errorf("invalid value of storage setting %v: %v", setting, err)
+		}
+		storageSettings[setting] = decoded
+	}
+
+	if len(domain.Spec.StorageSecret) > 0 {
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Name: domain.Spec.StorageSecret, Namespace: domain.Namespace}, secret); err != nil {
+			return nil, "", fmt.Errorf("failed to get %v Secret: %v", domain.Spec.StorageSecret, err)
+		}
+		secretClass, secretSettings, err := storageSecretSettings(secret)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(storageClass) == 0 {
+			storageClass = secretClass
+		}
+		maps.Copy(storageSettings, secretSettings)
+	}
+
+	storage := map[string]any{"storage_class": storageClass, "storage_settings": storageSettings}
+	return storage, controllers.CalculateHash(storage), nil
+}
+
+// storageSecretSettings returns the storage class and settings from an object storage Secret.
+// Like in the Pulp CR storage, the keys of the Secret that are not handled by the operator are
+// passed through as settings (for example, the "s3-default-acl" key as the "default_acl" setting).
+func storageSecretSettings(secret *corev1.Secret) (string, map[string]any, error) {
+	for _, format := range storageSecretFormats {
+		if !slices.ContainsFunc(format.required, func(key string) bool { return len(secret.Data[key]) > 0 }) {
+			continue
+		}
+		for _, key := range format.required {
+			if len(secret.Data[key]) == 0 {
+				return "", nil, fmt.Errorf("the %v Secret does not have the %v key", secret.Name, key)
+			}
+		}
+		settings := maps.Clone(format.defaults)
+		for key, value := range secret.Data {
+			if !strings.HasPrefix(key, format.prefix) || slices.Contains(format.ignored, key) {
+				continue
+			}
+			if setting, ok := format.keys[key]; ok {
+				settings[setting] = string(value)
+				continue
+			}
+			settings[strings.ReplaceAll(strings.TrimPrefix(key, format.prefix), "-", "_")] = storageSettingValue(string(value))
+		}
+		return format.storageClass, settings, nil
+	}
+	return "", nil, fmt.Errorf("the %v Secret should have the keys of an S3 (s3-bucket-name) or Azure (azure-account-name) storage", secret.Name)
+}
+
+// storageSettingValue decodes the json values (like true, 10 or {"key": "value"}) from a Secret key,
+// the other values are kept as strings
+func storageSettingValue(value string) any {
+	value = strings.TrimSpace(value)
+	var decoded any
+	if err := json.Unmarshal([]byte(value), &decoded); err == nil {
+		return decoded
+	}
+	return value
+}
+
+// findDomainsForSecret enqueues the PulpDomains that reference the storage Secret
+func (r *PulpDomainReconciler) findDomainsForSecret(ctx context.Context, secret client.Object) []ctrl.Request {
+	domains := &pulpv1.PulpDomainList{}
+	if err := r.List(ctx, domains, client.InNamespace(secret.GetNamespace())); err != nil {
+		return nil
+	}
+	requests := []ctrl.Request{}
+	for _, domain := range domains.Items {
+		if domain.Spec.StorageSecret == secret.GetName() {
+			requests = append(requests, ctrl.Request{NamespacedName: types.NamespacedName{Name: domain.Name, Namespace: domain.Namespace}})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PulpDomainReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.NewPulpClient == nil {
+		r.NewPulpClient = pulpapi.NewForPulp
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&pulpv1.PulpDomain{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(
+			&pulpv1.Pulp{},
+			handler.EnqueueRequestsFromMapFunc(pulpobject.FindObjects(r.Client, &pulpv1.PulpDomainList{}, func(o client.Object) string { return o.(*pulpv1.PulpDomain).Spec.DeploymentName })),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.findDomainsForSecret),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		Complete(r)
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager_content
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/pulpapi"
+	"github.com/pulp/pulp-operator/controllers/pulpobject"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// PulpRemoteReconciler reconciles a PulpRemote object
+type PulpRemoteReconciler struct {
+	client.Client
+	RawLogger     logr.Logger
+	Scheme        *runtime.Scheme
+	NewPulpClient pulpobject.ClientFunc
+}
+
+//+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulpremotes,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulpremotes/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulpremotes/finalizers,verbs=update
+
+// Reconcile creates or updates the remote in Pulp through the REST API
+func (r *PulpRemoteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.RawLogger.WithValues("PulpRemote", req.NamespacedName)
+
+	remote := &pulpv1.PulpRemote{}
+	if err := r.Get(ctx, req.NamespacedName, remote); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get PulpRemote")
+		return ctrl.Result{}, err
+	}
+
+	return pulpobject.Reconcile(ctx, r.Client, r.NewPulpClient, log, pulpobject.Object{
+		Object:         remote,
+		DeploymentName: remote.Spec.DeploymentName,
+		DeletionPolicy: remote.Spec.DeletionPolicy,
+		Status:         &remote.Status.PulpObjectStatus,
+		Sync: func(ctx context.Context, api *pulpapi.Client) error {
+			obj, err := r.remoteObject(ctx, remote)
+			if err != nil {
+				return err
+			}
+			_, err = obj.sync(ctx, api)
+			return err
+		},
+		Remove: func(ctx context.Context, api *pulpapi.Client) error {
+			obj := pluginObject{path: pulpapi.RemotesPath(remote.Spec.Type), name: remote.Spec.Name, status: &remote.Status.PulpObjectStatus}
+			return obj.remove(ctx, api)
+		},
+	})
+}
+
+// remoteObject returns the fields of the remote in Pulp from spec
+func (r *PulpRemoteReconciler) remoteObject(ctx context.Context, remote *pulpv1.PulpRemote) (pluginObject, error) {
+	fields, err := withPluginFields(map[string]any{
+		"name":           remote.Spec.Name,
+		"url":            remote.Spec.URL,
+		"policy":         remote.Spec.Policy,
+		"tls_validation": remote.Spec.TLSValidation == nil || *remote.Spec.TLSValidation,
+		"proxy_url":      nilIfEmpty(remote.Spec.ProxyURL),
+	}, remote.Spec.PluginFields)
+	if err != nil {
+		return pluginObject{}, err
+	}
+
+	credentials, hash, err := r.remoteCredentials(ctx, remote)
+	if err != nil {
+		return pluginObject{}, err
+	}
+
+	return pluginObject{
+		path:          pulpapi.RemotesPath(remote.Spec.Type),
+		name:          remote.Spec.Name,
+		status:        &remote.Status.PulpObjectStatus,
+		fields:        fields,
+		writeOnly:     credentials,
+		hash:          hash,
+		writeOnlyHash: &remote.Status.CredentialsHash,
+	}, nil
+}
+
+// remoteCredentials returns the username and password fields from credentials_secret and their
+// hash (without the Secret, the credentials are cleared in Pulp)
+func (r *PulpRemoteReconciler) remoteCredentials(ctx context.Context, remote *pulpv1.PulpRemote) (map[string]any, string, error) {
+	if len(remote.Spec.CredentialsSecret) == 0 {
+		return map[string]any{"username": nil, "password": nil}, "", nil
+	}
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: remote.Spec.CredentialsSecret, Namespace: remote.Namespace}, secret); err != nil {
+		return nil, "", fmt.Errorf("failed to get %v Secret: %v", remote.Spec.CredentialsSecret, err)
+	}
+	username, password := string(secret.Data["username"]), string(secret.Data["password"])
+	if len(username) == 0 || len(password) == 0 {
+		return nil, "", fmt.Errorf("the %v Secret does not have the username and password keys", remote.Spec.CredentialsSecret)
+	}
+	return map[string]any{"username": username, "password": password}, controllers.CalculateHash(username + ":" + password), nil
+}
+
+// findRemotesForSecret enqueues the PulpRemotes that reference the credentials Secret
+func (r *PulpRemoteReconciler) findRemotesForSecret(ctx context.Context, secret client.Object) []ctrl.Request {
+	remotes := &pulpv1.PulpRemoteList{}
+	if err := r.List(ctx, remotes, client.InNamespace(secret.GetNamespace())); err != nil {
+		return nil
+	}
+	requests := []ctrl.Request{}
+	for _, remote := range remotes.Items {
+		if remote.Spec.CredentialsSecret == secret.GetName() {
+			requests = append(requests, ctrl.Request{NamespacedName: types.NamespacedName{Name: remote.Name, Namespace: remote.Namespace}})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PulpRemoteReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.NewPulpClient == nil {
+		r.NewPulpClient = pulpapi.NewForPulp
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&pulpv1.PulpRemote{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(
+			&pulpv1.Pulp{},
+			handler.EnqueueRequestsFromMapFunc(pulpobject.FindObjects(r.Client, &pulpv1.PulpRemoteList{}, func(o client.Object) string { return o.(*pulpv1.PulpRemote).Spec.DeploymentName })),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.findRemotesForSecret),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		Complete(r)
+}
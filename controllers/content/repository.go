@@ -0,0 +1,154 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager_content
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers/pulpapi"
+	"github.com/pulp/pulp-operator/controllers/pulpobject"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// PulpRepositoryReconciler reconciles a PulpRepository object
+type PulpRepositoryReconciler struct {
+	client.Client
+	RawLogger     logr.Logger
+	Scheme        *runtime.Scheme
+	NewPulpClient pulpobject.ClientFunc
+}
+
+//+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulprepositories,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulprepositories/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulprepositories/finalizers,verbs=update
+
+// Reconcile creates or updates the repository in Pulp through the REST API and syncs it from its remote
+func (r *PulpRepositoryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.RawLogger.WithValues("PulpRepository", req.NamespacedName)
+
+	repository := &pulpv1.PulpRepository{}
+	if err := r.Get(ctx, req.NamespacedName, repository); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get PulpRepository")
+		return ctrl.Result{}, err
+	}
+
+	return pulpobject.Reconcile(ctx, r.Client, r.NewPulpClient, log, pulpobject.Object{
+		Object:         repository,
+		DeploymentName: repository.Spec.DeploymentName,
+		DeletionPolicy: repository.Spec.DeletionPolicy,
+		Status:         &repository.Status.PulpObjectStatus,
+		Sync: func(ctx context.Context, api *pulpapi.Client) error {
+			return syncRepository(ctx, api, repository)
+		},
+		Remove: func(ctx context.Context, api *pulpapi.Client) error {
+			obj := pluginObject{path: pulpapi.RepositoriesPath(repository.Spec.Type), name: repository.Spec.Name, status: &repository.Status.PulpObjectStatus}
+			return obj.remove(ctx, api)
+		},
+	})
+}
+
+// syncRepository creates or updates the repository in Pulp and dispatches its sync from the remote
+// when the repository is created, the remote is modified in spec or the sync-request annotation is
+// modified. A failed sync is reported until a new one is dispatched.
+func syncRepository(ctx context.Context, api *pulpapi.Client, repository *pulpv1.PulpRepository) error {
+	status := &repository.Status
+
+	// the last sync is checked before the repository is retrieved, so that the latest version is up to date
+	if status.LastSync != nil {
+		if err := pulpobject.PollTask(ctx, api, &status.LastSync.PulpTaskStatus); err != nil && !pulpapi.TaskFinished(status.LastSync.State) {
+			return err
+		}
+	}
+
+	remoteHref := ""
+	if len(repository.Spec.Remote) > 0 {
+		href, err := api.FindObjectHref(ctx, pulpapi.RemotesPath(repository.Spec.Type), repository.Spec.Remote)
+		if err != nil {
+			return err
+		}
+		remoteHref = href
+	}
+
+	fields, err := withPluginFields(map[string]any{
+		"name":                 repository.Spec.Name,
+		"description":          nilIfEmpty(repository.Spec.Description),
+		"retain_repo_versions": repository.Spec.RetainRepoVersions,
+		"remote":               nilIfEmpty(remoteHref),
+	}, repository.Spec.PluginFields)
+	if err != nil {
+		return err
+	}
+
+	obj := pluginObject{path: pulpapi.RepositoriesPath(repository.Spec.Type), name: repository.Spec.Name, status: &status.PulpObjectStatus, fields: fields}
+	current, err := obj.sync(ctx, api)
+	if err != nil {
+		return err
+	}
+	if latestVersion, ok := current["latest_version_href"].(string); ok {
+		status.LatestVersionHref = latestVersion
+	}
+
+	if len(remoteHref) == 0 {
+		return nil
+	}
+	request := repository.Annotations[pulpv1.SyncRequestAnnotation]
+	if last := status.LastSync; last != nil && last.Remote == repository.Spec.Remote && last.Request == request {
+		if last.State != pulpapi.TaskCompleted {
+			return fmt.Errorf("sync from remote %v %v: %v", last.Remote, last.State, last.Error)
+		}
+		return nil
+	}
+
+	task, err := api.SyncRepository(ctx, current.Href(), remoteHref, repository.Spec.Mirror)
+	if err != nil {
+		return err
+	}
+	status.LastSync = &pulpv1.PulpRepositorySyncStatus{
+		PulpTaskStatus: pulpv1.PulpTaskStatus{Href: task, State: pulpapi.TaskWaiting},
+		Remote:         repository.Spec.Remote,
+		Request:        request,
+	}
+	return &pulpobject.TaskRunningError{Href: task}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PulpRepositoryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.NewPulpClient == nil {
+		r.NewPulpClient = pulpapi.NewForPulp
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		// the annotations are also watched because of the sync-request annotation
+		For(&pulpv1.PulpRepository{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		Watches(
+			&pulpv1.Pulp{},
+			handler.EnqueueRequestsFromMapFunc(pulpobject.FindObjects(r.Client, &pulpv1.PulpRepositoryList{}, func(o client.Object) string { return o.(*pulpv1.PulpRepository).Spec.DeploymentName })),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		Complete(r)
+}
@@ -38,6 +38,7 @@ import (
 type CommonDeployment struct {
 	replicas                          int32
 	podLabels                         map[string]string
+	podTemplateLabels                 map[string]string
 	deploymentLabels                  map[string]string
 	affinity                          *corev1.Affinity
 	strategy                          appsv1.DeploymentStrategy
@@ -87,7 +88,7 @@ func (d CommonDeployment) Deploy(resources any, pulpcoreType settings.PulpcoreTy
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels:      d.podLabels,
+					Labels:      d.podTemplateLabels,
 					Annotations: d.podAnnotations,
 				},
 				Spec: corev1.PodSpec{
@@ -148,14 +149,21 @@ func (d DeploymentWorkerCommon) Deploy(resources any) client.Object {
 // setReplicas defines the number of pod replicas
 func (d *CommonDeployment) setReplicas(pulp pulpv1.Pulp, pulpcoreType settings.PulpcoreType) {
 	d.replicas = int32(reflect.ValueOf(pulp.Spec).FieldByName(string(pulpcoreType)).FieldByName("Replicas").Int())
+
+	// keep the pods scaled down while the artifacts are copied to the new storage backend
+	if StorageMigrationInProgress(&pulp) {
+		d.replicas = 0
+	}
 }
 
 // setLabels defines the pod and deployment labels
 func (d *CommonDeployment) setLabels(pulp pulpv1.Pulp, pulpcoreType settings.PulpcoreType) {
 	d.podLabels = settings.PulpcoreLabels(pulp, strings.ToLower(string(pulpcoreType)))
 	d.deploymentLabels = make(map[string]string)
+	d.podTemplateLabels = make(map[string]string)
 	for k, v := range d.podLabels {
 		d.deploymentLabels[k] = v
+		d.podTemplateLabels[k] = v
 	}
 }
 
@@ -253,8 +261,10 @@ func (d *CommonDeployment) setEnvVars(resources any, pulpcoreType settings.Pulpc
 	// add postgres env vars
 	envVars = append(envVars, GetPostgresEnvVars(*pulp)...)
 
-	// add cache configuration if enabled
-	if pulp.Spec.Cache.Enabled {
+	// add cache configuration if enabled.
+	// In sentinel mode, there is no Service for the Redis master, it is
+	// discovered through the Sentinel pods in settings.py.
+	if pulp.Spec.Cache.Enabled && !CacheSentinelEnabled(*pulp) {
 
 		// if there is no ExternalCacheSecret defined, we should
 		// use the redis instance provided by the operator
@@ -305,7 +315,12 @@ func (d *CommonDeployment) setEnvVars(resources any, pulpcoreType settings.Pulpc
 							Key: "REDIS_DB",
 						},
 					},
-				}, {
+				},
+			}
+			// the password provided by a SecretProviderClass is not in the Secret, it is
+			// read by settings.py from the file mounted in the pods
+			if len(SecretsStoreClass(pulp, SecretsStoreCache)) == 0 {
+				redisEnvVars = append(redisEnvVars, corev1.EnvVar{
 					Name: "REDIS_SERVICE_PASSWORD",
 					ValueFrom: &corev1.EnvVarSource{
 						SecretKeyRef: &corev1.SecretKeySelector{
@@ -315,16 +330,16 @@ func (d *CommonDeployment) setEnvVars(resources any, pulpcoreType settings.Pulpc
 							Key: "REDIS_PASSWORD",
 						},
 					},
-				},
+				})
 			}
 			envVars = append(envVars, redisEnvVars...)
 		}
 	}
 
-	if pulp.Spec.SigningSecret != "" {
+	if SigningEnabled(pulp) {
 
 		// for now, we are just dumping the error, but we should handle it
-		signingKeyFingerprint, _ := GetSigningKeyFingerprint(ctx, resources.(FunctionResources).Client, pulp.Spec.SigningSecret, pulp.Namespace)
+		signingKeyFingerprint, _ := SigningKeyFingerprint(ctx, resources.(FunctionResources).Client, pulp)
 
 		signingKeyEnvVars := []corev1.EnvVar{
 			{Name: "PULP_SIGNING_KEY_FINGERPRINT", Value: signingKeyFingerprint},
@@ -535,7 +550,7 @@ func (d *CommonDeployment) setVolumes(resources any, pulpcoreType settings.Pulpc
 // signingMetadataVolumes defines the volumes for the signing metadata services
 func signingMetadataVolumes(resources any, storageType []string, volumes []corev1.Volume) []corev1.Volume {
 	pulp := *resources.(FunctionResources).Pulp
-	if pulp.Spec.SigningSecret != "" {
+	if SigningEnabled(&pulp) {
 		if storageType[0] != SCNameType && storageType[0] != PVCType {
 			ephemeralGpg := corev1.Volume{
 				Name: "ephemeral-gpg",
@@ -571,20 +586,7 @@ func signingMetadataVolumes(resources any, storageType []string, volumes []corev
 		}
 		volumePermissions := int32(0755)
 		signingSecretVolume := []corev1.Volume{
-			{
-				Name: "gpg-keys",
-				VolumeSource: corev1.VolumeSource{
-					Secret: &corev1.SecretVolumeSource{
-						SecretName: pulp.Spec.SigningSecret,
-						Items: []corev1.KeyToPath{
-							{
-								Key:  "signing_service.gpg",
-								Path: "signing_service.gpg",
-							},
-						},
-					},
-				},
-			},
+			SigningKeyVolume(&pulp),
 			{
 				Name: pulp.Name + "-signing-scripts",
 				VolumeSource: corev1.VolumeSource{
@@ -657,7 +659,7 @@ func (d *CommonDeployment) setVolumeMounts(pulp pulpv1.Pulp, pulpcoreType settin
 		volumeMounts = append(volumeMounts, fileStorageMount)
 	}
 
-	if pulp.Spec.SigningSecret != "" {
+	if SigningEnabled(&pulp) {
 		if storageType[0] != SCNameType && storageType[0] != PVCType {
 			signingSecretMount := corev1.VolumeMount{
 				Name:      "ephemeral-gpg",
@@ -746,18 +748,29 @@ func (d *CommonDeployment) setInitContainerResourceRequirements(pulp pulpv1.Pulp
 func (d *CommonDeployment) setReadinessProbe(resources any, pulp pulpv1.Pulp, pulpcoreType settings.PulpcoreType) {
 	readinessProbe := reflect.ValueOf(pulp.Spec).FieldByName(string(pulpcoreType)).FieldByName("ReadinessProbe").Interface().(*corev1.Probe)
 	ctx := resources.(FunctionResources).Context
+	// readyz.py only knows about plain HTTP, so with internal TLS the status
+	// endpoints are checked by the kubelet (which does not verify the certificate)
+	readinessHandler := func(path string, port int32) corev1.ProbeHandler {
+		if InternalTLSEnabled(&pulp) {
+			return corev1.ProbeHandler{
+				HTTPGet: &corev1.HTTPGetAction{
+					Path:   path,
+					Port:   intstr.IntOrString{IntVal: port},
+					Scheme: corev1.URISchemeHTTPS,
+				},
+			}
+		}
+		return corev1.ProbeHandler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"/usr/bin/readyz.py", path},
+			},
+		}
+	}
 	switch pulpcoreType {
 	case settings.API:
 		if readinessProbe == nil {
 			readinessProbe = &corev1.Probe{
-				ProbeHandler: corev1.ProbeHandler{
-					Exec: &corev1.ExecAction{
-						Command: []string{
-							"/usr/bin/readyz.py",
-							GetAPIRoot(ctx, resources.(FunctionResources).Client, &pulp) + "api/v3/status/",
-						},
-					},
-				},
+				ProbeHandler:        readinessHandler(GetAPIRoot(ctx, resources.(FunctionResources).Client, &pulp)+"api/v3/status/", 24817),
 				FailureThreshold:    1,
 				InitialDelaySeconds: 3,
 				PeriodSeconds:       10,
@@ -768,14 +781,7 @@ func (d *CommonDeployment) setReadinessProbe(resources any, pulp pulpv1.Pulp, pu
 	case settings.CONTENT:
 		if readinessProbe == nil {
 			readinessProbe = &corev1.Probe{
-				ProbeHandler: corev1.ProbeHandler{
-					Exec: &corev1.ExecAction{
-						Command: []string{
-							"/usr/bin/readyz.py",
-							GetContentPathPrefix(ctx, resources.(FunctionResources).Client, &pulp),
-						},
-					},
-				},
+				ProbeHandler:        readinessHandler(GetContentPathPrefix(ctx, resources.(FunctionResources).Client, &pulp), 24816),
 				FailureThreshold:    1,
 				InitialDelaySeconds: 3,
 				PeriodSeconds:       10,
@@ -820,7 +826,7 @@ func (d *CommonDeployment) setLivenessProbe(resources any, pulp pulpv1.Pulp, pul
 						Port: intstr.IntOrString{
 							IntVal: 24817,
 						},
-						Scheme: corev1.URIScheme("HTTP"),
+						Scheme: corev1.URIScheme(strings.ToUpper(InternalTLSScheme(&pulp))),
 					},
 				},
 				InitialDelaySeconds: 3,
@@ -882,7 +888,7 @@ func (d *CommonDeployment) setInitContainers(resources any, pulp pulpv1.Pulp, pu
 		},
 	}
 
-	if len(pulp.Spec.SigningSecret) > 0 {
+	if SigningEnabled(&pulp) {
 		initContainers = append(initContainers, setGpgInitContainer(resources, pulp))
 	}
 	d.initContainers = initContainers
@@ -926,7 +932,7 @@ func setGpgInitContainer(resources any, pulp pulpv1.Pulp) corev1.Container {
 		},
 	}
 
-	signingKeyFingerprint, _ := GetSigningKeyFingerprint(ctx, resources.(FunctionResources).Client, pulp.Spec.SigningSecret, pulp.Namespace)
+	signingKeyFingerprint, _ := SigningKeyFingerprint(ctx, resources.(FunctionResources).Client, &pulp)
 
 	// env vars
 	envVars := []corev1.EnvVar{{Name: "PULP_SIGNING_KEY_FINGERPRINT", Value: signingKeyFingerprint}}
@@ -963,42 +969,66 @@ func pulpcoreApiContainerArgs(pulp pulpv1.Pulp) []string {
 	}
 	return []string{
 		"-c",
-		`if which pulpcore-api
-then
-  PULP_API_ENTRYPOINT=("pulpcore-api")
-else
-  PULP_API_ENTRYPOINT=("gunicorn" "pulpcore.app.wsgi:application" "--name" "pulp-api" "--access-logformat" "pulp [%({correlation-id}o)s]: %(h)s %(l)s %(u)s %(t)s \"%(r)s\" %(s)s %(b)s \"%(f)s\" \"%(a)s\"")
-fi
+		pulpcoreApiEntrypoint(pulp) + `
 exec "${PULP_API_ENTRYPOINT[@]}" \
 --bind "` + gunicornBindAddress + `" \
 --timeout "${PULP_GUNICORN_TIMEOUT}" \
 --workers "${PULP_API_WORKERS}" \
---access-logfile -`,
+--access-logfile -` + gunicornTLSArgs(pulp),
 	}
 }
 
+// pulpcoreApiEntrypoint returns the script that defines the pulpcore-api entrypoint.
+// With internal TLS the certificate options are passed straight to gunicorn.
+func pulpcoreApiEntrypoint(pulp pulpv1.Pulp) string {
+	gunicorn := `PULP_API_ENTRYPOINT=("gunicorn" "pulpcore.app.wsgi:application" "--name" "pulp-api" "--access-logformat" "pulp [%({correlation-id}o)s]: %(h)s %(l)s %(u)s %(t)s \"%(r)s\" %(s)s %(b)s \"%(f)s\" \"%(a)s\"")`
+	if InternalTLSEnabled(&pulp) {
+		return gunicorn
+	}
+	return `if which pulpcore-api
+then
+  PULP_API_ENTRYPOINT=("pulpcore-api")
+else
+  ` + gunicorn + `
+fi`
+}
+
 func pulpcoreContentContainerArgs(pulp pulpv1.Pulp) []string {
 	gunicornBindAddress := "[::]:24816"
 	if Ipv6Disabled(pulp) {
 		gunicornBindAddress = "0.0.0.0:24816"
 	}
-	return []string{
-		"-c",
-		`if which pulpcore-content
+	entrypoint := `if which pulpcore-content
 then
   PULP_CONTENT_ENTRYPOINT=("pulpcore-content")
 else
   PULP_CONTENT_ENTRYPOINT=("gunicorn" "pulpcore.content:server" "--worker-class" "aiohttp.GunicornWebWorker" "--name" "pulp-content")
-fi
+fi`
+	if InternalTLSEnabled(&pulp) {
+		entrypoint = `PULP_CONTENT_ENTRYPOINT=("gunicorn" "pulpcore.content:server" "--worker-class" "aiohttp.GunicornWebWorker" "--name" "pulp-content")`
+	}
+	return []string{
+		"-c",
+		entrypoint + `
 exec "${PULP_CONTENT_ENTRYPOINT[@]}" \
 --bind "` + gunicornBindAddress + `" \
 --timeout "${PULP_GUNICORN_TIMEOUT}" \
 --workers "${PULP_CONTENT_WORKERS}" \
---access-logfile -
+--access-logfile -` + gunicornTLSArgs(pulp) + `
 `,
 	}
 }
 
+// gunicornTLSArgs returns the gunicorn options to serve HTTPS with the services certificate
+func gunicornTLSArgs(pulp pulpv1.Pulp) string {
+	if !InternalTLSEnabled(&pulp) {
+		return ""
+	}
+	return ` \
+--certfile "` + InternalTLSPath + `/tls.crt" \
+--keyfile "` + InternalTLSPath + `/tls.key"`
+}
+
 // setContainers defines pulpcore containers specs
 func (d *CommonDeployment) setContainers(pulp pulpv1.Pulp, pulpcoreType settings.PulpcoreType) {
 	securityContext := SetDefaultSecurityContext()
@@ -1144,10 +1174,13 @@ func (d *CommonDeployment) setLDAPConfigs(resources any) {
 	d.volumes = append(d.volumes, volume)
 
 	// retrieve the cert mountPoint from LDAP config Secret
-	secretName := pulp.Spec.LDAP.Config
-	secret := &corev1.Secret{}
-	client.Get(ctx, types.NamespacedName{Name: secretName, Namespace: pulp.Namespace}, secret)
-	mountPoint := string(secret.Data["auth_ldap_ca_file"])
+	mountPoint := LDAPCAPath
+	if !LDAPStructured(pulp) {
+		secretName := pulp.Spec.LDAP.Config
+		secret := &corev1.Secret{}
+		client.Get(ctx, types.NamespacedName{Name: secretName, Namespace: pulp.Namespace}, secret)
+		mountPoint = string(secret.Data["auth_ldap_ca_file"])
+	}
 
 	// mount the CA Secret
 	volumeMount := corev1.VolumeMount{
@@ -1159,6 +1192,131 @@ func (d *CommonDeployment) setLDAPConfigs(resources any) {
 	d.volumeMounts = append(d.volumeMounts, volumeMount)
 }
 
+// setGCSCredentials mounts the service account key from the gcs object storage Secret
+// and points GOOGLE_APPLICATION_CREDENTIALS to it, so that django-storages can authenticate
+// with Google Cloud Storage
+func (d *CommonDeployment) setGCSCredentials(resources any) {
+	pulp := resources.(FunctionResources).Pulp
+	if len(pulp.Spec.ObjectStorageGCSSecret) == 0 {
+		return
+	}
+
+	// the key provided by a SecretProviderClass is mounted with the other secrets store volumes
+	if len(SecretsStoreClass(pulp, SecretsStoreGCS)) > 0 {
+		d.envVars = append(d.envVars, corev1.EnvVar{Name: "GOOGLE_APPLICATION_CREDENTIALS", Value: SecretsStoreFile(SecretsStoreGCS, "gcs-credentials")})
+		return
+	}
+
+	ctx := resources.(FunctionResources).Context
+	client := resources.(FunctionResources).Client
+
+	// if no key file is provided we will rely on the application default credentials
+	// (for example, from the metadata server)
+	secret := &corev1.Secret{}
+	client.Get(ctx, types.NamespacedName{Name: pulp.Spec.ObjectStorageGCSSecret, Namespace: pulp.Namespace}, secret)
+	if _, found := secret.Data["gcs-credentials"]; !found {
+		return
+	}
+
+	volumeName := "gcs-credentials"
+	volume := corev1.Volume{
+		Name: volumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: pulp.Spec.ObjectStorageGCSSecret,
+				Items: []corev1.KeyToPath{{
+					Key:  "gcs-credentials",
+					Path: "gcs-credentials.json",
+				}},
+			},
+		},
+	}
+	d.volumes = append(d.volumes, volume)
+
+	volumeMount := corev1.VolumeMount{
+		Name:      volumeName,
+		MountPath: GCSCredentialsPath,
+		SubPath:   "gcs-credentials.json",
+		ReadOnly:  true,
+	}
+	d.volumeMounts = append(d.volumeMounts, volumeMount)
+	d.envVars = append(d.envVars, corev1.EnvVar{Name: "GOOGLE_APPLICATION_CREDENTIALS", Value: GCSCredentialsPath})
+}
+
+// setWorkloadIdentity adds the label expected by the Azure Workload Identity webhook
+// to inject the federated token into the pods. The label is not part of the
+// selector because it would make the Deployment immutable.
+func (d *CommonDeployment) setWorkloadIdentity(resources any) {
+	pulp := resources.(FunctionResources).Pulp
+	ctx := resources.(FunctionResources).Context
+	client := resources.(FunctionResources).Client
+
+	if AzureWorkloadIdentityEnabled(ctx, client, pulp) {
+		d.podTemplateLabels[AzureWIUseLabel] = "true"
+	}
+}
+
+// setInternalTLS mounts the services certificate in pulpcore-api and pulpcore-content pods.
+// gunicorn does not reload the certificate, so a hash of it is kept in the pod template
+// to roll out the pods when the certificate is renewed.
+func (d *CommonDeployment) setInternalTLS(resources any, pulpcoreType settings.PulpcoreType) {
+	pulp := resources.(FunctionResources).Pulp
+	if !InternalTLSEnabled(pulp) || pulpcoreType == settings.WORKER {
+		return
+	}
+
+	ctx := resources.(FunctionResources).Context
+	client := resources.(FunctionResources).Client
+
+	volumeName := "internal-tls"
+	volume := corev1.Volume{
+		Name: volumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: settings.InternalTLSSecret(pulp.Name),
+				Items: []corev1.KeyToPath{
+					{Key: corev1.TLSCertKey, Path: corev1.TLSCertKey},
+					{Key: corev1.TLSPrivateKeyKey, Path: corev1.TLSPrivateKeyKey},
+				},
+			},
+		},
+	}
+	d.volumes = append(d.volumes, volume)
+
+	volumeMount := corev1.VolumeMount{
+		Name:      volumeName,
+		MountPath: InternalTLSPath,
+		ReadOnly:  true,
+	}
+	d.volumeMounts = append(d.volumeMounts, volumeMount)
+
+	secret := &corev1.Secret{}
+	if err := client.Get(ctx, types.NamespacedName{Name: settings.InternalTLSSecret(pulp.Name), Namespace: pulp.Namespace}, secret); err == nil {
+		d.podAnnotations["repo-manager.pulpproject.org/internal-tls-hash"] = CalculateHash(secret.Data[corev1.TLSCertKey])
+	}
+}
+
+// setOIDC mounts the pulp_oidc python module, which is referenced by the OIDC settings,
+// in the pulpcore containers and in the init-container (django checks import the urls)
+func (d *CommonDeployment) setOIDC(resources any) {
+	pulp := resources.(FunctionResources).Pulp
+	if !OIDCEnabled(pulp) {
+		return
+	}
+	d.volumes = append(d.volumes, OIDCModuleVolume(pulp))
+	d.volumeMounts = append(d.volumeMounts, OIDCModuleVolumeMount())
+	d.initContainerVolumeMounts = append(d.initContainerVolumeMounts, OIDCModuleVolumeMount())
+}
+
+// setSecretsStore mounts the SecretProviderClasses with the credentials read by settings.py
+// in the pulpcore containers and in the init-container (django checks load the settings)
+func (d *CommonDeployment) setSecretsStore(resources any) {
+	pulp := resources.(FunctionResources).Pulp
+	d.volumes = append(d.volumes, SecretsStoreVolumes(pulp)...)
+	d.volumeMounts = append(d.volumeMounts, SecretsStoreVolumeMounts(pulp)...)
+	d.initContainerVolumeMounts = append(d.initContainerVolumeMounts, SecretsStoreVolumeMounts(pulp)...)
+}
+
 // build constructs the fields used in the deployment specification
 func (d *CommonDeployment) build(resources any, pulpcoreType settings.PulpcoreType) {
 	pulp := resources.(FunctionResources).Pulp
@@ -1183,6 +1341,11 @@ func (d *CommonDeployment) build(resources any, pulpcoreType settings.PulpcoreTy
 	d.setInitContainerVolumeMounts(*pulp)
 	d.setInitContainerEnvVars(resources, pulpcoreType)
 	d.setLDAPConfigs(resources)
+	d.setGCSCredentials(resources)
+	d.setWorkloadIdentity(resources)
+	d.setInternalTLS(resources, pulpcoreType)
+	d.setOIDC(resources)
+	d.setSecretsStore(resources)
 	d.setInitContainers(resources, *pulp, pulpcoreType)
 	d.setContainers(*pulp, pulpcoreType)
 	d.setRestartPolicy()
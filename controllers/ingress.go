@@ -17,6 +17,7 @@ limitations under the License.
 package controllers
 
 import (
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
 	"github.com/pulp/pulp-operator/controllers/settings"
 	netv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -60,28 +61,19 @@ func IngressDefaults(resources any, plugins []IngressPlugin) (*netv1.Ingress, er
 		annotation[key] = val
 	}
 
-	hostname := pulp.Spec.IngressHost
+	// the content_host only exposes the content paths (proxied by pulp-web)
+	funcResources := resources.(FunctionResources)
+	contentPaths := []netv1.HTTPIngressPath{{
+		Path:     GetContentPathPrefix(funcResources.Context, funcResources.Client, pulp),
+		PathType: &pathType,
+		Backend:  path.Backend,
+	}}
+
+	hosts := IngressHosts(pulp)
 	ingressSpec := netv1.IngressSpec{
 		IngressClassName: &pulp.Spec.IngressClassName,
-		Rules: []netv1.IngressRule{
-			{
-				Host: hostname,
-				IngressRuleValue: netv1.IngressRuleValue{
-					HTTP: &netv1.HTTPIngressRuleValue{
-						Paths: paths,
-					},
-				},
-			},
-		},
-	}
-
-	if len(pulp.Spec.IngressTLSSecret) > 0 {
-		ingressSpec.TLS = []netv1.IngressTLS{
-			{
-				Hosts:      []string{hostname},
-				SecretName: pulp.Spec.IngressTLSSecret,
-			},
-		}
+		Rules:            IngressRules(hosts, paths, contentPaths),
+		TLS:              IngressTLS(hosts),
 	}
 	labels := settings.CommonLabels(*pulp)
 
@@ -97,3 +89,57 @@ func IngressDefaults(resources any, plugins []IngressPlugin) (*netv1.Ingress, er
 	ctrl.SetControllerReference(pulp, ingress, resources.(FunctionResources).Scheme)
 	return ingress, nil
 }
+
+// IngressRules returns an IngressRule with the paths provided for each host.
+// The content_host rule only gets the contentPaths.
+func IngressRules(hosts []PulpHost, paths, contentPaths []netv1.HTTPIngressPath) []netv1.IngressRule {
+	var rules []netv1.IngressRule
+	for _, host := range hosts {
+		hostPaths := paths
+		if host.ContentOnly {
+			hostPaths = contentPaths
+		}
+		// an IngressRule needs at least one path
+		if len(hostPaths) == 0 {
+			continue
+		}
+		rules = append(rules, netv1.IngressRule{
+			Host: host.Host,
+			IngressRuleValue: netv1.IngressRuleValue{
+				HTTP: &netv1.HTTPIngressRuleValue{
+					Paths: hostPaths,
+				},
+			},
+		})
+	}
+	return rules
+}
+
+// ContentPaths returns the paths forwarded to the pulpcore-content service
+func ContentPaths(pulp *pulpv1.Pulp, paths []netv1.HTTPIngressPath) []netv1.HTTPIngressPath {
+	var contentPaths []netv1.HTTPIngressPath
+	for _, path := range paths {
+		if path.Backend.Service != nil && path.Backend.Service.Name == settings.ContentService(pulp.Name) {
+			contentPaths = append(contentPaths, path)
+		}
+	}
+	return contentPaths
+}
+
+// IngressTLS returns the IngressTLS list grouping the hosts by TLS Secret
+func IngressTLS(hosts []PulpHost) []netv1.IngressTLS {
+	var ingressTLS []netv1.IngressTLS
+	secretIndex := map[string]int{}
+	for _, host := range hosts {
+		if len(host.TLSSecret) == 0 {
+			continue
+		}
+		if i, found := secretIndex[host.TLSSecret]; found {
+			ingressTLS[i].Hosts = append(ingressTLS[i].Hosts, host.Host)
+			continue
+		}
+		secretIndex[host.TLSSecret] = len(ingressTLS)
+		ingressTLS = append(ingressTLS, netv1.IngressTLS{Hosts: []string{host.Host}, SecretName: host.TLSSecret})
+	}
+	return ingressTLS
+}
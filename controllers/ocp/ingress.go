@@ -17,6 +17,7 @@ limitations under the License.
 package ocp
 
 import (
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
 	"github.com/pulp/pulp-operator/controllers"
 	netv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -87,6 +88,7 @@ func deployOCPIngressRedirect(resources controllers.FunctionResources, plugins [
 		return nil
 	}
 
+	setInternalTLSAnnotations(pulp, redirectAnnotation)
 	for key, val := range pulp.Spec.IngressAnnotations {
 		redirectAnnotation[key] = val
 	}
@@ -94,11 +96,7 @@ func deployOCPIngressRedirect(resources controllers.FunctionResources, plugins [
 	expectedIngress.ObjectMeta.Annotations = redirectAnnotation
 	expectedIngress.ObjectMeta.Name = ingressName
 	expectedIngress.Spec.IngressClassName = &pulp.Spec.IngressClassName
-	expectedIngress.Spec.Rules[0].IngressRuleValue = netv1.IngressRuleValue{
-		HTTP: &netv1.HTTPIngressRuleValue{
-			Paths: redirectPaths,
-		},
-	}
+	expectedIngress.Spec.Rules = controllers.IngressRules(controllers.IngressHosts(pulp), redirectPaths, controllers.ContentPaths(pulp, redirectPaths))
 
 	// [TODO] Refactor this. We should not be deploying the ingress here (through this function).
 	// For now, keeping the same approach as of the old commits/implementation while I cannot find a
@@ -168,6 +166,7 @@ func deployOCPIngress(resources controllers.FunctionResources, plugins []control
 		hAProxyTimeout = "180s"
 	}
 	annotations["haproxy.router.openshift.io/timeout"] = hAProxyTimeout
+	setInternalTLSAnnotations(pulp, annotations)
 
 	for key, val := range pulp.Spec.IngressAnnotations {
 		annotations[key] = val
@@ -175,7 +174,7 @@ func deployOCPIngress(resources controllers.FunctionResources, plugins []control
 
 	ingress.ObjectMeta.Annotations = annotations
 	ingress.Spec.IngressClassName = &pulp.Spec.IngressClassName
-	ingress.Spec.Rules[0].HTTP.Paths = paths
+	ingress.Spec.Rules = controllers.IngressRules(controllers.IngressHosts(pulp), paths, controllers.ContentPaths(pulp, paths))
 	return ingress, nil
 }
 
@@ -199,3 +198,18 @@ func UpdateIngressClass(resources controllers.FunctionResources) {
 		}
 	}
 }
+
+// setInternalTLSAnnotations configures the Routes generated from the Ingresses to re-encrypt
+// the requests to pulpcore-api and pulpcore-content.
+// The router expects the destination CA in the tls.crt key, which is how the CA Secret
+// is stored (cert-manager issued certificates do not have a CA Secret, in this case
+// ingress_type: route should be used).
+func setInternalTLSAnnotations(pulp *pulpv1.Pulp, annotations map[string]string) {
+	if !controllers.InternalTLSEnabled(pulp) {
+		return
+	}
+	annotations["route.openshift.io/termination"] = "reencrypt"
+	if !controllers.InternalTLSCertManager(pulp) {
+		annotations["route.openshift.io/destination-ca-certificate-secret"] = controllers.InternalTLSCASecret(pulp)
+	}
+}
@@ -18,21 +18,19 @@ package ocp
 
 import (
 	"context"
-	"encoding/json"
-	"time"
+	"strconv"
 
 	"github.com/go-logr/logr"
 	routev1 "github.com/openshift/api/route/v1"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
 	"github.com/pulp/pulp-operator/controllers"
 	"github.com/pulp/pulp-operator/controllers/settings"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
-	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -52,15 +50,9 @@ type RoutePlugin struct {
 	Rewrite     string `json:"rewrite"`
 }
 
-// PodExec contains the configs to execute a command inside a pod
-type PodExec struct {
-	RESTClient rest.Interface
-	RESTConfig *rest.Config
-	Scheme     *runtime.Scheme
-}
-
-// PulpRouteController creates the routes based on snippets defined in pulp-worker pod
-func PulpRouteController(resources controllers.FunctionResources, restClient rest.Interface, restConfig *rest.Config) (ctrl.Result, error) {
+// PulpRouteController creates a route for each path exposed by pulpcore and its plugins
+// (see the plugin paths discovery in repo_manager)
+func PulpRouteController(resources controllers.FunctionResources, plugins []controllers.IngressPlugin) (ctrl.Result, error) {
 
 	pulp := resources.Pulp
 	log := resources.Logger
@@ -69,87 +61,25 @@ func PulpRouteController(resources controllers.FunctionResources, restClient res
 	// conditionType is used to update .status.conditions with the current resource state
 	conditionType := "Pulp-Route-Ready"
 
-	podList := &corev1.PodList{}
-	labels := settings.PulpcoreLabels(*pulp, "worker")
-	listOpts := []client.ListOption{
-		client.InNamespace(pulp.Namespace),
-		client.MatchingLabels(labels),
-	}
-	if err := resources.Client.List(ctx, podList, listOpts...); err != nil {
-		log.Error(err, "Failed to list Worker pods", "Pulp.Namespace", pulp.Namespace, "Pulp.Name", pulp.Name)
-		return ctrl.Result{RequeueAfter: time.Minute}, nil
-	}
-	var isPodRunning bool = false
-	var pod = corev1.Pod{}
-	for _, p := range podList.Items {
-		log.V(1).Info("Checking Worker pod", "Pod", p.Name, "Status", p.Status.Phase)
-		if p.Status.Phase == "Running" {
-			log.V(1).Info("Running!", "Pod", p.Name, "Status", p.Status.Phase)
-			isPodRunning = true
-			pod = p
-			break
-		} else {
-			log.Info("Worker Pod isn't running yet!", "Pod", p.Name, "Status", p.Status.Phase)
-		}
-	}
-
-	if !isPodRunning {
-		log.Info("Worker pod isn't running yet!")
-		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
-	}
-	execCmd := []string{
-		"/usr/bin/route_paths.py", pulp.Name,
-	}
-	cmdOutput, err := controllers.ContainerExec(ctx, PodExec{restClient, restConfig, resources.Scheme}, &pod, execCmd, "worker", pod.Namespace)
-	if err != nil {
-		controllers.CustomZapLogger().Warn(err.Error() + " Failed to get routes from " + pod.Name)
-		controllers.UpdateStatus(ctx, resources.Client, pulp, metav1.ConditionFalse, conditionType, "Failed to get routes!", "FailedGet"+pod.Name)
-		return ctrl.Result{Requeue: true}, nil
-	}
 	var pulpPlugins []RoutePlugin
-	json.Unmarshal([]byte(cmdOutput), &pulpPlugins)
-	defaultPlugins := []RoutePlugin{
-		{
-			Name:        pulp.Name + "-content",
-			Path:        controllers.GetContentPathPrefix(ctx, resources.Client, pulp),
-			TargetPort:  "content-24816",
-			ServiceName: settings.ContentService(pulp.Name),
-		},
-		{
-			Name:        pulp.Name + "-api-v3",
-			Path:        controllers.GetAPIRoot(ctx, resources.Client, pulp) + "api/v3/",
-			TargetPort:  "api-24817",
-			ServiceName: settings.ApiService(pulp.Name),
-		},
-		{
-			Name:        pulp.Name + "-auth",
-			Path:        "/auth/login/",
-			TargetPort:  "api-24817",
-			ServiceName: settings.ApiService(pulp.Name),
-		},
-		{
-			Name:        pulp.Name,
-			Path:        "/",
-			TargetPort:  "api-24817",
-			ServiceName: settings.ApiService(pulp.Name),
-		},
+	for _, plugin := range plugins {
+		pulpPlugins = append(pulpPlugins, RoutePlugin(plugin))
 	}
-	routeHost := GetRouteHost(pulp)
-	pulpPlugins = append(defaultPlugins, pulpPlugins...)
+	hostRoutes := pulpHostRoutes(pulp, pulpPlugins)
 
 	// channel used to receive the return value from each goroutine
 	c := make(chan statusReturn)
 
-	for _, plugin := range pulpPlugins {
+	for _, hostRoute := range hostRoutes {
 
 		// provision each route resource concurrently
-		go func(plugin RoutePlugin) {
+		go func(plugin RoutePlugin, host controllers.PulpHost) {
 
 			// get route
 			currentRoute := &routev1.Route{}
 			resources := controllers.FunctionResources{Context: ctx, Client: resources.Client, Pulp: pulp, Scheme: resources.Scheme, Logger: log}
 
-			expectedRoute := PulpRouteObject(ctx, resources, &plugin, routeHost)
+			expectedRoute := PulpRouteObject(ctx, resources, &plugin, host)
 			err := resources.Client.Get(ctx, types.NamespacedName{Name: plugin.Name, Namespace: pulp.Namespace}, currentRoute)
 
 			// Create the route in case it is not found
@@ -182,7 +112,7 @@ func PulpRouteController(resources controllers.FunctionResources, restClient res
 				return
 			}
 
-		}(plugin)
+		}(hostRoute.plugin, hostRoute.host)
 
 		// if there is no element in chan it means the goroutine didnt have any errors
 		// nor any reconciliation loop (ctrl.Result{}, nil) requested
@@ -194,6 +124,12 @@ func PulpRouteController(resources controllers.FunctionResources, restClient res
 		}
 	}
 
+	// remove the routes from host_aliases or content_host not defined anymore
+	if err := removeRoutes(resources, hostRoutes); err != nil {
+		log.Error(err, "Failed to remove routes")
+		return ctrl.Result{}, err
+	}
+
 	// remove pulp-web components if ingress_type was not route
 	controllers.RemovePulpWebResources(resources)
 
@@ -204,8 +140,71 @@ func PulpRouteController(resources controllers.FunctionResources, restClient res
 	return ctrl.Result{}, nil
 }
 
+// hostRoute is a plugin path exposed in a host
+type hostRoute struct {
+	plugin RoutePlugin
+	host   controllers.PulpHost
+}
+
+// pulpHostRoutes returns the routes for each plugin path in each host.
+// The content_host only exposes the content paths.
+// The routes from route_host keep the plugin name and the routes from the other hosts
+// are suffixed with the host kind and index.
+func pulpHostRoutes(pulp *pulpv1.Pulp, plugins []RoutePlugin) []hostRoute {
+	var routes []hostRoute
+	for i, host := range RouteHosts(pulp) {
+		for _, plugin := range plugins {
+			if host.ContentOnly && plugin.ServiceName != settings.ContentService(pulp.Name) {
+				continue
+			}
+			if host.ContentOnly {
+				plugin.Name = plugin.Name + "-content-host"
+			} else if i > 0 {
+				plugin.Name = plugin.Name + "-alias-" + strconv.Itoa(i)
+			}
+			routes = append(routes, hostRoute{plugin: plugin, host: host})
+		}
+	}
+	return routes
+}
+
+// RouteHosts returns the hosts exposed through the routes
+func RouteHosts(pulp *pulpv1.Pulp) []controllers.PulpHost {
+	return controllers.PulpHosts(pulp, GetRouteHost(pulp), controllers.RouteTLSSecret(pulp))
+}
+
+// removeRoutes deletes the routes provisioned by the operator that are not in hostRoutes
+func removeRoutes(resources controllers.FunctionResources, hostRoutes []hostRoute) error {
+	pulp := resources.Pulp
+	expectedRoutes := map[string]struct{}{}
+	for _, hostRoute := range hostRoutes {
+		expectedRoutes[hostRoute.plugin.Name] = struct{}{}
+	}
+
+	routeList := &routev1.RouteList{}
+	listOpts := []client.ListOption{
+		client.InNamespace(pulp.Namespace),
+		client.MatchingLabels(settings.CommonLabels(*pulp)),
+	}
+	if err := resources.Client.List(resources.Context, routeList, listOpts...); err != nil {
+		return err
+	}
+	for i := range routeList.Items {
+		route := &routeList.Items[i]
+		// routes created by the ingress-to-route controller are owned by the Ingress
+		if _, expected := expectedRoutes[route.Name]; expected || !metav1.IsControlledBy(route, pulp) {
+			continue
+		}
+		resources.Logger.Info("Removing " + route.Name + " route")
+		if err := resources.Client.Delete(resources.Context, route); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
 // PulpRouteObject returns the route object with the specs defined in pulp CR
-func PulpRouteObject(ctx context.Context, resources controllers.FunctionResources, p *RoutePlugin, routeHost string) *routev1.Route {
+func PulpRouteObject(ctx context.Context, resources controllers.FunctionResources, p *RoutePlugin, host controllers.PulpHost) *routev1.Route {
 
 	log := logr.Logger{}
 	weight := int32(100)
@@ -232,18 +231,32 @@ func PulpRouteObject(ctx context.Context, resources controllers.FunctionResource
 	}
 
 	certTLSConfig := routev1.TLSConfig{}
-	if len(resources.Pulp.Spec.RouteTLSSecret) > 0 {
-		certData, err := controllers.RetrieveSecretData(ctx, resources.Pulp.Spec.RouteTLSSecret, resources.Pulp.Namespace, true, resources.Client, "key", "certificate")
+	if len(host.TLSSecret) > 0 {
+		certData, err := controllers.RetrieveSecretData(ctx, host.TLSSecret, resources.Pulp.Namespace, false, resources.Client, "key", "certificate", "caCertificate", corev1.TLSPrivateKeyKey, corev1.TLSCertKey, "ca.crt")
 		if err != nil {
 			log.Error(err, "Failed to retrieve secret data.")
-		} else {
+		} else if len(certData["certificate"]) > 0 {
+			// caCertificate is optional
 			certTLSConfig.Certificate = certData["certificate"]
 			certTLSConfig.Key = certData["key"]
-
-			// caCertificate is optional
-			certData, _ = controllers.RetrieveSecretData(ctx, resources.Pulp.Spec.RouteTLSSecret, resources.Pulp.Namespace, false, resources.Client, "caCertificate")
 			certTLSConfig.CACertificate = certData["caCertificate"]
+		} else {
+			// the Secrets issued by cert-manager (and any other kubernetes.io/tls Secret) follow the tls.* keys
+			certTLSConfig.Certificate = certData[corev1.TLSCertKey]
+			certTLSConfig.Key = certData[corev1.TLSPrivateKeyKey]
+			certTLSConfig.CACertificate = certData["ca.crt"]
+		}
+	}
+
+	// with internal TLS the router re-encrypts the requests to pulpcore-api and pulpcore-content
+	termination := routev1.TLSTerminationEdge
+	if controllers.InternalTLSEnabled(resources.Pulp) {
+		termination = routev1.TLSTerminationReencrypt
+		certData, err := controllers.RetrieveSecretData(ctx, settings.InternalTLSSecret(resources.Pulp.Name), resources.Pulp.Namespace, true, resources.Client, "ca.crt")
+		if err != nil {
+			log.Error(err, "Failed to retrieve secret data.")
 		}
+		certTLSConfig.DestinationCACertificate = certData["ca.crt"]
 	}
 
 	route := &routev1.Route{
@@ -254,17 +267,18 @@ func PulpRouteObject(ctx context.Context, resources controllers.FunctionResource
 			Labels:      labels,
 		},
 		Spec: routev1.RouteSpec{
-			Host: routeHost,
+			Host: host.Host,
 			Path: p.Path,
 			Port: &routev1.RoutePort{
 				TargetPort: intstr.FromString(p.TargetPort),
 			},
 			TLS: &routev1.TLSConfig{
-				Termination:                   routev1.TLSTerminationEdge,
+				Termination:                   termination,
 				InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
 				Certificate:                   certTLSConfig.Certificate,
 				Key:                           certTLSConfig.Key,
 				CACertificate:                 certTLSConfig.CACertificate,
+				DestinationCACertificate:      certTLSConfig.DestinationCACertificate,
 			},
 			To: routev1.RouteTargetReference{
 				Kind:   "Service",
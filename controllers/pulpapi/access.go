@@ -0,0 +1,115 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pulpapi
+
+import (
+	"context"
+	"net/url"
+	"path"
+)
+
+const (
+	// UsersPath is the endpoint of Pulp users
+	UsersPath = "users/"
+	// GroupsPath is the endpoint of Pulp groups
+	GroupsPath = "groups/"
+)
+
+// User is a Pulp user (/pulp/api/v3/users/)
+type User struct {
+	Href      string `json:"pulp_href,omitempty"`
+	ID        int    `json:"id,omitempty"`
+	Username  string `json:"username"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+	IsStaff   bool   `json:"is_staff"`
+	IsActive  bool   `json:"is_active"`
+	// Password is write-only (Pulp never returns it)
+	Password string `json:"password,omitempty"`
+}
+
+// Group is a Pulp group (/pulp/api/v3/groups/)
+type Group struct {
+	Href string `json:"pulp_href,omitempty"`
+	ID   int    `json:"id,omitempty"`
+	Name string `json:"name"`
+}
+
+// GroupUser is a member of a Pulp group (/pulp/api/v3/groups/<id>/users/)
+type GroupUser struct {
+	Href     string `json:"pulp_href,omitempty"`
+	Username string `json:"username"`
+}
+
+// RoleAssignment is a role assigned to a user or to a group
+// (/pulp/api/v3/users/<id>/roles/ or /pulp/api/v3/groups/<id>/roles/).
+// A nil ContentObject assigns the role to all the objects (model-level).
+type RoleAssignment struct {
+	Href          string  `json:"pulp_href,omitempty"`
+	Role          string  `json:"role"`
+	ContentObject *string `json:"content_object"`
+}
+
+// FindUser returns the user with username (or nil if it does not exist)
+func (c *Client) FindUser(ctx context.Context, username string) (*User, error) {
+	return Find[User](ctx, c, UsersPath, url.Values{"username": {username}})
+}
+
+// FindGroup returns the group with name (or nil if it does not exist)
+func (c *Client) FindGroup(ctx context.Context, name string) (*Group, error) {
+	return Find[Group](ctx, c, GroupsPath, url.Values{"name": {name}})
+}
+
+// GroupUsers returns the members of the group from groupHref
+func (c *Client) GroupUsers(ctx context.Context, groupHref string) ([]GroupUser, error) {
+	return List[GroupUser](ctx, c, groupHref+"users/", nil)
+}
+
+// AddGroupUser adds the user with username to the group from groupHref
+func (c *Client) AddGroupUser(ctx context.Context, groupHref, username string) error {
+	return c.Create(ctx, groupHref+"users/", GroupUser{Username: username}, nil)
+}
+
+// RemoveGroupUser removes the user from userHref from the group from groupHref
+func (c *Client) RemoveGroupUser(ctx context.Context, groupHref, userHref string) error {
+	return c.Delete(ctx, groupHref+"users/"+path.Base(userHref)+"/")
+}
+
+// FindRoleAssignment returns the assignment of role (to contentObject or, if empty, model-level)
+// of the user or group from href (or nil if the role is not assigned)
+func (c *Client) FindRoleAssignment(ctx context.Context, href, role, contentObject string) (*RoleAssignment, error) {
+	assignments, err := List[RoleAssignment](ctx, c, href+"roles/", url.Values{"role": {role}})
+	if err != nil {
+		return nil, err
+	}
+	for _, assignment := range assignments {
+		if (assignment.ContentObject == nil && len(contentObject) == 0) || (assignment.ContentObject != nil && *assignment.ContentObject == contentObject) {
+			return &assignment, nil
+		}
+	}
+	return nil, nil
+}
+
+// AssignRole assigns role (to contentObject or, if empty, model-level) to the user or group from href
+func (c *Client) AssignRole(ctx context.Context, href, role, contentObject string) error {
+	assignment := RoleAssignment{Role: role}
+	if len(contentObject) > 0 {
+		assignment.ContentObject = &contentObject
+	}
+	return c.Create(ctx, href+"roles/", assignment, nil)
+}
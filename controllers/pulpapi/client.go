@@ -0,0 +1,239 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pulpapi is a minimal client of the Pulp REST API used by the controllers that
+// manage Pulp objects (users, groups, roles, etc.) declaratively.
+package pulpapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/settings"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AdminUsername is the user created by the operator and used to manage the Pulp objects
+const AdminUsername = "admin"
+
+// Client sends the requests to the Pulp REST API
+type Client struct {
+	// BaseURL is the url of the api (for example, http://example-pulp-api-svc.pulp.svc:24817/pulp/api/v3/).
+	// The relative paths are resolved against it and the hrefs returned by Pulp against its host.
+	BaseURL    *url.URL
+	Username   string
+	Password   string
+	HTTPClient *http.Client
+}
+
+// Error is returned when Pulp answers a request with an unexpected status code
+type Error struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%v %v returned %v: %v", e.Method, e.URL, e.StatusCode, e.Body)
+}
+
+// IsNotFound returns true if Pulp answered the request with 404
+func IsNotFound(err error) bool {
+	apiErr := &Error{}
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// page is a page of a Pulp list endpoint
+type page[T any] struct {
+	Next    *string `json:"next"`
+	Results []T     `json:"results"`
+}
+
+// New returns a Client for the api in baseURL
+func New(baseURL, username, password string, httpClient *http.Client) (*Client, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Client{BaseURL: base, Username: username, Password: password, HTTPClient: httpClient}, nil
+}
+
+// NewForPulp returns a Client for the api of the Pulp CR authenticated as the admin user with the
+// password from the admin password Secret. The requests are sent to the api Service, so the operator
+// does not depend on the ingress/route configuration.
+func NewForPulp(ctx context.Context, r client.Client, pulp *pulpv1.Pulp) (*Client, error) {
+	adminSecretName := pulp.Status.AdminPasswordSecret
+	if len(adminSecretName) == 0 {
+		adminSecretName = settings.DefaultAdminPassword(pulp.Name)
+	}
+	adminSecret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: adminSecretName, Namespace: pulp.Namespace}, adminSecret); err != nil {
+		return nil, fmt.Errorf("failed to get the admin password Secret %v: %v", adminSecretName, err)
+	}
+
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	if controllers.InternalTLSEnabled(pulp) {
+		tlsSecret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Name: settings.InternalTLSSecret(pulp.Name), Namespace: pulp.Namespace}, tlsSecret); err != nil {
+			return nil, fmt.Errorf("failed to get the internal TLS Secret: %v", err)
+		}
+		pool := x509.NewCertPool()
+		pool.AppendCertsFromPEM(tlsSecret.Data["ca.crt"])
+		httpClient.Transport = &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}}
+	}
+
+	return New(APIURL(ctx, r, pulp), AdminUsername, string(adminSecret.Data["password"]), httpClient)
+}
+
+// APIURL returns the url of the api from the api Service (for example, http://example-pulp-api-svc.pulp.svc:24817/pulp/api/v3/).
+// With the domains enabled, the url of the default domain is returned (the domains are managed from it).
+func APIURL(ctx context.Context, r client.Client, pulp *pulpv1.Pulp) string {
+	apiRoot := controllers.GetAPIRoot(ctx, r, pulp)
+	if controllers.DomainEnabled(ctx, r, pulp) {
+		apiRoot += "default/"
+	}
+	return fmt.Sprintf("%v://%v.%v.svc:24817%vapi/v3/", controllers.InternalTLSScheme(pulp), settings.ApiService(pulp.Name), pulp.Namespace, apiRoot)
+}
+
+// resolve returns the url of path (relative to the api or an href returned by Pulp)
+func (c *Client) resolve(path string) (string, error) {
+	ref, err := url.Parse(path)
+	if err != nil {
+		return "", err
+	}
+	return c.BaseURL.ResolveReference(ref).String(), nil
+}
+
+// Do sends a request with body encoded as json and decodes the response into out
+// (body and out can be nil)
+func (c *Client) Do(ctx context.Context, method, path string, body, out any) error {
+	requestURL, err := c.resolve(path)
+	if err != nil {
+		return err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(content)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, requestURL, reader)
+	if err != nil {
+		return err
+	}
+	request.SetBasicAuth(c.Username, c.Password)
+	request.Header.Set("Accept", "application/json")
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := c.HTTPClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return &Error{Method: method, URL: requestURL, StatusCode: response.StatusCode, Body: string(content)}
+	}
+	if out == nil || len(content) == 0 {
+		return nil
+	}
+	return json.Unmarshal(content, out)
+}
+
+// Get retrieves the object from path
+func (c *Client) Get(ctx context.Context, path string, out any) error {
+	return c.Do(ctx, http.MethodGet, path, nil, out)
+}
+
+// Create posts body to path
+func (c *Client) Create(ctx context.Context, path string, body, out any) error {
+	return c.Do(ctx, http.MethodPost, path, body, out)
+}
+
+// Update patches the object from href with the fields from body
+func (c *Client) Update(ctx context.Context, href string, body, out any) error {
+	return c.Do(ctx, http.MethodPatch, href, body, out)
+}
+
+// Delete removes the object from href (an object that does not exist is not an error)
+func (c *Client) Delete(ctx context.Context, href string) error {
+	if err := c.Do(ctx, http.MethodDelete, href, nil, nil); err != nil && !IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// List returns all the objects from the list endpoint in path (following the pagination)
+// that match the query filters
+func List[T any](ctx context.Context, c *Client, path string, query url.Values) ([]T, error) {
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	objects := []T{}
+	for len(path) > 0 {
+		result := page[T]{}
+		if err := c.Get(ctx, path, &result); err != nil {
+			return nil, err
+		}
+		objects = append(objects, result.Results...)
+		path = ""
+		if result.Next != nil {
+			path = *result.Next
+		}
+	}
+	return objects, nil
+}
+
+// Find returns the first object from the list endpoint in path that matches the query filters
+// (or nil if there is none)
+func Find[T any](ctx context.Context, c *Client, path string, query url.Values) (*T, error) {
+	query.Set("limit", "1")
+	result := page[T]{}
+	if err := c.Get(ctx, path+"?"+query.Encode(), &result); err != nil {
+		return nil, err
+	}
+	if len(result.Results) == 0 {
+		return nil, nil
+	}
+	return &result.Results[0], nil
+}
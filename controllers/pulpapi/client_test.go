@@ -0,0 +1,75 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pulpapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestList(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("offset") {
+		case "":
+			if r.URL.Path != "/pulp/api/v3/groups/" || r.URL.Query().Get("name__contains") != "admins" {
+				t.Errorf("unexpected request %v", r.URL)
+			}
+			fmt.Fprintf(w, `{"count": 3, "next": "%v/pulp/api/v3/groups/?name__contains=admins&offset=2", "results": [{"name": "admins-1"}, {"name": "admins-2"}]}`, server.URL)
+		case "2":
+			fmt.Fprint(w, `{"count": 3, "next": null, "results": [{"name": "admins-3"}]}`)
+		}
+	}))
+	defer server.Close()
+
+	c, _ := New(server.URL+"/pulp/api/v3/", "admin", "password", server.Client())
+	groups, err := List[Group](context.TODO(), c, GroupsPath, map[string][]string{"name__contains": {"admins"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 3 || groups[2].Name != "admins-3" {
+		t.Errorf("expected the groups from all the pages, got %+v", groups)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "admin" || password != "password" {
+			t.Errorf("unexpected credentials %v %v", username, password)
+		}
+		// the hrefs returned by Pulp are resolved against the server (not the api root)
+		if r.URL.Path != "/pulp/api/v3/users/42/" {
+			t.Errorf("unexpected path %v", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"detail": "Not found."}`)
+	}))
+	defer server.Close()
+
+	c, _ := New(server.URL+"/pulp/api/v3/", "admin", "password", server.Client())
+	err := c.Get(context.TODO(), "/pulp/api/v3/users/42/", &User{})
+	if !IsNotFound(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+	if err := c.Delete(context.TODO(), "/pulp/api/v3/users/42/"); err != nil {
+		t.Errorf("expected the removal of a missing object to succeed, got %v", err)
+	}
+}
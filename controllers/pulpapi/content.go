@@ -0,0 +1,103 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pulpapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// RemotesPath returns the endpoint of the remotes of pluginType (for example, rpm/rpm)
+func RemotesPath(pluginType string) string {
+	return "remotes/" + pluginType + "/"
+}
+
+// RepositoriesPath returns the endpoint of the repositories of pluginType (for example, rpm/rpm)
+func RepositoriesPath(pluginType string) string {
+	return "repositories/" + pluginType + "/"
+}
+
+// DistributionsPath returns the endpoint of the distributions of pluginType (for example, rpm/rpm)
+func DistributionsPath(pluginType string) string {
+	return "distributions/" + pluginType + "/"
+}
+
+// DomainsPath is the endpoint of the domains
+const DomainsPath = "domains/"
+
+// Object is a Pulp remote, repository, distribution or domain. Their fields depend on the plugin,
+// so they are kept as decoded from the json.
+// The requests that create, update or remove them can also be answered with
+// an asynchronous operation, in which case the object only has the task field.
+type Object map[string]any
+
+// Href returns the pulp_href of the object
+func (o Object) Href() string {
+	href, _ := o["pulp_href"].(string)
+	return href
+}
+
+// Task returns the href of the task dispatched by the request (or an empty string
+// if the request was not asynchronous)
+func (o Object) Task() string {
+	task, _ := o["task"].(string)
+	return task
+}
+
+// FindObject returns the object with name from the list endpoint in path (or nil if it does not exist)
+func (c *Client) FindObject(ctx context.Context, path, name string) (Object, error) {
+	object, err := Find[Object](ctx, c, path, url.Values{"name": {name}})
+	if err != nil || object == nil {
+		return nil, err
+	}
+	return *object, nil
+}
+
+// FindObjectHref returns the href of the object with name from the list endpoint in path
+// (or an error if it does not exist)
+func (c *Client) FindObjectHref(ctx context.Context, path, name string) (string, error) {
+	object, err := c.FindObject(ctx, path, name)
+	if err != nil {
+		return "", err
+	}
+	if object == nil {
+		return "", fmt.Errorf("%v%v not found in Pulp", path, name)
+	}
+	return object.Href(), nil
+}
+
+// SyncRepository dispatches the sync of the repository from href with the remote from
+// remoteHref and returns the href of the task
+func (c *Client) SyncRepository(ctx context.Context, href, remoteHref string, mirror bool) (string, error) {
+	response := Object{}
+	if err := c.Create(ctx, href+"sync/", map[string]any{"remote": remoteHref, "mirror": mirror}, &response); err != nil {
+		return "", err
+	}
+	return response.Task(), nil
+}
+
+// DeleteObject removes the object from href and returns the href of the task dispatched by Pulp
+// (an object that does not exist is not an error)
+func (c *Client) DeleteObject(ctx context.Context, href string) (string, error) {
+	response := Object{}
+	if err := c.Do(ctx, http.MethodDelete, href, nil, &response); err != nil && !IsNotFound(err) {
+		return "", err
+	}
+	return response.Task(), nil
+}
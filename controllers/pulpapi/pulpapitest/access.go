@@ -0,0 +1,222 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pulpapitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pulp/pulp-operator/controllers/pulpapi"
+)
+
+// AddUser creates an active user
+func (s *Server) AddUser(username string) *pulpapi.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.id()
+	user := &pulpapi.User{ID: id, Href: fmt.Sprintf("%vusers/%v/", APIRoot, id), Username: username, IsActive: true}
+	s.Users[id] = user
+	return user
+}
+
+// AddGroup creates a group without users
+func (s *Server) AddGroup(name string) *pulpapi.Group {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.id()
+	group := &pulpapi.Group{ID: id, Href: fmt.Sprintf("%vgroups/%v/", APIRoot, id), Name: name}
+	s.Groups[id] = group
+	return group
+}
+
+// serveAccess serves the users, groups, group users and role assignments endpoints
+func (s *Server) serveAccess(w http.ResponseWriter, r *http.Request, parts []string) {
+	ids := []int{}
+	for _, part := range parts[1:] {
+		if id, err := strconv.Atoi(part); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	switch {
+	case parts[0] == "users" && len(parts) == 1:
+		s.serveUsers(w, r)
+	case parts[0] == "users" && len(parts) == 2:
+		s.serveUser(w, r, ids[0])
+	case parts[0] == "groups" && len(parts) == 1:
+		s.serveGroups(w, r)
+	case parts[0] == "groups" && len(parts) == 2:
+		s.serveGroup(w, r, ids[0])
+	case len(parts) < 3:
+		writeJSON(w, http.StatusNotFound, nil)
+	case parts[0] == "groups" && parts[2] == "users":
+		s.serveGroupUsers(w, r, ids)
+	case parts[2] == "roles":
+		s.serveRoles(w, r, APIRoot+strings.Join(parts[:2], "/")+"/", ids)
+	default:
+		writeJSON(w, http.StatusNotFound, nil)
+	}
+}
+
+func (s *Server) serveUsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		user := &pulpapi.User{}
+		json.NewDecoder(r.Body).Decode(user)
+		for _, u := range s.Users {
+			if u.Username == user.Username {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"username": []string{"A user with that username already exists."}})
+				return
+			}
+		}
+		user.ID = s.id()
+		user.Href = fmt.Sprintf("%vusers/%v/", APIRoot, user.ID)
+		s.Users[user.ID] = user
+		response := *user
+		response.Password = ""
+		writeJSON(w, http.StatusCreated, response)
+		return
+	}
+	results := []pulpapi.User{}
+	for _, user := range s.Users {
+		if username := r.URL.Query().Get("username"); username == "" || username == user.Username {
+			response := *user
+			response.Password = ""
+			results = append(results, response)
+		}
+	}
+	writeList(w, results)
+}
+
+func (s *Server) serveUser(w http.ResponseWriter, r *http.Request, id int) {
+	user, ok := s.Users[id]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"detail": "Not found."})
+		return
+	}
+	switch r.Method {
+	case http.MethodPatch:
+		json.NewDecoder(r.Body).Decode(user)
+	case http.MethodDelete:
+		delete(s.Users, id)
+		writeJSON(w, http.StatusNoContent, nil)
+		return
+	}
+	response := *user
+	response.Password = ""
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) serveGroups(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		group := &pulpapi.Group{}
+		json.NewDecoder(r.Body).Decode(group)
+		group.ID = s.id()
+		group.Href = fmt.Sprintf("%vgroups/%v/", APIRoot, group.ID)
+		s.Groups[group.ID] = group
+		writeJSON(w, http.StatusCreated, group)
+		return
+	}
+	results := []pulpapi.Group{}
+	for _, group := range s.Groups {
+		if name := r.URL.Query().Get("name"); name == "" || name == group.Name {
+			results = append(results, *group)
+		}
+	}
+	writeList(w, results)
+}
+
+func (s *Server) serveGroup(w http.ResponseWriter, r *http.Request, id int) {
+	group, ok := s.Groups[id]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"detail": "Not found."})
+		return
+	}
+	switch r.Method {
+	case http.MethodPatch:
+		json.NewDecoder(r.Body).Decode(group)
+	case http.MethodDelete:
+		delete(s.Groups, id)
+		delete(s.Members, id)
+		writeJSON(w, http.StatusNoContent, nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, group)
+}
+
+func (s *Server) serveGroupUsers(w http.ResponseWriter, r *http.Request, ids []int) {
+	groupID := ids[0]
+	switch r.Method {
+	case http.MethodPost:
+		member := pulpapi.GroupUser{}
+		json.NewDecoder(r.Body).Decode(&member)
+		for _, user := range s.Users {
+			if user.Username == member.Username {
+				s.Members[groupID] = append(s.Members[groupID], user.ID)
+				writeJSON(w, http.StatusCreated, pulpapi.GroupUser{Href: user.Href, Username: user.Username})
+				return
+			}
+		}
+		writeJSON(w, http.StatusBadRequest, map[string]string{"username": "User does not exist."})
+	case http.MethodDelete:
+		members := []int{}
+		for _, id := range s.Members[groupID] {
+			if id != ids[1] {
+				members = append(members, id)
+			}
+		}
+		s.Members[groupID] = members
+		writeJSON(w, http.StatusNoContent, nil)
+	default:
+		results := []pulpapi.GroupUser{}
+		for _, id := range s.Members[groupID] {
+			results = append(results, pulpapi.GroupUser{Href: s.Users[id].Href, Username: s.Users[id].Username})
+		}
+		writeList(w, results)
+	}
+}
+
+func (s *Server) serveRoles(w http.ResponseWriter, r *http.Request, owner string, ids []int) {
+	switch r.Method {
+	case http.MethodPost:
+		assignment := pulpapi.RoleAssignment{}
+		json.NewDecoder(r.Body).Decode(&assignment)
+		assignment.Href = fmt.Sprintf("%vroles/%v/", owner, s.id())
+		s.Roles[owner] = append(s.Roles[owner], assignment)
+		writeJSON(w, http.StatusCreated, assignment)
+	case http.MethodDelete:
+		href := fmt.Sprintf("%vroles/%v/", owner, ids[1])
+		assignments := []pulpapi.RoleAssignment{}
+		for _, assignment := range s.Roles[owner] {
+			if assignment.Href != href {
+				assignments = append(assignments, assignment)
+			}
+		}
+		s.Roles[owner] = assignments
+		writeJSON(w, http.StatusNoContent, nil)
+	default:
+		results := []pulpapi.RoleAssignment{}
+		for _, assignment := range s.Roles[owner] {
+			if role := r.URL.Query().Get("role"); role == "" || role == assignment.Role {
+				results = append(results, assignment)
+			}
+		}
+		writeList(w, results)
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pulpapitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"strings"
+
+	"github.com/pulp/pulp-operator/controllers/pulpapi"
+)
+
+// Add creates an object in the list endpoint in path (relative to the api root)
+func (s *Server) Add(path string, fields pulpapi.Object) pulpapi.Object {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.create(APIRoot+path, fields)
+}
+
+func (s *Server) create(listPath string, fields pulpapi.Object) pulpapi.Object {
+	href := fmt.Sprintf("%v%v/", listPath, s.id())
+	object := pulpapi.Object{"pulp_href": href}
+	for field, value := range fields {
+		object[field] = value
+	}
+	if strings.HasPrefix(listPath, APIRoot+"distributions/") {
+		object["base_url"] = "https://pulp.example.com/pulp/content/" + fmt.Sprint(object["base_path"]) + "/"
+	}
+	s.objects[href] = object
+	return object
+}
+
+// Find returns the object with name from the list endpoint in path (relative to the api root)
+func (s *Server) Find(path, name string) pulpapi.Object {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for href, object := range s.objects {
+		if strings.HasPrefix(href, APIRoot+path) && object["name"] == name {
+			return object
+		}
+	}
+	return nil
+}
+
+// SetTaskState defines the state of the tasks dispatched from now on
+func (s *Server) SetTaskState(state, taskError string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.taskState, s.taskError = state, taskError
+}
+
+// dispatch creates a task and answers the request with it
+func (s *Server) dispatch(w http.ResponseWriter, createdResources ...string) {
+	task := &pulpapi.Task{Href: fmt.Sprintf("%vtasks/%v/", APIRoot, s.id()), State: s.taskState, CreatedResources: createdResources}
+	if len(s.taskError) > 0 {
+		task.Error = map[string]any{"description": s.taskError}
+	}
+	s.tasks[task.Href] = task
+	writeJSON(w, http.StatusAccepted, map[string]string{"task": task.Href})
+}
+
+// withoutWriteOnly returns the object as answered by Pulp (the password of the remotes and the
+// storage settings of the domains are write-only)
+func withoutWriteOnly(object pulpapi.Object) pulpapi.Object {
+	response := maps.Clone(object)
+	delete(response, "password")
+	delete(response, "storage_settings")
+	return response
+}
+
+// serveContent serves the tasks, domains and the plugin (remotes, repositories and distributions) endpoints
+func (s *Server) serveContent(w http.ResponseWriter, r *http.Request, parts []string) {
+	body := pulpapi.Object{}
+	json.NewDecoder(r.Body).Decode(&body)
+
+	switch {
+	case parts[0] == "tasks":
+		task, ok := s.tasks[r.URL.Path]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"detail": "Not found."})
+			return
+		}
+		writeJSON(w, http.StatusOK, task)
+	case len(parts) == 3 || parts[0] == "domains" && len(parts) == 1:
+		s.serveList(w, r, body)
+	case len(parts) == 4 || parts[0] == "domains" && len(parts) == 2:
+		s.serveObject(w, r, body)
+	case len(parts) == 5 && parts[0] == "repositories" && parts[4] == "sync":
+		s.serveSync(w, r, body)
+	default:
+		writeJSON(w, http.StatusNotFound, nil)
+	}
+}
+
+func (s *Server) serveList(w http.ResponseWriter, r *http.Request, body pulpapi.Object) {
+	if r.Method == http.MethodPost {
+		object := s.create(r.URL.Path, body)
+		// like Pulp, the distributions are created asynchronously
+		if strings.HasPrefix(r.URL.Path, APIRoot+"distributions/") {
+			s.dispatch(w, object.Href())
+			return
+		}
+		writeJSON(w, http.StatusCreated, withoutWriteOnly(object))
+		return
+	}
+	results := []pulpapi.Object{}
+	for href, object := range s.objects {
+		if strings.HasPrefix(href, r.URL.Path) && strings.Count(href, "/") == strings.Count(r.URL.Path, "/")+1 {
+			if name := r.URL.Query().Get("name"); name == "" || name == object["name"] {
+				results = append(results, withoutWriteOnly(object))
+			}
+		}
+	}
+	writeList(w, results)
+}
+
+func (s *Server) serveObject(w http.ResponseWriter, r *http.Request, body pulpapi.Object) {
+	object, ok := s.objects[r.URL.Path]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"detail": "Not found."})
+		return
+	}
+	switch r.Method {
+	case http.MethodPatch:
+		for field, value := range body {
+			object[field] = value
+		}
+		s.dispatch(w)
+	case http.MethodDelete:
+		delete(s.objects, r.URL.Path)
+		s.dispatch(w)
+	default:
+		writeJSON(w, http.StatusOK, withoutWriteOnly(object))
+	}
+}
+
+func (s *Server) serveSync(w http.ResponseWriter, r *http.Request, body pulpapi.Object) {
+	href := strings.TrimSuffix(r.URL.Path, "sync/")
+	repository, ok := s.objects[href]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"detail": "Not found."})
+		return
+	}
+	if _, ok := s.objects[fmt.Sprint(body["remote"])]; !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"remote": []string{"Invalid hyperlink - Object does not exist."}})
+		return
+	}
+	if s.taskState != pulpapi.TaskCompleted {
+		s.dispatch(w)
+		return
+	}
+	version := fmt.Sprintf("%vversions/%v/", href, s.id())
+	repository["latest_version_href"] = version
+	s.dispatch(w, version)
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pulpapitest
+
+import (
+	"context"
+	"testing"
+
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers/pulpapi"
+	"github.com/pulp/pulp-operator/controllers/pulpobject"
+	v1 "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	// Namespace and PulpName are the namespace and name of the Pulp CR created by NewClients
+	Namespace = "pulp"
+	PulpName  = "example-pulp"
+)
+
+// Reconciler is implemented by the controllers of the CRs synchronized with Pulp
+type Reconciler interface {
+	Reconcile(context.Context, ctrl.Request) (ctrl.Result, error)
+}
+
+// NewClients returns a fake kubernetes client with a Pulp CR and the objs (with the status
+// subresource of the statusTypes), a stub Server and a ClientFunc that sends the requests to it
+func NewClients(t testing.TB, statusTypes []client.Object, objs ...client.Object) (client.Client, *Server, pulpobject.ClientFunc) {
+	scheme := runtime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	pulpv1.AddToScheme(scheme)
+
+	objs = append(objs, &pulpv1.Pulp{ObjectMeta: metav1.ObjectMeta{Name: PulpName, Namespace: Namespace}})
+	k8sClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(statusTypes...).
+		Build()
+
+	stub := NewServer(t)
+	newPulpClient := func(ctx context.Context, r client.Client, pulp *pulpv1.Pulp) (*pulpapi.Client, error) {
+		return stub.NewClient()
+	}
+	return k8sClient, stub, newPulpClient
+}
+
+// Request returns the reconcile request of the object with name in Namespace
+func Request(name string) ctrl.Request {
+	return ctrl.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: Namespace}}
+}
+
+// ReconcileObject runs r, reloads obj and returns its Ready condition and the result
+func ReconcileObject(t testing.TB, r Reconciler, k8sClient client.Client, obj client.Object, conditions func() []metav1.Condition) (*metav1.Condition, ctrl.Result) {
+	t.Helper()
+	result, err := r.Reconcile(context.TODO(), Request(obj.GetName()))
+	if err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if err := k8sClient.Get(context.TODO(), Request(obj.GetName()).NamespacedName, obj); err != nil {
+		t.Fatalf("failed to get %v: %v", obj.GetName(), err)
+	}
+	return v1.FindStatusCondition(conditions(), pulpobject.ReadyCondition), result
+}
+
+// ReconcileTask runs r and fails the test if the object is not waiting for a task
+func ReconcileTask(t testing.TB, r Reconciler, k8sClient client.Client, obj client.Object, conditions func() []metav1.Condition) {
+	t.Helper()
+	condition, result := ReconcileObject(t, r, k8sClient, obj, conditions)
+	if condition == nil || condition.Reason != "TaskRunning" {
+		t.Fatalf("%v is not waiting for a task: %+v", obj.GetName(), condition)
+	}
+	if result.RequeueAfter != pulpobject.TaskPollPeriod {
+		t.Errorf("expected requeue after %v, got %v", pulpobject.TaskPollPeriod, result.RequeueAfter)
+	}
+}
+
+// ReconcileReady runs r and fails the test if the object is not Ready
+func ReconcileReady(t testing.TB, r Reconciler, k8sClient client.Client, obj client.Object, conditions func() []metav1.Condition) {
+	t.Helper()
+	condition, result := ReconcileObject(t, r, k8sClient, obj, conditions)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Fatalf("%v is not ready: %+v", obj.GetName(), condition)
+	}
+	if result.RequeueAfter != pulpobject.ResyncPeriod {
+		t.Errorf("expected requeue after %v, got %v", pulpobject.ResyncPeriod, result.RequeueAfter)
+	}
+}
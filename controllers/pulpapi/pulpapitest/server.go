@@ -0,0 +1,130 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pulpapitest provides an in-memory implementation of the Pulp REST API endpoints used
+// by the pulpapi client, for the tests of the controllers that synchronize CRs with Pulp.
+package pulpapitest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pulp/pulp-operator/controllers/pulpapi"
+)
+
+const (
+	// APIRoot is the path of the api served by the Server
+	APIRoot = "/pulp/api/v3/"
+	// Username and Password are the only credentials accepted by the Server
+	Username = "admin"
+	Password = "password"
+)
+
+// Server is an in-memory implementation of the Pulp users, groups and roles endpoints and of
+// the remotes, repositories, distributions, domains and tasks endpoints. The objects are modified
+// as soon as the requests are received, and the tasks dispatched are created with the state
+// defined by SetTaskState.
+type Server struct {
+	// Users, Groups, Members (the ids of the users of each group) and Roles (the assignments
+	// of each user or group href) can be modified by the tests to simulate a drift in Pulp
+	Users   map[int]*pulpapi.User
+	Groups  map[int]*pulpapi.Group
+	Members map[int][]int
+	Roles   map[string][]pulpapi.RoleAssignment
+
+	mu        sync.Mutex
+	server    *httptest.Server
+	nextID    int
+	objects   map[string]pulpapi.Object
+	tasks     map[string]*pulpapi.Task
+	taskState string
+	taskError string
+	requests  []string
+}
+
+// NewServer starts a Server that is closed at the end of the test
+func NewServer(t testing.TB) *Server {
+	s := &Server{
+		Users:     map[int]*pulpapi.User{},
+		Groups:    map[int]*pulpapi.Group{},
+		Members:   map[int][]int{},
+		Roles:     map[string][]pulpapi.RoleAssignment{},
+		objects:   map[string]pulpapi.Object{},
+		tasks:     map[string]*pulpapi.Task{},
+		taskState: pulpapi.TaskCompleted,
+	}
+	s.server = httptest.NewServer(s)
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+// NewClient returns a pulpapi client that sends the requests to the Server
+func (s *Server) NewClient() (*pulpapi.Client, error) {
+	return pulpapi.New(s.server.URL+APIRoot, Username, Password, s.server.Client())
+}
+
+func (s *Server) id() int {
+	s.nextID++
+	return s.nextID
+}
+
+// RequestsWith returns the requests sent with method
+func (s *Server) RequestsWith(method string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requests := []string{}
+	for _, request := range s.requests {
+		if strings.HasPrefix(request, method+" ") {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if body != nil {
+		json.NewEncoder(w).Encode(body)
+	}
+}
+
+func writeList[T any](w http.ResponseWriter, results []T) {
+	writeJSON(w, http.StatusOK, map[string]any{"count": len(results), "next": nil, "results": results})
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+
+	if username, password, ok := r.BasicAuth(); !ok || username != Username || password != Password {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"detail": "Invalid username/password."})
+		return
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, APIRoot), "/"), "/")
+
+	switch {
+	case parts[0] == "users" || parts[0] == "groups" || len(parts) == 3 && parts[2] == "roles":
+		s.serveAccess(w, r, parts)
+	default:
+		s.serveContent(w, r, parts)
+	}
+}
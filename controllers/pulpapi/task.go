@@ -0,0 +1,69 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pulpapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// States of a Pulp task
+const (
+	TaskWaiting   = "waiting"
+	TaskRunning   = "running"
+	TaskCanceling = "canceling"
+	TaskCompleted = "completed"
+	TaskFailed    = "failed"
+	TaskCanceled  = "canceled"
+	TaskSkipped   = "skipped"
+)
+
+// Task is a Pulp task (/pulp/api/v3/tasks/<id>/)
+type Task struct {
+	Href             string         `json:"pulp_href"`
+	State            string         `json:"state"`
+	Error            map[string]any `json:"error,omitempty"`
+	StartedAt        *time.Time     `json:"started_at,omitempty"`
+	FinishedAt       *time.Time     `json:"finished_at,omitempty"`
+	CreatedResources []string       `json:"created_resources,omitempty"`
+}
+
+// TaskFinished returns true if a task in state will not run anymore
+func TaskFinished(state string) bool {
+	return state != TaskWaiting && state != TaskRunning && state != TaskCanceling
+}
+
+// ErrorDescription returns the description of the error of a failed task
+func (t *Task) ErrorDescription() string {
+	if description, ok := t.Error["description"]; ok {
+		return fmt.Sprint(description)
+	}
+	if len(t.Error) > 0 {
+		return fmt.Sprint(t.Error)
+	}
+	return ""
+}
+
+// GetTask returns the task from href
+func (c *Client) GetTask(ctx context.Context, href string) (*Task, error) {
+	task := &Task{}
+	if err := c.Get(ctx, href, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pulpobject has the reconciliation steps shared by the controllers of the CRs that
+// are synchronized with objects from the Pulp REST API (users, groups, repositories, etc.).
+package pulpobject
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers/pulpapi"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// Finalizer removes the object from Pulp before the CR is removed
+	Finalizer = "repo-manager.pulpproject.org/pulp-object"
+
+	// ReadyCondition reports if the object is synchronized with Pulp
+	ReadyCondition = "Ready"
+
+	// ResyncPeriod is the interval between the synchronizations of an object, so that the
+	// modifications made through the Pulp API are reverted
+	ResyncPeriod = 10 * time.Minute
+
+	// RetryPeriod is the interval between the synchronizations of an object that failed
+	// (for example, while Pulp API is not ready)
+	RetryPeriod = time.Minute
+
+	// TaskPollPeriod is the interval between the checks of a running Pulp task
+	TaskPollPeriod = 10 * time.Second
+)
+
+// ClientFunc returns the client of the Pulp REST API from a Pulp CR
+type ClientFunc func(ctx context.Context, r client.Client, pulp *pulpv1.Pulp) (*pulpapi.Client, error)
+
+// TaskRunningError is returned by the Sync and Remove steps while the object is waiting
+// for a Pulp task, so it is reconciled again after the TaskPollPeriod
+type TaskRunningError struct {
+	Href string
+}
+
+func (e *TaskRunningError) Error() string {
+	return fmt.Sprintf("waiting for task %v", e.Href)
+}
+
+// Object groups the fields and steps used to reconcile a CR with its Pulp object
+type Object struct {
+	Object         client.Object
+	DeploymentName string
+	DeletionPolicy pulpv1.DeletionPolicy
+	Status         *pulpv1.PulpObjectStatus
+
+	// Sync creates or updates the object in Pulp
+	Sync func(ctx context.Context, api *pulpapi.Client) error
+	// Remove deletes the object from Pulp
+	Remove func(ctx context.Context, api *pulpapi.Client) error
+}
+
+// Reconcile synchronizes obj with Pulp (or removes it from Pulp if the CR is being deleted)
+// and reports the result in the Ready condition. The object is synchronized again after the
+// ResyncPeriod to revert the modifications made through the Pulp API.
+func Reconcile(ctx context.Context, r client.Client, newPulpClient ClientFunc, log logr.Logger, obj Object) (ctrl.Result, error) {
+	deleting := !obj.Object.GetDeletionTimestamp().IsZero()
+	if deleting {
+		if !controllerutil.ContainsFinalizer(obj.Object, Finalizer) {
+			return ctrl.Result{}, nil
+		}
+		// the object is kept in Pulp, so the API is not needed (and the CR can be
+		// removed even if Pulp is not available)
+		if obj.DeletionPolicy == pulpv1.DeletionPolicyRetain {
+			return ctrl.Result{}, removeFinalizer(ctx, r, obj.Object)
+		}
+	}
+
+	pulp := &pulpv1.Pulp{}
+	if err := r.Get(ctx, types.NamespacedName{Name: obj.DeploymentName, Namespace: obj.Object.GetNamespace()}, pulp); err != nil {
+		if k8s_errors.IsNotFound(err) && deleting {
+			// without the Pulp CR there is nothing to clean up
+			return ctrl.Result{}, removeFinalizer(ctx, r, obj.Object)
+		}
+		log.Error(err, "Failed to get Pulp CR "+obj.DeploymentName)
+		return retry(ctx, r, obj, "PulpNotFound", "Failed to get Pulp CR "+obj.DeploymentName+": "+err.Error())
+	}
+	if deleting && !pulp.GetDeletionTimestamp().IsZero() {
+		// Pulp is also being removed (for example, with the namespace), so its API may be
+		// already unavailable and the object would block the deletion forever
+		return ctrl.Result{}, removeFinalizer(ctx, r, obj.Object)
+	}
+
+	api, err := newPulpClient(ctx, r, pulp)
+	if err != nil {
+		log.Error(err, "Failed to create Pulp API client")
+		return retry(ctx, r, obj, "PulpAPIUnavailable", err.Error())
+	}
+
+	if deleting {
+		if err := obj.Remove(ctx, api); err != nil {
+			if taskErr := (*TaskRunningError)(nil); errors.As(err, &taskErr) {
+				return waitTask(ctx, r, obj, taskErr)
+			}
+			log.Error(err, "Failed to remove the object from Pulp")
+			return retry(ctx, r, obj, "DeleteFailed", err.Error())
+		}
+		return ctrl.Result{}, removeFinalizer(ctx, r, obj.Object)
+	}
+
+	if controllerutil.AddFinalizer(obj.Object, Finalizer) {
+		if err := r.Update(ctx, obj.Object); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := obj.Sync(ctx, api); err != nil {
+		if taskErr := (*TaskRunningError)(nil); errors.As(err, &taskErr) {
+			return waitTask(ctx, r, obj, taskErr)
+		}
+		log.Error(err, "Failed to synchronize the object with Pulp")
+		return retry(ctx, r, obj, "SyncFailed", err.Error())
+	}
+
+	now := metav1.Now()
+	obj.Status.ObservedGeneration = obj.Object.GetGeneration()
+	obj.Status.LastSyncTime = &now
+	v1.SetStatusCondition(&obj.Status.Conditions, metav1.Condition{
+		Type:               ReadyCondition,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Synced",
+		Message:            "Object synchronized with Pulp",
+		ObservedGeneration: obj.Object.GetGeneration(),
+	})
+	if err := r.Status().Update(ctx, obj.Object); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: ResyncPeriod}, nil
+}
+
+// retry sets the Ready condition to false and requeues the object after the RetryPeriod
+func retry(ctx context.Context, r client.Client, obj Object, reason, message string) (ctrl.Result, error) {
+	v1.SetStatusCondition(&obj.Status.Conditions, metav1.Condition{
+		Type:               ReadyCondition,
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: obj.Object.GetGeneration(),
+	})
+	if err := r.Status().Update(ctx, obj.Object); err != nil && !k8s_errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: RetryPeriod}, nil
+}
+
+// waitTask sets the Ready condition to false and requeues the object after the TaskPollPeriod
+func waitTask(ctx context.Context, r client.Client, obj Object, taskErr *TaskRunningError) (ctrl.Result, error) {
+	result, err := retry(ctx, r, obj, "TaskRunning", "Waiting for Pulp task "+taskErr.Href)
+	if err != nil {
+		return result, err
+	}
+	return ctrl.Result{RequeueAfter: TaskPollPeriod}, nil
+}
+
+// PollTask updates task with the state of the Pulp task and returns a TaskRunningError while it
+// is running or an error if it did not complete. Tasks already finished are not checked again.
+func PollTask(ctx context.Context, api *pulpapi.Client, task *pulpv1.PulpTaskStatus) error {
+	if task == nil || pulpapi.TaskFinished(task.State) {
+		return nil
+	}
+	current, err := api.GetTask(ctx, task.Href)
+	if err != nil {
+		if pulpapi.IsNotFound(err) {
+			// the task was purged (or Pulp database was replaced), so its result is unknown
+			task.State, task.Error = pulpapi.TaskFailed, "task not found in Pulp"
+		}
+		return err
+	}
+	task.State, task.Error = current.State, current.ErrorDescription()
+	if current.StartedAt != nil {
+		task.StartedAt = &metav1.Time{Time: *current.StartedAt}
+	}
+	if current.FinishedAt != nil {
+		task.FinishedAt = &metav1.Time{Time: *current.FinishedAt}
+	}
+	if !pulpapi.TaskFinished(current.State) {
+		return &TaskRunningError{Href: task.Href}
+	}
+	if current.State != pulpapi.TaskCompleted {
+		return fmt.Errorf("task %v %v: %v", task.Href, task.State, task.Error)
+	}
+	return nil
+}
+
+// removeFinalizer allows the CR to be removed
+func removeFinalizer(ctx context.Context, r client.Client, obj client.Object) error {
+	if controllerutil.RemoveFinalizer(obj, Finalizer) {
+		return r.Update(ctx, obj)
+	}
+	return nil
+}
+
+// FindObjects returns a func that enqueues the objects of list that reference the Pulp CR
+// (so they are synchronized as soon as Pulp is deployed or restored)
+func FindObjects(r client.Client, list client.ObjectList, deploymentName func(client.Object) string) func(context.Context, client.Object) []reconcile.Request {
+	return func(ctx context.Context, pulp client.Object) []reconcile.Request {
+		objects := list.DeepCopyObject().(client.ObjectList)
+		if err := r.List(ctx, objects, client.InNamespace(pulp.GetNamespace())); err != nil {
+			return nil
+		}
+		requests := []reconcile.Request{}
+		v1.EachListItem(objects, func(o runtime.Object) error {
+			obj := o.(client.Object)
+			if deploymentName(obj) == pulp.GetName() {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}})
+			}
+			return nil
+		})
+		return requests
+	}
+}
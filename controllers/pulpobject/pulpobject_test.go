@@ -0,0 +1,112 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pulpobject
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers/pulpapi"
+	v1 "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileDeletion(t *testing.T) {
+	tests := []struct {
+		name            string
+		policy          pulpv1.DeletionPolicy
+		pulpDeleting    bool
+		clientCalls     int
+		finalizerKept   bool
+		conditionReason string
+	}{
+		{name: "retain", policy: pulpv1.DeletionPolicyRetain},
+		{name: "pulp being deleted", policy: pulpv1.DeletionPolicyDelete, pulpDeleting: true},
+		{name: "delete", policy: pulpv1.DeletionPolicyDelete, clientCalls: 1, finalizerKept: true, conditionReason: "PulpAPIUnavailable"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			clientgoscheme.AddToScheme(scheme)
+			pulpv1.AddToScheme(scheme)
+
+			pulp := &pulpv1.Pulp{ObjectMeta: metav1.ObjectMeta{Name: "example-pulp", Namespace: "pulp", Finalizers: []string{"test"}}}
+			user := &pulpv1.PulpUser{
+				ObjectMeta: metav1.ObjectMeta{Name: "alice", Namespace: "pulp", Finalizers: []string{Finalizer}},
+				Spec:       pulpv1.PulpUserSpec{DeploymentName: "example-pulp", Username: "alice", DeletionPolicy: test.policy},
+			}
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pulp, user).WithStatusSubresource(user).Build()
+			if test.pulpDeleting {
+				if err := k8sClient.Delete(context.TODO(), pulp); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := k8sClient.Delete(context.TODO(), user); err != nil {
+				t.Fatal(err)
+			}
+			if err := k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(user), user); err != nil {
+				t.Fatal(err)
+			}
+
+			// the Pulp API is not available
+			calls := 0
+			newPulpClient := func(ctx context.Context, r client.Client, pulp *pulpv1.Pulp) (*pulpapi.Client, error) {
+				calls++
+				return nil, errors.New("connection refused")
+			}
+			removed := false
+			_, err := Reconcile(context.TODO(), k8sClient, newPulpClient, logr.Discard(), Object{
+				Object:         user,
+				DeploymentName: user.Spec.DeploymentName,
+				DeletionPolicy: user.Spec.DeletionPolicy,
+				Status:         &user.Status.PulpObjectStatus,
+				Remove: func(ctx context.Context, api *pulpapi.Client) error {
+					removed = true
+					return nil
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if calls != test.clientCalls || removed {
+				t.Errorf("expected %v Pulp API client(s) and no removal, got %v client(s) (removed: %v)", test.clientCalls, calls, removed)
+			}
+			err = k8sClient.Get(context.TODO(), types.NamespacedName{Name: "alice", Namespace: "pulp"}, user)
+			if test.finalizerKept && err != nil {
+				t.Fatalf("expected the PulpUser to be kept until it is removed from Pulp: %v", err)
+			}
+			if !test.finalizerKept && err == nil {
+				t.Errorf("expected the PulpUser to be removed after the finalizer")
+			}
+			if test.finalizerKept {
+				if condition := v1.FindStatusCondition(user.Status.Conditions, ReadyCondition); condition == nil || condition.Reason != test.conditionReason {
+					t.Errorf("expected the %v condition, got %+v", test.conditionReason, condition)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pysettings provides a typed model of the pulpcore settings.py file.
+// The settings are stored as an ordered list of assignments and rendered by a
+// single serializer, so that the values retrieved from Secrets, ConfigMaps and
+// Pulp CR are always converted into valid python literals (a quote, backslash or
+// newline in a value cannot break or inject code into settings.py).
+package pysettings
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// lineWidth is the maximum length of a line with a list, tuple or dict before
+// splitting its items into multiple lines (the same default from black)
+const lineWidth = 88
+
+// indentation used for the items of the multi-line lists, tuples and dicts
+const indentation = "    "
+
+// identifier matches the valid python variable names
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// IsIdentifier returns true if name can be used as a setting name
+func IsIdentifier(name string) bool {
+	return identifier.MatchString(name)
+}
+
+// Expr is a python expression rendered as is. It should only be used for
+// values defined by the operator or by the cluster admin (for example, the
+// custom_pulp_settings ConfigMap), never for values that should be a literal.
+type Expr string
+
+// FileContent returns an expression that reads the content of the file from path (without the
+// leading and trailing whitespace) when settings.py is loaded. It is used for the credentials
+// mounted in the pods, so that they are not stored in settings.py.
+func FileContent(path string) Expr {
+	return Expr("open(" + quote(path) + ").read().strip()")
+}
+
+// Tuple is rendered as a python tuple
+type Tuple []any
+
+// Item is a key/value pair of a Dict
+type Item struct {
+	Key   any
+	Value any
+}
+
+// Dict is rendered as a python dict keeping the order of its items
+// (go maps are rendered sorted by key)
+type Dict []Item
+
+// Set adds key to the dict or replaces its value if it is already defined
+func (d *Dict) Set(key, value any) {
+	for i := range *d {
+		if (*d)[i].Key == key {
+			(*d)[i].Value = value
+			return
+		}
+	}
+	*d = append(*d, Item{Key: key, Value: value})
+}
+
+// Kwarg is a keyword argument of a Call
+type Kwarg struct {
+	Name  string
+	Value any
+}
+
+// Call is rendered as a python function call, for example:
+// Call{Func: "LDAPSearch", Args: []any{"dc=example,dc=com", Expr("ldap.SCOPE_SUBTREE")}}
+type Call struct {
+	Func string
+	Args []any
+}
+
+// statement is an entry of settings.py: an assignment (name = value) or a
+// piece of python code defined by the operator (imports, comments, etc.)
+type statement struct {
+	name  string
+	value any
+	code  string
+}
+
+// Settings is the ordered list of statements of a settings.py file
+type Settings struct {
+	statements []statement
+}
+
+// New returns an empty Settings
+func New() *Settings {
+	return &Settings{}
+}
+
+// Set assigns value to the setting name. If the setting was already defined,
+// its value is replaced in the same position (python would keep the last
+// assignment anyway). Invalid setting names are ignored, so the names that are
+// not defined by the operator should be verified with IsIdentifier first.
+func (s *Settings) Set(name string, value any) {
+	if !IsIdentifier(name) {
+		return
+	}
+	for i := range s.statements {
+		if s.statements[i].name == name {
+			s.statements[i].value = value
+			return
+		}
+	}
+	s.statements = append(s.statements, statement{name: name, value: value})
+}
+
+// Has returns true if the setting name is defined
+func (s *Settings) Has(name string) bool {
+	for _, st := range s.statements {
+		if st.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns the value of the setting name
+func (s *Settings) Get(name string) (any, bool) {
+	for _, st := range s.statements {
+		if st.name == name {
+			return st.value, true
+		}
+	}
+	return nil, false
+}
+
+// Code appends a python code block (imports, comments, try/except blocks) defined by the operator
+func (s *Settings) Code(code string) {
+	s.statements = append(s.statements, statement{code: code})
+}
+
+// Render returns the settings in python format
+func (s *Settings) Render() string {
+	var b strings.Builder
+	for _, st := range s.statements {
+		if len(st.name) == 0 {
+			b.WriteString(st.code)
+			if !strings.HasSuffix(st.code, "\n") {
+				b.WriteString("\n")
+			}
+			continue
+		}
+		prefix := st.name + " = "
+		b.WriteString(prefix + render(st.value, "", len(prefix)) + "\n")
+	}
+	return b.String()
+}
+
+// Literal returns value as a python literal
+func Literal(value any) string {
+	return render(value, "", 0)
+}
+
+// render returns value as a python literal. indent is the indentation of the
+// line in which the value is rendered and column is the position of the value in it.
+func render(value any, indent string, column int) string {
+	switch v := value.(type) {
+	case nil:
+		return "None"
+	case Expr:
+		return string(v)
+	case string:
+		return quote(v)
+	case bool:
+		if v {
+			return "True"
+		}
+		return "False"
+	case int:
+		return strconv.Itoa(v)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []string:
+		items := make([]any, len(v))
+		for i := range v {
+			items[i] = v[i]
+		}
+		return container("[", "]", literals(items), false, indent, column)
+	case []any:
+		return container("[", "]", literals(v), false, indent, column)
+	case Tuple:
+		return container("(", ")", literals(v), len(v) == 1, indent, column)
+	case Dict:
+		return container("{", "}", items(v), false, indent, column)
+	case map[string]string:
+		keys := sortedKeys(v)
+		dict := make(Dict, 0, len(v))
+		for _, k := range keys {
+			dict = append(dict, Item{k, v[k]})
+		}
+		return render(dict, indent, column)
+	case map[string]any:
+		keys := sortedKeys(v)
+		dict := make(Dict, 0, len(v))
+		for _, k := range keys {
+			dict = append(dict, Item{k, v[k]})
+		}
+		return render(dict, indent, column)
+	case Call:
+		args := literals(v.Args)
+		for i := range args {
+			if kwarg, ok := v.Args[i].(Kwarg); ok {
+				args[i] = element{prefix: kwarg.Name + "=", value: kwarg.Value}
+			}
+		}
+		return container(v.Func+"(", ")", args, false, indent, column)
+	}
+	// values from unknown types are rendered as strings
+	return quote(fmt.Sprint(value))
+}
+
+// quote returns s as a python string literal.
+// The escape sequences from strconv.Quote (\\, \", \n, \t, \xNN, \uNNNN and
+// \UNNNNNNNN) have the same meaning in python.
+func quote(s string) string {
+	return strconv.Quote(s)
+}
+
+// element is an item of a container: a list/tuple item, an argument or a
+// dict item (prefix is the rendered key followed by ": ")
+type element struct {
+	prefix string
+	value  any
+}
+
+// literals returns the elements of a list, tuple or argument list
+func literals(values []any) []element {
+	elements := make([]element, len(values))
+	for i := range values {
+		elements[i] = element{value: values[i]}
+	}
+	return elements
+}
+
+// items returns the elements of a dict
+func items(dict Dict) []element {
+	elements := make([]element, len(dict))
+	for i, item := range dict {
+		elements[i] = element{prefix: render(item.Key, "", 0) + ": ", value: item.Value}
+	}
+	return elements
+}
+
+// container renders a list, tuple, dict or call in a single line if it fits in
+// lineWidth, otherwise each element is rendered in its own line
+func container(open, close string, elements []element, trailingComma bool, indent string, column int) string {
+	inline := make([]string, len(elements))
+	for i, e := range elements {
+		inline[i] = e.prefix + render(e.value, "", 0)
+	}
+	line := open + strings.Join(inline, ", ")
+	if trailingComma {
+		line += ","
+	}
+	line += close
+	if len(elements) == 0 || (column+len(line) <= lineWidth && !strings.Contains(line, "\n")) {
+		return line
+	}
+
+	itemIndent := indent + indentation
+	var b strings.Builder
+	b.WriteString(open + "\n")
+	for _, e := range elements {
+		b.WriteString(itemIndent + e.prefix + render(e.value, itemIndent, len(itemIndent)+len(e.prefix)) + ",\n")
+	}
+	b.WriteString(indent + close)
+	return b.String()
+}
+
+// sortedKeys returns the keys of m in alphabetical order
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pysettings
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// run "go test ./controllers/pysettings/ -update" to regenerate the golden files
+var update = flag.Bool("update", false, "update the golden files")
+
+// golden compares got with the content of testdata/<name>.golden
+func golden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(expected) {
+		t.Errorf("%v does not match the golden file:\n--- got ---\n%v\n--- expected ---\n%v", name, got, string(expected))
+	}
+}
+
+func TestLiteral(t *testing.T) {
+	tests := []struct {
+		value    any
+		expected string
+	}{
+		{"value", `"value"`},
+		{`quote " and 'single quote'`, `"quote \" and 'single quote'"`},
+		{`back\slash`, `"back\\slash"`},
+		{"new\nline\r\ttab", `"new\nline\r\ttab"`},
+		{"\x00\x1b", `"\x00\x1b"`},
+		{"ünïcödé", `"ünïcödé"`},
+		{`"""; import os; os.system("id"); """`, `"\"\"\"; import os; os.system(\"id\"); \"\"\""`},
+		{true, "True"},
+		{false, "False"},
+		{nil, "None"},
+		{0, "0"},
+		{int32(-10), "-10"},
+		{int64(6379), "6379"},
+		{1.5, "1.5"},
+		{float64(3600), "3600"},
+		{[]string{}, "[]"},
+		{[]string{"a", "b"}, `["a", "b"]`},
+		{[]any{"a", 1, true, nil}, `["a", 1, True, None]`},
+		{Tuple{"host", 26379}, `("host", 26379)`},
+		{Tuple{"host"}, `("host",)`},
+		{map[string]string{"b": "2", "a": "1"}, `{"a": "1", "b": "2"}`},
+		{map[string]any{"b": 2, "a": []string{"x"}}, `{"a": ["x"], "b": 2}`},
+		{Dict{{"b", 2}, {"a", 1}}, `{"b": 2, "a": 1}`},
+		{Dict{{Expr("ldap.OPT_REFERRALS"), 0}}, `{ldap.OPT_REFERRALS: 0}`},
+		{Expr("ldap.SCOPE_SUBTREE"), "ldap.SCOPE_SUBTREE"},
+		{FileContent(`/etc/pulp/secrets-store/db/"pass"`), `open("/etc/pulp/secrets-store/db/\"pass\"").read().strip()`},
+		{Call{Func: "GroupOfNamesType", Args: []any{Kwarg{"name_attr", "cn"}}}, `GroupOfNamesType(name_attr="cn")`},
+		{Call{Func: "LDAPSearch", Args: []any{"ou=users", Expr("ldap.SCOPE_SUBTREE"), "(uid=%(user)s)"}}, `LDAPSearch("ou=users", ldap.SCOPE_SUBTREE, "(uid=%(user)s)")`},
+	}
+
+	for _, test := range tests {
+		if got := Literal(test.value); got != test.expected {
+			t.Errorf("Literal(%#v) = %v, expected %v", test.value, got, test.expected)
+		}
+	}
+}
+
+func TestIsIdentifier(t *testing.T) {
+	for _, name := range []string{"SECRET_KEY", "_private", "api_root", "AUTH_LDAP_1"} {
+		if !IsIdentifier(name) {
+			t.Errorf("%v should be a valid identifier", name)
+		}
+	}
+	for _, name := range []string{"", "1KEY", "KEY-NAME", "KEY = 1\nimport os", "KEY.NAME"} {
+		if IsIdentifier(name) {
+			t.Errorf("%q should not be a valid identifier", name)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	s := New()
+	s.Set("A", 1)
+	s.Set("B", 2)
+	s.Set("A", "replaced")
+	s.Set("INVALID NAME", 3)
+
+	if !s.Has("A") || !s.Has("B") || s.Has("INVALID NAME") {
+		t.Errorf("unexpected settings: %v", s.Render())
+	}
+	if value, _ := s.Get("A"); value != "replaced" {
+		t.Errorf("A = %v, expected replaced", value)
+	}
+	if got := s.Render(); got != "A = \"replaced\"\nB = 2\n" {
+		t.Errorf("unexpected settings: %q", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	s := New()
+	s.Code("# settings.py")
+	s.Set("CONTENT_ORIGIN", "https://pulp.example.com")
+	s.Set("TOKEN_AUTH_DISABLED", false)
+	s.Set("SECRET_KEY", "k3y\"\nimport os\n")
+	s.Set("ALLOWED_CONTENT_CHECKSUMS", []string{"sha224", "sha256", "sha384", "sha512"})
+	s.Set("REDIS_SENTINELS", []any{Tuple{"pulp-redis-sentinel-svc.pulp", 26379}})
+	s.Set("DATABASES", Dict{
+		{"default", Dict{
+			{"HOST", "pulp-database-svc"},
+			{"ENGINE", "django.db.backends.postgresql"},
+			{"NAME", "pulp"},
+			{"USER", "pulp"},
+			{"PASSWORD", `p@ss'"\`},
+			{"PORT", "5432"},
+			{"CONN_MAX_AGE", 0},
+			{"OPTIONS", Dict{{"sslmode", "prefer"}}},
+		}},
+	})
+	s.Code("import ldap")
+	s.Set("AUTH_LDAP_CONNECTION_OPTIONS", Dict{{Expr("ldap.OPT_X_TLS_CACERTFILE"), "/etc/pulp/ldap/ca.crt"}, {Expr("ldap.OPT_X_TLS_NEWCTX"), 0}})
+	s.Set("SOCIAL_AUTH_PIPELINE", []string{
+		"social_core.pipeline.social_auth.social_details",
+		"social_core.pipeline.social_auth.social_uid",
+		"pulp_oidc.pipeline.map_claims",
+	})
+	s.Set("PULP_OIDC_CLAIM_MAPPINGS", []any{
+		Dict{{"claim", "admins"}, {"groups", []string{}}, {"roles", []string{}}, {"superuser", true}},
+		Dict{{"claim", "developers"}, {"groups", []string{"developers"}}, {"roles", []string{"file.filerepository_creator"}}, {"superuser", false}},
+	})
+	s.Set("CUSTOM", Expr("{\n    'a': 1,\n}"))
+
+	golden(t, "render", s.Render())
+}
@@ -0,0 +1,291 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	pulp_ocp "github.com/pulp/pulp-operator/controllers/ocp"
+	"github.com/pulp/pulp-operator/controllers/settings"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// certificateGVK is the cert-manager Certificate kind.
+// Like the HTTPRoutes, Certificates are handled as unstructured objects to avoid
+// requiring the cert-manager CRDs in clusters that are not using tls.issuer_ref.
+var certificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// pulpCertificate holds the name (which is also the name of the Secret issued by cert-manager)
+// and the hostnames of a Certificate
+type pulpCertificate struct {
+	name     string
+	dnsNames []string
+}
+
+// certificateController provisions the cert-manager Certificates for ingress_host, route_host,
+// pulp-web (when web.tls_termination_mechanism is passthrough) and the pulpcore services
+// (when internal_tls is enabled)
+func (r *RepoManagerReconciler) certificateController(ctx context.Context, pulp *pulpv1.Pulp, log logr.Logger) (ctrl.Result, error) {
+
+	// conditionType is used to update .status.conditions with the current resource state
+	conditionType := "Pulp-Certificates-Ready"
+
+	expectedCertificates := r.pulpCertificates(pulp)
+
+	// remove the Certificates that are not needed anymore (for example, if tls.issuer_ref was
+	// removed or a Secret was provided through ingress_tls_secret/route_tls_secret)
+	if err := r.removeCertificates(ctx, pulp, expectedCertificates); err != nil {
+		log.Error(err, "Failed to remove Certificates")
+		return ctrl.Result{}, err
+	}
+	if len(expectedCertificates) == 0 {
+		if v1.FindStatusCondition(pulp.Status.Conditions, conditionType) != nil {
+			v1.RemoveStatusCondition(&pulp.Status.Conditions, conditionType)
+			r.Status().Update(ctx, pulp)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	requeue := false
+	for _, certificate := range expectedCertificates {
+		expectedCertificate := certificateObject(controllers.FunctionResources{Context: ctx, Client: r.Client, Pulp: pulp, Scheme: r.Scheme, Logger: log}, certificate)
+		currentCertificate := &unstructured.Unstructured{}
+		currentCertificate.SetGroupVersionKind(certificateGVK)
+		err := r.Get(ctx, types.NamespacedName{Name: certificate.name, Namespace: pulp.Namespace}, currentCertificate)
+
+		// Create the Certificate in case it is not found
+		if err != nil && errors.IsNotFound(err) {
+			log.Info("Creating a new Certificate", "Certificate.Namespace", pulp.Namespace, "Certificate.Name", certificate.name)
+			controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, conditionType, "CreatingCertificate", "Creating "+certificate.name+" Certificate")
+			if err := r.Create(ctx, expectedCertificate); err != nil {
+				log.Error(err, "Failed to create new Certificate", "Certificate.Namespace", pulp.Namespace, "Certificate.Name", certificate.name)
+				controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, conditionType, "ErrorCreatingCertificate", "Failed to create "+certificate.name+" Certificate: "+err.Error())
+				r.recorder.Event(pulp, corev1.EventTypeWarning, "Failed", "Failed to create new Certificate")
+				return ctrl.Result{}, err
+			}
+			requeue = true
+			continue
+		} else if err != nil {
+			log.Error(err, "Failed to get Certificate")
+			return ctrl.Result{}, err
+		}
+
+		// Ensure Certificate spec and labels are as expected
+		if !equality.Semantic.DeepDerivative(expectedCertificate.Object["spec"], currentCertificate.Object["spec"]) ||
+			!equality.Semantic.DeepEqual(expectedCertificate.GetLabels(), currentCertificate.GetLabels()) {
+			log.Info("The " + certificate.name + " Certificate has been modified! Reconciling ...")
+			controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, conditionType, "UpdatingCertificate", "Reconciling "+certificate.name+" Certificate")
+			currentCertificate.Object["spec"] = expectedCertificate.Object["spec"]
+			currentCertificate.SetLabels(expectedCertificate.GetLabels())
+			if err := r.Update(ctx, currentCertificate); err != nil {
+				log.Error(err, "Failed to reconcile "+certificate.name+" Certificate")
+				controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, conditionType, "ErrorUpdatingCertificate", "Failed to reconcile "+certificate.name+" Certificate: "+err.Error())
+				r.recorder.Event(pulp, corev1.EventTypeWarning, "Failed", "Failed to reconcile "+certificate.name+" Certificate")
+				return ctrl.Result{}, err
+			}
+			r.recorder.Event(pulp, corev1.EventTypeNormal, "Updated", certificate.name+" Certificate reconciled")
+			requeue = true
+		}
+	}
+	if requeue {
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	// we should only update the status when Certificates-Ready==false
+	if v1.IsStatusConditionFalse(pulp.Status.Conditions, conditionType) {
+		controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionTrue, conditionType, "CertificateTasksFinished", "All Certificate tasks ran successfully")
+		r.recorder.Event(pulp, corev1.EventTypeNormal, "CertificatesReady", "All Certificate tasks ran successfully")
+	}
+	return ctrl.Result{}, nil
+}
+
+// pulpCertificates returns the Certificates that should be issued by cert-manager.
+// A Certificate is not provisioned if the Secret is provided through ingress_tls_secret
+// or route_tls_secret.
+func (r *RepoManagerReconciler) pulpCertificates(pulp *pulpv1.Pulp) []pulpCertificate {
+	certificates := []pulpCertificate{}
+	if !controllers.CertManagerEnabled(pulp) {
+		return certificates
+	}
+
+	if isIngress(pulp) && len(pulp.Spec.IngressTLSSecret) == 0 && len(pulp.Spec.IngressHost) > 0 {
+		certificates = append(certificates, pulpCertificate{
+			name:     settings.IngressCertificate(pulp.Name),
+			dnsNames: certificateDNSNames(controllers.IngressHosts(pulp), settings.IngressCertificate(pulp.Name)),
+		})
+	}
+
+	if isRoute(pulp) && len(pulp.Spec.RouteTLSSecret) == 0 {
+		certificates = append(certificates, pulpCertificate{
+			name:     settings.RouteCertificate(pulp.Name),
+			dnsNames: certificateDNSNames(pulp_ocp.RouteHosts(pulp), settings.RouteCertificate(pulp.Name)),
+		})
+	}
+
+	if controllers.WebTLSPassthrough(pulp) && r.needsPulpWeb(pulp) {
+		webService := settings.PulpWebService(pulp.Name)
+		dnsNames := []string{
+			webService,
+			webService + "." + pulp.Namespace,
+			webService + "." + pulp.Namespace + ".svc",
+			webService + "." + pulp.Namespace + ".svc.cluster.local",
+		}
+		for _, host := range controllers.IngressHosts(pulp) {
+			if len(host.Host) > 0 {
+				dnsNames = append(dnsNames, host.Host)
+			}
+		}
+		certificates = append(certificates, pulpCertificate{
+			name:     settings.WebCertificate(pulp.Name),
+			dnsNames: dnsNames,
+		})
+	}
+
+	if controllers.InternalTLSCertManager(pulp) {
+		certificates = append(certificates, pulpCertificate{
+			name:     settings.InternalTLSSecret(pulp.Name),
+			dnsNames: controllers.InternalTLSDNSNames(pulp),
+		})
+	}
+
+	return certificates
+}
+
+// certificateDNSNames returns the hosts that should be added to the certificate stored in secretName
+// (the hosts without a tls_secret share the certificate from the main host)
+func certificateDNSNames(hosts []controllers.PulpHost, secretName string) []string {
+	dnsNames := []string{}
+	for _, host := range hosts {
+		if host.TLSSecret == secretName && len(host.Host) > 0 {
+			dnsNames = append(dnsNames, host.Host)
+		}
+	}
+	return dnsNames
+}
+
+// certificateObject returns the cert-manager Certificate for the given hostnames
+func certificateObject(resources controllers.FunctionResources, certificate pulpCertificate) *unstructured.Unstructured {
+	pulp := resources.Pulp
+	issuerRef := pulp.Spec.TLS.IssuerRef
+
+	issuerKind := issuerRef.Kind
+	if len(issuerKind) == 0 {
+		issuerKind = "Issuer"
+	}
+	issuerGroup := issuerRef.Group
+	if len(issuerGroup) == 0 {
+		issuerGroup = certificateGVK.Group
+	}
+
+	dnsNames := []any{}
+	for _, dnsName := range certificate.dnsNames {
+		dnsNames = append(dnsNames, dnsName)
+	}
+	spec := map[string]any{
+		"secretName": certificate.name,
+		"dnsNames":   dnsNames,
+		"issuerRef": map[string]any{
+			"name":  issuerRef.Name,
+			"kind":  issuerKind,
+			"group": issuerGroup,
+		},
+		// the labels are propagated to the Secret so that it can be identified as part of this Pulp instance
+		"secretTemplate": map[string]any{
+			"labels": stringMapToAny(settings.CommonLabels(*pulp)),
+		},
+	}
+	if len(pulp.Spec.TLS.Duration) > 0 {
+		spec["duration"] = pulp.Spec.TLS.Duration
+	}
+	if len(pulp.Spec.TLS.RenewBefore) > 0 {
+		spec["renewBefore"] = pulp.Spec.TLS.RenewBefore
+	}
+
+	certificateObj := &unstructured.Unstructured{Object: map[string]any{"spec": spec}}
+	certificateObj.SetGroupVersionKind(certificateGVK)
+	certificateObj.SetName(certificate.name)
+	certificateObj.SetNamespace(pulp.Namespace)
+	certificateObj.SetLabels(settings.CommonLabels(*pulp))
+
+	// Set Pulp instance as the owner and controller
+	ctrl.SetControllerReference(pulp, certificateObj, resources.Scheme)
+	return certificateObj
+}
+
+// stringMapToAny converts a map[string]string into the map format expected by unstructured objects
+func stringMapToAny(m map[string]string) map[string]any {
+	converted := make(map[string]any, len(m))
+	for k, v := range m {
+		converted[k] = v
+	}
+	return converted
+}
+
+// removeCertificates deletes the Certificates provisioned by the operator that are not
+// in the expected list anymore
+func (r *RepoManagerReconciler) removeCertificates(ctx context.Context, pulp *pulpv1.Pulp, expected []pulpCertificate) error {
+
+	// nothing to do if cert-manager was never configured for this instance
+	if !controllers.CertManagerEnabled(pulp) && v1.FindStatusCondition(pulp.Status.Conditions, "Pulp-Certificates-Ready") == nil {
+		return nil
+	}
+
+	certificateList := &unstructured.UnstructuredList{}
+	certificateList.SetGroupVersionKind(certificateGVK.GroupVersion().WithKind("CertificateList"))
+	listOpts := []client.ListOption{
+		client.InNamespace(pulp.Namespace),
+		client.MatchingLabels(settings.CommonLabels(*pulp)),
+	}
+	if err := r.List(ctx, certificateList, listOpts...); err != nil {
+		// cert-manager CRDs not installed
+		if v1.IsNoMatchError(err) {
+			return nil
+		}
+		return err
+	}
+
+	for i := range certificateList.Items {
+		certificate := &certificateList.Items[i]
+		found := false
+		for _, expectedCertificate := range expected {
+			if certificate.GetName() == expectedCertificate.name {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		r.RawLogger.Info("Removing " + certificate.GetName() + " Certificate")
+		if err := r.Delete(ctx, certificate); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -26,6 +26,7 @@ import (
 	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
 	"github.com/pulp/pulp-operator/controllers"
 	pulp_ocp "github.com/pulp/pulp-operator/controllers/ocp"
+	"github.com/pulp/pulp-operator/controllers/settings"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -55,14 +56,20 @@ type RepoManagerReconciler struct {
 //+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulps,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulps/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulps/finalizers,verbs=update
-//+kubebuilder:rbac:groups=networking.k8s.io,namespace=pulp-operator-system,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.k8s.io,namespace=pulp-operator-system,resources=ingresses;networkpolicies,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=route.openshift.io,namespace=pulp-operator-system,resources=routes;routes/custom-host,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=cert-manager.io,namespace=pulp-operator-system,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,namespace=pulp-operator-system,resources=httproutes,verbs=get;list;watch;create;update;patch;delete;deletecollection
+//+kubebuilder:rbac:groups=traefik.io,namespace=pulp-operator-system,resources=ingressroutes;middlewares;serverstransports,verbs=get;list;watch;create;update;patch;delete;deletecollection
+//+kubebuilder:rbac:groups=projectcontour.io,namespace=pulp-operator-system,resources=httpproxies,verbs=get;list;watch;create;update;patch;delete;deletecollection
 //+kubebuilder:rbac:groups=rbac.authorization.k8s.io,namespace=pulp-operator-system,resources=roles;rolebindings,verbs=create;update;patch;delete;watch;get;list
 //+kubebuilder:rbac:groups=core,namespace=pulp-operator-system,resources=pods;pods/log;serviceaccounts;configmaps;secrets;services;persistentvolumeclaims,verbs=create;update;patch;delete;watch;get;list
 //+kubebuilder:rbac:groups=core,namespace=pulp-operator-system,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
 //+kubebuilder:rbac:groups=apps,namespace=pulp-operator-system,resources=deployments;statefulsets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=policy,namespace=pulp-operator-system,resources=poddisruptionbudgets,verbs=get;list;create;delete;patch;update;watch
 //+kubebuilder:rbac:groups=batch,namespace=pulp-operator-system,resources=cronjobs;jobs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=secrets-store.csi.x-k8s.io,namespace=pulp-operator-system,resources=secretproviderclasses,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -122,7 +129,9 @@ func (r *RepoManagerReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	// If we get into here it means that there is no reconciliation
 	// nor controller tasks pending
 	log.Info("Operator tasks synced")
-	return ctrl.Result{}, nil
+
+	// sample the file storage usage periodically and wait for the next secret rotation
+	return earliestResult(r.fileStorageUsage(ctx, pulp), r.nextSecretRotation(ctx, pulp)), nil
 }
 
 func ocpTasks(ctx context.Context, pulp *pulpv1.Pulp, r RepoManagerReconciler) (*ctrl.Result, error) {
@@ -187,11 +196,26 @@ func pulpCoreTasks(ctx context.Context, pulp *pulpv1.Pulp, r RepoManagerReconcil
 		return pulpController, err
 	}
 
+	log.V(1).Info("Running storage migration tasks ...")
+	if pulpController, err := r.storageMigrationController(ctx, pulp); pulpController != nil || err != nil {
+		return pulpController, err
+	}
+
 	log.V(1).Info("Running secrets tasks ...")
 	if pulpController, err := r.createSecrets(ctx, pulp); pulpController != nil || err != nil {
 		return pulpController, err
 	}
 
+	log.V(1).Info("Running certificate tasks")
+	if pulpController, err := r.certificateController(ctx, pulp, log); needsRequeue(err, pulpController) {
+		return &pulpController, err
+	}
+
+	log.V(1).Info("Running internal TLS tasks")
+	if pulpController, err := r.internalTLSController(ctx, pulp, log); needsRequeue(err, pulpController) {
+		return &pulpController, err
+	}
+
 	log.V(1).Info("Running API tasks")
 	if pulpController, err := r.pulpApiController(ctx, pulp, log); needsRequeue(err, pulpController) {
 		return &pulpController, err
@@ -216,18 +240,38 @@ func pulpCoreTasks(ctx context.Context, pulp *pulpv1.Pulp, r RepoManagerReconcil
 		return &pulpController, err
 	}
 
+	// rotate the admin password and the container token key pair
+	if pulpController, err := r.secretRotationController(ctx, pulp, log); needsRequeue(err, pulpController) {
+		return &pulpController, err
+	}
+
+	// rotate the database fields encryption key
+	if pulpController, err := r.dbKeyRotationController(ctx, pulp, log); needsRequeue(err, pulpController) {
+		return &pulpController, err
+	}
+
 	// create the job to reset pulp admin password in case admin_password_secret has changed
 	r.updateAdminPasswordJob(ctx, pulp)
 
 	// create the job to update the allowed_content_checksums
 	r.updateContentChecksumsJob(ctx, pulp)
 
+	// the paths from the installed plugins are used by the Ingress, Routes, HTTPRoutes and pulp-web
+	log.V(1).Info("Running plugin paths tasks")
+	if pulpController, err := r.pluginPathsController(ctx, pulp, log); needsRequeue(err, pulpController) {
+		return &pulpController, err
+	}
+
 	// if this is the first reconciliation loop (.status.ingress_type == "") OR
 	// if there is no update in ingressType field
 	if len(pulp.Status.IngressType) == 0 || pulp.Status.IngressType == pulp.Spec.IngressType {
 		if isRoute(pulp) {
 			log.V(1).Info("Running route tasks")
-			pulpController, err := pulp_ocp.PulpRouteController(controllers.FunctionResources{Context: ctx, Client: r.Client, Pulp: pulp, Scheme: r.Scheme, Logger: log}, r.RESTClient, r.RESTConfig)
+			pulpPlugins, reconcile := r.ingressPlugins(ctx, pulp, log, "Pulp-Route-Ready")
+			if reconcile != nil {
+				return reconcile, nil
+			}
+			pulpController, err := pulp_ocp.PulpRouteController(controllers.FunctionResources{Context: ctx, Client: r.Client, Pulp: pulp, Scheme: r.Scheme, Logger: log}, pulpPlugins)
 			if needsRequeue(err, pulpController) {
 				return &pulpController, err
 			}
@@ -237,6 +281,12 @@ func pulpCoreTasks(ctx context.Context, pulp *pulpv1.Pulp, r RepoManagerReconcil
 			if needsRequeue(err, pulpController) {
 				return &pulpController, err
 			}
+		} else if isGateway(pulp) {
+			log.V(1).Info("Running gateway tasks")
+			pulpController, err := r.pulpGatewayController(ctx, pulp, log)
+			if needsRequeue(err, pulpController) {
+				return &pulpController, err
+			}
 		} else {
 			log.V(1).Info("Running web tasks")
 			pulpController, err := r.pulpWebController(ctx, pulp, log)
@@ -256,16 +306,33 @@ func pulpCoreTasks(ctx context.Context, pulp *pulpv1.Pulp, r RepoManagerReconcil
 		return &ctrl.Result{Requeue: true}, nil
 	}
 
+	// if the ingress_controller (or is_nginx_ingress) has been modified, remove the resources
+	// provisioned for the previous controller
+	if isIngress(pulp) && pulp.Status.IngressController != controllers.IngressController(pulp) {
+		r.updateIngressController(ctx, pulp)
+		return &ctrl.Result{Requeue: true}, nil
+	}
+
 	log.V(1).Info("Running PDB tasks")
 	if pulpController, err := r.pdbController(ctx, pulp, log); needsRequeue(err, pulpController) {
 		return &pulpController, err
 	}
 
+	log.V(1).Info("Running NetworkPolicy tasks")
+	if pulpController, err := r.networkPolicyController(ctx, pulp, log); needsRequeue(err, pulpController) {
+		return &pulpController, err
+	}
+
 	// remove telemetry resources in case it is not enabled anymore
 	if pulp.Status.TelemetryEnabled && !pulp.Spec.Telemetry.Enabled {
 		controllers.RemoveTelemetryResources(controllers.FunctionResources{Context: ctx, Client: r.Client, Pulp: pulp, Scheme: r.Scheme, Logger: log})
 	}
 
+	log.V(1).Info("Running LDAP connectivity test tasks")
+	if pulpController, err := r.ldapCheckController(ctx, pulp, log); needsRequeue(err, pulpController) {
+		return &pulpController, err
+	}
+
 	return nil, nil
 }
 
@@ -300,7 +367,7 @@ func indexerFunc(obj client.Object) []string {
 	pulp := obj.(*pulpv1.Pulp)
 	var keys []string
 
-	secrets := []string{"ObjectStorageAzureSecret", "ObjectStorageS3Secret", "SSOSecret", "AdminPasswordSecret", "PulpSecretKey", "SigningScripts", "SigningSecret"}
+	secrets := []string{"ObjectStorageAzureSecret", "ObjectStorageS3Secret", "ObjectStorageGCSSecret", "SSOSecret", "AdminPasswordSecret", "PulpSecretKey", "SigningScripts", "SigningSecret"}
 	for _, secretField := range secrets {
 		structField := reflect.Indirect(reflect.ValueOf(pulp)).FieldByName("Spec").FieldByName(secretField).String()
 		if structField != "" {
@@ -319,9 +386,31 @@ func indexerFunc(obj client.Object) []string {
 	if pulp.Spec.LDAP.CA != "" {
 		keys = append(keys, pulp.Spec.LDAP.CA)
 	}
+	if pulp.Spec.LDAP.BindPasswordSecret != "" {
+		keys = append(keys, pulp.Spec.LDAP.BindPasswordSecret)
+	}
 	if customSettings := pulp.Spec.CustomPulpSettings; customSettings != "" {
 		keys = append(keys, customSettings)
 	}
+	// the certificates are embedded in the routes and mounted in pulp-web pods,
+	// so their rotation needs to be propagated
+	if routeTLSSecret := controllers.RouteTLSSecret(pulp); routeTLSSecret != "" {
+		keys = append(keys, routeTLSSecret)
+	}
+	for _, alias := range pulp.Spec.HostAliases {
+		if alias.TLSSecret != "" {
+			keys = append(keys, alias.TLSSecret)
+		}
+	}
+	if pulp.Spec.ContentHost != nil && pulp.Spec.ContentHost.TLSSecret != "" {
+		keys = append(keys, pulp.Spec.ContentHost.TLSSecret)
+	}
+	if controllers.WebTLSPassthrough(pulp) {
+		keys = append(keys, settings.WebCertificate(pulp.Name))
+	}
+	if controllers.InternalTLSEnabled(pulp) {
+		keys = append(keys, settings.InternalTLSSecret(pulp.Name), controllers.InternalTLSCASecret(pulp))
+	}
 
 	return keys
 }
@@ -338,7 +427,7 @@ func (r *RepoManagerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	}
 
 	controller := ctrl.NewControllerManagedBy(mgr).
-		For(&pulpv1.Pulp{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		For(&pulpv1.Pulp{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, rotationAnnotationsChanged()))).
 		Owns(&appsv1.StatefulSet{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.Service{}).
@@ -348,6 +437,7 @@ func (r *RepoManagerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&corev1.ServiceAccount{}).
 		Owns(&batchv1.CronJob{}, builder.WithPredicates(ignoreCronjobStatus())).
 		Owns(&netv1.Ingress{}).
+		Owns(&netv1.NetworkPolicy{}).
 		Watches(
 			&corev1.Secret{},
 			handler.EnqueueRequestsFromMapFunc(r.findPulpDependentObjects),
@@ -357,6 +447,11 @@ func (r *RepoManagerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			&corev1.ConfigMap{},
 			handler.EnqueueRequestsFromMapFunc(r.findPulpDependentObjects),
 			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		Watches(
+			&pulpv1.PulpDomain{},
+			handler.EnqueueRequestsFromMapFunc(findPulpForDomain),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
 		)
 
 	if isOpenShift, _ := controllers.IsOpenShift(); isOpenShift {
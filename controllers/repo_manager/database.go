@@ -96,6 +96,15 @@ func (r *RepoManagerReconciler) databaseController(ctx context.Context, pulp *pu
 		return ctrl.Result{}, err
 	}
 
+	// volumeClaimTemplates are immutable, so instead of updating the StatefulSet
+	// we will expand the PVC created from the template
+	if len(pgSts.Spec.VolumeClaimTemplates) > 0 {
+		expected_sts.Spec.VolumeClaimTemplates = pgSts.Spec.VolumeClaimTemplates
+		if err := r.expandPVC(ctx, pulp, settings.DBStatefulSetPVC(pulp.Name), pulp.Spec.Database.PostgresStorageRequirements); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	// Reconcile StatefulSet
 	if !equality.Semantic.DeepDerivative(expected_sts.Spec, pgSts.Spec) {
 		log.Info("The " + statefulSetName + " StatefulSet has been modified! Reconciling ...")
@@ -0,0 +1,343 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/settings"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// dbKeyRotationAnnotation requests a new rotation of the database fields encryption key whenever its value changes
+	dbKeyRotationAnnotation = "repo-manager.pulpproject.org/rotate-db-fields-encryption-key"
+	dbKeyRotationCondition  = "Pulp-DB-Fields-Encryption-Key-Rotation"
+	// dbFieldsEncryptionKey is the db_fields_encryption_secret key mounted in /etc/pulp/keys
+	dbFieldsEncryptionKey = "database_fields.symmetric.key"
+	// dbKeyAddedAnnotation is set in the db_fields_encryption_secret with the time the new key
+	// was added, so that the key is added only once for each rotation
+	dbKeyAddedAnnotation = "repo-manager.pulpproject.org/db-fields-encryption-key-added"
+
+	dbKeyRotationRollingOut = "RollingOut"
+	dbKeyRotationRotating   = "Rotating"
+	dbKeyRotationCompleted  = "Completed"
+	dbKeyRotationFailed     = "Failed"
+)
+
+// dbKeyRotationController rotates the key used to encrypt the database fields.
+// pulpcore reads a list of keys (one per line) from DB_ENCRYPTION_KEY: the first one encrypts
+// the fields and all of them are used to decrypt. The rotation follows the pulpcore procedure:
+//   - a new key is added at the top of the db_fields_encryption_secret
+//   - the pulpcore pods are restarted (so that all of them can decrypt the fields with the new key)
+//   - a Job runs pulpcore-manager rotate-db-key to re-encrypt the fields with the new key
+//   - the previous keys are removed from the Secret and the pods are restarted again
+func (r *RepoManagerReconciler) dbKeyRotationController(ctx context.Context, pulp *pulpv1.Pulp, log logr.Logger) (ctrl.Result, error) {
+	rotation := pulp.Status.DBFieldsEncryptionRotation
+	if rotation == nil || rotation.Phase == dbKeyRotationCompleted {
+		return r.startDBKeyRotation(ctx, pulp, log)
+	}
+
+	switch rotation.Phase {
+	case dbKeyRotationRollingOut:
+		return r.dbKeyRotationRollingOut(ctx, pulp, log)
+	case dbKeyRotationRotating:
+		return r.dbKeyRotationRotating(ctx, pulp, log)
+	}
+
+	// the rotation failed, it will be retried only after the failed Job is removed
+	job := &batchv1.Job{}
+	if err := r.Get(ctx, types.NamespacedName{Name: rotation.Job, Namespace: pulp.Namespace}, job); err != nil && errors.IsNotFound(err) {
+		log.Info("DB key rotation Job " + rotation.Job + " not found. Retrying the rotation of the database fields encryption key ...")
+		rotation.Phase = dbKeyRotationRollingOut
+		rotation.Job = ""
+		if err := r.setDBKeyRotationCondition(ctx, pulp, metav1.ConditionFalse, dbKeyRotationRollingOut, "Waiting the pulpcore pods to load the new database fields encryption key"); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// startDBKeyRotation starts a new rotation if it is due. The RollingOut phase is stored in
+// the status before the new key is added to the Secret so that a failed status update does
+// not add another key in the next reconciliation.
+func (r *RepoManagerReconciler) startDBKeyRotation(ctx context.Context, pulp *pulpv1.Pulp, log logr.Logger) (ctrl.Result, error) {
+	policy := pulp.Spec.SecretRotation.DBFieldsEncryption
+	trigger := pulp.Annotations[dbKeyRotationAnnotation]
+	if len(policy.Interval) == 0 && len(trigger) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	secretName := dbFieldsEncryptionSecretName(pulp)
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: secretName, Namespace: pulp.Namespace}, secret); err != nil {
+		log.Error(err, "Failed to get "+secretName+" Secret")
+		return ctrl.Result{}, err
+	}
+	var status *pulpv1.SecretRotationStatus
+	if pulp.Status.DBFieldsEncryptionRotation != nil {
+		status = &pulp.Status.DBFieldsEncryptionRotation.SecretRotationStatus
+	}
+	if !rotationDue(policy.Interval, status, trigger, secret.CreationTimestamp) {
+		return ctrl.Result{}, nil
+	}
+
+	now := metav1.Now()
+	rotation := &pulpv1.DBFieldsEncryptionRotationStatus{Phase: dbKeyRotationRollingOut, StartTime: &now}
+	rotation.Trigger = trigger
+	if status != nil {
+		rotation.LastRotationTime = status.LastRotationTime
+	}
+	pulp.Status.DBFieldsEncryptionRotation = rotation
+	if err := r.setDBKeyRotationCondition(ctx, pulp, metav1.ConditionFalse, dbKeyRotationRollingOut, "Waiting the pulpcore pods to load the new database fields encryption key"); err != nil {
+		return ctrl.Result{}, err
+	}
+	return r.dbKeyRotationRollingOut(ctx, pulp, log)
+}
+
+// dbKeyRotationRollingOut adds the new key at the top of the db_fields_encryption_secret,
+// waits until all the pulpcore pods are restarted with it and creates the Job to re-encrypt
+// the database fields
+func (r *RepoManagerReconciler) dbKeyRotationRollingOut(ctx context.Context, pulp *pulpv1.Pulp, log logr.Logger) (ctrl.Result, error) {
+	rotation := pulp.Status.DBFieldsEncryptionRotation
+
+	secretName := dbFieldsEncryptionSecretName(pulp)
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: secretName, Namespace: pulp.Namespace}, secret); err != nil {
+		log.Error(err, "Failed to get "+secretName+" Secret")
+		return ctrl.Result{}, err
+	}
+	if len(secret.Annotations[dbKeyAddedAnnotation]) == 0 {
+		log.Info("Adding a new database fields encryption key to " + secretName + " Secret")
+		keys := append([]string{createFernetKey()}, fernetKeys(secret.Data[dbFieldsEncryptionKey])...)
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		if secret.Annotations == nil {
+			secret.Annotations = map[string]string{}
+		}
+		secret.Data[dbFieldsEncryptionKey] = []byte(strings.Join(keys, "\n") + "\n")
+		secret.Annotations[dbKeyAddedAnnotation] = time.Now().Format(time.RFC3339)
+		if err := r.Update(ctx, secret); err != nil {
+			log.Error(err, "Failed to update "+secretName+" Secret")
+			return ctrl.Result{}, err
+		}
+		r.recorder.Event(pulp, corev1.EventTypeNormal, "DBKeyRotationStarted", "Added a new database fields encryption key to "+secretName+" Secret")
+	}
+
+	// the key is mounted through subPath, so the pods need to be recreated to load it
+	// (the restart is retried until it is stored in the status after the key was added)
+	addedAt, _ := time.Parse(time.RFC3339, secret.Annotations[dbKeyAddedAnnotation])
+	if restartedAt, err := time.Parse(time.RFC3339, pulp.Status.LastDeploymentUpdate); err != nil || restartedAt.Before(addedAt) {
+		r.restartPulpCorePods(ctx, pulp)
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	if !r.pulpcoreRolledOut(ctx, pulp) {
+		log.Info("Waiting pulpcore pods to load the new database fields encryption key ...")
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+
+	job := r.dbKeyRotationJob(pulp)
+	log.Info("Creating a new " + settings.RotateDBKeyJob(pulp.Name) + "* Job")
+	if err := r.Create(ctx, job); err != nil {
+		log.Error(err, "Failed to create "+settings.RotateDBKeyJob(pulp.Name)+"* Job!")
+		return ctrl.Result{}, err
+	}
+
+	rotation.Phase = dbKeyRotationRotating
+	rotation.Job = job.Name
+	if err := r.setDBKeyRotationCondition(ctx, pulp, metav1.ConditionFalse, dbKeyRotationRotating, "Re-encrypting the database fields with the new key (Job "+job.Name+")"); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+}
+
+// dbKeyRotationRotating follows the execution of the rotate-db-key Job and removes the
+// previous keys from the db_fields_encryption_secret after it succeeds
+func (r *RepoManagerReconciler) dbKeyRotationRotating(ctx context.Context, pulp *pulpv1.Pulp, log logr.Logger) (ctrl.Result, error) {
+	rotation := pulp.Status.DBFieldsEncryptionRotation
+
+	job := &batchv1.Job{}
+	if err := r.Get(ctx, types.NamespacedName{Name: rotation.Job, Namespace: pulp.Namespace}, job); err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("DB key rotation Job " + rotation.Job + " not found. Recreating it ...")
+			rotation.Phase = dbKeyRotationRollingOut
+			if err := r.Status().Update(ctx, pulp); err != nil {
+				log.Error(err, "Failed to update pulp status with the DB key rotation")
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	switch {
+	case job.Status.Succeeded > 0:
+		secretName := dbFieldsEncryptionSecretName(pulp)
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Name: secretName, Namespace: pulp.Namespace}, secret); err != nil {
+			log.Error(err, "Failed to get "+secretName+" Secret")
+			return ctrl.Result{}, err
+		}
+		if keys := fernetKeys(secret.Data[dbFieldsEncryptionKey]); len(keys) > 1 || (len(keys) == 1 && len(secret.Annotations[dbKeyAddedAnnotation]) > 0) {
+			log.Info("Removing the previous database fields encryption keys from " + secretName + " Secret")
+			secret.Data[dbFieldsEncryptionKey] = []byte(keys[0] + "\n")
+			delete(secret.Annotations, dbKeyAddedAnnotation)
+			if err := r.Update(ctx, secret); err != nil {
+				log.Error(err, "Failed to update "+secretName+" Secret")
+				return ctrl.Result{}, err
+			}
+		}
+
+		now := metav1.Now()
+		rotation.Phase = dbKeyRotationCompleted
+		rotation.LastRotationTime = &now
+		if err := r.setDBKeyRotationCondition(ctx, pulp, metav1.ConditionTrue, dbKeyRotationCompleted, "Database fields re-encrypted with the new key and previous keys removed"); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.recorder.Event(pulp, corev1.EventTypeNormal, "DBKeyRotationCompleted", "Rotated the database fields encryption key from "+secretName+" Secret")
+
+		// redeploy pulpcore pods without the previous keys
+		r.restartPulpCorePods(ctx, pulp)
+		return ctrl.Result{Requeue: true}, nil
+	case jobFailed(job):
+		rotation.Phase = dbKeyRotationFailed
+		if err := r.setDBKeyRotationCondition(ctx, pulp, metav1.ConditionFalse, dbKeyRotationFailed, "Failed to re-encrypt the database fields. Verify the logs from "+job.Name+" Job and remove it to retry."); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.recorder.Event(pulp, corev1.EventTypeWarning, "DBKeyRotationFailed", "Failed to re-encrypt the database fields with the new key")
+		return ctrl.Result{}, nil
+	}
+
+	return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+}
+
+// setDBKeyRotationCondition updates the DB key rotation condition with the current step
+// (unlike controllers.UpdateStatus, the reason and message are updated even if the status is the same)
+func (r *RepoManagerReconciler) setDBKeyRotationCondition(ctx context.Context, pulp *pulpv1.Pulp, status metav1.ConditionStatus, reason, message string) error {
+	v1.SetStatusCondition(&pulp.Status.Conditions, metav1.Condition{
+		Type:               dbKeyRotationCondition,
+		Status:             status,
+		Reason:             reason,
+		LastTransitionTime: metav1.Now(),
+		Message:            message,
+	})
+	if err := r.Status().Update(ctx, pulp); err != nil {
+		r.RawLogger.Error(err, "Failed to update pulp status with the DB key rotation")
+		return err
+	}
+	return nil
+}
+
+// pulpcoreRolledOut returns true if the api, content and worker Deployments finished
+// the rollout of the pods from the last restart (.status.last_deployment_update)
+func (r *RepoManagerReconciler) pulpcoreRolledOut(ctx context.Context, pulp *pulpv1.Pulp) bool {
+	for _, pulpcoreType := range []settings.PulpcoreType{settings.API, settings.CONTENT, settings.WORKER} {
+		deployment := &appsv1.Deployment{}
+		if err := r.Get(ctx, types.NamespacedName{Name: pulpcoreType.DeploymentName(pulp.Name), Namespace: pulp.Namespace}, deployment); err != nil {
+			continue
+		}
+		if deployment.Spec.Template.Annotations["repo-manager.pulpproject.org/restartedAt"] != pulp.Status.LastDeploymentUpdate {
+			return false
+		}
+		if deployment.Status.ObservedGeneration < deployment.Generation || deployment.Status.Replicas != deployment.Status.UpdatedReplicas {
+			return false
+		}
+		if deployment.Spec.Replicas != nil && deployment.Status.UpdatedReplicas < *deployment.Spec.Replicas {
+			return false
+		}
+	}
+	return true
+}
+
+// dbKeyRotationJob returns the definition of the Job that re-encrypts the database fields
+func (r *RepoManagerReconciler) dbKeyRotationJob(pulp *pulpv1.Pulp) *batchv1.Job {
+	labels := jobLabels(*pulp)
+	labels["app.kubernetes.io/component"] = "rotate-db-key"
+	backOffLimit := int32(2)
+
+	job := commonJob(pulpJobConfig{
+		settings.RotateDBKeyJob(pulp.Name),
+		pulp.Namespace,
+		settings.PulpServiceAccount(pulp.Name),
+		labels,
+		&backOffLimit,
+		nil, // the Job is kept to track its state (and to allow retrying in case of failure)
+		[]corev1.Container{dbKeyRotationContainer(pulp)},
+		pulpcoreVolumes(pulp, ""),
+	})
+	ctrl.SetControllerReference(pulp, job, r.Scheme)
+	return job
+}
+
+// dbKeyRotationContainer defines the container spec for the rotate-db-key Job
+func dbKeyRotationContainer(pulp *pulpv1.Pulp) corev1.Container {
+	envVars := controllers.GetPostgresEnvVars(*pulp)
+	envVars = append(envVars, controllers.SetCustomEnvVars(*pulp, string(settings.API))...)
+
+	return corev1.Container{
+		Name:            "rotate-db-key",
+		Image:           pulp.Spec.Image + ":" + pulp.Spec.ImageVersion,
+		ImagePullPolicy: corev1.PullPolicy(pulp.Spec.ImagePullPolicy),
+		Env:             envVars,
+		Command:         []string{"/bin/sh"},
+		Args: []string{
+			"-c",
+			`/usr/bin/wait_on_postgres.py
+/usr/bin/wait_on_database_migrations.sh
+pulpcore-manager rotate-db-key`,
+		},
+		Resources:       pulp.Spec.MigrationJob.PulpContainer.ResourceRequirements,
+		VolumeMounts:    pulpcoreVolumeMounts(pulp),
+		SecurityContext: controllers.SetDefaultSecurityContext(),
+	}
+}
+
+// dbFieldsEncryptionSecretName returns the name of the Secret with the database fields encryption keys
+func dbFieldsEncryptionSecretName(pulp *pulpv1.Pulp) string {
+	if len(pulp.Spec.DBFieldsEncryptionSecret) > 0 {
+		return pulp.Spec.DBFieldsEncryptionSecret
+	}
+	return settings.DefaultDBFieldsEncryptionSecret(pulp.Name)
+}
+
+// fernetKeys returns the keys from a DB_ENCRYPTION_KEY file (empty lines and comments are ignored)
+func fernetKeys(data []byte) []string {
+	keys := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keys = append(keys, line)
+	}
+	return keys
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers/settings"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestFernetKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		keys []string
+	}{
+		{name: "empty", data: "", keys: []string{}},
+		{name: "single key", data: "key1\n", keys: []string{"key1"}},
+		{name: "single key without line break", data: "key1", keys: []string{"key1"}},
+		{name: "multiple keys", data: "key2\nkey1\n", keys: []string{"key2", "key1"}},
+		{name: "comments and empty lines", data: "# new key\nkey2\n\n  # old key\n  key1  \n\n", keys: []string{"key2", "key1"}},
+		{name: "windows line breaks", data: "key2\r\nkey1\r\n", keys: []string{"key2", "key1"}},
+	}
+	for _, test := range tests {
+		if keys := fernetKeys([]byte(test.data)); !reflect.DeepEqual(keys, test.keys) {
+			t.Errorf("%v: expected %v, got %v", test.name, test.keys, keys)
+		}
+	}
+}
+
+// rolloutPulpcore simulates the rollout of the pulpcore Deployments restarted by the operator
+func rolloutPulpcore(ctx context.Context, r *RepoManagerReconciler, pulp *pulpv1.Pulp) {
+	for _, pulpcoreType := range []settings.PulpcoreType{settings.API, settings.CONTENT, settings.WORKER} {
+		deployment := &appsv1.Deployment{}
+		r.Get(ctx, types.NamespacedName{Name: pulpcoreType.DeploymentName(pulp.Name), Namespace: pulp.Namespace}, deployment)
+		deployment.Spec.Template.Annotations = map[string]string{"repo-manager.pulpproject.org/restartedAt": pulp.Status.LastDeploymentUpdate}
+		r.Update(ctx, deployment)
+		deployment.Status.UpdatedReplicas = 1
+		deployment.Status.ObservedGeneration = deployment.Generation
+		r.Status().Update(ctx, deployment)
+	}
+}
+
+func TestDBKeyRotation(t *testing.T) {
+	ctx := context.TODO()
+	pulp := settingsTestPulp()
+	pulp.Annotations = map[string]string{dbKeyRotationAnnotation: "1"}
+	secretName := settings.DefaultDBFieldsEncryptionSecret(pulp.Name)
+	r := newTestReconciler(append(pulpcoreDeployments(pulp), pulp, settingsTestSecret(secretName, map[string]string{dbFieldsEncryptionKey: "old-key\n"}))...)
+	keys := func() []string {
+		secret := &corev1.Secret{}
+		r.Get(ctx, types.NamespacedName{Name: secretName, Namespace: pulp.Namespace}, secret)
+		return fernetKeys(secret.Data[dbFieldsEncryptionKey])
+	}
+	phase := func() string {
+		condition := v1.FindStatusCondition(pulp.Status.Conditions, dbKeyRotationCondition)
+		if pulp.Status.DBFieldsEncryptionRotation == nil || condition == nil || condition.Reason != pulp.Status.DBFieldsEncryptionRotation.Phase {
+			t.Fatalf("expected the condition to follow the rotation phase, got %v %v", pulp.Status.DBFieldsEncryptionRotation, condition)
+		}
+		return pulp.Status.DBFieldsEncryptionRotation.Phase
+	}
+	rotationJob := func() *batchv1.Job {
+		job := &batchv1.Job{}
+		r.Get(ctx, types.NamespacedName{Name: pulp.Status.DBFieldsEncryptionRotation.Job, Namespace: pulp.Namespace}, job)
+		return job
+	}
+
+	// the new key is added at the top and the pods are restarted
+	r.dbKeyRotationController(ctx, pulp, logr.Discard())
+	if phase() != dbKeyRotationRollingOut || len(keys()) != 2 || keys()[1] != "old-key" {
+		t.Fatalf("expected a new key at the top of the Secret, got %v", keys())
+	}
+	newKey := keys()[0]
+	if len(pulp.Status.LastDeploymentUpdate) == 0 {
+		t.Fatalf("expected the pulpcore pods to be restarted")
+	}
+
+	// the Job is created only after all the pods are running with the new key
+	r.dbKeyRotationController(ctx, pulp, logr.Discard())
+	if phase() != dbKeyRotationRollingOut {
+		t.Fatalf("expected to wait for the pulpcore rollout, got %v", phase())
+	}
+	rolloutPulpcore(ctx, r, pulp)
+	r.dbKeyRotationController(ctx, pulp, logr.Discard())
+	if phase() != dbKeyRotationRotating || len(pulp.Status.DBFieldsEncryptionRotation.Job) == 0 {
+		t.Fatalf("expected the rotate-db-key Job to be created, got %v", pulp.Status.DBFieldsEncryptionRotation)
+	}
+	job := rotationJob()
+	if job.Labels["app.kubernetes.io/component"] != "rotate-db-key" || job.Spec.Template.Spec.Containers[0].Args[1] != "/usr/bin/wait_on_postgres.py\n/usr/bin/wait_on_database_migrations.sh\npulpcore-manager rotate-db-key" {
+		t.Errorf("unexpected rotate-db-key Job %v", job.Spec.Template.Spec.Containers[0].Args)
+	}
+
+	// a failed Job keeps the previous keys until it is removed
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}
+	r.Status().Update(ctx, job)
+	r.dbKeyRotationController(ctx, pulp, logr.Discard())
+	if phase() != dbKeyRotationFailed || len(keys()) != 2 {
+		t.Fatalf("expected the rotation to fail keeping the keys, got %v %v", phase(), keys())
+	}
+	r.dbKeyRotationController(ctx, pulp, logr.Discard())
+	if phase() != dbKeyRotationFailed {
+		t.Fatalf("expected the rotation to wait for the failed Job removal")
+	}
+	r.Delete(ctx, job)
+	r.dbKeyRotationController(ctx, pulp, logr.Discard())
+	if phase() != dbKeyRotationRollingOut {
+		t.Fatalf("expected the rotation to be retried, got %v", phase())
+	}
+	r.dbKeyRotationController(ctx, pulp, logr.Discard())
+	if phase() != dbKeyRotationRotating || pulp.Status.DBFieldsEncryptionRotation.Job == job.Name {
+		t.Fatalf("expected a new rotate-db-key Job, got %v", pulp.Status.DBFieldsEncryptionRotation)
+	}
+
+	// the previous keys are removed after the Job succeeds
+	pulp.Status.LastDeploymentUpdate = ""
+	job = rotationJob()
+	job.Status.Succeeded = 1
+	r.Status().Update(ctx, job)
+	r.dbKeyRotationController(ctx, pulp, logr.Discard())
+	if phase() != dbKeyRotationCompleted || !reflect.DeepEqual(keys(), []string{newKey}) {
+		t.Fatalf("expected only the new key after the rotation, got %v %v", phase(), keys())
+	}
+	rotation := pulp.Status.DBFieldsEncryptionRotation
+	if rotation.LastRotationTime == nil || rotation.Trigger != "1" {
+		t.Errorf("expected the rotation time and trigger, got %v", rotation)
+	}
+	if len(pulp.Status.LastDeploymentUpdate) == 0 {
+		t.Errorf("expected the pulpcore pods to be restarted without the previous keys")
+	}
+	secret := &corev1.Secret{}
+	r.Get(ctx, types.NamespacedName{Name: secretName, Namespace: pulp.Namespace}, secret)
+	if _, found := secret.Annotations[dbKeyAddedAnnotation]; found {
+		t.Errorf("expected the %v annotation to be removed", dbKeyAddedAnnotation)
+	}
+
+	// the same trigger does not start a new rotation
+	r.dbKeyRotationController(ctx, pulp, logr.Discard())
+	if phase() != dbKeyRotationCompleted || len(keys()) != 1 {
+		t.Errorf("expected no new rotation, got %v %v", phase(), keys())
+	}
+	jobList := &batchv1.JobList{}
+	r.List(ctx, jobList, client.InNamespace(pulp.Namespace))
+	if len(jobList.Items) != 1 {
+		t.Errorf("expected a single rotate-db-key Job, got %v", len(jobList.Items))
+	}
+}
+
+func TestDBKeyRotationStatusUpdateFailure(t *testing.T) {
+	ctx := context.TODO()
+	pulp := settingsTestPulp()
+	pulp.Annotations = map[string]string{dbKeyRotationAnnotation: "1"}
+	secretName := settings.DefaultDBFieldsEncryptionSecret(pulp.Name)
+	r := newTestReconciler(append(pulpcoreDeployments(pulp), pulp, settingsTestSecret(secretName, map[string]string{dbFieldsEncryptionKey: "old-key\n"}))...)
+	secret := func() *corev1.Secret {
+		secret := &corev1.Secret{}
+		r.Get(ctx, types.NamespacedName{Name: secretName, Namespace: pulp.Namespace}, secret)
+		return secret
+	}
+
+	// the key is not added if the rotation could not be stored in the status
+	stale := pulp.DeepCopy()
+	stale.ResourceVersion = "1"
+	if _, err := r.dbKeyRotationController(ctx, stale, logr.Discard()); err == nil {
+		t.Fatalf("expected the status update conflict to be returned")
+	}
+	if keys := fernetKeys(secret().Data[dbFieldsEncryptionKey]); len(keys) != 1 {
+		t.Fatalf("expected the Secret to be kept, got %v", keys)
+	}
+
+	r.Get(ctx, types.NamespacedName{Name: pulp.Name, Namespace: pulp.Namespace}, pulp)
+	r.dbKeyRotationController(ctx, pulp, logr.Discard())
+	keys := fernetKeys(secret().Data[dbFieldsEncryptionKey])
+	if len(keys) != 2 || len(secret().Annotations[dbKeyAddedAnnotation]) == 0 {
+		t.Fatalf("expected a new key at the top of the Secret, got %v", keys)
+	}
+
+	// a restart lost after the key was added is retried without adding another key
+	pulp.Status.LastDeploymentUpdate = "2020-01-01T00:00:00Z"
+	r.dbKeyRotationController(ctx, pulp, logr.Discard())
+	if pulp.Status.LastDeploymentUpdate == "2020-01-01T00:00:00Z" {
+		t.Errorf("expected the pulpcore pods to be restarted")
+	}
+	if newKeys := fernetKeys(secret().Data[dbFieldsEncryptionKey]); !reflect.DeepEqual(newKeys, keys) {
+		t.Errorf("expected the same keys, got %v", newKeys)
+	}
+	if pulp.Status.DBFieldsEncryptionRotation.Phase != dbKeyRotationRollingOut {
+		t.Errorf("expected to wait for the pulpcore rollout, got %v", pulp.Status.DBFieldsEncryptionRotation.Phase)
+	}
+}
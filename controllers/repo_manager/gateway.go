@@ -0,0 +1,191 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/settings"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// httpRouteGVK is the Gateway API HTTPRoute kind.
+// We are handling HTTPRoutes as unstructured objects to avoid requiring the
+// Gateway API CRDs in clusters that are not using ingress_type: gateway.
+var httpRouteGVK = schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "HTTPRoute"}
+
+// pulpGatewayController provisions an HTTPRoute for each path exposed by pulpcore and its plugins
+func (r *RepoManagerReconciler) pulpGatewayController(ctx context.Context, pulp *pulpv1.Pulp, log logr.Logger) (ctrl.Result, error) {
+
+	// conditionType is used to update .status.conditions with the current resource state
+	conditionType := "Pulp-Gateway-Ready"
+
+	pulpPlugins, reconcile := r.ingressPlugins(ctx, pulp, log, conditionType)
+	if reconcile != nil {
+		return *reconcile, nil
+	}
+
+	resources := controllers.FunctionResources{Context: ctx, Client: r.Client, Pulp: pulp, Scheme: r.Scheme, Logger: log}
+	expectedRoutes := []*unstructured.Unstructured{}
+	for _, plugin := range pulpPlugins {
+		expectedRoutes = append(expectedRoutes, httpRouteObject(resources, plugin))
+	}
+	if requeue, err := r.reconcileUnstructuredObjects(ctx, pulp, log, conditionType, httpRouteGVK, expectedRoutes); err != nil || requeue {
+		return ctrl.Result{Requeue: requeue}, err
+	}
+
+	// we should only update the status when Gateway-Ready==false
+	if v1.IsStatusConditionFalse(pulp.Status.Conditions, conditionType) {
+		controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionTrue, conditionType, "GatewayTasksFinished", "All Gateway tasks ran successfully")
+		r.recorder.Event(pulp, corev1.EventTypeNormal, "GatewayReady", "All Gateway tasks ran successfully")
+	}
+	return ctrl.Result{}, nil
+}
+
+// httpRouteObject returns the HTTPRoute that will forward the requests from plugin.Path
+// to the pulpcore service
+func httpRouteObject(resources controllers.FunctionResources, plugin controllers.IngressPlugin) *unstructured.Unstructured {
+	pulp := resources.Pulp
+
+	parentRef := map[string]any{"name": pulp.Spec.Gateway.Name}
+	if len(pulp.Spec.Gateway.Namespace) > 0 {
+		parentRef["namespace"] = pulp.Spec.Gateway.Namespace
+	}
+	if len(pulp.Spec.Gateway.SectionName) > 0 {
+		parentRef["sectionName"] = pulp.Spec.Gateway.SectionName
+	}
+
+	rule := map[string]any{
+		"matches": []any{
+			map[string]any{
+				"path": map[string]any{"type": "PathPrefix", "value": plugin.Path},
+			},
+		},
+		"backendRefs": []any{
+			map[string]any{"name": plugin.ServiceName, "port": servicePortNumber(plugin.TargetPort)},
+		},
+		"timeouts": httpRouteTimeouts(pulp),
+	}
+	if len(plugin.Rewrite) > 0 {
+		rule["filters"] = []any{
+			map[string]any{
+				"type": "URLRewrite",
+				"urlRewrite": map[string]any{
+					"path": map[string]any{"type": "ReplacePrefixMatch", "replacePrefixMatch": plugin.Rewrite},
+				},
+			},
+		}
+	}
+
+	labels := settings.CommonLabels(*pulp)
+	for k, v := range pulp.Spec.Gateway.Labels {
+		labels[k] = v
+	}
+	route := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{
+			"parentRefs": []any{parentRef},
+			"hostnames":  httpRouteHostnames(pulp, plugin),
+			"rules":      []any{rule},
+		},
+	}}
+	route.SetGroupVersionKind(httpRouteGVK)
+	route.SetName(plugin.Name)
+	route.SetNamespace(pulp.Namespace)
+	route.SetLabels(labels)
+	if len(pulp.Spec.Gateway.Annotations) > 0 {
+		route.SetAnnotations(pulp.Spec.Gateway.Annotations)
+	}
+
+	// Set Pulp instance as the owner and controller
+	ctrl.SetControllerReference(pulp, route, resources.Scheme)
+	return route
+}
+
+// httpRouteHostnames returns the ingress_host and host_aliases and, for the content paths, the content_host
+func httpRouteHostnames(pulp *pulpv1.Pulp, plugin controllers.IngressPlugin) []any {
+	hostnames := []any{}
+	for _, host := range controllers.IngressHosts(pulp) {
+		if host.ContentOnly && plugin.ServiceName != settings.ContentService(pulp.Name) {
+			continue
+		}
+		hostnames = append(hostnames, host.Host)
+	}
+	return hostnames
+}
+
+// maxGatewayDuration is the longest duration accepted by the Gateway API (5 digits of hours)
+const maxGatewayDuration = 99999 * time.Hour
+
+// httpRouteTimeouts returns the HTTPRoute timeouts equivalent to the nginx_proxy_*_timeout fields.
+// Gateway API does not have a connect/send timeout, so the request timeout is the longest
+// of them and the backendRequest timeout (which cannot be longer than the request timeout)
+// is the read timeout.
+func httpRouteTimeouts(pulp *pulpv1.Pulp) map[string]any {
+	timeouts := ingressProxyTimeouts(pulp)
+
+	// the timeouts are validated in the prechecks
+	readTimeout, _ := nginxDuration(timeouts.read)
+	requestTimeout := readTimeout
+	for _, timeout := range []string{timeouts.send, timeouts.connect} {
+		if duration, _ := nginxDuration(timeout); duration > requestTimeout {
+			requestTimeout = duration
+		}
+	}
+	return map[string]any{"request": gatewayDuration(requestTimeout), "backendRequest": gatewayDuration(readTimeout)}
+}
+
+// gatewayDuration returns the duration in the Gateway API format (for example, 1h30m or 500ms).
+// Unlike time.Duration.String(), it does not use fractional values.
+func gatewayDuration(duration time.Duration) string {
+	if duration <= 0 {
+		return "0s"
+	}
+	value := ""
+	for _, unit := range []struct {
+		suffix   string
+		duration time.Duration
+	}{{"h", time.Hour}, {"m", time.Minute}, {"s", time.Second}, {"ms", time.Millisecond}} {
+		if count := duration / unit.duration; count > 0 {
+			value += strconv.FormatInt(int64(count), 10) + unit.suffix
+			duration -= count * unit.duration
+		}
+	}
+	return value
+}
+
+// servicePortNumber returns the port number from the service port name (for example, 24817 from api-24817).
+// HTTPRoute backendRefs do not accept port names.
+func servicePortNumber(portName string) int64 {
+	port, _ := strconv.ParseInt(portName[strings.LastIndex(portName, "-")+1:], 10, 64)
+	return port
+}
+
+// removeHTTPRoutes deletes all HTTPRoutes provisioned by the operator
+func (r *RepoManagerReconciler) removeHTTPRoutes(ctx context.Context, pulp *pulpv1.Pulp) {
+	r.removeUnstructuredObjects(ctx, pulp, httpRouteGVK)
+}
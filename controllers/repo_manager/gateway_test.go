@@ -0,0 +1,180 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/settings"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// gatewayTestPulp returns a Pulp CR exposed through a Gateway
+func gatewayTestPulp() *pulpv1.Pulp {
+	pulp := settingsTestPulp()
+	pulp.Spec.IngressType = "gateway"
+	pulp.Spec.IngressHost = "pulp.example.com"
+	pulp.Spec.HostAliases = []pulpv1.Hostname{{Host: "pulp.example.org"}}
+	pulp.Spec.ContentHost = &pulpv1.Hostname{Host: "content.example.com"}
+	pulp.Spec.Gateway = pulpv1.Gateway{Name: "my-gateway", Namespace: "gateway-system", SectionName: "https"}
+	return pulp
+}
+
+func TestNginxDuration(t *testing.T) {
+	tests := []struct {
+		value    string
+		duration time.Duration
+		invalid  bool
+	}{
+		{value: "120", duration: 120 * time.Second},
+		{value: "120s", duration: 120 * time.Second},
+		{value: "500ms", duration: 500 * time.Millisecond},
+		{value: "5m", duration: 5 * time.Minute},
+		{value: "1d", duration: 24 * time.Hour},
+		{value: "1w", duration: 7 * 24 * time.Hour},
+		{value: "1h 30m", duration: 90 * time.Minute},
+		{value: "1h30m15", duration: 90*time.Minute + 15*time.Second},
+		{value: "", invalid: true},
+		{value: "s", invalid: true},
+		{value: "10x", invalid: true},
+		{value: "-10s", invalid: true},
+	}
+	for _, test := range tests {
+		duration, err := nginxDuration(test.value)
+		if test.invalid {
+			if err == nil {
+				t.Errorf("expected an error for %q, got %v", test.value, duration)
+			}
+			continue
+		}
+		if err != nil || duration != test.duration {
+			t.Errorf("nginxDuration(%q) = %v (%v), expected %v", test.value, duration, err, test.duration)
+		}
+	}
+}
+
+func TestGatewayDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                                        "0s",
+		120 * time.Second:                        "2m",
+		90 * time.Second:                         "1m30s",
+		1500 * time.Millisecond:                  "1s500ms",
+		24 * time.Hour:                           "24h",
+		25*time.Hour + time.Minute + time.Second: "25h1m1s",
+	}
+	for duration, expected := range tests {
+		if value := gatewayDuration(duration); value != expected {
+			t.Errorf("gatewayDuration(%v) = %v, expected %v", duration, value, expected)
+		}
+	}
+}
+
+func TestHTTPRouteTimeouts(t *testing.T) {
+	tests := []struct {
+		name                    string
+		read, send, connect     string
+		request, backendRequest string
+	}{
+		{name: "defaults", request: "2m", backendRequest: "2m"},
+		{name: "nginx format", read: "300", send: "1d", request: "24h", backendRequest: "5m"},
+		{name: "read timeout is the longest", read: "1h", connect: "30s", request: "1h", backendRequest: "1h"},
+		{name: "connect timeout is the longest", read: "60s", connect: "1h 30m", request: "1h30m", backendRequest: "1m"},
+	}
+	for _, test := range tests {
+		pulp := gatewayTestPulp()
+		pulp.Spec.NginxProxyReadTimeout = test.read
+		pulp.Spec.NginxProxySendTimeout = test.send
+		pulp.Spec.NginxProxyConnectTimeout = test.connect
+		expected := map[string]any{"request": test.request, "backendRequest": test.backendRequest}
+		if timeouts := httpRouteTimeouts(pulp); !reflect.DeepEqual(timeouts, expected) {
+			t.Errorf("%v: expected %v, got %v", test.name, expected, timeouts)
+		}
+	}
+}
+
+func TestHTTPRouteObject(t *testing.T) {
+	pulp := gatewayTestPulp()
+	pulp.Spec.NginxProxyReadTimeout = "1d"
+	pulp.Spec.Gateway.Labels = map[string]string{"team": "pulp"}
+	r := newTestReconciler(pulp)
+	resources := controllers.FunctionResources{Client: r.Client, Pulp: pulp, Scheme: r.Scheme, Logger: logr.Discard()}
+
+	api := httpRouteObject(resources, controllers.IngressPlugin{Name: "test-api-v3", Path: "/pulp/api/v3/", ServiceName: settings.ApiService(pulp.Name), TargetPort: "api-24817"})
+	if api.GetName() != "test-api-v3" || api.GetNamespace() != "pulp" || api.GetLabels()["team"] != "pulp" || len(api.GetOwnerReferences()) != 1 {
+		t.Errorf("unexpected HTTPRoute metadata %v", api.Object["metadata"])
+	}
+	parentRefs, _, _ := unstructured.NestedSlice(api.Object, "spec", "parentRefs")
+	if expected := []any{map[string]any{"name": "my-gateway", "namespace": "gateway-system", "sectionName": "https"}}; !reflect.DeepEqual(parentRefs, expected) {
+		t.Errorf("expected parentRefs %v, got %v", expected, parentRefs)
+	}
+	hostnames, _, _ := unstructured.NestedSlice(api.Object, "spec", "hostnames")
+	if expected := []any{"pulp.example.com", "pulp.example.org"}; !reflect.DeepEqual(hostnames, expected) {
+		t.Errorf("expected the api hostnames %v, got %v", expected, hostnames)
+	}
+	rules, _, _ := unstructured.NestedSlice(api.Object, "spec", "rules")
+	expectedRule := map[string]any{
+		"matches":     []any{map[string]any{"path": map[string]any{"type": "PathPrefix", "value": "/pulp/api/v3/"}}},
+		"backendRefs": []any{map[string]any{"name": settings.ApiService(pulp.Name), "port": int64(24817)}},
+		"timeouts":    map[string]any{"request": "24h", "backendRequest": "24h"},
+	}
+	if !reflect.DeepEqual(rules, []any{expectedRule}) {
+		t.Errorf("expected the rules %v, got %v", expectedRule, rules)
+	}
+
+	content := httpRouteObject(resources, controllers.IngressPlugin{Name: "test-content", Path: "/pulp/content/", ServiceName: settings.ContentService(pulp.Name), TargetPort: "content-24816", Rewrite: "/pulp/content/"})
+	hostnames, _, _ = unstructured.NestedSlice(content.Object, "spec", "hostnames")
+	if expected := []any{"pulp.example.com", "pulp.example.org", "content.example.com"}; !reflect.DeepEqual(hostnames, expected) {
+		t.Errorf("expected the content hostnames %v, got %v", expected, hostnames)
+	}
+	rules, _, _ = unstructured.NestedSlice(content.Object, "spec", "rules")
+	filters, _ := rules[0].(map[string]any)["filters"].([]any)
+	if len(filters) != 1 || filters[0].(map[string]any)["type"] != "URLRewrite" {
+		t.Errorf("expected a URLRewrite filter, got %v", filters)
+	}
+}
+
+func TestCheckGatewayDefinition(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*pulpv1.Pulp)
+		fail   bool
+	}{
+		{name: "valid", modify: func(*pulpv1.Pulp) {}},
+		{name: "nginx timeouts", modify: func(pulp *pulpv1.Pulp) {
+			pulp.Spec.NginxProxyReadTimeout = "1d"
+			pulp.Spec.NginxProxySendTimeout = "300"
+		}},
+		{name: "no gateway name", modify: func(pulp *pulpv1.Pulp) { pulp.Spec.Gateway.Name = "" }, fail: true},
+		{name: "no ingress_host", modify: func(pulp *pulpv1.Pulp) { pulp.Spec.IngressHost = "" }, fail: true},
+		{name: "internal_tls", modify: func(pulp *pulpv1.Pulp) { pulp.Spec.InternalTLS.Enabled = true }, fail: true},
+		{name: "invalid timeout", modify: func(pulp *pulpv1.Pulp) { pulp.Spec.NginxProxyConnectTimeout = "10x" }, fail: true},
+		{name: "timeout too long", modify: func(pulp *pulpv1.Pulp) { pulp.Spec.NginxProxyReadTimeout = "20y" }, fail: true},
+		{name: "zero timeout", modify: func(pulp *pulpv1.Pulp) { pulp.Spec.NginxProxySendTimeout = "0s" }, fail: true},
+	}
+	for _, test := range tests {
+		pulp := gatewayTestPulp()
+		test.modify(pulp)
+		if failed := checkGatewayDefinition(logr.Discard(), pulp) != nil; failed != test.fail {
+			t.Errorf("%v: expected the precheck to fail=%v, got %v", test.name, test.fail, failed)
+		}
+	}
+}
@@ -18,7 +18,9 @@ package repo_manager
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -32,6 +34,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -42,76 +45,25 @@ func (r *RepoManagerReconciler) pulpIngressController(ctx context.Context, pulp
 	// conditionType is used to update .status.conditions with the current resource state
 	conditionType := "Pulp-Ingress-Ready"
 
-	podList := &corev1.PodList{}
-	labels := settings.PulpcoreLabels(*pulp, "content")
-	listOpts := []client.ListOption{
-		client.InNamespace(pulp.Namespace),
-		client.MatchingLabels(labels),
-	}
-	if err := r.List(ctx, podList, listOpts...); err != nil {
-		log.Error(err, "Failed to list Content pods", "Pulp.Namespace", pulp.Namespace, "Pulp.Name", pulp.Name)
-		return ctrl.Result{RequeueAfter: time.Minute}, nil
-	}
-	var IsPodRunning bool = false
-	var pod = corev1.Pod{}
-	for _, p := range podList.Items {
-		log.V(1).Info("Checking Content pod", "Pod", p.Name, "Status", p.Status.Phase)
-		if p.Status.Phase == "Running" {
-			log.V(1).Info("Running!", "Pod", p.Name, "Status", p.Status.Phase)
-			IsPodRunning = true
-			pod = p
-			break
-		} else {
-			log.Info("Content Pod isn't running yet!", "Pod", p.Name, "Status", p.Status.Phase)
-		}
+	pulpPlugins, reconcile := r.ingressPlugins(ctx, pulp, log, conditionType)
+	if reconcile != nil {
+		return *reconcile, nil
 	}
+	resources := controllers.FunctionResources{Context: ctx, Client: r.Client, Pulp: pulp, Scheme: r.Scheme, Logger: log}
 
-	if !IsPodRunning {
-		log.Info("Content pod isn't running yet!")
-		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
-	}
-	execCmd := []string{
-		"/usr/bin/route_paths.py", pulp.Name,
-	}
-	cmdOutput, err := controllers.ContainerExec(ctx, r, &pod, execCmd, "content", pod.Namespace)
-	if err != nil {
-		controllers.CustomZapLogger().Warn(err.Error() + " Failed to get ingresss from " + pod.Name)
-		controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, conditionType, "Failed to get ingresss!", "FailedGet"+pod.Name)
-		return ctrl.Result{Requeue: true}, nil
-	}
-	var pulpPlugins []controllers.IngressPlugin
-	json.Unmarshal([]byte(cmdOutput), &pulpPlugins)
-	defaultPlugins := []controllers.IngressPlugin{
-		{
-			Name:        pulp.Name + "-content",
-			Path:        controllers.GetContentPathPrefix(ctx, r.Client, pulp),
-			TargetPort:  "content-24816",
-			ServiceName: settings.ContentService(pulp.Name),
-		},
-		{
-			Name:        pulp.Name + "-api-v3",
-			Path:        controllers.GetAPIRoot(ctx, r.Client, pulp) + "api/v3/",
-			TargetPort:  "api-24817",
-			ServiceName: settings.ApiService(pulp.Name),
-		},
-		{
-			Name:        pulp.Name + "-auth",
-			Path:        "/auth/login/",
-			TargetPort:  "api-24817",
-			ServiceName: settings.ApiService(pulp.Name),
-		},
-		{
-			Name:        pulp.Name,
-			Path:        "/",
-			TargetPort:  "api-24817",
-			ServiceName: settings.ApiService(pulp.Name),
-		},
+	// traefik and contour are configured through their own CRDs instead of an Ingress
+	if objectsFunc, found := ingressControllerObjects[controllers.IngressController(pulp)]; found {
+		for _, expected := range objectsFunc(resources, pulpPlugins) {
+			if requeue, err := r.reconcileUnstructuredObjects(ctx, pulp, log, conditionType, expected.gvk, expected.objects); err != nil || requeue {
+				return ctrl.Result{Requeue: requeue}, err
+			}
+		}
+		r.ingressReady(ctx, pulp, conditionType)
+		return ctrl.Result{}, nil
 	}
-	pulpPlugins = append(defaultPlugins, pulpPlugins...)
 
 	// get ingress
 	currentIngress := &netv1.Ingress{}
-	resources := controllers.FunctionResources{Context: ctx, Client: r.Client, Pulp: pulp, Scheme: r.Scheme, Logger: log}
 	ingress, err := r.initIngress(resources)
 	if err != nil {
 		return ctrl.Result{}, err
@@ -149,11 +101,7 @@ func (r *RepoManagerReconciler) pulpIngressController(ctx context.Context, pulp
 		return ctrl.Result{Requeue: requeue}, err
 	}
 
-	// we should only update the status when Ingress-Ready==false
-	if v1.IsStatusConditionFalse(pulp.Status.Conditions, conditionType) {
-		controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionTrue, conditionType, "IngressTasksFinished", "All Ingress tasks ran successfully")
-		r.recorder.Event(pulp, corev1.EventTypeNormal, "IngressReady", "All Ingress tasks ran successfully")
-	}
+	r.ingressReady(ctx, pulp, conditionType)
 
 	if expectedIngress.Annotations["web"] == "true" {
 		log.V(1).Info("Running web tasks")
@@ -165,6 +113,117 @@ func (r *RepoManagerReconciler) pulpIngressController(ctx context.Context, pulp
 	return ctrl.Result{}, nil
 }
 
+// ingressReady updates the Ingress-Ready condition after all the Ingress tasks ran successfully
+func (r *RepoManagerReconciler) ingressReady(ctx context.Context, pulp *pulpv1.Pulp, conditionType string) {
+	// we should only update the status when Ingress-Ready==false
+	if v1.IsStatusConditionFalse(pulp.Status.Conditions, conditionType) {
+		controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionTrue, conditionType, "IngressTasksFinished", "All Ingress tasks ran successfully")
+		r.recorder.Event(pulp, corev1.EventTypeNormal, "IngressReady", "All Ingress tasks ran successfully")
+	}
+}
+
+// ingressControllerObjects has the functions returning the resources of the ingress controllers
+// configured through CRDs (instead of an Ingress)
+var ingressControllerObjects = map[string]func(controllers.FunctionResources, []controllers.IngressPlugin) []unstructuredObjects{
+	controllers.TraefikIngressController: traefikObjects,
+	controllers.ContourIngressController: contourObjects,
+}
+
+// ingressControllerGVKs has the kinds provisioned for the ingress controllers configured through CRDs
+var ingressControllerGVKs = map[string][]schema.GroupVersionKind{
+	controllers.TraefikIngressController: {traefikIngressRouteGVK, traefikMiddlewareGVK, traefikServersTransportGVK},
+	controllers.ContourIngressController: {contourHTTPProxyGVK},
+}
+
+// removeIngressControllerObjects deletes the resources provisioned for the ingress controller
+func (r *RepoManagerReconciler) removeIngressControllerObjects(ctx context.Context, pulp *pulpv1.Pulp, ingressController string) {
+	for _, gvk := range ingressControllerGVKs[ingressController] {
+		r.removeUnstructuredObjects(ctx, pulp, gvk)
+	}
+}
+
+// ingressHostObjectName returns the name of the object exposing the i-th host from controllers.IngressHosts.
+// The objects from the other hosts are suffixed with the host kind and index (like the Routes).
+func ingressHostObjectName(pulpName string, i int, host controllers.PulpHost) string {
+	switch {
+	case host.ContentOnly:
+		return pulpName + "-content-host"
+	case i > 0:
+		return pulpName + "-alias-" + strconv.Itoa(i)
+	}
+	return pulpName
+}
+
+// ingressPlugins returns the list of paths (from pulpcore and the installed plugins) that
+// should be exposed by the Ingress/Route/HTTPRoute resources.
+// The plugins paths are discovered by the plugin paths Job (see pluginPathsController).
+func (r *RepoManagerReconciler) ingressPlugins(ctx context.Context, pulp *pulpv1.Pulp, log logr.Logger, conditionType string) ([]controllers.IngressPlugin, *ctrl.Result) {
+	pulpPlugins, err := r.pluginPaths(ctx, pulp)
+	if err != nil {
+		log.Info("Plugin paths not discovered yet!")
+		controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, conditionType, "WaitingPluginPaths", "Waiting for the plugin paths discovery")
+		return nil, &ctrl.Result{RequeueAfter: 5 * time.Second}
+	}
+	defaultPlugins := []controllers.IngressPlugin{
+		{
+			Name:        pulp.Name + "-content",
+			Path:        controllers.GetContentPathPrefix(ctx, r.Client, pulp),
+			TargetPort:  "content-24816",
+			ServiceName: settings.ContentService(pulp.Name),
+		},
+		{
+			Name:        pulp.Name + "-api-v3",
+			Path:        controllers.GetAPIRoot(ctx, r.Client, pulp) + "api/v3/",
+			TargetPort:  "api-24817",
+			ServiceName: settings.ApiService(pulp.Name),
+		},
+		{
+			Name:        pulp.Name + "-auth",
+			Path:        "/auth/login/",
+			TargetPort:  "api-24817",
+			ServiceName: settings.ApiService(pulp.Name),
+		},
+		{
+			Name:        pulp.Name,
+			Path:        "/",
+			TargetPort:  "api-24817",
+			ServiceName: settings.ApiService(pulp.Name),
+		},
+	}
+	defaultPlugins = append(defaultPlugins, r.domainContentPlugins(ctx, pulp)...)
+	return append(defaultPlugins, pulpPlugins...), nil
+}
+
+// domainContentPlugins returns the content paths of the PulpDomains from the Pulp CR that are not
+// exposed by the content path prefix (the default domain content)
+func (r *RepoManagerReconciler) domainContentPlugins(ctx context.Context, pulp *pulpv1.Pulp) []controllers.IngressPlugin {
+	if !controllers.DomainEnabled(ctx, r.Client, pulp) {
+		return nil
+	}
+	domainList := &pulpv1.PulpDomainList{}
+	if err := r.List(ctx, domainList, client.InNamespace(pulp.Namespace)); err != nil {
+		return nil
+	}
+	// sorted, so that the paths are kept in the same order
+	slices.SortFunc(domainList.Items, func(a, b pulpv1.PulpDomain) int { return strings.Compare(a.Spec.Name, b.Spec.Name) })
+
+	contentPathPrefix := controllers.GetContentPathPrefix(ctx, r.Client, pulp)
+	plugins := []controllers.IngressPlugin{}
+	for _, domain := range domainList.Items {
+		contentPath := controllers.GetDomainContentPath(ctx, r.Client, pulp, domain.Spec.Name)
+		if domain.Spec.DeploymentName != pulp.Name || strings.HasPrefix(contentPath, contentPathPrefix) {
+			continue
+		}
+		plugins = append(plugins, controllers.IngressPlugin{
+			Name:        pulp.Name + "-content-" + strings.ToLower(strings.ReplaceAll(domain.Spec.Name, "_", "-")),
+			Path:        contentPath,
+			TargetPort:  "content-24816",
+			ServiceName: settings.ContentService(pulp.Name),
+		})
+	}
+	return plugins
+}
+
 // IngressObj represents the k8s "Ingress" resource
 type IngressObj struct {
 	Ingresser
@@ -179,6 +238,11 @@ func (r *RepoManagerReconciler) initIngress(resources controllers.FunctionResour
 		return &IngressObj{IngressNginx{}}, nil
 	}
 
+	// if the ingressclass provided has haproxy as controller set the IngressObj as IngressHAProxy
+	if controllers.IngressController(resources.Pulp) == controllers.HAProxyIngressController {
+		return &IngressObj{IngressHAProxy{}}, nil
+	}
+
 	// if this is an ocp cluster and ingressclass provided is the ocp default set the IngressObj as IngressOCP
 	if isOpenShift, _ := controllers.IsOpenShift(); isOpenShift && resources.Pulp.Spec.IngressClassName == controllers.DefaultOCPIngressClass {
 		return &IngressObj{pulp_ocp.IngressOCP{}}, nil
@@ -263,17 +327,22 @@ func (i IngressNginx) Deploy(resources controllers.FunctionResources, plugins []
 	annotation["nginx.ingress.kubernetes.io/proxy-read-timeout"] = nginxProxyReadTimeout
 	annotation["nginx.ingress.kubernetes.io/proxy-connect-timeout"] = nginxProxyConnectTimeout
 	annotation["nginx.ingress.kubernetes.io/proxy-send-timeout"] = nginxProxySendTimeout
+
+	// with internal TLS the backends are verified against the CA from the services certificate Secret
+	if controllers.InternalTLSEnabled(pulp) {
+		annotation["nginx.ingress.kubernetes.io/backend-protocol"] = "HTTPS"
+		annotation["nginx.ingress.kubernetes.io/proxy-ssl-secret"] = pulp.Namespace + "/" + settings.InternalTLSSecret(pulp.Name)
+		annotation["nginx.ingress.kubernetes.io/proxy-ssl-verify"] = "on"
+		annotation["nginx.ingress.kubernetes.io/proxy-ssl-server-name"] = "on"
+		annotation["nginx.ingress.kubernetes.io/proxy-ssl-name"] = settings.ApiService(pulp.Name) + "." + pulp.Namespace + ".svc"
+	}
 	for key, val := range pulp.Spec.IngressAnnotations {
 		annotation[key] = val
 	}
 
 	ingress.ObjectMeta.Annotations = annotation
 	ingress.Spec.IngressClassName = &pulp.Spec.IngressClassName
-	ingress.Spec.Rules[0].IngressRuleValue = netv1.IngressRuleValue{
-		HTTP: &netv1.HTTPIngressRuleValue{
-			Paths: paths,
-		},
-	}
+	ingress.Spec.Rules = controllers.IngressRules(controllers.IngressHosts(pulp), paths, controllers.ContentPaths(pulp, paths))
 
 	return ingress, nil
 }
@@ -288,3 +357,102 @@ func (i IngressOthers) Deploy(resources controllers.FunctionResources, plugins [
 	}
 	return ingress, nil
 }
+
+// proxyTimeouts are the nginx_proxy_*_timeout fields (with their default values)
+type proxyTimeouts struct {
+	read    string
+	send    string
+	connect string
+}
+
+// ingressProxyTimeouts returns the timeouts used to configure the ingress controllers
+func ingressProxyTimeouts(pulp *pulpv1.Pulp) proxyTimeouts {
+	timeouts := proxyTimeouts{read: "120s", send: "120s", connect: "120s"}
+	if len(pulp.Spec.NginxProxyReadTimeout) > 0 {
+		timeouts.read = pulp.Spec.NginxProxyReadTimeout
+	}
+	if len(pulp.Spec.NginxProxySendTimeout) > 0 {
+		timeouts.send = pulp.Spec.NginxProxySendTimeout
+	}
+	if len(pulp.Spec.NginxProxyConnectTimeout) > 0 {
+		timeouts.connect = pulp.Spec.NginxProxyConnectTimeout
+	}
+	return timeouts
+}
+
+// nginxTimeUnits are the units accepted by nginx in the time intervals
+var nginxTimeUnits = map[string]time.Duration{
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+	"M":  30 * 24 * time.Hour,
+	"y":  365 * 24 * time.Hour,
+}
+
+// nginxDuration parses a time interval in the nginx format (for example, 120, 90s, 1d or 1h 30m).
+// Values without a unit are in seconds.
+func nginxDuration(value string) (time.Duration, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), " ", "")
+	if len(value) == 0 {
+		return 0, fmt.Errorf("empty time interval")
+	}
+	duration := time.Duration(0)
+	for len(value) > 0 {
+		digits := len(value) - len(strings.TrimLeft(value, "0123456789"))
+		if digits == 0 {
+			return 0, fmt.Errorf("invalid time interval %q", value)
+		}
+		number, err := strconv.ParseInt(value[:digits], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		value = value[digits:]
+
+		// values without a unit are only accepted as the last (or single) component
+		end := strings.IndexAny(value, "0123456789")
+		if end < 0 {
+			end = len(value)
+		}
+		unit := value[:end]
+		value = value[end:]
+		if len(unit) == 0 {
+			unit = "s"
+		}
+		multiplier, found := nginxTimeUnits[unit]
+		if !found {
+			return 0, fmt.Errorf("invalid time unit %q", unit)
+		}
+		duration += time.Duration(number) * multiplier
+	}
+	return duration, nil
+}
+
+// ingressProxyBodySize returns the nginx_proxy_body_size in bytes.
+// The field uses the nginx size format (for example, 512k, 10m or 1g) and 0 (the default)
+// disables the limit.
+func ingressProxyBodySize(pulp *pulpv1.Pulp) int64 {
+	size := strings.ToLower(strings.TrimSpace(pulp.Spec.NginxProxyBodySize))
+	if len(size) == 0 {
+		return 0
+	}
+	multiplier := int64(1)
+	switch size[len(size)-1] {
+	case 'k':
+		multiplier = 1 << 10
+	case 'm':
+		multiplier = 1 << 20
+	case 'g':
+		multiplier = 1 << 30
+	}
+	if multiplier > 1 {
+		size = size[:len(size)-1]
+	}
+	value, err := strconv.ParseInt(size, 10, 64)
+	if err != nil || value < 0 {
+		return 0
+	}
+	return value * multiplier
+}
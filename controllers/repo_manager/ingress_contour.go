@@ -0,0 +1,86 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/settings"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var contourHTTPProxyGVK = schema.GroupVersionKind{Group: "projectcontour.io", Version: "v1", Kind: "HTTPProxy"}
+
+// contourObjects returns an HTTPProxy for each host (ingress_host, host_aliases and content_host).
+// Contour does not provide a request body size limit nor a connect timeout per route, so only
+// nginx_proxy_read_timeout is used (as the route response timeout).
+func contourObjects(resources controllers.FunctionResources, plugins []controllers.IngressPlugin) []unstructuredObjects {
+	pulp := resources.Pulp
+	timeouts := ingressProxyTimeouts(pulp)
+
+	var httpProxies []*unstructured.Unstructured
+	for i, host := range controllers.IngressHosts(pulp) {
+		routes := []any{}
+		for _, plugin := range plugins {
+			if host.ContentOnly && plugin.ServiceName != settings.ContentService(pulp.Name) {
+				continue
+			}
+			service := map[string]any{
+				"name": plugin.ServiceName,
+				"port": servicePortNumber(plugin.TargetPort),
+			}
+			// with internal TLS the backends are verified against the CA from the services certificate Secret
+			if controllers.InternalTLSEnabled(pulp) {
+				service["protocol"] = "tls"
+				service["validation"] = map[string]any{
+					"caSecret":    settings.InternalTLSSecret(pulp.Name),
+					"subjectName": plugin.ServiceName + "." + pulp.Namespace + ".svc",
+				}
+			}
+			route := map[string]any{
+				"conditions":    []any{map[string]any{"prefix": plugin.Path}},
+				"services":      []any{service},
+				"timeoutPolicy": map[string]any{"response": timeouts.read},
+			}
+			if len(plugin.Rewrite) > 0 {
+				route["pathRewritePolicy"] = map[string]any{
+					"replacePrefix": []any{map[string]any{"prefix": plugin.Path, "replacement": plugin.Rewrite}},
+				}
+			}
+			routes = append(routes, route)
+		}
+
+		virtualHost := map[string]any{"fqdn": host.Host}
+		if len(host.TLSSecret) > 0 {
+			virtualHost["tls"] = map[string]any{"secretName": host.TLSSecret}
+		}
+		spec := map[string]any{
+			"virtualhost": virtualHost,
+			"routes":      routes,
+		}
+		if len(pulp.Spec.IngressClassName) > 0 {
+			spec["ingressClassName"] = pulp.Spec.IngressClassName
+		}
+		httpProxy := ingressControllerObject(resources, contourHTTPProxyGVK, ingressHostObjectName(pulp.Name, i, host), spec)
+		if len(pulp.Spec.IngressAnnotations) > 0 {
+			httpProxy.SetAnnotations(pulp.Spec.IngressAnnotations)
+		}
+		httpProxies = append(httpProxies, httpProxy)
+	}
+
+	return []unstructuredObjects{{gvk: contourHTTPProxyGVK, objects: httpProxies}}
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/settings"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// contourTestProxies returns the HTTPProxies (by name) rendered for pulp
+func contourTestProxies(t *testing.T, pulp *pulpv1.Pulp) map[string]*unstructured.Unstructured {
+	r := newTestReconciler(pulp)
+	resources := controllers.FunctionResources{Client: r.Client, Pulp: pulp, Scheme: r.Scheme, Logger: logr.Discard()}
+	groups := contourObjects(resources, ingressTestPlugins(pulp))
+	if len(groups) != 1 || groups[0].gvk != contourHTTPProxyGVK {
+		t.Fatalf("expected only HTTPProxies, got %v", groups)
+	}
+	proxies := map[string]*unstructured.Unstructured{}
+	for _, obj := range groups[0].objects {
+		if obj.GroupVersionKind() != contourHTTPProxyGVK {
+			t.Errorf("expected %v to be an HTTPProxy, got %v", obj.GetName(), obj.GroupVersionKind())
+		}
+		if obj.GetNamespace() != "pulp" || len(obj.GetOwnerReferences()) != 1 {
+			t.Errorf("expected %v to be owned by the Pulp CR", obj.GetName())
+		}
+		proxies[obj.GetName()] = obj
+	}
+	return proxies
+}
+
+func TestContourObjects(t *testing.T) {
+	api, content := settings.ApiService("test"), settings.ContentService("test")
+	route := func(prefix, service string, port int64, timeout string) map[string]any {
+		return map[string]any{
+			"conditions":    []any{map[string]any{"prefix": prefix}},
+			"services":      []any{map[string]any{"name": service, "port": port}},
+			"timeoutPolicy": map[string]any{"response": timeout},
+		}
+	}
+	withRewrite := func(route map[string]any, prefix, replacement string) map[string]any {
+		route["pathRewritePolicy"] = map[string]any{
+			"replacePrefix": []any{map[string]any{"prefix": prefix, "replacement": replacement}},
+		}
+		return route
+	}
+	withTLS := func(route map[string]any) map[string]any {
+		service := route["services"].([]any)[0].(map[string]any)
+		service["protocol"] = "tls"
+		service["validation"] = map[string]any{
+			"caSecret":    settings.InternalTLSSecret("test"),
+			"subjectName": service["name"].(string) + ".pulp.svc",
+		}
+		return route
+	}
+
+	tests := []struct {
+		name        string
+		modify      func(*pulpv1.Pulp)
+		proxy       string
+		annotations map[string]string
+		spec        map[string]any
+	}{
+		{
+			name:  "defaults",
+			proxy: "test",
+			spec: map[string]any{
+				"virtualhost": map[string]any{"fqdn": "pulp.example.com", "tls": map[string]any{"secretName": "pulp-tls"}},
+				"routes": []any{
+					route("/pulp/content/", content, 24816, "120s"),
+					route("/pulp/api/v3/", api, 24817, "120s"),
+					withRewrite(route("/pulp_ansible/galaxy/", api, 24817, "120s"), "/pulp_ansible/galaxy/", "/api/galaxy/"),
+				},
+			},
+		},
+		{
+			name: "ingress class, annotations and read timeout",
+			modify: func(pulp *pulpv1.Pulp) {
+				pulp.Spec.IngressClassName = "contour"
+				pulp.Spec.IngressAnnotations = map[string]string{"projectcontour.io/upstream-protocol.tls": "https"}
+				pulp.Spec.NginxProxyReadTimeout = "300s"
+				pulp.Spec.IngressTLSSecret = ""
+			},
+			proxy:       "test",
+			annotations: map[string]string{"projectcontour.io/upstream-protocol.tls": "https"},
+			spec: map[string]any{
+				"ingressClassName": "contour",
+				"virtualhost":      map[string]any{"fqdn": "pulp.example.com"},
+				"routes": []any{
+					route("/pulp/content/", content, 24816, "300s"),
+					route("/pulp/api/v3/", api, 24817, "300s"),
+					withRewrite(route("/pulp_ansible/galaxy/", api, 24817, "300s"), "/pulp_ansible/galaxy/", "/api/galaxy/"),
+				},
+			},
+		},
+		{
+			name: "internal TLS",
+			modify: func(pulp *pulpv1.Pulp) {
+				pulp.Spec.InternalTLS.Enabled = true
+			},
+			proxy: "test",
+			spec: map[string]any{
+				"virtualhost": map[string]any{"fqdn": "pulp.example.com", "tls": map[string]any{"secretName": "pulp-tls"}},
+				"routes": []any{
+					withTLS(route("/pulp/content/", content, 24816, "120s")),
+					withTLS(route("/pulp/api/v3/", api, 24817, "120s")),
+					withTLS(withRewrite(route("/pulp_ansible/galaxy/", api, 24817, "120s"), "/pulp_ansible/galaxy/", "/api/galaxy/")),
+				},
+			},
+		},
+		{
+			name: "host alias",
+			modify: func(pulp *pulpv1.Pulp) {
+				pulp.Spec.HostAliases = []pulpv1.Hostname{{Host: "pulp.example.org", TLSSecret: "alias-tls"}}
+			},
+			proxy: "test-alias-1",
+			spec: map[string]any{
+				"virtualhost": map[string]any{"fqdn": "pulp.example.org", "tls": map[string]any{"secretName": "alias-tls"}},
+				"routes": []any{
+					route("/pulp/content/", content, 24816, "120s"),
+					route("/pulp/api/v3/", api, 24817, "120s"),
+					withRewrite(route("/pulp_ansible/galaxy/", api, 24817, "120s"), "/pulp_ansible/galaxy/", "/api/galaxy/"),
+				},
+			},
+		},
+		{
+			// the content host only exposes the content paths
+			name: "content host",
+			modify: func(pulp *pulpv1.Pulp) {
+				pulp.Spec.ContentHost = &pulpv1.Hostname{Host: "content.example.com"}
+			},
+			proxy: "test-content-host",
+			spec: map[string]any{
+				"virtualhost": map[string]any{"fqdn": "content.example.com", "tls": map[string]any{"secretName": "pulp-tls"}},
+				"routes":      []any{route("/pulp/content/", content, 24816, "120s")},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pulp := settingsTestPulp()
+			pulp.Spec.IngressType = "ingress"
+			pulp.Spec.IngressHost = "pulp.example.com"
+			pulp.Spec.IngressTLSSecret = "pulp-tls"
+			if test.modify != nil {
+				test.modify(pulp)
+			}
+			proxies := contourTestProxies(t, pulp)
+			proxy := proxies[test.proxy]
+			if proxy == nil {
+				t.Fatalf("expected the %v HTTPProxy, got %v", test.proxy, objectNames(proxies))
+			}
+			if !reflect.DeepEqual(proxy.GetAnnotations(), test.annotations) {
+				t.Errorf("expected the annotations %v, got %v", test.annotations, proxy.GetAnnotations())
+			}
+			spec, _, _ := unstructured.NestedMap(proxy.Object, "spec")
+			if !reflect.DeepEqual(spec, test.spec) {
+				t.Errorf("expected the spec\n%v\ngot\n%v", test.spec, spec)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/settings"
+	netv1 "k8s.io/api/networking/v1"
+)
+
+type IngressHAProxy struct{}
+
+// Deploy returns an ingress using the HAProxy Kubernetes Ingress Controller (haproxy.org annotations)
+func (i IngressHAProxy) Deploy(resources controllers.FunctionResources, plugins []controllers.IngressPlugin) (*netv1.Ingress, error) {
+	pulp := resources.Pulp
+
+	ingress, err := controllers.IngressDefaults(resources, plugins)
+	if err != nil {
+		return nil, err
+	}
+
+	annotation := map[string]string{
+		"web": "false",
+	}
+	var paths []netv1.HTTPIngressPath
+	pathType := netv1.PathTypePrefix
+	rewrites := ""
+	for _, plugin := range plugins {
+		// haproxy.org/path-rewrite accepts a rewrite rule per line
+		if len(plugin.Rewrite) > 0 {
+			rewrites += "^" + regexp.QuoteMeta(plugin.Path) + "(.*) " + plugin.Rewrite + "\\1\n"
+		}
+		paths = append(paths, netv1.HTTPIngressPath{
+			Path:     plugin.Path,
+			PathType: &pathType,
+			Backend: netv1.IngressBackend{
+				Service: &netv1.IngressServiceBackend{
+					Name: plugin.ServiceName,
+					Port: netv1.ServiceBackendPort{
+						Name: plugin.TargetPort,
+					},
+				},
+			},
+		})
+	}
+	if len(rewrites) > 0 {
+		annotation["haproxy.org/path-rewrite"] = rewrites
+	}
+
+	timeouts := ingressProxyTimeouts(pulp)
+	annotation["haproxy.org/timeout-connect"] = timeouts.connect
+	annotation["haproxy.org/timeout-server"] = timeouts.read
+
+	// there is no annotation to limit the request body size, so the requests are denied based on their Content-Length
+	if bodySize := ingressProxyBodySize(pulp); bodySize > 0 {
+		annotation["haproxy.org/backend-config-snippet"] = "http-request deny deny_status 413 if { req.hdr_val(content-length) gt " + strconv.FormatInt(bodySize, 10) + " }"
+	}
+
+	// with internal TLS the backends are verified against the CA from the services certificate Secret
+	if controllers.InternalTLSEnabled(pulp) {
+		annotation["haproxy.org/server-ssl"] = "true"
+		annotation["haproxy.org/server-ca"] = pulp.Namespace + "/" + settings.InternalTLSSecret(pulp.Name)
+	}
+	for key, val := range pulp.Spec.IngressAnnotations {
+		annotation[key] = val
+	}
+
+	ingress.ObjectMeta.Annotations = annotation
+	ingress.Spec.Rules = controllers.IngressRules(controllers.IngressHosts(pulp), paths, controllers.ContentPaths(pulp, paths))
+
+	return ingress, nil
+}
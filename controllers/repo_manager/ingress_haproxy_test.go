@@ -0,0 +1,168 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/settings"
+)
+
+// ingressTestPlugins returns the plugin paths exposed in the ingress controllers tests
+func ingressTestPlugins(pulp *pulpv1.Pulp) []controllers.IngressPlugin {
+	api, content := settings.ApiService(pulp.Name), settings.ContentService(pulp.Name)
+	return []controllers.IngressPlugin{
+		{Name: "test-content", Path: "/pulp/content/", ServiceName: content, TargetPort: "content-24816"},
+		{Name: "test-api-v3", Path: "/pulp/api/v3/", ServiceName: api, TargetPort: "api-24817"},
+		{Name: "test-ansible", Path: "/pulp_ansible/galaxy/", ServiceName: api, TargetPort: "api-24817", Rewrite: "/api/galaxy/"},
+	}
+}
+
+func TestIngressHAProxy(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*pulpv1.Pulp)
+		plugins  func(*pulpv1.Pulp) []controllers.IngressPlugin
+		expected map[string]string
+	}{
+		{
+			name:    "defaults",
+			plugins: ingressTestPlugins,
+			expected: map[string]string{
+				"web":                         "false",
+				"haproxy.org/path-rewrite":    `^/pulp_ansible/galaxy/(.*) /api/galaxy/\1` + "\n",
+				"haproxy.org/timeout-connect": "120s",
+				"haproxy.org/timeout-server":  "120s",
+			},
+		},
+		{
+			name: "without rewrites",
+			plugins: func(pulp *pulpv1.Pulp) []controllers.IngressPlugin {
+				return ingressTestPlugins(pulp)[:2]
+			},
+			expected: map[string]string{
+				"web":                         "false",
+				"haproxy.org/timeout-connect": "120s",
+				"haproxy.org/timeout-server":  "120s",
+			},
+		},
+		{
+			name: "rewrite with regex characters",
+			plugins: func(pulp *pulpv1.Pulp) []controllers.IngressPlugin {
+				return []controllers.IngressPlugin{
+					{Name: "test-a", Path: "/pulp/a.b/", ServiceName: settings.ApiService(pulp.Name), TargetPort: "api-24817", Rewrite: "/a/"},
+					{Name: "test-c", Path: "/pulp/c+d/", ServiceName: settings.ApiService(pulp.Name), TargetPort: "api-24817", Rewrite: "/c/"},
+				}
+			},
+			expected: map[string]string{
+				"web":                         "false",
+				"haproxy.org/path-rewrite":    `^/pulp/a\.b/(.*) /a/\1` + "\n" + `^/pulp/c\+d/(.*) /c/\1` + "\n",
+				"haproxy.org/timeout-connect": "120s",
+				"haproxy.org/timeout-server":  "120s",
+			},
+		},
+		{
+			name: "body size and timeouts",
+			modify: func(pulp *pulpv1.Pulp) {
+				pulp.Spec.NginxProxyBodySize = "10m"
+				pulp.Spec.NginxProxyConnectTimeout = "30s"
+				pulp.Spec.NginxProxyReadTimeout = "300s"
+			},
+			plugins: ingressTestPlugins,
+			expected: map[string]string{
+				"web":                                "false",
+				"haproxy.org/path-rewrite":           `^/pulp_ansible/galaxy/(.*) /api/galaxy/\1` + "\n",
+				"haproxy.org/timeout-connect":        "30s",
+				"haproxy.org/timeout-server":         "300s",
+				"haproxy.org/backend-config-snippet": "http-request deny deny_status 413 if { req.hdr_val(content-length) gt 10485760 }",
+			},
+		},
+		{
+			name: "internal TLS",
+			modify: func(pulp *pulpv1.Pulp) {
+				pulp.Spec.InternalTLS.Enabled = true
+			},
+			plugins: ingressTestPlugins,
+			expected: map[string]string{
+				"web":                         "false",
+				"haproxy.org/path-rewrite":    `^/pulp_ansible/galaxy/(.*) /api/galaxy/\1` + "\n",
+				"haproxy.org/timeout-connect": "120s",
+				"haproxy.org/timeout-server":  "120s",
+				"haproxy.org/server-ssl":      "true",
+				// the CA is read from the services certificate Secret, which is also the one
+				// provided by cert-manager (there is no CA Secret managed by the operator then)
+				"haproxy.org/server-ca": "pulp/" + settings.InternalTLSSecret("test"),
+			},
+		},
+		{
+			name: "custom annotations",
+			modify: func(pulp *pulpv1.Pulp) {
+				pulp.Spec.IngressAnnotations = map[string]string{"haproxy.org/timeout-server": "600s", "haproxy.org/rate-limit-requests": "100"}
+			},
+			plugins: ingressTestPlugins,
+			expected: map[string]string{
+				"web":                             "false",
+				"haproxy.org/path-rewrite":        `^/pulp_ansible/galaxy/(.*) /api/galaxy/\1` + "\n",
+				"haproxy.org/timeout-connect":     "120s",
+				"haproxy.org/timeout-server":      "600s",
+				"haproxy.org/rate-limit-requests": "100",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pulp := settingsTestPulp()
+			pulp.Spec.IngressType = "ingress"
+			pulp.Spec.IngressClassName = "haproxy"
+			pulp.Spec.IngressHost = "pulp.example.com"
+			if test.modify != nil {
+				test.modify(pulp)
+			}
+			r := newTestReconciler(pulp)
+			resources := controllers.FunctionResources{Context: context.TODO(), Client: r.Client, Pulp: pulp, Scheme: r.Scheme, Logger: logr.Discard()}
+			plugins := test.plugins(pulp)
+
+			ingress, err := IngressHAProxy{}.Deploy(resources, plugins)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(ingress.Annotations, test.expected) {
+				t.Errorf("expected the annotations %v, got %v", test.expected, ingress.Annotations)
+			}
+
+			// the plugin paths are routed straight to the pulpcore services
+			if len(ingress.Spec.Rules) != 1 || ingress.Spec.Rules[0].Host != "pulp.example.com" {
+				t.Fatalf("expected a rule for pulp.example.com, got %v", ingress.Spec.Rules)
+			}
+			paths := ingress.Spec.Rules[0].HTTP.Paths
+			if len(paths) != len(plugins) {
+				t.Fatalf("expected a path for each plugin, got %v", paths)
+			}
+			for i, plugin := range plugins {
+				backend := paths[i].Backend.Service
+				if paths[i].Path != plugin.Path || backend.Name != plugin.ServiceName || backend.Port.Name != plugin.TargetPort {
+					t.Errorf("expected %v to be routed to %v:%v, got %v", plugin.Path, plugin.ServiceName, plugin.TargetPort, paths[i])
+				}
+			}
+		})
+	}
+}
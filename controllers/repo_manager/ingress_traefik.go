@@ -0,0 +1,147 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"regexp"
+
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/settings"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var (
+	traefikIngressRouteGVK     = schema.GroupVersionKind{Group: "traefik.io", Version: "v1alpha1", Kind: "IngressRoute"}
+	traefikMiddlewareGVK       = schema.GroupVersionKind{Group: "traefik.io", Version: "v1alpha1", Kind: "Middleware"}
+	traefikServersTransportGVK = schema.GroupVersionKind{Group: "traefik.io", Version: "v1alpha1", Kind: "ServersTransport"}
+)
+
+// traefikObjects returns the Traefik resources used to expose pulpcore and its plugins:
+// * a ServersTransport with the timeouts (and the internal TLS CA) used to reach the pulpcore services
+// * a replacePathRegex Middleware for each plugin path that should be rewritten
+// * a buffering Middleware limiting the body size (if nginx_proxy_body_size is defined)
+// * an IngressRoute for each host (ingress_host, host_aliases and content_host)
+func traefikObjects(resources controllers.FunctionResources, plugins []controllers.IngressPlugin) []unstructuredObjects {
+	pulp := resources.Pulp
+	timeouts := ingressProxyTimeouts(pulp)
+
+	transportSpec := map[string]any{
+		"forwardingTimeouts": map[string]any{
+			"dialTimeout":           timeouts.connect,
+			"responseHeaderTimeout": timeouts.read,
+		},
+	}
+	if controllers.InternalTLSEnabled(pulp) {
+		transportSpec["serverName"] = settings.ApiService(pulp.Name) + "." + pulp.Namespace + ".svc"
+		transportSpec["rootCAsSecrets"] = []any{settings.InternalTLSSecret(pulp.Name)}
+	}
+	transportName := pulp.Name + "-transport"
+	transports := []*unstructured.Unstructured{ingressControllerObject(resources, traefikServersTransportGVK, transportName, transportSpec)}
+
+	var middlewares []*unstructured.Unstructured
+	commonMiddlewares := []any{}
+	if bodySize := ingressProxyBodySize(pulp); bodySize > 0 {
+		name := pulp.Name + "-buffering"
+		middlewares = append(middlewares, ingressControllerObject(resources, traefikMiddlewareGVK, name, map[string]any{
+			"buffering": map[string]any{"maxRequestBodyBytes": bodySize},
+		}))
+		commonMiddlewares = append(commonMiddlewares, map[string]any{"name": name})
+	}
+
+	pluginMiddlewares := map[string][]any{}
+	for _, plugin := range plugins {
+		pluginMiddlewares[plugin.Path] = commonMiddlewares
+		if len(plugin.Rewrite) == 0 {
+			continue
+		}
+		name := plugin.Name + "-rewrite"
+		middlewares = append(middlewares, ingressControllerObject(resources, traefikMiddlewareGVK, name, map[string]any{
+			"replacePathRegex": map[string]any{
+				"regex":       "^" + regexp.QuoteMeta(plugin.Path) + "(.*)",
+				"replacement": plugin.Rewrite + "$1",
+			},
+		}))
+		pluginMiddlewares[plugin.Path] = append(append([]any{}, commonMiddlewares...), map[string]any{"name": name})
+	}
+
+	var ingressRoutes []*unstructured.Unstructured
+	for i, host := range controllers.IngressHosts(pulp) {
+		routes := []any{}
+		for _, plugin := range plugins {
+			if host.ContentOnly && plugin.ServiceName != settings.ContentService(pulp.Name) {
+				continue
+			}
+			service := map[string]any{
+				"name":             plugin.ServiceName,
+				"port":             servicePortNumber(plugin.TargetPort),
+				"serversTransport": transportName,
+			}
+			if controllers.InternalTLSEnabled(pulp) {
+				service["scheme"] = "https"
+			}
+			route := map[string]any{
+				"kind":     "Rule",
+				"match":    "Host(`" + host.Host + "`) && PathPrefix(`" + plugin.Path + "`)",
+				"services": []any{service},
+			}
+			if len(pluginMiddlewares[plugin.Path]) > 0 {
+				route["middlewares"] = pluginMiddlewares[plugin.Path]
+			}
+			routes = append(routes, route)
+		}
+		spec := map[string]any{"routes": routes}
+		if len(host.TLSSecret) > 0 {
+			spec["tls"] = map[string]any{"secretName": host.TLSSecret}
+		}
+		ingressRoute := ingressControllerObject(resources, traefikIngressRouteGVK, ingressHostObjectName(pulp.Name, i, host), spec)
+
+		// the IngressClass is matched by Traefik through the (deprecated) ingress.class annotation
+		annotations := map[string]string{}
+		if len(pulp.Spec.IngressClassName) > 0 {
+			annotations["kubernetes.io/ingress.class"] = pulp.Spec.IngressClassName
+		}
+		for key, val := range pulp.Spec.IngressAnnotations {
+			annotations[key] = val
+		}
+		if len(annotations) > 0 {
+			ingressRoute.SetAnnotations(annotations)
+		}
+		ingressRoutes = append(ingressRoutes, ingressRoute)
+	}
+
+	return []unstructuredObjects{
+		{gvk: traefikServersTransportGVK, objects: transports},
+		{gvk: traefikMiddlewareGVK, objects: middlewares},
+		{gvk: traefikIngressRouteGVK, objects: ingressRoutes},
+	}
+}
+
+// ingressControllerObject returns an unstructured object owned by pulp with the spec provided
+func ingressControllerObject(resources controllers.FunctionResources, gvk schema.GroupVersionKind, name string, spec map[string]any) *unstructured.Unstructured {
+	pulp := resources.Pulp
+	obj := &unstructured.Unstructured{Object: map[string]any{"spec": spec}}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetName(name)
+	obj.SetNamespace(pulp.Namespace)
+	obj.SetLabels(settings.CommonLabels(*pulp))
+
+	// Set Pulp instance as the owner and controller
+	ctrl.SetControllerReference(pulp, obj, resources.Scheme)
+	return obj
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/settings"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// traefikTestPulp returns a Pulp CR exposed through Traefik
+func traefikTestPulp() *pulpv1.Pulp {
+	pulp := settingsTestPulp()
+	pulp.Spec.IngressType = "ingress"
+	pulp.Spec.IngressClassName = "traefik"
+	pulp.Spec.IngressHost = "pulp.example.com"
+	pulp.Spec.IngressTLSSecret = "pulp-tls"
+	return pulp
+}
+
+// traefikTestObjects returns the Traefik objects (by kind and name) rendered for pulp
+func traefikTestObjects(t *testing.T, pulp *pulpv1.Pulp) map[string]map[string]*unstructured.Unstructured {
+	r := newTestReconciler(pulp)
+	resources := controllers.FunctionResources{Client: r.Client, Pulp: pulp, Scheme: r.Scheme, Logger: logr.Discard()}
+
+	objects := map[string]map[string]*unstructured.Unstructured{}
+	for _, group := range traefikObjects(resources, ingressTestPlugins(pulp)) {
+		objects[group.gvk.Kind] = map[string]*unstructured.Unstructured{}
+		for _, obj := range group.objects {
+			if obj.GroupVersionKind() != group.gvk {
+				t.Errorf("expected %v to be a %v, got %v", obj.GetName(), group.gvk, obj.GroupVersionKind())
+			}
+			if obj.GetNamespace() != "pulp" || len(obj.GetOwnerReferences()) != 1 {
+				t.Errorf("expected %v to be owned by the Pulp CR", obj.GetName())
+			}
+			objects[group.gvk.Kind][obj.GetName()] = obj
+		}
+	}
+	return objects
+}
+
+// objectNames returns the names of the objects
+func objectNames(objects map[string]*unstructured.Unstructured) []string {
+	names := []string{}
+	for name := range objects {
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestTraefikObjects(t *testing.T) {
+	pulp := traefikTestPulp()
+	pulp.Spec.IngressAnnotations = map[string]string{"traefik.ingress.kubernetes.io/router.priority": "10"}
+	pulp.Spec.NginxProxyReadTimeout = "300s"
+	objects := traefikTestObjects(t, pulp)
+
+	transport := objects["ServersTransport"]["test-transport"]
+	if transport == nil {
+		t.Fatalf("expected the test-transport ServersTransport, got %v", objectNames(objects["ServersTransport"]))
+	}
+	timeouts, _, _ := unstructured.NestedMap(transport.Object, "spec", "forwardingTimeouts")
+	if expected := map[string]any{"dialTimeout": "120s", "responseHeaderTimeout": "300s"}; !reflect.DeepEqual(timeouts, expected) {
+		t.Errorf("expected the timeouts %v, got %v", expected, timeouts)
+	}
+	if _, found, _ := unstructured.NestedString(transport.Object, "spec", "serverName"); found {
+		t.Errorf("unexpected serverName without internal TLS")
+	}
+
+	// only the rewritten plugin paths have a Middleware
+	if names := objectNames(objects["Middleware"]); !reflect.DeepEqual(names, []string{"test-ansible-rewrite"}) {
+		t.Errorf("expected only the test-ansible-rewrite Middleware, got %v", names)
+	}
+	rewrite, _, _ := unstructured.NestedMap(objects["Middleware"]["test-ansible-rewrite"].Object, "spec", "replacePathRegex")
+	if expected := map[string]any{"regex": `^/pulp_ansible/galaxy/(.*)`, "replacement": "/api/galaxy/$1"}; !reflect.DeepEqual(rewrite, expected) {
+		t.Errorf("expected the rewrite %v, got %v", expected, rewrite)
+	}
+
+	route := objects["IngressRoute"]["test"]
+	if route == nil {
+		t.Fatalf("expected the test IngressRoute, got %v", objectNames(objects["IngressRoute"]))
+	}
+	expectedAnnotations := map[string]string{"kubernetes.io/ingress.class": "traefik", "traefik.ingress.kubernetes.io/router.priority": "10"}
+	if !reflect.DeepEqual(route.GetAnnotations(), expectedAnnotations) {
+		t.Errorf("expected the annotations %v, got %v", expectedAnnotations, route.GetAnnotations())
+	}
+	if secret, _, _ := unstructured.NestedString(route.Object, "spec", "tls", "secretName"); secret != "pulp-tls" {
+		t.Errorf("expected the pulp-tls Secret, got %v", secret)
+	}
+	routes, _, _ := unstructured.NestedSlice(route.Object, "spec", "routes")
+	expectedRoutes := []any{
+		map[string]any{
+			"kind":     "Rule",
+			"match":    "Host(`pulp.example.com`) && PathPrefix(`/pulp/content/`)",
+			"services": []any{map[string]any{"name": settings.ContentService("test"), "port": int64(24816), "serversTransport": "test-transport"}},
+		},
+		map[string]any{
+			"kind":     "Rule",
+			"match":    "Host(`pulp.example.com`) && PathPrefix(`/pulp/api/v3/`)",
+			"services": []any{map[string]any{"name": settings.ApiService("test"), "port": int64(24817), "serversTransport": "test-transport"}},
+		},
+		map[string]any{
+			"kind":        "Rule",
+			"match":       "Host(`pulp.example.com`) && PathPrefix(`/pulp_ansible/galaxy/`)",
+			"services":    []any{map[string]any{"name": settings.ApiService("test"), "port": int64(24817), "serversTransport": "test-transport"}},
+			"middlewares": []any{map[string]any{"name": "test-ansible-rewrite"}},
+		},
+	}
+	if !reflect.DeepEqual(routes, expectedRoutes) {
+		t.Errorf("expected the routes %v, got %v", expectedRoutes, routes)
+	}
+}
+
+func TestTraefikObjectsHosts(t *testing.T) {
+	pulp := traefikTestPulp()
+	pulp.Spec.HostAliases = []pulpv1.Hostname{{Host: "pulp.example.org", TLSSecret: "alias-tls"}}
+	pulp.Spec.ContentHost = &pulpv1.Hostname{Host: "content.example.com"}
+	routes := traefikTestObjects(t, pulp)["IngressRoute"]
+
+	tests := []struct {
+		name, host, tlsSecret string
+		routes                int
+	}{
+		{name: "test", host: "pulp.example.com", tlsSecret: "pulp-tls", routes: 3},
+		{name: "test-alias-1", host: "pulp.example.org", tlsSecret: "alias-tls", routes: 3},
+		// the content host only exposes the content paths
+		{name: "test-content-host", host: "content.example.com", tlsSecret: "pulp-tls", routes: 1},
+	}
+	if len(routes) != len(tests) {
+		t.Errorf("expected an IngressRoute for each host, got %v", objectNames(routes))
+	}
+	for _, test := range tests {
+		route := routes[test.name]
+		if route == nil {
+			t.Errorf("expected the %v IngressRoute", test.name)
+			continue
+		}
+		if secret, _, _ := unstructured.NestedString(route.Object, "spec", "tls", "secretName"); secret != test.tlsSecret {
+			t.Errorf("%v: expected the %v Secret, got %v", test.name, test.tlsSecret, secret)
+		}
+		rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "routes")
+		if len(rules) != test.routes {
+			t.Errorf("%v: expected %v routes, got %v", test.name, test.routes, len(rules))
+		}
+		for _, rule := range rules {
+			if match := rule.(map[string]any)["match"].(string); !strings.HasPrefix(match, "Host(`"+test.host+"`)") {
+				t.Errorf("%v: unexpected match %v", test.name, match)
+			}
+		}
+	}
+}
+
+func TestTraefikObjectsBodySizeAndInternalTLS(t *testing.T) {
+	pulp := traefikTestPulp()
+	pulp.Spec.NginxProxyBodySize = "10m"
+	pulp.Spec.InternalTLS.Enabled = true
+	objects := traefikTestObjects(t, pulp)
+
+	buffering, _, _ := unstructured.NestedMap(objects["Middleware"]["test-buffering"].Object, "spec", "buffering")
+	if !reflect.DeepEqual(buffering, map[string]any{"maxRequestBodyBytes": int64(10 << 20)}) {
+		t.Errorf("expected a 10m body size limit, got %v", buffering)
+	}
+
+	transport := objects["ServersTransport"]["test-transport"]
+	if serverName, _, _ := unstructured.NestedString(transport.Object, "spec", "serverName"); serverName != settings.ApiService("test")+".pulp.svc" {
+		t.Errorf("unexpected serverName %v", serverName)
+	}
+	if secrets, _, _ := unstructured.NestedStringSlice(transport.Object, "spec", "rootCAsSecrets"); !reflect.DeepEqual(secrets, []string{settings.InternalTLSSecret("test")}) {
+		t.Errorf("unexpected rootCAsSecrets %v", secrets)
+	}
+
+	routes, _, _ := unstructured.NestedSlice(objects["IngressRoute"]["test"].Object, "spec", "routes")
+	for _, route := range routes {
+		route := route.(map[string]any)
+		middlewares := route["middlewares"].([]any)
+		if middlewares[0].(map[string]any)["name"] != "test-buffering" {
+			t.Errorf("expected the buffering Middleware in all the routes, got %v", middlewares)
+		}
+		if service := route["services"].([]any)[0].(map[string]any); service["scheme"] != "https" {
+			t.Errorf("expected the https scheme with internal TLS, got %v", service)
+		}
+	}
+	if routes[2].(map[string]any)["middlewares"].([]any)[1].(map[string]any)["name"] != "test-ansible-rewrite" {
+		t.Errorf("expected the rewrite Middleware after the buffering one")
+	}
+}
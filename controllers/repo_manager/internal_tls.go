@@ -0,0 +1,302 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	crypt_rand "crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/settings"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	internalCAValidity          = 10 * 365 * 24 * time.Hour
+	internalTLSValidity         = 365 * 24 * time.Hour
+	internalTLSRenewBefore      = 30 * 24 * time.Hour
+	internalTLSReadyCondition   = "Pulp-Internal-TLS-Ready"
+	internalCACommonName        = "pulp-operator internal CA"
+	internalTLSCommonNamePrefix = "pulp-operator "
+)
+
+// internalTLSController provisions the certificate used by pulpcore-api and pulpcore-content
+// to serve HTTPS when it is not issued by cert-manager.
+// The certificate is signed by the CA from internal_tls.ca_secret or by a self-signed CA
+// created by the operator, and it is renewed 30 days before the expiration.
+func (r *RepoManagerReconciler) internalTLSController(ctx context.Context, pulp *pulpv1.Pulp, log logr.Logger) (ctrl.Result, error) {
+	if !controllers.InternalTLSEnabled(pulp) || controllers.InternalTLSCertManager(pulp) {
+		return ctrl.Result{}, nil
+	}
+
+	// CA Secret
+	caSecretName := controllers.InternalTLSCASecret(pulp)
+	caSecret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Name: caSecretName, Namespace: pulp.Namespace}, caSecret)
+	if err != nil && errors.IsNotFound(err) {
+		if len(pulp.Spec.InternalTLS.CASecret) > 0 {
+			log.Error(err, "Secret "+caSecretName+" defined in internal_tls.ca_secret not found")
+			controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, internalTLSReadyCondition, "CASecretNotFound", "Secret "+caSecretName+" not found")
+			return ctrl.Result{RequeueAfter: time.Minute}, nil
+		}
+
+		log.Info("Creating a new internal CA Secret", "Secret.Namespace", pulp.Namespace, "Secret.Name", caSecretName)
+		controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, internalTLSReadyCondition, "CreatingCA", "Creating "+caSecretName+" Secret")
+		certPEM, keyPEM, err := generateInternalCA()
+		if err != nil {
+			log.Error(err, "Failed to generate the internal CA")
+			return ctrl.Result{}, err
+		}
+		caSecret = r.internalTLSSecretObject(pulp, caSecretName, map[string][]byte{
+			corev1.TLSCertKey:       certPEM,
+			corev1.TLSPrivateKeyKey: keyPEM,
+		})
+		if err := r.Create(ctx, caSecret); err != nil {
+			log.Error(err, "Failed to create the internal CA Secret")
+			controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, internalTLSReadyCondition, "ErrorCreatingCA", "Failed to create "+caSecretName+" Secret: "+err.Error())
+			r.recorder.Event(pulp, corev1.EventTypeWarning, "Failed", "Failed to create internal CA Secret")
+			return ctrl.Result{}, err
+		}
+		r.recorder.Event(pulp, corev1.EventTypeNormal, "Created", "Internal CA Secret created")
+		return ctrl.Result{Requeue: true}, nil
+	} else if err != nil {
+		log.Error(err, "Failed to get "+caSecretName+" Secret")
+		return ctrl.Result{}, err
+	}
+
+	caCert, caKey, err := parseInternalCA(caSecret)
+	if err != nil {
+		log.Error(err, "Invalid CA in "+caSecretName+" Secret")
+		controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, internalTLSReadyCondition, "InvalidCA", "Invalid CA in "+caSecretName+" Secret: "+err.Error())
+		return ctrl.Result{}, err
+	}
+
+	// services certificate Secret
+	tlsSecretName := settings.InternalTLSSecret(pulp.Name)
+	dnsNames := controllers.InternalTLSDNSNames(pulp)
+	tlsSecret := &corev1.Secret{}
+	err = r.Get(ctx, types.NamespacedName{Name: tlsSecretName, Namespace: pulp.Namespace}, tlsSecret)
+	if err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "Failed to get "+tlsSecretName+" Secret")
+		return ctrl.Result{}, err
+	}
+	notFound := errors.IsNotFound(err)
+	if !notFound && !internalTLSNeedsRenewal(tlsSecret, caSecret.Data[corev1.TLSCertKey], caCert, dnsNames) {
+		if v1.IsStatusConditionFalse(pulp.Status.Conditions, internalTLSReadyCondition) {
+			controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionTrue, internalTLSReadyCondition, "InternalTLSTasksFinished", "All internal TLS tasks ran successfully")
+		}
+		return ctrl.Result{}, nil
+	}
+
+	certPEM, keyPEM, err := issueInternalCertificate(caCert, caKey, pulp.Name, dnsNames)
+	if err != nil {
+		log.Error(err, "Failed to issue the internal TLS certificate")
+		return ctrl.Result{}, err
+	}
+	data := map[string][]byte{
+		corev1.TLSCertKey:       certPEM,
+		corev1.TLSPrivateKeyKey: keyPEM,
+		"ca.crt":                caSecret.Data[corev1.TLSCertKey],
+	}
+
+	if notFound {
+		log.Info("Creating a new internal TLS Secret", "Secret.Namespace", pulp.Namespace, "Secret.Name", tlsSecretName)
+		controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, internalTLSReadyCondition, "CreatingCertificate", "Creating "+tlsSecretName+" Secret")
+		if err := r.Create(ctx, r.internalTLSSecretObject(pulp, tlsSecretName, data)); err != nil {
+			log.Error(err, "Failed to create the internal TLS Secret")
+			controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, internalTLSReadyCondition, "ErrorCreatingCertificate", "Failed to create "+tlsSecretName+" Secret: "+err.Error())
+			r.recorder.Event(pulp, corev1.EventTypeWarning, "Failed", "Failed to create internal TLS Secret")
+			return ctrl.Result{}, err
+		}
+		r.recorder.Event(pulp, corev1.EventTypeNormal, "Created", "Internal TLS Secret created")
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	log.Info("Renewing the internal TLS certificate", "Secret.Namespace", pulp.Namespace, "Secret.Name", tlsSecretName)
+	controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, internalTLSReadyCondition, "RenewingCertificate", "Renewing "+tlsSecretName+" certificate")
+	tlsSecret.Data = data
+	if err := r.Update(ctx, tlsSecret); err != nil {
+		log.Error(err, "Failed to renew the internal TLS certificate")
+		controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, internalTLSReadyCondition, "ErrorRenewingCertificate", "Failed to renew "+tlsSecretName+" certificate: "+err.Error())
+		r.recorder.Event(pulp, corev1.EventTypeWarning, "Failed", "Failed to renew internal TLS certificate")
+		return ctrl.Result{}, err
+	}
+	r.recorder.Event(pulp, corev1.EventTypeNormal, "Updated", "Internal TLS certificate renewed")
+	return ctrl.Result{Requeue: true}, nil
+}
+
+// internalTLSSecretObject returns a kubernetes.io/tls Secret owned by the Pulp CR
+func (r *RepoManagerReconciler) internalTLSSecretObject(pulp *pulpv1.Pulp, name string, data map[string][]byte) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: pulp.Namespace,
+			Labels:    settings.CommonLabels(*pulp),
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: data,
+	}
+	ctrl.SetControllerReference(pulp, secret, r.Scheme)
+	return secret
+}
+
+// internalTLSNeedsRenewal returns true if the certificate from the Secret is about to expire,
+// was not signed by the current CA or does not have the expected hostnames
+func internalTLSNeedsRenewal(secret *corev1.Secret, caPEM []byte, caCert *x509.Certificate, dnsNames []string) bool {
+	if !bytes.Equal(secret.Data["ca.crt"], caPEM) || len(secret.Data[corev1.TLSPrivateKeyKey]) == 0 {
+		return true
+	}
+	block, _ := pem.Decode(secret.Data[corev1.TLSCertKey])
+	if block == nil {
+		return true
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return true
+	}
+	if time.Until(cert.NotAfter) < internalTLSRenewBefore || cert.CheckSignatureFrom(caCert) != nil {
+		return true
+	}
+	certDNSNames := slices.Clone(cert.DNSNames)
+	expectedDNSNames := slices.Clone(dnsNames)
+	slices.Sort(certDNSNames)
+	slices.Sort(expectedDNSNames)
+	return !slices.Equal(certDNSNames, expectedDNSNames)
+}
+
+// generateInternalCA returns the certificate and key (PEM encoded) of a new self-signed CA
+func generateInternalCA() ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), crypt_rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serialNumber, err := newSerialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: internalCACommonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(internalCAValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	certDER, err := x509.CreateCertificate(crypt_rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), nil
+}
+
+// issueInternalCertificate returns the certificate and key (PEM encoded) for the given hostnames
+// signed by the CA
+func issueInternalCertificate(caCert *x509.Certificate, caKey crypto.Signer, pulpName string, dnsNames []string) ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), crypt_rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serialNumber, err := newSerialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+	notAfter := time.Now().Add(internalTLSValidity)
+	if notAfter.After(caCert.NotAfter) {
+		notAfter = caCert.NotAfter
+	}
+	template := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      pkix.Name{CommonName: internalTLSCommonNamePrefix + pulpName},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	certDER, err := x509.CreateCertificate(crypt_rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), nil
+}
+
+// parseInternalCA returns the CA certificate and key from the tls.crt and tls.key Secret keys
+func parseInternalCA(secret *corev1.Secret) (*x509.Certificate, crypto.Signer, error) {
+	certBlock, _ := pem.Decode(secret.Data[corev1.TLSCertKey])
+	if certBlock == nil {
+		return nil, nil, fmt.Errorf("no PEM certificate found in %v key", corev1.TLSCertKey)
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !cert.IsCA {
+		return nil, nil, fmt.Errorf("the certificate is not a CA")
+	}
+
+	keyBlock, _ := pem.Decode(secret.Data[corev1.TLSPrivateKeyKey])
+	if keyBlock == nil {
+		return nil, nil, fmt.Errorf("no PEM key found in %v key", corev1.TLSPrivateKeyKey)
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(keyBlock.Bytes); err == nil {
+		if signer, ok := key.(crypto.Signer); ok {
+			return cert, signer, nil
+		}
+		return nil, nil, fmt.Errorf("unsupported private key type")
+	}
+	if key, err := x509.ParseECPrivateKey(keyBlock.Bytes); err == nil {
+		return cert, key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(keyBlock.Bytes); err == nil {
+		return cert, key, nil
+	}
+	return nil, nil, fmt.Errorf("unsupported private key format")
+}
+
+// newSerialNumber returns a random 128-bit certificate serial number
+func newSerialNumber() (*big.Int, error) {
+	return crypt_rand.Int(crypt_rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
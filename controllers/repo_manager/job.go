@@ -118,6 +118,11 @@ func pulpcoreVolumes(pulp *pulpv1.Pulp, adminSecretName string) []corev1.Volume
 		},
 	}
 
+	if controllers.OIDCEnabled(pulp) {
+		volumes = append(volumes, controllers.OIDCModuleVolume(pulp))
+	}
+	volumes = append(volumes, controllers.SecretsStoreVolumes(pulp)...)
+
 	if len(adminSecretName) > 0 {
 		adminSecret := corev1.Volume{
 			Name: adminSecretName,
@@ -139,7 +144,7 @@ func pulpcoreVolumes(pulp *pulpv1.Pulp, adminSecretName string) []corev1.Volume
 
 // pulpcoreVolumeMounts defines the list of volumeMounts from pulpcore containers
 func pulpcoreVolumeMounts(pulp *pulpv1.Pulp) []corev1.VolumeMount {
-	return []corev1.VolumeMount{
+	volumeMounts := []corev1.VolumeMount{
 		{
 			Name:      pulp.Name + "-server",
 			MountPath: "/etc/pulp/settings.py",
@@ -153,6 +158,11 @@ func pulpcoreVolumeMounts(pulp *pulpv1.Pulp) []corev1.VolumeMount {
 			ReadOnly:  true,
 		},
 	}
+	if controllers.OIDCEnabled(pulp) {
+		volumeMounts = append(volumeMounts, controllers.OIDCModuleVolumeMount())
+	}
+	volumeMounts = append(volumeMounts, controllers.SecretsStoreVolumeMounts(pulp)...)
+	return volumeMounts
 }
 
 // resetAdminPasswordContainer defines the container spec for the reset admin password job
@@ -398,7 +408,7 @@ func signingScriptContainer(ctx context.Context, pulp *pulpv1.Pulp, scriptsSecre
 	// resource requirements
 	resources := pulp.Spec.SigningJob.PulpContainer.ResourceRequirements
 
-	fingerprint, _ := controllers.GetSigningKeyFingerprint(ctx, r.Client, pulp.Spec.SigningSecret, pulp.Namespace)
+	fingerprint, _ := controllers.SigningKeyFingerprint(ctx, r.Client, pulp)
 
 	// env vars
 	envVars := controllers.GetPostgresEnvVars(*pulp)
@@ -478,15 +488,7 @@ func signingScriptJobVolumes(pulp *pulpv1.Pulp, secret corev1.Secret) []corev1.V
 		},
 	}
 
-	gpgKeysVolume := corev1.Volume{
-		Name: "gpg-keys",
-		VolumeSource: corev1.VolumeSource{
-			Secret: &corev1.SecretVolumeSource{
-				SecretName: pulp.Spec.SigningSecret,
-				Items:      []corev1.KeyToPath{{Key: "signing_service.gpg", Path: "signing_service.gpg"}},
-			},
-		},
-	}
+	gpgKeysVolume := controllers.SigningKeyVolume(pulp)
 
 	tmp := corev1.Volume{
 		Name: "ephemeral-gpg",
@@ -17,44 +17,52 @@ limitations under the License.
 package repo_manager
 
 import (
-	"fmt"
 	"regexp"
 	"strings"
 
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
 	"github.com/pulp/pulp-operator/controllers"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
+	"github.com/pulp/pulp-operator/controllers/pysettings"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
 
+// ldapHeader imports the modules used by the django-auth-ldap settings
+const ldapHeader = `
+#### LDAP SETTINGS ####
+import ldap
+from django_auth_ldap.config import *
+`
+
+// ldapBackends are the AUTHENTICATION_BACKENDS used with LDAP
+var ldapBackends = []string{
+	"django_auth_ldap.backend.LDAPBackend",
+	"django.contrib.auth.backends.ModelBackend",
+	"pulpcore.backends.ObjectRolePermissionBackend",
+}
+
 // ldapSettings reads the contents from ldapSecret, formats it, and append to pulpSettings
-func ldapSettings(resources controllers.FunctionResources, pulpSettings *string) {
+func ldapSettings(resources controllers.FunctionResources, pulpSettings *pysettings.Settings) {
 
 	pulp := resources.Pulp
 	log := controllers.CustomZapLogger()
+	if controllers.LDAPStructured(pulp) {
+		ldapStructuredSettings(resources, pulpSettings)
+		return
+	}
 	if len(pulp.Spec.LDAP.Config) == 0 {
 		return
 	}
 
-	settings := `
-#### LDAP SETTINGS ####
-import ldap
-from django_auth_ldap.config import *
-
-AUTHENTICATION_BACKENDS = [
-  "django_auth_ldap.backend.LDAPBackend",
-  "django.contrib.auth.backends.ModelBackend",
-  "pulpcore.backends.ObjectRolePermissionBackend",
-]
-`
-
 	secret := &corev1.Secret{}
 	if err := resources.Get(resources.Context, types.NamespacedName{Name: pulp.Spec.LDAP.Config, Namespace: pulp.Namespace}, secret); err != nil {
 		log.Error("Error trying to read " + pulp.Spec.LDAP.Config + " Secret")
 		return
 	}
 
+	pulpSettings.Code(ldapHeader)
+	pulpSettings.Set("AUTHENTICATION_BACKENDS", ldapBackends)
+
 	// regex to validate the keys from Secret
 	r, _ := regexp.Compile("(?i)^AUTH_LDAP_.*$")
 
@@ -62,7 +70,8 @@ AUTHENTICATION_BACKENDS = [
 	// when we iterate through golang maps the output are not ordered and it can
 	// mislead the controller to think the data are different
 	for _, k := range sortKeys(secret.Data) {
-		if !r.MatchString(k) { // ignore secret key if it is not in LDAP_AUTH_*
+		key := strings.ToUpper(k)
+		if !r.MatchString(k) || !pysettings.IsIdentifier(key) { // ignore secret key if it is not in LDAP_AUTH_*
 			log.Warn("The key \"" + k + "\" from Secret \"" + pulp.Spec.LDAP.Config + "\" is invalid, ignoring it ...")
 			continue
 		}
@@ -71,18 +80,124 @@ AUTHENTICATION_BACKENDS = [
 		// Kubernetes' Secret data is a map[string]string field. Because of that, I could not find a
 		// generic way to handle each AUTH_LDAP_ configuration, since they will all be stored as
 		// a string in the Secret.
-		switch strings.ToUpper(k) {
-		// these fields should not be defined as strings (don't add double quotes in it)
+		switch key {
+		// these fields are python expressions (LDAPSearch, GroupOfNamesType, dicts, etc.)
 		case "AUTH_LDAP_CACHE_TIMEOUT", "AUTH_LDAP_GROUP_SEARCH", "AUTH_LDAP_USER_SEARCH", "AUTH_LDAP_GROUP_TYPE", "AUTH_LDAP_GLOBAL_OPTIONS", "AUTH_LDAP_CONNECTION_OPTIONS":
-			settings += fmt.Sprintf("%v = %v\n", strings.ToUpper(k), configValue)
-		//these fields are boolean, we need to capitalize them
+			pulpSettings.Set(key, pysettings.Expr(configValue))
+		// these fields are boolean
 		case "AUTH_LDAP_MIRROR_GROUPS", "AUTH_LDAP_ALWAYS_UPDATE_USER", "AUTH_LDAP_FIND_GROUP_PERMS", "AUTH_LDAP_START_TLS":
-			settings += fmt.Sprintf("%v = %v\n", strings.ToUpper(k), cases.Title(language.English, cases.Compact).String(configValue))
+			pulpSettings.Set(key, strings.EqualFold(strings.TrimSpace(configValue), "true"))
 		default:
-			settings += fmt.Sprintf("%v = \"%v\"\n", strings.ToUpper(k), configValue)
+			pulpSettings.Set(key, configValue)
+		}
+	}
+
+	if len(controllers.SecretsStoreClass(pulp, controllers.SecretsStoreLDAP)) > 0 {
+		pulpSettings.Set("AUTH_LDAP_BIND_PASSWORD", ldapBindPasswordFile())
+	}
+
+	pulpSettings.Code("#### END OF LDAP SETTINGS ####\n")
+}
+
+// ldapBindPasswordFile returns the expression that reads the bind password from the
+// file mounted from the ldap_bind_password_secret SecretProviderClass
+func ldapBindPasswordFile() pysettings.Expr {
+	return pysettings.FileContent(controllers.SecretsStoreFile(controllers.SecretsStoreLDAP, "bind_password"))
+}
+
+// ldapScopes maps the ldap search scopes to the python-ldap constants
+var ldapScopes = map[string]string{
+	"base":     "ldap.SCOPE_BASE",
+	"onelevel": "ldap.SCOPE_ONELEVEL",
+	"subtree":  "ldap.SCOPE_SUBTREE",
+}
+
+// ldapStructuredSettings generates the django-auth-ldap settings from the ldap fields
+func ldapStructuredSettings(resources controllers.FunctionResources, pulpSettings *pysettings.Settings) {
+	pulp := resources.Pulp
+	ldapSpec := pulp.Spec.LDAP
+
+	var bindPassword any
+	if len(controllers.SecretsStoreClass(pulp, controllers.SecretsStoreLDAP)) > 0 {
+		bindPassword = ldapBindPasswordFile()
+	} else if len(ldapSpec.BindPasswordSecret) > 0 {
+		password, err := controllers.RetrieveSecretData(resources.Context, ldapSpec.BindPasswordSecret, pulp.Namespace, true, resources.Client, "bind_password")
+		if err != nil {
+			resources.Logger.Error(err, "Secret Not Found!", "Secret.Namespace", pulp.Namespace, "Secret.Name", ldapSpec.BindPasswordSecret)
+			return
 		}
+		bindPassword = password["bind_password"]
+	}
+
+	pulpSettings.Code(ldapHeader)
+	pulpSettings.Set("AUTHENTICATION_BACKENDS", ldapBackends)
+	pulpSettings.Set("AUTH_LDAP_SERVER_URI", ldapSpec.ServerURI)
+	if len(ldapSpec.BindDN) > 0 {
+		pulpSettings.Set("AUTH_LDAP_BIND_DN", ldapSpec.BindDN)
+	}
+	if bindPassword != nil {
+		pulpSettings.Set("AUTH_LDAP_BIND_PASSWORD", bindPassword)
+	}
+	if len(ldapSpec.UserSearch.BaseDN) > 0 {
+		pulpSettings.Set("AUTH_LDAP_USER_SEARCH", ldapSearch(ldapSpec.UserSearch, "(uid=%(user)s)"))
+	}
+	if len(ldapSpec.GroupSearch.BaseDN) > 0 {
+		pulpSettings.Set("AUTH_LDAP_GROUP_SEARCH", ldapSearch(ldapSpec.GroupSearch, "(objectClass=*)"))
+		pulpSettings.Set("AUTH_LDAP_GROUP_TYPE", pysettings.Call{Func: ldapGroupType(pulp), Args: []any{pysettings.Kwarg{Name: "name_attr", Value: ldapGroupNameAttr(pulp)}}})
+	}
+	if len(ldapSpec.UserAttrMap) > 0 {
+		pulpSettings.Set("AUTH_LDAP_USER_ATTR_MAP", ldapSpec.UserAttrMap)
+	}
+	if ldapSpec.MirrorGroups {
+		pulpSettings.Set("AUTH_LDAP_MIRROR_GROUPS", true)
+	}
+	if ldapSpec.StartTLS {
+		pulpSettings.Set("AUTH_LDAP_START_TLS", true)
+	}
+	if len(ldapSpec.CA) > 0 {
+		// OPT_X_TLS_NEWCTX needs to be the last option to create a new TLS context with the CA file
+		pulpSettings.Set("AUTH_LDAP_CONNECTION_OPTIONS", pysettings.Dict{
+			{Key: pysettings.Expr("ldap.OPT_X_TLS_CACERTFILE"), Value: controllers.LDAPCAPath},
+			{Key: pysettings.Expr("ldap.OPT_X_TLS_NEWCTX"), Value: 0},
+		})
+	}
+
+	pulpSettings.Code("#### END OF LDAP SETTINGS ####\n")
+}
+
+// ldapSearch returns the python LDAPSearch definition of search
+func ldapSearch(search pulpv1.LDAPSearch, defaultFilter string) pysettings.Call {
+	return pysettings.Call{Func: "LDAPSearch", Args: []any{search.BaseDN, pysettings.Expr(ldapScopes[ldapSearchScope(search)]), ldapSearchFilter(search, defaultFilter)}}
+}
+
+// ldapSearchFilter returns the filter of search or defaultFilter if it is not defined
+func ldapSearchFilter(search pulpv1.LDAPSearch, defaultFilter string) string {
+	if len(search.Filter) == 0 {
+		return defaultFilter
 	}
+	return search.Filter
+}
 
-	settings += "#### END OF LDAP SETTINGS ####\n\n"
-	*pulpSettings = *pulpSettings + settings
+// ldapSearchScope returns the scope of search or subtree if it is not defined
+func ldapSearchScope(search pulpv1.LDAPSearch) string {
+	if len(search.Scope) == 0 {
+		return "subtree"
+	}
+	return search.Scope
+}
+
+// ldapGroupType returns the django-auth-ldap group type or GroupOfNamesType if it is not defined
+func ldapGroupType(pulp *pulpv1.Pulp) string {
+	if len(pulp.Spec.LDAP.GroupType) == 0 {
+		return "GroupOfNamesType"
+	}
+	return pulp.Spec.LDAP.GroupType
+}
+
+// ldapGroupNameAttr returns the attribute with the group name or cn if it is not defined
+func ldapGroupNameAttr(pulp *pulpv1.Pulp) string {
+	if len(pulp.Spec.LDAP.GroupNameAttr) == 0 {
+		return "cn"
+	}
+	return pulp.Spec.LDAP.GroupNameAttr
 }
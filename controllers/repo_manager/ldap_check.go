@@ -0,0 +1,283 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/settings"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	ldapConditionType = "Pulp-LDAP-Ready"
+	// ldapConfigHashAnnotation is the hash of the ldap configuration tested by the Job
+	ldapConfigHashAnnotation = "repo-manager.pulpproject.org/ldap-config-hash"
+)
+
+// ldapCheckScript binds to the ldap server and runs the user and group searches with the
+// same options used by django-auth-ldap
+const ldapCheckScript = `import os
+import sys
+
+import ldap
+
+SCOPES = {"base": ldap.SCOPE_BASE, "onelevel": ldap.SCOPE_ONELEVEL, "subtree": ldap.SCOPE_SUBTREE}
+
+
+def search(conn, kind):
+    base = os.environ.get("LDAP_%s_BASE_DN" % kind)
+    if not base:
+        return True
+    scope = SCOPES[os.environ["LDAP_%s_SCOPE" % kind]]
+    search_filter = os.environ["LDAP_%s_FILTER" % kind].replace("%(user)s", "*")
+    try:
+        found = len(conn.search_ext_s(base, scope, search_filter, attrlist=["1.1"], timeout=10, sizelimit=1)) > 0
+    except ldap.SIZELIMIT_EXCEEDED:
+        found = True
+    print("%s search %s %s: %s" % (kind.lower(), base, search_filter, "entries found" if found else "no entries found"))
+    return found
+
+
+try:
+    conn = ldap.initialize(os.environ["LDAP_SERVER_URI"])
+    conn.set_option(ldap.OPT_REFERRALS, 0)
+    conn.set_option(ldap.OPT_NETWORK_TIMEOUT, 10)
+    if os.environ.get("LDAP_CA_FILE"):
+        conn.set_option(ldap.OPT_X_TLS_CACERTFILE, os.environ["LDAP_CA_FILE"])
+        conn.set_option(ldap.OPT_X_TLS_NEWCTX, 0)
+    if os.environ.get("LDAP_START_TLS") == "true":
+        conn.start_tls_s()
+    password = os.environ.get("LDAP_BIND_PASSWORD", "")
+    if os.environ.get("LDAP_BIND_PASSWORD_FILE"):
+        with open(os.environ["LDAP_BIND_PASSWORD_FILE"]) as f:
+            password = f.read().strip()
+    conn.simple_bind_s(os.environ.get("LDAP_BIND_DN", ""), password)
+    print("bind to %s succeeded" % os.environ["LDAP_SERVER_URI"])
+    found = [search(conn, "USER"), search(conn, "GROUP")]
+except ldap.LDAPError as e:
+    print("ldap error: %s" % e)
+    sys.exit(1)
+sys.exit(0 if all(found) else 1)`
+
+// ldapCheckController runs a Job to verify if the operator can bind to the ldap server and find
+// the users and groups whenever the ldap configuration changes. The result is reported in the
+// Pulp-LDAP-Ready condition and the tested configuration is kept in .status.ldap_config_hash.
+// A failed Job is kept (and the test is not retried) until it is removed by its TTL or the
+// configuration is modified. The Jobs are not watched, so the controller is requeued to retry
+// the test once the TTL of the failed Job expires.
+func (r *RepoManagerReconciler) ldapCheckController(ctx context.Context, pulp *pulpv1.Pulp, log logr.Logger) (ctrl.Result, error) {
+	if !controllers.LDAPStructured(pulp) {
+		if len(pulp.Status.LDAPConfigHash) > 0 || v1.FindStatusCondition(pulp.Status.Conditions, ldapConditionType) != nil {
+			pulp.Status.LDAPConfigHash = ""
+			v1.RemoveStatusCondition(&pulp.Status.Conditions, ldapConditionType)
+			r.Status().Update(ctx, pulp)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	hash := r.ldapConfigHash(ctx, pulp)
+	if pulp.Status.LDAPConfigHash == hash {
+		return ctrl.Result{}, nil
+	}
+
+	jobList := &batchv1.JobList{}
+	if err := r.List(ctx, jobList, client.InNamespace(pulp.Namespace), client.MatchingLabels(ldapCheckJobLabels(pulp))); err != nil {
+		log.Error(err, "Failed to list the ldap connectivity test Jobs")
+		return ctrl.Result{}, err
+	}
+
+	propagationPolicy := metav1.DeletePropagationBackground
+	running := false
+	for i := range jobList.Items {
+		job := &jobList.Items[i]
+		switch {
+		case job.Annotations[ldapConfigHashAnnotation] != hash:
+			// the Job tested an outdated configuration
+			if err := r.Delete(ctx, job, &client.DeleteOptions{PropagationPolicy: &propagationPolicy}); err != nil && !errors.IsNotFound(err) {
+				log.Error(err, "Failed to remove "+job.Name+" Job")
+			}
+		case jobFailed(job):
+			if condition := v1.FindStatusCondition(pulp.Status.Conditions, ldapConditionType); condition == nil || condition.Reason != "LDAPConnectionFailed" {
+				v1.RemoveStatusCondition(&pulp.Status.Conditions, ldapConditionType)
+				controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, ldapConditionType, "LDAPConnectionFailed", "Failed to bind or search the ldap server. Verify the logs from "+job.Name+" Job.")
+				r.recorder.Event(pulp, corev1.EventTypeWarning, "Failed", "LDAP connectivity test failed")
+			}
+			return ctrl.Result{RequeueAfter: jobTTLRemaining(job)}, nil
+		case job.Status.Succeeded > 0:
+			pulp.Status.LDAPConfigHash = hash
+			v1.SetStatusCondition(&pulp.Status.Conditions, metav1.Condition{
+				Type:               ldapConditionType,
+				Status:             metav1.ConditionTrue,
+				Reason:             "LDAPConnectionSucceeded",
+				LastTransitionTime: metav1.Now(),
+				Message:            "Bind and search to " + pulp.Spec.LDAP.ServerURI + " succeeded",
+			})
+			if err := r.Status().Update(ctx, pulp); err != nil {
+				log.Error(err, "Failed to update pulp status with the ldap connectivity test result")
+				return ctrl.Result{}, err
+			}
+			r.recorder.Event(pulp, corev1.EventTypeNormal, "LDAPReady", "LDAP connectivity test succeeded")
+			return ctrl.Result{}, nil
+		default:
+			running = true
+		}
+	}
+
+	if !running {
+		log.Info("Creating " + settings.LDAPCheckJob(pulp.Name) + "* Job")
+		if err := r.Create(ctx, r.ldapCheckJob(pulp, hash)); err != nil {
+			log.Error(err, "Failed to create "+settings.LDAPCheckJob(pulp.Name)+"* Job!")
+			return ctrl.Result{}, err
+		}
+		v1.RemoveStatusCondition(&pulp.Status.Conditions, ldapConditionType)
+		controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, ldapConditionType, "LDAPConnectionTesting", "Testing the connection with "+pulp.Spec.LDAP.ServerURI)
+	}
+	return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+}
+
+// jobTTLRemaining returns the time until the finished job is removed by its TTL (or 10s if
+// it should already have been removed)
+func jobTTLRemaining(job *batchv1.Job) time.Duration {
+	remaining := 10 * time.Second
+	if job.Spec.TTLSecondsAfterFinished == nil {
+		return remaining
+	}
+	finishTime := job.CreationTimestamp.Time
+	for _, condition := range job.Status.Conditions {
+		if (condition.Type == batchv1.JobFailed || condition.Type == batchv1.JobComplete) && condition.Status == corev1.ConditionTrue {
+			finishTime = condition.LastTransitionTime.Time
+		}
+	}
+	ttl := time.Duration(*job.Spec.TTLSecondsAfterFinished) * time.Second
+	if expiration := time.Until(finishTime.Add(ttl)); expiration > remaining {
+		remaining = expiration
+	}
+	return remaining
+}
+
+// ldapConfigHash returns the hash of the ldap fields and the content of the Secrets they reference,
+// so that a rotated bind password or CA is also tested (a bind password from a SecretProviderClass
+// is not available to the operator, only the SecretProviderClass name is part of the hash)
+func (r *RepoManagerReconciler) ldapConfigHash(ctx context.Context, pulp *pulpv1.Pulp) string {
+	config := []any{pulp.Spec.LDAP, pulp.Spec.SecretsStore.LDAPBindPasswordSecret}
+	for _, secretName := range []string{pulp.Spec.LDAP.BindPasswordSecret, pulp.Spec.LDAP.CA} {
+		if len(secretName) == 0 {
+			continue
+		}
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Name: secretName, Namespace: pulp.Namespace}, secret); err == nil {
+			config = append(config, secret.Data)
+		}
+	}
+	return controllers.CalculateHash(config)
+}
+
+// ldapCheckJobLabels returns the labels of the ldap connectivity test Job
+func ldapCheckJobLabels(pulp *pulpv1.Pulp) map[string]string {
+	labels := jobLabels(*pulp)
+	labels["app.kubernetes.io/component"] = "ldap-check"
+	return labels
+}
+
+// ldapCheckJob returns the Job that tests the ldap configuration
+func (r *RepoManagerReconciler) ldapCheckJob(pulp *pulpv1.Pulp, hash string) *batchv1.Job {
+	ldapSpec := pulp.Spec.LDAP
+	envVars := []corev1.EnvVar{
+		{Name: "LDAP_SERVER_URI", Value: ldapSpec.ServerURI},
+		{Name: "LDAP_BIND_DN", Value: ldapSpec.BindDN},
+		{Name: "LDAP_START_TLS", Value: strconv.FormatBool(ldapSpec.StartTLS)},
+		{Name: "LDAP_USER_BASE_DN", Value: ldapSpec.UserSearch.BaseDN},
+		{Name: "LDAP_USER_SCOPE", Value: ldapSearchScope(ldapSpec.UserSearch)},
+		{Name: "LDAP_USER_FILTER", Value: ldapSearchFilter(ldapSpec.UserSearch, "(uid=%(user)s)")},
+		{Name: "LDAP_GROUP_BASE_DN", Value: ldapSpec.GroupSearch.BaseDN},
+		{Name: "LDAP_GROUP_SCOPE", Value: ldapSearchScope(ldapSpec.GroupSearch)},
+		{Name: "LDAP_GROUP_FILTER", Value: ldapSearchFilter(ldapSpec.GroupSearch, "(objectClass=*)")},
+	}
+	volumes := []corev1.Volume{}
+	volumeMounts := []corev1.VolumeMount{}
+	if len(pulp.Spec.SecretsStore.LDAPBindPasswordSecret) > 0 {
+		envVars = append(envVars, corev1.EnvVar{Name: "LDAP_BIND_PASSWORD_FILE", Value: controllers.SecretsStoreFile(controllers.SecretsStoreLDAP, "bind_password")})
+		volume := controllers.SecretsStoreVolume(pulp, controllers.SecretsStoreLDAP)
+		volumes = append(volumes, volume)
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: volume.Name, MountPath: path.Join(controllers.SecretsStorePath, controllers.SecretsStoreLDAP), ReadOnly: true})
+	} else if len(ldapSpec.BindPasswordSecret) > 0 {
+		envVars = append(envVars, corev1.EnvVar{
+			Name: "LDAP_BIND_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: ldapSpec.BindPasswordSecret},
+					Key:                  "bind_password",
+				},
+			},
+		})
+	}
+
+	if len(ldapSpec.CA) > 0 {
+		envVars = append(envVars, corev1.EnvVar{Name: "LDAP_CA_FILE", Value: controllers.LDAPCAPath})
+		volumes = append(volumes, corev1.Volume{
+			Name: "ldap-cert",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: ldapSpec.CA,
+					Items:      []corev1.KeyToPath{{Key: "ca.crt", Path: "ca.crt"}},
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: "ldap-cert", MountPath: controllers.LDAPCAPath, SubPath: "ca.crt", ReadOnly: true})
+	}
+
+	containers := []corev1.Container{{
+		Name:            "ldap-check",
+		Image:           pulpcoreImage(pulp),
+		ImagePullPolicy: corev1.PullPolicy(pulp.Spec.ImagePullPolicy),
+		Env:             envVars,
+		Command:         []string{"python3", "-c", ldapCheckScript},
+		VolumeMounts:    volumeMounts,
+		SecurityContext: controllers.SetDefaultSecurityContext(),
+	}}
+	backOffLimit := int32(0)
+	jobTTL := int32(3600)
+
+	job := commonJob(pulpJobConfig{
+		settings.LDAPCheckJob(pulp.Name),
+		pulp.Namespace,
+		settings.PulpServiceAccount(pulp.Name),
+		ldapCheckJobLabels(pulp),
+		&backOffLimit,
+		&jobTTL,
+		containers,
+		volumes,
+	})
+	job.Annotations = map[string]string{ldapConfigHashAnnotation: hash}
+
+	ctrl.SetControllerReference(pulp, job, r.Scheme)
+	return job
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// finishedJob returns a Job finished (with the condition provided) at finishTime
+func finishedJob(conditionType batchv1.JobConditionType, finishTime time.Time, ttl *int32) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-job", Namespace: "pulp", CreationTimestamp: metav1.NewTime(finishTime.Add(-time.Minute))},
+		Spec:       batchv1.JobSpec{TTLSecondsAfterFinished: ttl},
+		Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+			{Type: conditionType, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(finishTime)},
+		}},
+	}
+}
+
+func TestJobTTLRemaining(t *testing.T) {
+	ttl := int32(3600)
+	tests := []struct {
+		name     string
+		job      *batchv1.Job
+		min, max time.Duration
+	}{
+		{name: "failed 10 minutes ago", job: finishedJob(batchv1.JobFailed, time.Now().Add(-10*time.Minute), &ttl), min: 49 * time.Minute, max: 50 * time.Minute},
+		{name: "completed now", job: finishedJob(batchv1.JobComplete, time.Now(), &ttl), min: 59 * time.Minute, max: time.Hour},
+		{name: "TTL expired", job: finishedJob(batchv1.JobFailed, time.Now().Add(-2*time.Hour), &ttl), min: 10 * time.Second, max: 10 * time.Second},
+		{name: "no TTL", job: finishedJob(batchv1.JobFailed, time.Now(), nil), min: 10 * time.Second, max: 10 * time.Second},
+	}
+	for _, test := range tests {
+		if remaining := jobTTLRemaining(test.job); remaining < test.min || remaining > test.max {
+			t.Errorf("%v: expected between %v and %v, got %v", test.name, test.min, test.max, remaining)
+		}
+	}
+}
+
+func TestLDAPCheckJobFailed(t *testing.T) {
+	ctx := context.TODO()
+	pulp := settingsTestPulp()
+	pulp.Spec.LDAP.ServerURI = "ldaps://ldap.example.com"
+	r := newTestReconciler(pulp)
+	recorder := r.recorder.(*record.FakeRecorder)
+
+	ttl := int32(3600)
+	job := finishedJob(batchv1.JobFailed, time.Now().Add(-10*time.Minute), &ttl)
+	job.Labels = ldapCheckJobLabels(pulp)
+	job.Annotations = map[string]string{ldapConfigHashAnnotation: r.ldapConfigHash(ctx, pulp)}
+	r.Create(ctx, job)
+
+	// the controller is requeued to retry the test when the Job is removed by its TTL
+	for range 2 {
+		result, err := r.ldapCheckController(ctx, pulp, logr.Discard())
+		if err != nil || result.RequeueAfter < 49*time.Minute || result.RequeueAfter > 50*time.Minute {
+			t.Errorf("expected a requeue when the Job TTL expires, got %v (%v)", result, err)
+		}
+	}
+	if condition := v1.FindStatusCondition(pulp.Status.Conditions, ldapConditionType); condition == nil || condition.Reason != "LDAPConnectionFailed" {
+		t.Errorf("expected the LDAPConnectionFailed condition, got %v", condition)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("expected a single event, got %v", len(recorder.Events))
+	}
+	if len(pulp.Status.LDAPConfigHash) > 0 {
+		t.Errorf("the failed configuration should not be stored as tested")
+	}
+
+	// a new Job is created once the failed Job is removed
+	r.Delete(ctx, job)
+	if result, _ := r.ldapCheckController(ctx, pulp, logr.Discard()); result.RequeueAfter != 10*time.Second {
+		t.Errorf("expected the test to be retried, got %v", result)
+	}
+	jobList := &batchv1.JobList{}
+	r.List(ctx, jobList)
+	if len(jobList.Items) != 1 {
+		t.Errorf("expected a new ldap-check Job, got %v", len(jobList.Items))
+	}
+}
@@ -0,0 +1,276 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/settings"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// pulpcoreClients are the components (deployments and jobs) that need to reach the database and the cache
+var pulpcoreClients = []string{"api", "content", "worker", "migration", "reset-admin-password", "allowed-content-checksums", "signing-script", "storage-migration", "plugin-paths", "rotate-db-key"}
+
+// networkPolicyController provisions a NetworkPolicy for each Pulp component allowing
+// only the traffic required by Pulp
+func (r *RepoManagerReconciler) networkPolicyController(ctx context.Context, pulp *pulpv1.Pulp, log logr.Logger) (ctrl.Result, error) {
+
+	// conditionType is used to update .status.conditions with the current resource state
+	conditionType := "Pulp-Network-Policies-Ready"
+
+	expectedPolicies := map[string]*netv1.NetworkPolicy{}
+	if pulp.Spec.NetworkPolicies.Enabled {
+		for _, policy := range r.pulpNetworkPolicies(pulp) {
+			expectedPolicies[policy.Name] = policy
+		}
+	}
+
+	// remove the NetworkPolicies that are not expected anymore (network_policies disabled,
+	// external database or cache configured, ingress_type modified, etc)
+	if err := r.removeNetworkPolicies(ctx, pulp, expectedPolicies); err != nil {
+		log.Error(err, "Failed to remove NetworkPolicies")
+		return ctrl.Result{}, err
+	}
+	if len(expectedPolicies) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	resources := controllers.FunctionResources{Context: ctx, Client: r.Client, Pulp: pulp, Scheme: r.Scheme, Logger: log}
+	for name, expectedPolicy := range expectedPolicies {
+		ctrl.SetControllerReference(pulp, expectedPolicy, r.Scheme)
+		currentPolicy := &netv1.NetworkPolicy{}
+		err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: pulp.Namespace}, currentPolicy)
+
+		// Create the NetworkPolicy in case it is not found
+		if err != nil && errors.IsNotFound(err) {
+			log.Info("Creating a new NetworkPolicy", "NetworkPolicy.Namespace", pulp.Namespace, "NetworkPolicy.Name", name)
+			controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, conditionType, "CreatingNetworkPolicy", "Creating "+name+" NetworkPolicy")
+			if err := r.Create(ctx, expectedPolicy); err != nil {
+				log.Error(err, "Failed to create new NetworkPolicy", "NetworkPolicy.Namespace", pulp.Namespace, "NetworkPolicy.Name", name)
+				controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, conditionType, "ErrorCreatingNetworkPolicy", "Failed to create "+name+" NetworkPolicy: "+err.Error())
+				r.recorder.Event(pulp, corev1.EventTypeWarning, "Failed", "Failed to create new "+name+" NetworkPolicy")
+				return ctrl.Result{}, err
+			}
+			r.recorder.Event(pulp, corev1.EventTypeNormal, "Created", name+" NetworkPolicy created")
+			return ctrl.Result{Requeue: true}, nil
+		} else if err != nil {
+			log.Error(err, "Failed to get NetworkPolicy")
+			return ctrl.Result{}, err
+		}
+
+		// Ensure NetworkPolicy spec is as expected
+		if requeue, err := controllers.ReconcileObject(resources, expectedPolicy, currentPolicy, conditionType, controllers.PulpNetworkPolicy{}); err != nil || requeue {
+			return ctrl.Result{Requeue: requeue}, err
+		}
+
+		// Ensure NetworkPolicy labels and annotations are as expected
+		if requeue, err := controllers.ReconcileMetadata(resources, expectedPolicy, currentPolicy, conditionType); err != nil || requeue {
+			return ctrl.Result{Requeue: requeue}, err
+		}
+	}
+
+	// we should only update the status when Network-Policies-Ready==false
+	if v1.IsStatusConditionFalse(pulp.Status.Conditions, conditionType) {
+		controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionTrue, conditionType, "NetworkPoliciesTasksFinished", "All NetworkPolicies tasks ran successfully")
+		r.recorder.Event(pulp, corev1.EventTypeNormal, "NetworkPoliciesReady", "All NetworkPolicies tasks ran successfully")
+	}
+	return ctrl.Result{}, nil
+}
+
+// pulpNetworkPolicies returns the NetworkPolicies expected for the components deployed by the operator
+func (r *RepoManagerReconciler) pulpNetworkPolicies(pulp *pulpv1.Pulp) []*netv1.NetworkPolicy {
+	clients := componentsPeer(pulp, pulpcoreClients...)
+	ingressPeers := networkPolicyIngressPeers(pulp)
+	webPeers := append([]netv1.NetworkPolicyPeer{componentsPeer(pulp, "web")}, ingressPeers...)
+
+	// the PulpSyncSchedule Jobs dispatch the syncs through the api and the operator
+	// reconciles the Pulp objects (PulpRepository, PulpDomain, etc) through the api Service
+	apiPeers := append([]netv1.NetworkPolicyPeer{componentsPeer(pulp, "sync-schedule"), operatorPeer()}, webPeers...)
+	apiRules := []netv1.NetworkPolicyIngressRule{{From: apiPeers, Ports: networkPolicyPorts(24817)}}
+	if pulp.Spec.Telemetry.Enabled {
+		telemetryNamespaces := pulp.Spec.NetworkPolicies.TelemetryNamespaceSelector
+		if telemetryNamespaces == nil {
+			telemetryNamespaces = &metav1.LabelSelector{}
+		}
+		apiRules = append(apiRules, netv1.NetworkPolicyIngressRule{
+			From:  []netv1.NetworkPolicyPeer{{NamespaceSelector: telemetryNamespaces}},
+			Ports: networkPolicyPorts(settings.OtelContainerPort),
+		})
+	}
+
+	policies := []*netv1.NetworkPolicy{
+		networkPolicyObject(pulp, "api", apiRules),
+		networkPolicyObject(pulp, "content", []netv1.NetworkPolicyIngressRule{{From: webPeers, Ports: networkPolicyPorts(24816)}}),
+		// workers do not receive any connection
+		networkPolicyObject(pulp, "worker", nil),
+	}
+
+	if r.needsPulpWeb(pulp) {
+		webPorts := []int32{8080}
+		if controllers.WebTLSPassthrough(pulp) {
+			webPorts = append(webPorts, 8443)
+		}
+		webRule := netv1.NetworkPolicyIngressRule{Ports: networkPolicyPorts(webPorts...)}
+		// with nodeport and loadbalancer pulp-web is the entrypoint for the external clients
+		if isIngress(pulp) {
+			webRule.From = ingressPeers
+		}
+		policies = append(policies, networkPolicyObject(pulp, "web", []netv1.NetworkPolicyIngressRule{webRule}))
+	}
+
+	if len(pulp.Spec.Database.ExternalDBSecret) == 0 {
+		// the backup-storage pods run the database dump/restore
+		backupPeer := netv1.NetworkPolicyPeer{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{
+			"app.kubernetes.io/component":  "backup-storage",
+			"app.kubernetes.io/part-of":    "pulp",
+			"app.kubernetes.io/managed-by": "pulp-operator",
+		}}}
+		dbPort := int32(5432)
+		if pulp.Spec.Database.PostgresPort != 0 {
+			dbPort = int32(pulp.Spec.Database.PostgresPort)
+		}
+		policies = append(policies, networkPolicyObject(pulp, "database", []netv1.NetworkPolicyIngressRule{
+			{From: []netv1.NetworkPolicyPeer{clients, backupPeer}, Ports: networkPolicyPorts(dbPort)},
+		}))
+	}
+
+	if len(pulp.Spec.Cache.ExternalCacheSecret) == 0 && pulp.Spec.Cache.Enabled {
+		cachePeers := []netv1.NetworkPolicyPeer{clients}
+		// in sentinel mode the redis replicas connect to the master and
+		// the sentinels monitor the redis pods and each other
+		if controllers.CacheSentinelEnabled(*pulp) {
+			cachePeers = append(cachePeers, componentsPeer(pulp, "cache", "redis-sentinel"))
+			policies = append(policies, networkPolicyObject(pulp, "redis-sentinel", []netv1.NetworkPolicyIngressRule{
+				{From: cachePeers, Ports: networkPolicyPorts(26379)},
+			}))
+		}
+		policies = append(policies, networkPolicyObject(pulp, "cache", []netv1.NetworkPolicyIngressRule{
+			{From: cachePeers, Ports: networkPolicyPorts(6379)},
+		}))
+	}
+
+	return policies
+}
+
+// networkPolicyObject returns a NetworkPolicy selecting the component pods and allowing only the ingress rules provided
+func networkPolicyObject(pulp *pulpv1.Pulp, component string, rules []netv1.NetworkPolicyIngressRule) *netv1.NetworkPolicy {
+	labels := settings.CommonLabels(*pulp)
+	labels["app.kubernetes.io/component"] = component
+	return &netv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      settings.NetworkPolicyName(pulp.Name, component),
+			Namespace: pulp.Namespace,
+			Labels:    labels,
+		},
+		Spec: netv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: settings.PulpcoreLabels(*pulp, component)},
+			PolicyTypes: []netv1.PolicyType{netv1.PolicyTypeIngress},
+			Ingress:     rules,
+		},
+	}
+}
+
+// componentsPeer returns a NetworkPolicyPeer selecting the pods from the components provided
+func componentsPeer(pulp *pulpv1.Pulp, components ...string) netv1.NetworkPolicyPeer {
+	return netv1.NetworkPolicyPeer{
+		PodSelector: &metav1.LabelSelector{
+			MatchLabels: settings.CommonLabels(*pulp),
+			MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      "app.kubernetes.io/component",
+				Operator: metav1.LabelSelectorOpIn,
+				Values:   components,
+			}},
+		},
+	}
+}
+
+// operatorNamespaceFile is the file with the namespace of the operator pod
+var operatorNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// operatorPeer returns the NetworkPolicyPeer selecting the operator pods.
+// If the operator is not running in a pod, its pods will be selected from any namespace.
+func operatorPeer() netv1.NetworkPolicyPeer {
+	namespaceSelector := &metav1.LabelSelector{}
+	if namespace, err := os.ReadFile(operatorNamespaceFile); err == nil {
+		namespaceSelector.MatchLabels = map[string]string{"kubernetes.io/metadata.name": strings.TrimSpace(string(namespace))}
+	}
+	return netv1.NetworkPolicyPeer{
+		NamespaceSelector: namespaceSelector,
+		PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{
+			"app.kubernetes.io/name": "pulp-operator",
+			"control-plane":          "controller-manager",
+		}},
+	}
+}
+
+// networkPolicyIngressPeers returns the NetworkPolicyPeer selecting the ingress controller pods.
+// If no namespace selector is provided, the OpenShift router namespaces will be allowed
+// (the prechecks require the selector in other clusters).
+func networkPolicyIngressPeers(pulp *pulpv1.Pulp) []netv1.NetworkPolicyPeer {
+	namespaceSelector := pulp.Spec.NetworkPolicies.IngressNamespaceSelector
+	if namespaceSelector == nil {
+		namespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"network.openshift.io/policy-group": "ingress"}}
+	}
+	return []netv1.NetworkPolicyPeer{{NamespaceSelector: namespaceSelector, PodSelector: pulp.Spec.NetworkPolicies.IngressPodSelector}}
+}
+
+// networkPolicyPorts returns the NetworkPolicyPort list from the TCP ports provided
+func networkPolicyPorts(ports ...int32) []netv1.NetworkPolicyPort {
+	protocol := corev1.ProtocolTCP
+	policyPorts := []netv1.NetworkPolicyPort{}
+	for _, port := range ports {
+		port := intstr.FromInt32(port)
+		policyPorts = append(policyPorts, netv1.NetworkPolicyPort{Protocol: &protocol, Port: &port})
+	}
+	return policyPorts
+}
+
+// removeNetworkPolicies deletes the NetworkPolicies provisioned by the operator that are not in expectedPolicies
+func (r *RepoManagerReconciler) removeNetworkPolicies(ctx context.Context, pulp *pulpv1.Pulp, expectedPolicies map[string]*netv1.NetworkPolicy) error {
+	policyList := &netv1.NetworkPolicyList{}
+	listOpts := []client.ListOption{
+		client.InNamespace(pulp.Namespace),
+		client.MatchingLabels(settings.CommonLabels(*pulp)),
+	}
+	if err := r.List(ctx, policyList, listOpts...); err != nil {
+		return err
+	}
+	for i := range policyList.Items {
+		if _, expected := expectedPolicies[policyList.Items[i].Name]; expected {
+			continue
+		}
+		r.RawLogger.Info("Removing " + policyList.Items[i].Name + " NetworkPolicy")
+		if err := r.Delete(ctx, &policyList.Items[i]); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
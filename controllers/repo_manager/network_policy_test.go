@@ -0,0 +1,264 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// networkPolicyTestPulp returns a Pulp CR with the NetworkPolicies enabled
+func networkPolicyTestPulp() *pulpv1.Pulp {
+	pulp := settingsTestPulp()
+	pulp.Spec.NetworkPolicies = pulpv1.NetworkPolicies{
+		Enabled:                  true,
+		IngressNamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": "ingress-nginx"}},
+	}
+	pulp.Spec.Cache.Enabled = true
+	return pulp
+}
+
+// policyPorts returns the ports allowed by the rule
+func policyPorts(rule netv1.NetworkPolicyIngressRule) []int {
+	ports := []int{}
+	for _, port := range rule.Ports {
+		ports = append(ports, port.Port.IntValue())
+	}
+	return ports
+}
+
+// peersComponents returns the components selected by the peers
+func peersComponents(peers []netv1.NetworkPolicyPeer) []string {
+	components := []string{}
+	for _, peer := range peers {
+		if peer.PodSelector != nil && len(peer.PodSelector.MatchExpressions) > 0 {
+			components = append(components, peer.PodSelector.MatchExpressions[0].Values...)
+		}
+	}
+	return components
+}
+
+func TestPulpNetworkPolicies(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*pulpv1.Pulp)
+		policies map[string][]int
+	}{
+		{
+			name:     "nodeport",
+			modify:   func(*pulpv1.Pulp) {},
+			policies: map[string][]int{"test-api": {24817}, "test-content": {24816}, "test-worker": nil, "test-web": {8080}, "test-database": {5432}, "test-cache": {6379}},
+		},
+		{
+			name:     "route",
+			modify:   func(pulp *pulpv1.Pulp) { pulp.Spec.IngressType = "route" },
+			policies: map[string][]int{"test-api": {24817}, "test-content": {24816}, "test-worker": nil, "test-database": {5432}, "test-cache": {6379}},
+		},
+		{
+			name: "external database and cache",
+			modify: func(pulp *pulpv1.Pulp) {
+				pulp.Spec.Database.ExternalDBSecret = "external-db"
+				pulp.Spec.Cache.ExternalCacheSecret = "external-cache"
+			},
+			policies: map[string][]int{"test-api": {24817}, "test-content": {24816}, "test-worker": nil, "test-web": {8080}},
+		},
+		{
+			name:     "cache disabled and custom database port",
+			modify:   func(pulp *pulpv1.Pulp) { pulp.Spec.Cache.Enabled = false; pulp.Spec.Database.PostgresPort = 5433 },
+			policies: map[string][]int{"test-api": {24817}, "test-content": {24816}, "test-worker": nil, "test-web": {8080}, "test-database": {5433}},
+		},
+		{
+			name:     "sentinel",
+			modify:   func(pulp *pulpv1.Pulp) { pulp.Spec.Cache.Mode = controllers.CacheSentinelMode },
+			policies: map[string][]int{"test-api": {24817}, "test-content": {24816}, "test-worker": nil, "test-web": {8080}, "test-database": {5432}, "test-cache": {6379}, "test-redis-sentinel": {26379}},
+		},
+		{
+			name:     "telemetry",
+			modify:   func(pulp *pulpv1.Pulp) { pulp.Spec.Telemetry.Enabled = true },
+			policies: map[string][]int{"test-api": {24817, 8889}, "test-content": {24816}, "test-worker": nil, "test-web": {8080}, "test-database": {5432}, "test-cache": {6379}},
+		},
+	}
+
+	r := &RepoManagerReconciler{}
+	for _, test := range tests {
+		pulp := networkPolicyTestPulp()
+		test.modify(pulp)
+		policies := map[string][]int{}
+		for _, policy := range r.pulpNetworkPolicies(pulp) {
+			if !reflect.DeepEqual(policy.Spec.PolicyTypes, []netv1.PolicyType{netv1.PolicyTypeIngress}) {
+				t.Errorf("%v: expected %v to restrict only the ingress traffic", test.name, policy.Name)
+			}
+			policies[policy.Name] = nil
+			for _, rule := range policy.Spec.Ingress {
+				policies[policy.Name] = append(policies[policy.Name], policyPorts(rule)...)
+			}
+		}
+		if !reflect.DeepEqual(policies, test.policies) {
+			t.Errorf("%v: expected the policies %v, got %v", test.name, test.policies, policies)
+		}
+	}
+}
+
+func TestPulpNetworkPoliciesPeers(t *testing.T) {
+	pulp := networkPolicyTestPulp()
+	pulp.Spec.IngressType = "ingress"
+	pulp.Spec.Cache.Mode = controllers.CacheSentinelMode
+	r := &RepoManagerReconciler{}
+	rules := map[string][]netv1.NetworkPolicyIngressRule{}
+	for _, policy := range r.pulpNetworkPolicies(pulp) {
+		rules[policy.Name] = policy.Spec.Ingress
+	}
+	ingressPeer := netv1.NetworkPolicyPeer{NamespaceSelector: pulp.Spec.NetworkPolicies.IngressNamespaceSelector}
+
+	apiPeers := rules["test-api"][0].From
+	if components := peersComponents(apiPeers); !reflect.DeepEqual(components, []string{"sync-schedule", "web"}) {
+		t.Errorf("unexpected api clients %v", components)
+	}
+	if !slices.ContainsFunc(apiPeers, func(peer netv1.NetworkPolicyPeer) bool { return reflect.DeepEqual(peer, operatorPeer()) }) {
+		t.Errorf("expected the operator to reach the api, got %v", apiPeers)
+	}
+	if !slices.ContainsFunc(apiPeers, func(peer netv1.NetworkPolicyPeer) bool { return reflect.DeepEqual(peer, ingressPeer) }) {
+		t.Errorf("expected the ingress controller to reach the api, got %v", apiPeers)
+	}
+	if webPeers := rules["test-web"][0].From; !reflect.DeepEqual(webPeers, []netv1.NetworkPolicyPeer{ingressPeer}) {
+		t.Errorf("expected only the ingress controller to reach pulp-web, got %v", webPeers)
+	}
+	if components := peersComponents(rules["test-database"][0].From); !reflect.DeepEqual(components, pulpcoreClients) {
+		t.Errorf("unexpected database clients %v", components)
+	}
+	if components := peersComponents(rules["test-cache"][0].From); !reflect.DeepEqual(components, append(slices.Clone(pulpcoreClients), "cache", "redis-sentinel")) {
+		t.Errorf("unexpected cache clients %v", components)
+	}
+
+	// with nodeport pulp-web accepts connections from any client
+	pulp.Spec.IngressType = "nodeport"
+	for _, policy := range r.pulpNetworkPolicies(pulp) {
+		if policy.Name == "test-web" && policy.Spec.Ingress[0].From != nil {
+			t.Errorf("expected pulp-web to be reached from any client, got %v", policy.Spec.Ingress[0].From)
+		}
+	}
+}
+
+func TestNetworkPolicyIngressPeers(t *testing.T) {
+	pulp := networkPolicyTestPulp()
+	podSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/name": "ingress-nginx"}}
+	pulp.Spec.NetworkPolicies.IngressPodSelector = podSelector
+	expected := []netv1.NetworkPolicyPeer{{NamespaceSelector: pulp.Spec.NetworkPolicies.IngressNamespaceSelector, PodSelector: podSelector}}
+	if peers := networkPolicyIngressPeers(pulp); !reflect.DeepEqual(peers, expected) {
+		t.Errorf("expected %v, got %v", expected, peers)
+	}
+
+	// OpenShift routers
+	pulp.Spec.NetworkPolicies = pulpv1.NetworkPolicies{Enabled: true}
+	expected = []netv1.NetworkPolicyPeer{{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"network.openshift.io/policy-group": "ingress"}}}}
+	if peers := networkPolicyIngressPeers(pulp); !reflect.DeepEqual(peers, expected) {
+		t.Errorf("expected %v, got %v", expected, peers)
+	}
+}
+
+func TestOperatorPeer(t *testing.T) {
+	defaultFile := operatorNamespaceFile
+	defer func() { operatorNamespaceFile = defaultFile }()
+
+	operatorNamespaceFile = filepath.Join(t.TempDir(), "namespace")
+	if peer := operatorPeer(); len(peer.NamespaceSelector.MatchLabels) > 0 {
+		t.Errorf("expected any namespace outside of a pod, got %v", peer.NamespaceSelector)
+	}
+	os.WriteFile(operatorNamespaceFile, []byte("pulp-operator-system\n"), 0600)
+	peer := operatorPeer()
+	if namespace := peer.NamespaceSelector.MatchLabels["kubernetes.io/metadata.name"]; namespace != "pulp-operator-system" {
+		t.Errorf("expected the operator namespace, got %v", namespace)
+	}
+	if peer.PodSelector.MatchLabels["app.kubernetes.io/name"] != "pulp-operator" {
+		t.Errorf("unexpected operator pod selector %v", peer.PodSelector)
+	}
+}
+
+func TestCheckNetworkPolicies(t *testing.T) {
+	tests := []struct {
+		name        string
+		policies    pulpv1.NetworkPolicies
+		isOpenShift bool
+		fail        bool
+	}{
+		{name: "disabled", policies: pulpv1.NetworkPolicies{}},
+		{name: "no namespace selector", policies: pulpv1.NetworkPolicies{Enabled: true}, fail: true},
+		{name: "no namespace selector in OpenShift", policies: pulpv1.NetworkPolicies{Enabled: true}, isOpenShift: true},
+		{name: "namespace selector", policies: networkPolicyTestPulp().Spec.NetworkPolicies},
+	}
+	for _, test := range tests {
+		pulp := settingsTestPulp()
+		pulp.Spec.NetworkPolicies = test.policies
+		if failed := checkNetworkPolicies(logr.Discard(), pulp, test.isOpenShift) != nil; failed != test.fail {
+			t.Errorf("%v: expected the precheck to fail=%v, got %v", test.name, test.fail, failed)
+		}
+	}
+}
+
+// componentsWithoutPulpcoreAccess are the components (from the labels set in this package) that
+// do not reach the database nor the cache
+var componentsWithoutPulpcoreAccess = map[string]string{
+	"storage":            "PVC label",
+	"ldap-check":         "only binds to the ldap server",
+	"file-storage-usage": "only reads the file storage",
+	"sync-schedule":      "reaches the api (allowed in the api NetworkPolicy)",
+}
+
+// TestJobComponentsAllowed verifies that the components of the Jobs created by the operator
+// are allowed to reach the database and the cache, so a new Job is not blocked by the NetworkPolicies
+func TestJobComponentsAllowed(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	componentLabel := regexp.MustCompile(`\["app.kubernetes.io/component"\] = "([a-z0-9-]+)"`)
+	components := map[string]bool{}
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, match := range componentLabel.FindAllStringSubmatch(string(content), -1) {
+			components[match[1]] = true
+		}
+	}
+	if !components["migration"] || !components["rotate-db-key"] {
+		t.Fatalf("expected the Job components to be found, got %v", components)
+	}
+	for component := range components {
+		if _, exempt := componentsWithoutPulpcoreAccess[component]; exempt {
+			continue
+		}
+		if !slices.Contains(pulpcoreClients, component) {
+			t.Errorf("the %v component is not allowed to reach the database and the cache (pulpcoreClients)", component)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/pysettings"
+)
+
+// oidcPipeline is the social-auth pipeline step that synchronizes the Pulp groups, roles
+// and superuser permission of the user from the claim_mappings
+const oidcPipeline = `from django.conf import settings
+from django.db import transaction
+from pulpcore.app.models import Group, Role, UserRole
+
+
+def claim_values(backend, response):
+    claims = dict(getattr(backend, "id_token", None) or {})
+    claims.update(response or {})
+    values = claims.get(settings.PULP_OIDC_GROUPS_CLAIM) or []
+    if isinstance(values, str):
+        values = [values]
+    return set(values)
+
+
+def map_claims(backend, user=None, response=None, *args, **kwargs):
+    if user is None:
+        return
+
+    values = claim_values(backend, response)
+    groups, roles, superuser = set(), set(), False
+    managed_groups, managed_roles, manage_superuser = set(), set(), False
+    for mapping in settings.PULP_OIDC_CLAIM_MAPPINGS:
+        managed_groups.update(mapping["groups"])
+        managed_roles.update(mapping["roles"])
+        manage_superuser = manage_superuser or mapping["superuser"]
+        if mapping["claim"] in values:
+            groups.update(mapping["groups"])
+            roles.update(mapping["roles"])
+            superuser = superuser or mapping["superuser"]
+
+    with transaction.atomic():
+        for name in managed_groups:
+            group, _ = Group.objects.get_or_create(name=name)
+            if name in groups:
+                user.groups.add(group)
+            else:
+                user.groups.remove(group)
+
+        for name in managed_roles:
+            if name not in roles:
+                UserRole.objects.filter(user=user, role__name=name, object_id=None).delete()
+                continue
+            role = Role.objects.filter(name=name).first()
+            if role is not None:
+                UserRole.objects.get_or_create(user=user, role=role, content_type=None, object_id=None)
+
+        if manage_superuser and user.is_superuser != superuser:
+            user.is_superuser = superuser
+            user.save()
+`
+
+// oidcURLs adds the social-auth login and callback endpoints to the pulpcore urls
+const oidcURLs = `from django.urls import include, path
+from pulpcore.app.urls import urlpatterns as pulpcore_urlpatterns
+
+urlpatterns = [path("", include("social_django.urls", namespace="social"))] + pulpcore_urlpatterns
+`
+
+// oidcModule returns the files of the pulp_oidc python module stored in the pulp-server Secret
+// (see controllers.OIDCModuleVolume)
+func oidcModule(pulp *pulpv1.Pulp) map[string]string {
+	if !controllers.OIDCEnabled(pulp) {
+		return nil
+	}
+	return map[string]string{
+		"oidc_init.py":     "",
+		"oidc_pipeline.py": oidcPipeline,
+		"oidc_urls.py":     oidcURLs,
+	}
+}
+
+// oidcPipelineSteps are the social-auth pipeline steps used to authenticate the OIDC users
+var oidcPipelineSteps = []string{
+	"social_core.pipeline.social_auth.social_details",
+	"social_core.pipeline.social_auth.social_uid",
+	"social_core.pipeline.social_auth.auth_allowed",
+	"social_core.pipeline.social_auth.social_user",
+	"social_core.pipeline.user.get_username",
+	"social_core.pipeline.user.create_user",
+	"social_core.pipeline.social_auth.associate_user",
+	"social_core.pipeline.social_auth.load_extra_data",
+	"social_core.pipeline.user.user_details",
+	"pulp_oidc.pipeline.map_claims",
+}
+
+// oidcSettings appends the social-auth OpenID Connect settings into pulpSettings
+func oidcSettings(resources controllers.FunctionResources, pulpSettings *pysettings.Settings) {
+	pulp := resources.Pulp
+	if !controllers.OIDCEnabled(pulp) {
+		return
+	}
+
+	logger := resources.Logger
+	// the client secret provided by a SecretProviderClass is read from the file mounted in the pods
+	secretsStore := len(controllers.SecretsStoreClass(pulp, controllers.SecretsStoreOIDC)) > 0
+	keys := []string{"client_id", "client_secret"}
+	if secretsStore {
+		keys = []string{"client_id"}
+	}
+	client, err := controllers.RetrieveSecretData(resources.Context, pulp.Spec.OIDC.ClientSecret, pulp.Namespace, true, resources.Client, keys...)
+	if err != nil {
+		logger.Error(err, "Secret Not Found!", "Secret.Namespace", pulp.Namespace, "Secret.Name", pulp.Spec.OIDC.ClientSecret)
+		return
+	}
+
+	backends := []string{"social_core.backends.open_id_connect.OpenIdConnectAuth"}
+	if controllers.LDAPEnabled(pulp) {
+		backends = append(backends, "django_auth_ldap.backend.LDAPBackend")
+	}
+	backends = append(backends, "django.contrib.auth.backends.ModelBackend", "pulpcore.backends.ObjectRolePermissionBackend")
+
+	scopes := pulp.Spec.OIDC.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "profile", "email"}
+	}
+	usernameClaim := pulp.Spec.OIDC.UsernameClaim
+	if len(usernameClaim) == 0 {
+		usernameClaim = "preferred_username"
+	}
+	groupsClaim := pulp.Spec.OIDC.GroupsClaim
+	if len(groupsClaim) == 0 {
+		groupsClaim = "groups"
+	}
+
+	mappings := []any{}
+	for _, mapping := range pulp.Spec.OIDC.ClaimMappings {
+		groups, roles := mapping.Groups, mapping.Roles
+		if groups == nil {
+			groups = []string{}
+		}
+		if roles == nil {
+			roles = []string{}
+		}
+		mappings = append(mappings, pysettings.Dict{
+			{Key: "claim", Value: mapping.Claim},
+			{Key: "groups", Value: groups},
+			{Key: "roles", Value: roles},
+			{Key: "superuser", Value: mapping.Superuser},
+		})
+	}
+
+	apiRoot := controllers.GetAPIRoot(resources.Context, resources.Client, pulp)
+
+	pulpSettings.Code(`
+#### OIDC SETTINGS ####
+import sys
+sys.path.insert(0, ` + pysettings.Literal(controllers.OIDCPath) + `)
+`)
+	pulpSettings.Set("INSTALLED_APPS", []string{"dynaconf_merge", "social_django"})
+	pulpSettings.Set("ROOT_URLCONF", "pulp_oidc.urls")
+	pulpSettings.Set("AUTHENTICATION_BACKENDS", backends)
+	pulpSettings.Set("SOCIAL_AUTH_OIDC_OIDC_ENDPOINT", strings.TrimSuffix(pulp.Spec.OIDC.IssuerURL, "/"))
+	pulpSettings.Set("SOCIAL_AUTH_OIDC_KEY", client["client_id"])
+	if secretsStore {
+		pulpSettings.Set("SOCIAL_AUTH_OIDC_SECRET", pysettings.FileContent(controllers.SecretsStoreFile(controllers.SecretsStoreOIDC, "client_secret")))
+	} else {
+		pulpSettings.Set("SOCIAL_AUTH_OIDC_SECRET", client["client_secret"])
+	}
+	pulpSettings.Set("SOCIAL_AUTH_OIDC_SCOPE", scopes)
+	pulpSettings.Set("SOCIAL_AUTH_OIDC_IGNORE_DEFAULT_SCOPE", true)
+	pulpSettings.Set("SOCIAL_AUTH_OIDC_USERNAME_KEY", usernameClaim)
+	pulpSettings.Set("SOCIAL_AUTH_LOGIN_REDIRECT_URL", apiRoot+"api/v3/")
+	pulpSettings.Set("SOCIAL_AUTH_REDIRECT_IS_HTTPS", strings.HasPrefix(getRootURL(*pulp), "https://"))
+	pulpSettings.Set("SOCIAL_AUTH_PIPELINE", oidcPipelineSteps)
+	pulpSettings.Set("PULP_OIDC_GROUPS_CLAIM", groupsClaim)
+	pulpSettings.Set("PULP_OIDC_CLAIM_MAPPINGS", mappings)
+}
+
+// oidcDiscovery is the subset of the OpenID provider configuration used by social-auth
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+// validateOIDCIssuer retrieves the OpenID provider configuration and verifies if it is
+// valid for issuerURL
+func validateOIDCIssuer(ctx context.Context, issuerURL string) error {
+	issuer := strings.TrimSuffix(issuerURL, "/")
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return err
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("the OpenID provider configuration request returned %v", response.Status)
+	}
+
+	discovery := oidcDiscovery{}
+	if err := json.NewDecoder(response.Body).Decode(&discovery); err != nil {
+		return fmt.Errorf("failed to parse the OpenID provider configuration: %v", err)
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != issuer {
+		return fmt.Errorf("the OpenID provider configuration issuer %v does not match %v", discovery.Issuer, issuerURL)
+	}
+	if len(discovery.AuthorizationEndpoint) == 0 || len(discovery.TokenEndpoint) == 0 || len(discovery.JwksURI) == 0 {
+		return fmt.Errorf("the OpenID provider configuration does not have the authorization_endpoint, token_endpoint and jwks_uri")
+	}
+	return nil
+}
@@ -41,6 +41,9 @@ func (r *RepoManagerReconciler) pdbController(ctx context.Context, pulp *pulpv1.
 		settings.CONTENT: pulp.Spec.Content.PDB,
 		settings.WORKER:  pulp.Spec.Worker.PDB,
 		settings.WEB:     pulp.Spec.Web.PDB,
+		// redis and sentinel PDBs are only provisioned in cache sentinel mode
+		settings.CACHE:    cachePDB(pulp, pulp.Spec.Cache.PDB),
+		settings.SENTINEL: cachePDB(pulp, pulp.Spec.Cache.Sentinel.PDB),
 	}
 
 	for component, pdb := range pdbList {
@@ -59,7 +62,7 @@ func (r *RepoManagerReconciler) pdbController(ctx context.Context, pulp *pulpv1.
 			// add label selector to PDBSpec
 			// even though it is possible to pass a selector through PodDisruptionBudgetSpec we will overwrite
 			// any config passed through pulp CR with the following
-			labels := settings.PulpcoreLabels(*pulp, strings.ToLower(string(component)))
+			labels := pdbSelectorLabels(pulp, component)
 			pdb.Selector = &metav1.LabelSelector{
 				MatchLabels: labels,
 			}
@@ -122,3 +125,11 @@ func (r *RepoManagerReconciler) pdbController(ctx context.Context, pulp *pulpv1.
 
 	return ctrl.Result{}, nil
 }
+
+// pdbSelectorLabels returns the labels used to select the pods of each component
+func pdbSelectorLabels(pulp *pulpv1.Pulp, component settings.PulpcoreType) map[string]string {
+	if component == settings.CACHE {
+		return labelsForCache(pulp)
+	}
+	return settings.PulpcoreLabels(*pulp, strings.ToLower(string(component)))
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/settings"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// pluginPathsKey is the ConfigMap key with the plugin paths (output from route_paths.py)
+	pluginPathsKey = "plugins.json"
+	// pluginPathsImageAnnotation is the pulpcore image used to discover the plugin paths
+	pluginPathsImageAnnotation = "repo-manager.pulpproject.org/image"
+)
+
+// pluginPathsScript collects the plugin paths and stores them in the plugin-paths ConfigMap
+const pluginPathsScript = `import json, os, ssl, subprocess, urllib.request
+paths = subprocess.check_output(["/usr/bin/route_paths.py", os.environ["PULP_NAME"]]).decode()
+json.loads(paths)
+sa = "/var/run/secrets/kubernetes.io/serviceaccount/"
+namespace = open(sa + "namespace").read()
+token = open(sa + "token").read()
+url = "https://kubernetes.default.svc/api/v1/namespaces/%s/configmaps/%s" % (namespace, os.environ["PLUGIN_PATHS_CONFIGMAP"])
+patch = {"metadata": {"annotations": {os.environ["IMAGE_ANNOTATION"]: os.environ["PULP_IMAGE"]}}, "data": {os.environ["PLUGIN_PATHS_KEY"]: paths}}
+request = urllib.request.Request(url, data=json.dumps(patch).encode(), method="PATCH", headers={"Authorization": "Bearer " + token, "Content-Type": "application/merge-patch+json"})
+urllib.request.urlopen(request, context=ssl.create_default_context(cafile=sa + "ca.crt"))
+print(paths)`
+
+// pluginPathsController runs a Job to discover the paths exposed by the installed plugins
+// whenever the pulpcore image changes. The Job stores the paths in the plugin-paths ConfigMap,
+// which is shared by the Ingress, Routes, HTTPRoutes and the pulp-web nginx.conf.
+func (r *RepoManagerReconciler) pluginPathsController(ctx context.Context, pulp *pulpv1.Pulp, log logr.Logger) (ctrl.Result, error) {
+	configMapName := settings.PluginPathsConfigMap(pulp.Name)
+	configMap := &corev1.ConfigMap{}
+	err := r.Get(ctx, types.NamespacedName{Name: configMapName, Namespace: pulp.Namespace}, configMap)
+	if err != nil && errors.IsNotFound(err) {
+		log.Info("Creating a new " + configMapName + " ConfigMap")
+		if err := r.Create(ctx, pluginPathsConfigMap(pulp, r)); err != nil {
+			log.Error(err, "Failed to create "+configMapName+" ConfigMap")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
+	} else if err != nil {
+		log.Error(err, "Failed to get "+configMapName+" ConfigMap")
+		return ctrl.Result{}, err
+	}
+
+	image := pulpcoreImage(pulp)
+	if configMap.Annotations[pluginPathsImageAnnotation] == image && len(configMap.Data[pluginPathsKey]) > 0 {
+		return ctrl.Result{}, nil
+	}
+
+	if requeue, err := r.runPluginPathsJob(ctx, pulp, image); err != nil || requeue != nil {
+		return *requeue, err
+	}
+
+	// while the Job is running, the paths discovered from the previous image are kept.
+	// The ConfigMap update from the Job will trigger a new reconciliation.
+	if len(configMap.Data[pluginPathsKey]) > 0 {
+		return ctrl.Result{}, nil
+	}
+	log.Info("Waiting for the plugin paths discovery ...")
+	return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+}
+
+// runPluginPathsJob creates the plugin paths Job if there is none running for the image.
+// Failed Jobs are removed to be retried in the next reconciliation.
+func (r *RepoManagerReconciler) runPluginPathsJob(ctx context.Context, pulp *pulpv1.Pulp, image string) (*ctrl.Result, error) {
+	log := r.RawLogger
+	labels := pluginPathsJobLabels(pulp)
+	jobList := &batchv1.JobList{}
+	if err := r.List(ctx, jobList, client.InNamespace(pulp.Namespace), client.MatchingLabels(labels)); err != nil {
+		log.Error(err, "Failed to list the plugin paths Jobs")
+		return &ctrl.Result{}, err
+	}
+
+	for i := range jobList.Items {
+		job := &jobList.Items[i]
+		if job.Annotations[pluginPathsImageAnnotation] != image {
+			continue
+		}
+		propagationPolicy := metav1.DeletePropagationBackground
+		switch {
+		case jobFailed(job):
+			log.Info("The plugin paths discovery Job " + job.Name + " failed! Retrying ...")
+			r.recorder.Event(pulp, corev1.EventTypeWarning, "Failed", "Failed to discover the plugin paths")
+			if err := r.Delete(ctx, job, &client.DeleteOptions{PropagationPolicy: &propagationPolicy}); err != nil && !errors.IsNotFound(err) {
+				log.Error(err, "Failed to remove "+job.Name+" Job")
+			}
+			return &ctrl.Result{RequeueAfter: time.Minute}, nil
+		case job.Status.Succeeded > 0:
+			// the Job finished but the ConfigMap does not have its paths (for example, the ConfigMap
+			// has been removed to force a new discovery)
+			if err := r.Delete(ctx, job, &client.DeleteOptions{PropagationPolicy: &propagationPolicy}); err != nil && !errors.IsNotFound(err) {
+				log.Error(err, "Failed to remove "+job.Name+" Job")
+			}
+		default:
+			// the Job is still running
+			return nil, nil
+		}
+	}
+
+	job := r.pluginPathsJob(pulp, image)
+	log.Info("Creating " + settings.PluginPathsJob(pulp.Name) + "* Job")
+	if err := r.Create(ctx, job); err != nil {
+		log.Error(err, "Failed to create "+settings.PluginPathsJob(pulp.Name)+"* Job!")
+		return &ctrl.Result{}, err
+	}
+	return nil, nil
+}
+
+// pluginPaths returns the plugin paths stored in the plugin-paths ConfigMap
+func (r *RepoManagerReconciler) pluginPaths(ctx context.Context, pulp *pulpv1.Pulp) ([]controllers.IngressPlugin, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := r.Get(ctx, types.NamespacedName{Name: settings.PluginPathsConfigMap(pulp.Name), Namespace: pulp.Namespace}, configMap); err != nil {
+		return nil, err
+	}
+	var pulpPlugins []controllers.IngressPlugin
+	if err := json.Unmarshal([]byte(configMap.Data[pluginPathsKey]), &pulpPlugins); err != nil {
+		return nil, err
+	}
+	return pulpPlugins, nil
+}
+
+// pluginPathsConfigMap returns the ConfigMap that will be populated by the plugin paths Job
+func pluginPathsConfigMap(pulp *pulpv1.Pulp, r *RepoManagerReconciler) *corev1.ConfigMap {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      settings.PluginPathsConfigMap(pulp.Name),
+			Namespace: pulp.Namespace,
+			Labels:    pluginPathsJobLabels(pulp),
+		},
+	}
+	ctrl.SetControllerReference(pulp, configMap, r.Scheme)
+	return configMap
+}
+
+// pluginPathsJobLabels returns the labels of the plugin paths Job and ConfigMap
+func pluginPathsJobLabels(pulp *pulpv1.Pulp) map[string]string {
+	labels := jobLabels(*pulp)
+	labels["app.kubernetes.io/component"] = "plugin-paths"
+	return labels
+}
+
+// pluginPathsJob returns the Job that discovers the plugin paths
+func (r *RepoManagerReconciler) pluginPathsJob(pulp *pulpv1.Pulp, image string) *batchv1.Job {
+	envVars := controllers.GetPostgresEnvVars(*pulp)
+	envVars = append(envVars,
+		corev1.EnvVar{Name: "PULP_NAME", Value: pulp.Name},
+		corev1.EnvVar{Name: "PULP_IMAGE", Value: image},
+		corev1.EnvVar{Name: "PLUGIN_PATHS_CONFIGMAP", Value: settings.PluginPathsConfigMap(pulp.Name)},
+		corev1.EnvVar{Name: "PLUGIN_PATHS_KEY", Value: pluginPathsKey},
+		corev1.EnvVar{Name: "IMAGE_ANNOTATION", Value: pluginPathsImageAnnotation},
+	)
+
+	containers := []corev1.Container{{
+		Name:            "plugin-paths",
+		Image:           image,
+		ImagePullPolicy: corev1.PullPolicy(pulp.Spec.ImagePullPolicy),
+		Env:             envVars,
+		Command:         []string{"python3", "-c", pluginPathsScript},
+		VolumeMounts:    pulpcoreVolumeMounts(pulp),
+		SecurityContext: controllers.SetDefaultSecurityContext(),
+	}}
+	backOffLimit := int32(2)
+	jobTTL := int32(3600)
+
+	job := commonJob(pulpJobConfig{
+		settings.PluginPathsJob(pulp.Name),
+		pulp.Namespace,
+		settings.PulpServiceAccount(pulp.Name),
+		pluginPathsJobLabels(pulp),
+		&backOffLimit,
+		&jobTTL,
+		containers,
+		pulpcoreVolumes(pulp, ""),
+	})
+	job.Annotations = map[string]string{pluginPathsImageAnnotation: image}
+
+	ctrl.SetControllerReference(pulp, job, r.Scheme)
+	return job
+}
+
+// pulpcoreImage returns the same pulpcore image used by the pulpcore deployments
+func pulpcoreImage(pulp *pulpv1.Pulp) string {
+	image := os.Getenv("RELATED_IMAGE_PULP")
+	if len(pulp.Spec.Image) > 0 && len(pulp.Spec.ImageVersion) > 0 {
+		image = pulp.Spec.Image + ":" + pulp.Spec.ImageVersion
+	} else if image == "" {
+		image = "quay.io/pulp/pulp-minimal:stable"
+	}
+	return image
+}
@@ -19,7 +19,12 @@ package repo_manager
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/go-logr/logr"
 	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
@@ -27,6 +32,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
@@ -49,21 +55,42 @@ func prechecks(ctx context.Context, r *RepoManagerReconciler, pulp *pulpv1.Pulp)
 		return reconcile, nil
 	}
 
+	// verify if all expected gateway fields are defined
+	if reconcile := checkGatewayDefinition(r.RawLogger, pulp); reconcile != nil {
+		return reconcile, nil
+	}
+
 	// verify if multiple storage types were provided
 	if reconcile := checkStorageDefinitions(r.RawLogger, pulp); reconcile != nil {
 		return reconcile, nil
 	}
 
+	// verify if the cache storage definition is compatible with the cache mode
+	if reconcile := checkCacheMode(r, pulp); reconcile != nil {
+		return reconcile, nil
+	}
+
 	// verify if ingress_type==route in a non-ocp cluster
 	if reconcile := checkRouteNotOCP(r.RawLogger, pulp); reconcile != nil {
 		return reconcile, nil
 	}
 
+	// verify if the ingress controller namespaces are defined for the NetworkPolicies
+	isOpenShift, _ := controllers.IsOpenShift()
+	if reconcile := checkNetworkPolicies(r.RawLogger, pulp, isOpenShift); reconcile != nil {
+		return reconcile, nil
+	}
+
 	// verify if all secrets defined in pulp cr are available
 	if reconcile := checkSecretsAvailability(ctx, r, pulp); reconcile != nil {
 		return reconcile, nil
 	}
 
+	// verify if the SecretProviderClasses are available
+	if reconcile := checkSecretsStore(ctx, r, pulp); reconcile != nil {
+		return reconcile, nil
+	}
+
 	// verify inconsistency in file_storage_* definition
 	if reconcile := checkFileStorage(r, pulp); reconcile != nil {
 		return reconcile, nil
@@ -74,16 +101,41 @@ func prechecks(ctx context.Context, r *RepoManagerReconciler, pulp *pulpv1.Pulp)
 		return reconcile, nil
 	}
 
+	// verify if the object storage secrets provide static keys or a workload identity
+	if reconcile := checkObjectStorageCredentials(ctx, r, pulp); reconcile != nil {
+		return reconcile, nil
+	}
+
+	// verify if the gcs object storage secret has the expected keys
+	if reconcile := checkObjectStorageGCS(ctx, r, pulp); reconcile != nil {
+		return reconcile, nil
+	}
+
 	// verify if LDAP CA is provided in case settings.py expects it
 	if reconcile := checkLDAPCA(ctx, r, pulp); reconcile != nil {
 		return reconcile, nil
 	}
 
+	// verify the structured ldap definition
+	if reconcile := checkLDAPDefinition(ctx, r, pulp); reconcile != nil {
+		return reconcile, nil
+	}
+
 	// verify the metadata signing definitions
 	if reconcile := checkSigningScripts(r, pulp); reconcile != nil {
 		return reconcile, nil
 	}
 
+	// verify the pulp-web nginx customizations
+	if reconcile := checkNginxDefinition(r.RawLogger, pulp); reconcile != nil {
+		return reconcile, nil
+	}
+
+	// verify the oidc definition and the OpenID provider issuer
+	if reconcile := checkOIDC(ctx, r, pulp); reconcile != nil {
+		return reconcile, nil
+	}
+
 	return nil, nil
 }
 
@@ -148,6 +200,41 @@ func checkIngressDefinition(log logr.Logger, pulp *pulpv1.Pulp) *ctrl.Result {
 	return nil
 }
 
+// checkGatewayDefinition verifies if all gateway fields are defined when ingress_type==gateway
+func checkGatewayDefinition(log logr.Logger, pulp *pulpv1.Pulp) *ctrl.Result {
+	if !isGateway(pulp) {
+		return nil
+	}
+
+	if len(pulp.Spec.Gateway.Name) == 0 {
+		log.Error(nil, "ingress_type defined as gateway but no gateway.name provided. Please, define the gateway.name field with the name of the Gateway that the HTTPRoutes should be attached to")
+		return &ctrl.Result{}
+	}
+
+	// ingress_host is used as the HTTPRoutes hostname and to populate CONTENT_ORIGIN
+	if len(pulp.Spec.IngressHost) == 0 {
+		log.Error(nil, "ingress_type defined as gateway but no ingress_host provided. Please, define the ingress_host field with the fqdn where Pulp should be accessed. This field is required to access API and also redirect Pulp CONTENT requests")
+		return &ctrl.Result{}
+	}
+
+	// HTTPRoutes cannot define the backends TLS configuration (it requires a BackendTLSPolicy)
+	if controllers.InternalTLSEnabled(pulp) {
+		log.Error(nil, "internal_tls is not supported with ingress_type defined as gateway. Please, disable internal_tls or choose another ingress_type")
+		return &ctrl.Result{}
+	}
+
+	// the nginx_proxy_*_timeout fields are converted to the HTTPRoute timeouts
+	timeouts := ingressProxyTimeouts(pulp)
+	for field, timeout := range map[string]string{"nginx_proxy_read_timeout": timeouts.read, "nginx_proxy_send_timeout": timeouts.send, "nginx_proxy_connect_timeout": timeouts.connect} {
+		duration, err := nginxDuration(timeout)
+		if err != nil || duration < time.Millisecond || duration > maxGatewayDuration {
+			log.Error(err, "Invalid "+field+" "+timeout+". Please, define the "+field+" field as a time interval (for example, 120s, 5m or 1h) between 1ms and 99999h")
+			return &ctrl.Result{}
+		}
+	}
+	return nil
+}
+
 // checkStorageDefinitions verifies if there is more than one storage type defined or none.
 // Only a single type should be provided, if more the operator will not be able to
 // determine which one should be used.
@@ -181,6 +268,17 @@ func checkStorageDefinitions(log logr.Logger, pulp *pulpv1.Pulp) *ctrl.Result {
 	return nil
 }
 
+// checkCacheMode verifies if the cache storage definition can be used with the cache.mode provided.
+// In sentinel mode each Redis pod needs its own volume, so a single PVC provisioned by the user
+// cannot be shared between them.
+func checkCacheMode(r *RepoManagerReconciler, pulp *pulpv1.Pulp) *ctrl.Result {
+	if controllers.CacheSentinelEnabled(*pulp) && len(pulp.Spec.Cache.PVC) > 0 {
+		r.RawLogger.Error(nil, "cache.pvc is not supported with \"cache.mode: sentinel\". Please, use cache.redis_storage_class to provision a PVC for each Redis pod.")
+		return &ctrl.Result{}
+	}
+	return nil
+}
+
 // checkRouteNotOCP verifies if this is an non-OCP cluster and "ingress_type: route".
 func checkRouteNotOCP(log logr.Logger, pulp *pulpv1.Pulp) *ctrl.Result {
 	isOpenShift, _ := controllers.IsOpenShift()
@@ -191,6 +289,16 @@ func checkRouteNotOCP(log logr.Logger, pulp *pulpv1.Pulp) *ctrl.Result {
 	return nil
 }
 
+// checkNetworkPolicies verifies if the ingress controller namespaces are defined when the
+// NetworkPolicies are enabled in a non-ocp cluster
+func checkNetworkPolicies(log logr.Logger, pulp *pulpv1.Pulp, isOpenShift bool) *ctrl.Result {
+	if !pulp.Spec.NetworkPolicies.Enabled || isOpenShift || pulp.Spec.NetworkPolicies.IngressNamespaceSelector != nil {
+		return nil
+	}
+	log.Error(nil, "network_policies enabled but no network_policies.ingress_namespace_selector provided. Please, define the network_policies.ingress_namespace_selector field with the namespaces of the ingress controller (or Gateway) allowed to reach Pulp")
+	return &ctrl.Result{}
+}
+
 // checkSecretsAvailability verifies if the secrets defined in Pulp CR are available.
 // If an expected secret is not found, the operator will fail early and
 // NOT trigger a reconciliation loop to avoid "spamming" error messages until
@@ -256,6 +364,50 @@ func checkAllowedContentChecksums(pulp *pulpv1.Pulp) *ctrl.Result {
 	return nil
 }
 
+// checkObjectStorageCredentials verifies the credentials provided in the S3 and Azure object storage Secrets.
+// The static keys are optional (the pods can get them from the cloud provider through the
+// workload identity), but if one of them is provided, the Secret should be complete.
+func checkObjectStorageCredentials(ctx context.Context, r *RepoManagerReconciler, pulp *pulpv1.Pulp) *ctrl.Result {
+	// the static keys from a SecretProviderClass are not available to the operator
+	if len(pulp.Spec.ObjectStorageS3Secret) > 0 && len(pulp.Spec.SecretsStore.ObjectStorageS3Secret) == 0 {
+		keys, _ := controllers.RetrieveSecretData(ctx, pulp.Spec.ObjectStorageS3Secret, pulp.Namespace, false, r.Client, "s3-access-key-id", "s3-secret-access-key")
+		if (len(keys["s3-access-key-id"]) == 0) != (len(keys["s3-secret-access-key"]) == 0) {
+			r.RawLogger.Error(nil, "The "+pulp.Spec.ObjectStorageS3Secret+" Secret should provide both s3-access-key-id and s3-secret-access-key keys or none of them (to use the pod's web identity)!")
+			return &ctrl.Result{}
+		}
+	}
+
+	if len(pulp.Spec.ObjectStorageAzureSecret) > 0 && len(pulp.Spec.SecretsStore.ObjectStorageAzureSecret) == 0 {
+		keys, _ := controllers.RetrieveSecretData(ctx, pulp.Spec.ObjectStorageAzureSecret, pulp.Namespace, false, r.Client, "azure-account-key", "azure-connection-string", "azure-client-id")
+		if len(keys["azure-account-key"]) == 0 && len(keys["azure-connection-string"]) == 0 && len(keys["azure-client-id"]) == 0 {
+			r.RawLogger.Error(nil, "The "+pulp.Spec.ObjectStorageAzureSecret+" Secret should provide azure-account-key, azure-connection-string or, to use Azure Workload Identity, azure-client-id!")
+			return &ctrl.Result{}
+		}
+	}
+	return nil
+}
+
+// checkObjectStorageGCS verifies if the Secret provided in .spec.object_storage_gcs_secret
+// has the gcs-bucket-name key and, if gcs-credentials is defined, that it is a valid json
+// (service account key file)
+func checkObjectStorageGCS(ctx context.Context, r *RepoManagerReconciler, pulp *pulpv1.Pulp) *ctrl.Result {
+	if len(pulp.Spec.ObjectStorageGCSSecret) == 0 {
+		return nil
+	}
+
+	if _, err := controllers.RetrieveSecretData(ctx, pulp.Spec.ObjectStorageGCSSecret, pulp.Namespace, true, r.Client, "gcs-bucket-name"); err != nil {
+		r.RawLogger.Error(err, "The "+pulp.Spec.ObjectStorageGCSSecret+" Secret must provide the gcs-bucket-name key!")
+		return &ctrl.Result{}
+	}
+
+	credentials, _ := controllers.RetrieveSecretData(ctx, pulp.Spec.ObjectStorageGCSSecret, pulp.Namespace, false, r.Client, "gcs-credentials")
+	if cred, found := credentials["gcs-credentials"]; found && !json.Valid([]byte(cred)) {
+		r.RawLogger.Error(nil, "The gcs-credentials key from "+pulp.Spec.ObjectStorageGCSSecret+" Secret is not a valid service account json key file!")
+		return &ctrl.Result{}
+	}
+	return nil
+}
+
 // checkLDAPCA verifies if there is a file provided in auth_ldap_ca_file (from pulp.Spec.LDAP.Config) field and if it does
 // we need to ensure that .spec.LDAP.CA is provided
 func checkLDAPCA(ctx context.Context, r *RepoManagerReconciler, pulp *pulpv1.Pulp) *ctrl.Result {
@@ -290,16 +442,285 @@ func checkLDAPCA(ctx context.Context, r *RepoManagerReconciler, pulp *pulpv1.Pul
 	return nil
 }
 
+// checkLDAPDefinition verifies the ldap fields used to generate the django-auth-ldap settings.
+// Invalid searches would only fail when the users try to log in, so the operator fails early instead.
+func checkLDAPDefinition(ctx context.Context, r *RepoManagerReconciler, pulp *pulpv1.Pulp) *ctrl.Result {
+	ldapSpec := pulp.Spec.LDAP
+	if !controllers.LDAPStructured(pulp) {
+		if len(ldapSpec.BindDN) > 0 || len(ldapSpec.BindPasswordSecret) > 0 || len(ldapSpec.UserSearch.BaseDN) > 0 || len(ldapSpec.GroupSearch.BaseDN) > 0 {
+			r.RawLogger.Error(nil, "spec.ldap fields are defined but spec.ldap.server_uri was not found!")
+			return &ctrl.Result{}
+		}
+		return nil
+	}
+
+	if len(ldapSpec.Config) > 0 {
+		r.RawLogger.Error(nil, "spec.ldap.config and spec.ldap.server_uri cannot be used together! Provide only one of them.")
+		return &ctrl.Result{}
+	}
+	if ldapSpec.StartTLS && strings.HasPrefix(ldapSpec.ServerURI, "ldaps://") {
+		r.RawLogger.Error(nil, "spec.ldap.start_tls cannot be used with an ldaps:// spec.ldap.server_uri!")
+		return &ctrl.Result{}
+	}
+	if len(ldapSpec.BindPasswordSecret) > 0 {
+		if _, err := controllers.RetrieveSecretData(ctx, ldapSpec.BindPasswordSecret, pulp.Namespace, true, r.Client, "bind_password"); err != nil {
+			r.RawLogger.Error(err, "The "+ldapSpec.BindPasswordSecret+" Secret should provide the bind_password key!")
+			return &ctrl.Result{}
+		}
+	}
+
+	if len(ldapSpec.UserSearch.BaseDN) == 0 {
+		r.RawLogger.Error(nil, "spec.ldap.user_search.base_dn is required with spec.ldap.server_uri!")
+		return &ctrl.Result{}
+	}
+	userFilter := ldapSearchFilter(ldapSpec.UserSearch, "(uid=%(user)s)")
+	if !strings.Contains(userFilter, "%(user)s") {
+		r.RawLogger.Error(nil, "spec.ldap.user_search.filter "+userFilter+" should have the %(user)s placeholder!")
+		return &ctrl.Result{}
+	}
+	for _, filter := range []string{ldapSpec.UserSearch.Filter, ldapSpec.GroupSearch.Filter} {
+		if err := validateLDAPFilter(filter); len(filter) > 0 && err != nil {
+			r.RawLogger.Error(err, "Invalid spec.ldap search filter "+filter)
+			return &ctrl.Result{}
+		}
+	}
+
+	if len(ldapSpec.GroupSearch.BaseDN) == 0 && (len(ldapSpec.GroupType) > 0 || len(ldapSpec.GroupNameAttr) > 0 || len(ldapSpec.GroupSearch.Filter) > 0 || ldapSpec.MirrorGroups) {
+		r.RawLogger.Error(nil, "spec.ldap group fields are defined but spec.ldap.group_search.base_dn was not found!")
+		return &ctrl.Result{}
+	}
+	for field := range ldapSpec.UserAttrMap {
+		if field != "first_name" && field != "last_name" && field != "email" {
+			r.RawLogger.Error(nil, "Invalid spec.ldap.user_attr_map field "+field+"! Only first_name, last_name and email are supported.")
+			return &ctrl.Result{}
+		}
+	}
+	return nil
+}
+
+// validateLDAPFilter verifies if an ldap search filter is enclosed in balanced parentheses
+func validateLDAPFilter(filter string) error {
+	if !strings.HasPrefix(filter, "(") || !strings.HasSuffix(filter, ")") {
+		return fmt.Errorf("the filter should be enclosed in parentheses")
+	}
+	depth := 0
+	for i, c := range filter {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		if depth < 0 || (depth == 0 && i < len(filter)-1) {
+			return fmt.Errorf("unbalanced parentheses")
+		}
+	}
+	if depth != 0 {
+		return fmt.Errorf("unbalanced parentheses")
+	}
+	return nil
+}
+
 // checkSigningScripts verifies if signing_script and/or signing_secret is/are defined
 func checkSigningScripts(r *RepoManagerReconciler, pulp *pulpv1.Pulp) *ctrl.Result {
-	if len(pulp.Spec.SigningScripts) > 0 && len(pulp.Spec.SigningSecret) == 0 {
+	if len(pulp.Spec.SigningScripts) > 0 && !controllers.SigningEnabled(pulp) {
 		r.RawLogger.Error(nil, "spec.signing_scripts is defined but spec.signing_secret was not found! Provide both values or none to avoid error in Pulp execution.")
 		return &ctrl.Result{}
 	}
-	if len(pulp.Spec.SigningScripts) == 0 && len(pulp.Spec.SigningSecret) > 0 {
+	if len(pulp.Spec.SigningScripts) == 0 && controllers.SigningEnabled(pulp) {
 		r.RawLogger.Error(nil, "spec.signing_secret is defined but spec.signing_scripts was not found! Provide both values or none to avoid error in Pulp execution.")
 		return &ctrl.Result{}
 	}
 
 	return nil
 }
+
+// checkSecretsStore verifies if the credentials provided by a SecretProviderClass are used by
+// Pulp (the other fields from the same configuration are still read from the Secret) and if the
+// SecretProviderClasses are available
+func checkSecretsStore(ctx context.Context, r *RepoManagerReconciler, pulp *pulpv1.Pulp) *ctrl.Result {
+	store := pulp.Spec.SecretsStore
+	required := []struct {
+		field, storeField, spc string
+		defined                bool
+	}{
+		{"database.external_db_secret", "external_db_secret", store.ExternalDBSecret, len(pulp.Spec.Database.ExternalDBSecret) > 0},
+		{"object_storage_s3_secret", "object_storage_s3_secret", store.ObjectStorageS3Secret, len(pulp.Spec.ObjectStorageS3Secret) > 0},
+		{"object_storage_azure_secret", "object_storage_azure_secret", store.ObjectStorageAzureSecret, len(pulp.Spec.ObjectStorageAzureSecret) > 0},
+		{"object_storage_gcs_secret", "object_storage_gcs_secret", store.ObjectStorageGCSSecret, len(pulp.Spec.ObjectStorageGCSSecret) > 0},
+		{"cache.external_cache_secret", "external_cache_secret", store.ExternalCacheSecret, pulp.Spec.Cache.Enabled && len(pulp.Spec.Cache.ExternalCacheSecret) > 0},
+		{"sso_secret", "sso_secret", store.SSOSecret, len(pulp.Spec.SSOSecret) > 0},
+		{"ldap", "ldap_bind_password_secret", store.LDAPBindPasswordSecret, controllers.LDAPEnabled(pulp)},
+		{"oidc.client_secret", "oidc_client_secret", store.OIDCClientSecret, controllers.OIDCEnabled(pulp) && len(pulp.Spec.OIDC.ClientSecret) > 0},
+	}
+	for _, source := range required {
+		if len(source.spc) > 0 && !source.defined {
+			r.RawLogger.Error(nil, "spec.secrets_store."+source.storeField+" is defined but spec."+source.field+" was not found! The SecretProviderClass provides only the credentials, the other configurations are still read from spec."+source.field+".")
+			return &ctrl.Result{}
+		}
+	}
+
+	if len(store.SigningSecret) > 0 {
+		if len(pulp.Spec.SigningSecret) > 0 {
+			r.RawLogger.Error(nil, "spec.signing_secret and spec.secrets_store.signing_secret are mutually exclusive! Provide only one of them.")
+			return &ctrl.Result{}
+		}
+		if len(store.SigningKeyFingerprint) == 0 {
+			r.RawLogger.Error(nil, "spec.secrets_store.signing_secret is defined but spec.secrets_store.signing_key_fingerprint was not found! The operator cannot read the signing key from the SecretProviderClass.")
+			return &ctrl.Result{}
+		}
+	}
+
+	for _, source := range []string{controllers.SecretsStoreDatabase, controllers.SecretsStoreS3, controllers.SecretsStoreAzure, controllers.SecretsStoreGCS, controllers.SecretsStoreCache, controllers.SecretsStoreSSO, controllers.SecretsStoreLDAP, controllers.SecretsStoreOIDC, controllers.SecretsStoreSigning} {
+		name := controllers.SecretsStoreClass(pulp, source)
+		if len(name) == 0 {
+			continue
+		}
+		spc := &unstructured.Unstructured{}
+		spc.SetGroupVersionKind(controllers.SecretProviderClassGVK)
+		if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: pulp.Namespace}, spc); err != nil {
+			r.RawLogger.Error(err, "SecretProviderClass "+name+" not found! Verify if the Secrets Store CSI driver is installed and if the SecretProviderClass is in "+pulp.Namespace+" namespace.")
+			return &ctrl.Result{RequeueAfter: time.Minute}
+		}
+	}
+
+	return nil
+}
+
+// checkOIDC verifies the oidc fields and if the OpenID provider configuration can be discovered from
+// the issuer_url. The issuer is validated only when it changes, the validated issuer is kept in .status.oidc_issuer.
+func checkOIDC(ctx context.Context, r *RepoManagerReconciler, pulp *pulpv1.Pulp) *ctrl.Result {
+	oidc := pulp.Spec.OIDC
+	if !controllers.OIDCEnabled(pulp) {
+		if len(oidc.ClientSecret) > 0 || len(oidc.ClaimMappings) > 0 {
+			r.RawLogger.Error(nil, "spec.oidc is defined but spec.oidc.issuer_url was not found!")
+			return &ctrl.Result{}
+		}
+		return nil
+	}
+
+	if len(pulp.Spec.SSOSecret) > 0 {
+		r.RawLogger.Error(nil, "spec.oidc and spec.sso_secret cannot be used together! Provide only one of them.")
+		return &ctrl.Result{}
+	}
+	if len(oidc.ClientSecret) == 0 {
+		r.RawLogger.Error(nil, "spec.oidc.issuer_url is defined but spec.oidc.client_secret was not found!")
+		return &ctrl.Result{}
+	}
+	// the client secret provided by a SecretProviderClass is read from the file mounted in the pods
+	keys := []string{"client_id", "client_secret"}
+	if len(controllers.SecretsStoreClass(pulp, controllers.SecretsStoreOIDC)) > 0 {
+		keys = []string{"client_id"}
+	}
+	if _, err := controllers.RetrieveSecretData(ctx, oidc.ClientSecret, pulp.Namespace, true, r.Client, keys...); err != nil {
+		r.RawLogger.Error(err, "The "+oidc.ClientSecret+" Secret should provide the "+strings.Join(keys, " and ")+" keys!")
+		return &ctrl.Result{}
+	}
+
+	if pulp.Status.OIDCIssuer == oidc.IssuerURL {
+		return nil
+	}
+	if err := validateOIDCIssuer(ctx, oidc.IssuerURL); err != nil {
+		r.RawLogger.Error(err, "Failed to discover the OpenID provider configuration from "+oidc.IssuerURL)
+		r.recorder.Event(pulp, corev1.EventTypeWarning, "Failed", "Invalid OIDC issuer "+oidc.IssuerURL)
+		return &ctrl.Result{RequeueAfter: time.Minute}
+	}
+	pulp.Status.OIDCIssuer = oidc.IssuerURL
+	if err := r.Status().Update(ctx, pulp); err != nil {
+		r.RawLogger.Error(err, "Failed to update pulp status with the OIDC issuer")
+		return &ctrl.Result{}
+	}
+	return nil
+}
+
+// nginxHeaderName matches the valid response header names
+var nginxHeaderName = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+// checkNginxDefinition verifies the web.nginx fields before rendering them into the pulp-web nginx.conf.
+// An invalid nginx.conf would make all pulp-web pods crash, so the operator fails early instead.
+func checkNginxDefinition(log logr.Logger, pulp *pulpv1.Pulp) *ctrl.Result {
+	nginx := pulp.Spec.Web.Nginx
+	for name, value := range nginx.Headers {
+		if !nginxHeaderName.MatchString(name) {
+			log.Error(nil, "Invalid header name \""+name+"\" in web.nginx.headers! Header names should only contain letters, numbers and hyphens.")
+			return &ctrl.Result{}
+		}
+		if strings.ContainsAny(value, "\"\\\n\r") {
+			log.Error(nil, "Invalid value for the "+name+" header in web.nginx.headers! Header values cannot contain quotes, backslashes or line breaks.")
+			return &ctrl.Result{}
+		}
+	}
+
+	for _, rateLimit := range nginx.RateLimits {
+		if strings.ContainsAny(rateLimit.Path, " \t\n\r{};\"'") {
+			log.Error(nil, "Invalid path \""+rateLimit.Path+"\" in web.nginx.rate_limits! Paths cannot contain whitespaces, quotes, braces or semicolons.")
+			return &ctrl.Result{}
+		}
+	}
+
+	for _, address := range append(append([]string{}, nginx.APIAllowList...), nginx.APIDenyList...) {
+		if net.ParseIP(address) == nil {
+			if _, _, err := net.ParseCIDR(address); err != nil {
+				log.Error(nil, "Invalid address \""+address+"\" in web.nginx.api_allow_list/api_deny_list! Provide an IP address or a CIDR.")
+				return &ctrl.Result{}
+			}
+		}
+	}
+
+	for field, snippet := range map[string]string{"http_snippet": nginx.HTTPSnippet, "server_snippet": nginx.ServerSnippet} {
+		if err := validateNginxSnippet(snippet); err != nil {
+			log.Error(err, "Invalid web.nginx."+field+"!")
+			return &ctrl.Result{}
+		}
+	}
+	return nil
+}
+
+// validateNginxSnippet verifies the structure of a raw nginx snippet: the quotes and braces
+// should be balanced (so the snippet cannot close the block it is rendered into) and every
+// directive should be terminated by a semicolon or a block.
+func validateNginxSnippet(snippet string) error {
+	depth := 0
+	var quote rune
+	comment, escaped := false, false
+	last := rune(0)
+	for _, c := range snippet {
+		switch {
+		case comment:
+			comment = c != '\n'
+			continue
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			comment = true
+			continue
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth < 0 {
+				return fmt.Errorf("unexpected \"}\"")
+			}
+		}
+		if !unicode.IsSpace(c) {
+			last = c
+		}
+	}
+	switch {
+	case quote != 0:
+		return fmt.Errorf("unterminated quoted string")
+	case depth > 0:
+		return fmt.Errorf("missing \"}\"")
+	case last != 0 && last != ';' && last != '}':
+		return fmt.Errorf("directive not terminated by \";\"")
+	}
+	return nil
+}
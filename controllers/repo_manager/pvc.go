@@ -18,17 +18,25 @@ package repo_manager
 
 import (
 	"context"
+	"strings"
 
 	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
 	"github.com/pulp/pulp-operator/controllers"
 	"github.com/pulp/pulp-operator/controllers/settings"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// volumeExpansionCondition reports the PVCs that could not be expanded
+const volumeExpansionCondition = "Pulp-Volume-Expansion"
+
 // pulpFileStorage will provision a PVC when spec.file_storage_storage_class is defined
 func (r *RepoManagerReconciler) pulpFileStorage(ctx context.Context, pulp *pulpv1.Pulp) (*ctrl.Result, error) {
 	if !storageClassProvided(pulp) {
@@ -42,9 +50,111 @@ func (r *RepoManagerReconciler) pulpFileStorage(ctx context.Context, pulp *pulpv
 		return &ctrl.Result{Requeue: true}, nil
 	}
 
+	if err := r.expandPVC(ctx, pulp, settings.DefaultPulpFileStorage(pulp.Name), pulp.Spec.FileStorageSize); err != nil {
+		return &ctrl.Result{}, err
+	}
+
 	return nil, nil
 }
 
+// expandPVC patches the storage request of a PVC when the size defined in Pulp CR
+// is bigger than the current one.
+// k8s does not support shrinking a PVC, so smaller sizes are ignored. PVCs from a
+// StorageClass that does not set allowVolumeExpansion are kept with their current size
+// and the Pulp-Volume-Expansion condition (and a single event for each size requested)
+// reports that they could not be expanded.
+func (r *RepoManagerReconciler) expandPVC(ctx context.Context, pulp *pulpv1.Pulp, pvcName, size string) error {
+	log := r.RawLogger
+	if len(size) == 0 {
+		return nil
+	}
+	expectedSize, err := resource.ParseQuantity(size)
+	if err != nil {
+		return nil
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.Get(ctx, types.NamespacedName{Name: pvcName, Namespace: pulp.Namespace}, pvc); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		log.Error(err, "Failed to get "+pvcName+" PVC")
+		return err
+	}
+
+	if !pvcExpansionNeeded(pvc, expectedSize) {
+		r.volumeExpansionCondition(ctx, pulp, pvcName, metav1.ConditionTrue, "VolumesExpanded", "")
+		return nil
+	}
+
+	storageClass := ""
+	if pvc.Spec.StorageClassName != nil {
+		storageClass = *pvc.Spec.StorageClassName
+	}
+	if !r.volumeExpansionAllowed(ctx, storageClass) {
+		message := "Could not expand " + pvcName + " PVC to " + expectedSize.String() + ": the " + storageClass + " StorageClass does not allow volume expansion"
+		r.volumeExpansionCondition(ctx, pulp, pvcName, metav1.ConditionFalse, "ExpansionNotAllowed", message)
+		return nil
+	}
+
+	currentSize := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	log.Info("Expanding "+pvcName+" PVC", "current", currentSize.String(), "expected", expectedSize.String())
+	patch := client.MergeFrom(pvc.DeepCopy())
+	pvc.Spec.Resources.Requests[corev1.ResourceStorage] = expectedSize
+	if err := r.Patch(ctx, pvc, patch); err != nil {
+		if errors.IsForbidden(err) || errors.IsInvalid(err) {
+			r.volumeExpansionCondition(ctx, pulp, pvcName, metav1.ConditionFalse, "ExpandFailed", "Failed to expand "+pvcName+" PVC to "+expectedSize.String()+": "+err.Error())
+			return nil
+		}
+		log.Error(err, "Failed to expand "+pvcName+" PVC")
+		return err
+	}
+	r.recorder.Event(pulp, corev1.EventTypeNormal, "Expanded", pvcName+" PVC expanded to "+expectedSize.String())
+	r.volumeExpansionCondition(ctx, pulp, pvcName, metav1.ConditionTrue, "VolumesExpanded", "")
+	return nil
+}
+
+// pvcExpansionNeeded returns true if the size requested is bigger than the PVC storage request
+func pvcExpansionNeeded(pvc *corev1.PersistentVolumeClaim, expectedSize resource.Quantity) bool {
+	currentSize := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	return expectedSize.Cmp(currentSize) > 0
+}
+
+// volumeExpansionAllowed returns true if the StorageClass sets allowVolumeExpansion
+func (r *RepoManagerReconciler) volumeExpansionAllowed(ctx context.Context, storageClassName string) bool {
+	if len(storageClassName) == 0 {
+		return false
+	}
+	storageClass := &storagev1.StorageClass{}
+	if err := r.Get(ctx, types.NamespacedName{Name: storageClassName}, storageClass); err != nil {
+		r.RawLogger.Error(err, "Failed to get "+storageClassName+" StorageClass")
+		return false
+	}
+	return storageClass.AllowVolumeExpansion != nil && *storageClass.AllowVolumeExpansion
+}
+
+// volumeExpansionCondition updates the Pulp-Volume-Expansion condition with the state of pvcName.
+// A failure is only reported (as a warning event) when the condition message changes, and the
+// condition is set back to True only by the PVC that failed to be expanded.
+func (r *RepoManagerReconciler) volumeExpansionCondition(ctx context.Context, pulp *pulpv1.Pulp, pvcName string, status metav1.ConditionStatus, reason, message string) {
+	current := v1.FindStatusCondition(pulp.Status.Conditions, volumeExpansionCondition)
+	if status == metav1.ConditionTrue {
+		if current == nil || current.Status == metav1.ConditionTrue || !strings.Contains(current.Message, " "+pvcName+" PVC ") {
+			return
+		}
+		message = "All the PVCs have the size requested"
+	} else if current != nil && current.Message == message {
+		return
+	}
+
+	v1.SetStatusCondition(&pulp.Status.Conditions, metav1.Condition{Type: volumeExpansionCondition, Status: status, Reason: reason, Message: message})
+	r.Status().Update(ctx, pulp)
+	if status == metav1.ConditionFalse {
+		r.RawLogger.Info(message)
+		r.recorder.Event(pulp, corev1.EventTypeWarning, reason, message)
+	}
+}
+
 // fileStoragePVC returns a PVC object
 func fileStoragePVC(resources controllers.FunctionResources) client.Object {
 
@@ -0,0 +1,121 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	v1 "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+// testPVC returns a PVC with the storage request and StorageClass provided
+func testPVC(name, size, storageClass string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "pulp"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &storageClass,
+			Resources:        corev1.VolumeResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)}},
+		},
+	}
+}
+
+func TestPVCExpansionNeeded(t *testing.T) {
+	tests := []struct {
+		current, expected string
+		needed            bool
+	}{
+		{"10Gi", "20Gi", true},
+		{"10Gi", "10Gi", false},
+		{"10Gi", "10240Mi", false},
+		{"10Gi", "5Gi", false},
+		{"1G", "1Gi", true},
+		{"1Gi", "1G", false},
+	}
+	for _, test := range tests {
+		pvc := testPVC("test", test.current, "standard")
+		if got := pvcExpansionNeeded(pvc, resource.MustParse(test.expected)); got != test.needed {
+			t.Errorf("pvcExpansionNeeded(%v -> %v) = %v, expected %v", test.current, test.expected, got, test.needed)
+		}
+	}
+}
+
+func TestExpandPVC(t *testing.T) {
+	ctx := context.TODO()
+	allowed, notAllowed := true, false
+	pulp := settingsTestPulp()
+	r := newTestReconciler(pulp,
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "expandable"}, AllowVolumeExpansion: &allowed},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "fixed"}, AllowVolumeExpansion: &notAllowed},
+		testPVC("test-file-storage", "10Gi", "fixed"),
+		testPVC("test-postgres", "5Gi", "expandable"),
+	)
+	recorder := r.recorder.(*record.FakeRecorder)
+	size := func(name string) string {
+		pvc := &corev1.PersistentVolumeClaim{}
+		r.Get(ctx, types.NamespacedName{Name: name, Namespace: "pulp"}, pvc)
+		quantity := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		return quantity.String()
+	}
+	condition := func() *metav1.Condition {
+		return v1.FindStatusCondition(pulp.Status.Conditions, volumeExpansionCondition)
+	}
+
+	// smaller and equal sizes are ignored
+	r.expandPVC(ctx, pulp, "test-file-storage", "5Gi")
+	r.expandPVC(ctx, pulp, "test-file-storage", "10Gi")
+	if condition() != nil || len(recorder.Events) != 0 || size("test-file-storage") != "10Gi" {
+		t.Fatalf("expected no expansion, got %v %v", condition(), size("test-file-storage"))
+	}
+
+	// the StorageClass does not allow volume expansion: the event is emitted only once
+	for range 3 {
+		r.expandPVC(ctx, pulp, "test-file-storage", "20Gi")
+	}
+	if c := condition(); c == nil || c.Status != metav1.ConditionFalse || c.Reason != "ExpansionNotAllowed" || size("test-file-storage") != "10Gi" {
+		t.Fatalf("expected the expansion not to be allowed, got %v", c)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("expected a single event, got %v", len(recorder.Events))
+	}
+	<-recorder.Events
+
+	// a new size is reported again
+	r.expandPVC(ctx, pulp, "test-file-storage", "30Gi")
+	if len(recorder.Events) != 1 {
+		t.Errorf("expected a new event for the new size, got %v", len(recorder.Events))
+	}
+	<-recorder.Events
+
+	// another PVC does not clear the condition from test-file-storage
+	r.expandPVC(ctx, pulp, "test-postgres", "8Gi")
+	if size("test-postgres") != "8Gi" || condition().Status != metav1.ConditionFalse {
+		t.Errorf("expected test-postgres to be expanded and the condition to be kept, got %v %v", size("test-postgres"), condition())
+	}
+
+	// the size is reverted
+	r.expandPVC(ctx, pulp, "test-file-storage", "10Gi")
+	if condition().Status != metav1.ConditionTrue {
+		t.Errorf("expected the condition to be cleared, got %v", condition())
+	}
+}
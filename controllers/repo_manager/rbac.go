@@ -25,6 +25,7 @@ import (
 	"github.com/pulp/pulp-operator/controllers/settings"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -38,7 +39,7 @@ func (r *RepoManagerReconciler) CreateServiceAccount(ctx context.Context, pulp *
 	serviceAccountName := settings.PulpServiceAccount(pulp.Name)
 	sa := &corev1.ServiceAccount{}
 	err := r.Get(ctx, types.NamespacedName{Name: serviceAccountName, Namespace: pulp.Namespace}, sa)
-	expectedSA := r.pulpSA(pulp)
+	expectedSA := r.pulpSA(ctx, pulp)
 	if err != nil && errors.IsNotFound(err) {
 		log.Info("Creating "+serviceAccountName+" ServiceAccount", "Namespace", expectedSA.Namespace, "Name", serviceAccountName)
 		controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, conditionType, "CreatingSA", "Creating "+serviceAccountName+" SA resource")
@@ -63,6 +64,33 @@ func (r *RepoManagerReconciler) CreateServiceAccount(ctx context.Context, pulp *
 		expectedSA.ImagePullSecrets = append([]corev1.LocalObjectReference{{Name: internalRegistrySecret}}, expectedSA.ImagePullSecrets...)
 	}
 
+	// the workload identity annotations need to be kept in sync with the object storage
+	// Secret, even with the SA reconciliation disabled, so that pulp pods can authenticate
+	// with the cloud provider
+	if workloadIdentityModified(sa, expectedSA) {
+		log.Info("Updating " + serviceAccountName + " SA workload identity annotations")
+		if sa.Annotations == nil {
+			sa.Annotations = map[string]string{}
+		}
+		for _, annotation := range []string{controllers.IRSARoleAnnotation, controllers.AzureWIClientIdAnnotation, controllers.AzureWITenantIdAnnotation} {
+			if value, found := expectedSA.Annotations[annotation]; found {
+				sa.Annotations[annotation] = value
+			} else {
+				delete(sa.Annotations, annotation)
+			}
+		}
+		if err := r.Update(ctx, sa); err != nil {
+			log.Error(err, "Error trying to update "+serviceAccountName+" SA!")
+			return ctrl.Result{}, err
+		}
+		r.recorder.Event(pulp, corev1.EventTypeNormal, "Updated", serviceAccountName+" SA workload identity annotations updated")
+
+		// the cloud provider credentials are injected (by the IRSA or Azure Workload Identity
+		// webhook) when the pods are created, so the pods need to be recreated to get them
+		r.restartPulpCorePods(ctx, pulp)
+		return ctrl.Result{Requeue: true}, nil
+	}
+
 	// Check and reconcile pulp-sa
 	// Temporarily disabling to prevent an infinite reconciliation loop issue in OCP 4.16.
 	/* 	if saModified(sa, expectedSA) {
@@ -101,6 +129,17 @@ func (r *RepoManagerReconciler) CreateRole(ctx context.Context, pulp *pulpv1.Pul
 		log.Error(err, "Failed to get Pulp Role")
 		return ctrl.Result{}, err
 	}
+
+	// Ensure the Role rules are as expected (new rules can be added in operator upgrades)
+	if !equality.Semantic.DeepEqual(role.Rules, expectedRole.Rules) {
+		log.Info("The " + pulp.Name + " Role has been modified! Reconciling ...")
+		role.Rules = expectedRole.Rules
+		if err := r.Update(ctx, role); err != nil {
+			log.Error(err, "Failed to update Pulp Role")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
 	return r.CreateRoleBinding(ctx, pulp)
 }
 
@@ -130,14 +169,23 @@ func (r *RepoManagerReconciler) CreateRoleBinding(ctx context.Context, pulp *pul
 	return ctrl.Result{}, nil
 }
 
-func (r *RepoManagerReconciler) pulpSA(m *pulpv1.Pulp) *corev1.ServiceAccount {
+func (r *RepoManagerReconciler) pulpSA(ctx context.Context, m *pulpv1.Pulp) *corev1.ServiceAccount {
 	var imagePullSecrets []corev1.LocalObjectReference
 
 	for _, pullSecret := range m.Spec.ImagePullSecrets {
 		imagePullSecrets = append(imagePullSecrets, corev1.LocalObjectReference{Name: pullSecret})
 	}
 
-	annotations := m.Spec.SAAnnotations
+	annotations := map[string]string{}
+	for k, v := range m.Spec.SAAnnotations {
+		annotations[k] = v
+	}
+
+	// annotations used by the cloud providers to federate pulp SA with an
+	// IAM role (IRSA) or a managed identity (Azure Workload Identity)
+	for k, v := range controllers.WorkloadIdentityAnnotations(ctx, r.Client, m) {
+		annotations[k] = v
+	}
 	labels := m.Spec.SALabels
 	if labels == nil {
 		labels = make(map[string]string)
@@ -160,6 +208,17 @@ func (r *RepoManagerReconciler) pulpSA(m *pulpv1.Pulp) *corev1.ServiceAccount {
 	return sa
 }
 
+// workloadIdentityModified returns true if the workload identity annotations from
+// the current SA differ from the expected
+func workloadIdentityModified(currentSA, expectedSA *corev1.ServiceAccount) bool {
+	for _, annotation := range []string{controllers.IRSARoleAnnotation, controllers.AzureWIClientIdAnnotation, controllers.AzureWITenantIdAnnotation} {
+		if currentSA.Annotations[annotation] != expectedSA.Annotations[annotation] {
+			return true
+		}
+	}
+	return false
+}
+
 // getInternalRegistrySecret gets the imagePullSecret for the internal registry that is created
 // and added to the SA in OCP environments based on pattern:
 //
@@ -202,6 +261,13 @@ func (r *RepoManagerReconciler) pulpRole(m *pulpv1.Pulp) *rbacv1.Role {
 				Resources: []string{"secrets"},
 				Verbs:     []string{"get", "create", "delete"},
 			},
+			{
+				// the plugin paths Job stores the paths discovered in this ConfigMap
+				APIGroups:     []string{""},
+				Resources:     []string{"configmaps"},
+				ResourceNames: []string{settings.PluginPathsConfigMap(m.Name)},
+				Verbs:         []string{"get", "patch"},
+			},
 		},
 	}
 }
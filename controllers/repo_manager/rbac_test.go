@@ -0,0 +1,74 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pulp/pulp-operator/controllers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestWorkloadIdentityAnnotationsModified(t *testing.T) {
+	ctx := context.TODO()
+	pulp := settingsTestPulp()
+	pulp.Spec.ObjectStorageS3Secret = "test-s3"
+	s3Secret := settingsTestSecret("test-s3", map[string]string{
+		"s3-bucket-name": "pulp",
+		"s3-region":      "us-east-1",
+		"s3-role-arn":    "arn:aws:iam::123456789012:role/pulp",
+	})
+	sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "pulp", Annotations: map[string]string{"custom": "value"}}}
+	r := newTestReconciler(pulp, s3Secret, sa)
+	serviceAccount := func() *corev1.ServiceAccount {
+		sa := &corev1.ServiceAccount{}
+		r.Get(ctx, types.NamespacedName{Name: "test", Namespace: "pulp"}, sa)
+		return sa
+	}
+
+	// the role annotation is added and the pods are restarted to get the credentials
+	if result, err := r.CreateServiceAccount(ctx, pulp); err != nil || !result.Requeue {
+		t.Fatalf("expected the SA to be updated, got %v (%v)", result, err)
+	}
+	annotations := serviceAccount().Annotations
+	if annotations[controllers.IRSARoleAnnotation] != "arn:aws:iam::123456789012:role/pulp" || annotations["custom"] != "value" {
+		t.Errorf("unexpected SA annotations %v", annotations)
+	}
+	if len(pulp.Status.LastDeploymentUpdate) == 0 {
+		t.Errorf("expected the pulpcore pods to be restarted after the role modification")
+	}
+
+	// the pods are not restarted again while the annotations are in sync
+	pulp.Status.LastDeploymentUpdate = ""
+	r.CreateServiceAccount(ctx, pulp)
+	if len(pulp.Status.LastDeploymentUpdate) > 0 {
+		t.Errorf("the pulpcore pods should not be restarted without modifications")
+	}
+
+	// and they are restarted again when the role is modified
+	s3Secret.Data["s3-role-arn"] = []byte("arn:aws:iam::123456789012:role/pulp-new")
+	if err := r.Update(ctx, s3Secret); err != nil {
+		t.Fatal(err)
+	}
+	r.CreateServiceAccount(ctx, pulp)
+	if role := serviceAccount().Annotations[controllers.IRSARoleAnnotation]; role != "arn:aws:iam::123456789012:role/pulp-new" || len(pulp.Status.LastDeploymentUpdate) == 0 {
+		t.Errorf("expected the new role (%v) and the pulpcore pods to be restarted", role)
+	}
+}
@@ -26,6 +26,16 @@ func (r *RepoManagerReconciler) pulpCacheController(ctx context.Context, pulp *p
 	conditionType := "Pulp-API-Ready"
 	funcResources := controllers.FunctionResources{Context: ctx, Client: r.Client, Pulp: pulp, Scheme: r.Scheme, Logger: log}
 
+	// replicated Redis monitored by Sentinel
+	if controllers.CacheSentinelEnabled(*pulp) {
+		return r.pulpCacheSentinelController(ctx, pulp, log)
+	}
+
+	// remove the resources from a previous sentinel deployment in case cache.mode has been modified
+	if pulp.Status.CacheMode == controllers.CacheSentinelMode {
+		r.removeSentinelCache(ctx, pulp, log)
+	}
+
 	// pulp-redis-data PVC
 	// the PVC will be created only if a StorageClassName is provided
 	if _, storageType := controllers.MultiStorageConfigured(pulp, "Cache"); storageType[0] == controllers.SCNameType {
@@ -131,6 +141,7 @@ func (r *RepoManagerReconciler) pulpCacheController(ctx context.Context, pulp *p
 
 	// Update managedCache status
 	pulp.Status.ManagedCacheEnabled = pulp.Spec.Cache.Enabled
+	pulp.Status.CacheMode = controllers.CacheStandaloneMode
 	r.Status().Update(ctx, pulp)
 
 	r.recorder.Event(pulp, corev1.EventTypeNormal, "RedisReady", "All Redis tasks ran successfully")
@@ -308,13 +319,6 @@ func redisDeployment(m *pulpv1.Pulp, funcResources controllers.FunctionResources
 		}
 	}
 
-	redisImage := os.Getenv("RELATED_IMAGE_PULP_REDIS")
-	if len(m.Spec.Cache.RedisImage) > 0 {
-		redisImage = m.Spec.Cache.RedisImage
-	} else if redisImage == "" {
-		redisImage = "docker.io/library/redis:latest"
-	}
-
 	resources := m.Spec.Cache.RedisResourceRequirements
 
 	removeStorageDefinition(&resources)
@@ -329,19 +333,6 @@ func redisDeployment(m *pulpv1.Pulp, funcResources controllers.FunctionResources
 	deploymentAnnotations["ignore-check.kube-linter.io/unset-memory-requirements"] = "Temporarily disabled"
 	deploymentAnnotations["ignore-check.kube-linter.io/no-node-affinity"] = "Do not check node affinity"
 
-	podSecurityContext := &corev1.PodSecurityContext{}
-	if isOpenshift, _ := controllers.IsOpenShift(); !isOpenshift {
-		runAsUser := int64(999)
-		fsGroup := int64(999)
-		fsGroupChangeOnRootMismatch := corev1.FSGroupChangeOnRootMismatch
-		podSecurityContext = &corev1.PodSecurityContext{
-			RunAsUser:           &runAsUser,
-			RunAsGroup:          &fsGroup,
-			FSGroup:             &fsGroup,
-			FSGroupChangePolicy: &fsGroupChangeOnRootMismatch,
-		}
-	}
-
 	// deployment definition
 	dep := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
@@ -365,10 +356,10 @@ func redisDeployment(m *pulpv1.Pulp, funcResources controllers.FunctionResources
 					NodeSelector:       nodeSelector,
 					Tolerations:        toleration,
 					ServiceAccountName: settings.PulpServiceAccount(m.Name),
-					SecurityContext:    podSecurityContext,
+					SecurityContext:    redisPodSecurityContext(),
 					Containers: []corev1.Container{{
 						Name:            "redis",
-						Image:           redisImage,
+						Image:           redisImage(m),
 						ImagePullPolicy: corev1.PullPolicy("IfNotPresent"),
 						VolumeMounts:    volumeMounts,
 						Ports: []corev1.ContainerPort{{
@@ -391,6 +382,34 @@ func redisDeployment(m *pulpv1.Pulp, funcResources controllers.FunctionResources
 	return dep
 }
 
+// redisImage returns the image used by Redis containers
+func redisImage(m *pulpv1.Pulp) string {
+	image := os.Getenv("RELATED_IMAGE_PULP_REDIS")
+	if len(m.Spec.Cache.RedisImage) > 0 {
+		image = m.Spec.Cache.RedisImage
+	} else if image == "" {
+		image = "docker.io/library/redis:latest"
+	}
+	return image
+}
+
+// redisPodSecurityContext returns the pod SecurityContext used by Redis pods
+func redisPodSecurityContext() *corev1.PodSecurityContext {
+	podSecurityContext := &corev1.PodSecurityContext{}
+	if isOpenshift, _ := controllers.IsOpenShift(); !isOpenshift {
+		runAsUser := int64(999)
+		fsGroup := int64(999)
+		fsGroupChangeOnRootMismatch := corev1.FSGroupChangeOnRootMismatch
+		podSecurityContext = &corev1.PodSecurityContext{
+			RunAsUser:           &runAsUser,
+			RunAsGroup:          &fsGroup,
+			FSGroup:             &fsGroup,
+			FSGroupChangePolicy: &fsGroupChangeOnRootMismatch,
+		}
+	}
+	return podSecurityContext
+}
+
 // removeStorageDefinition ensures that no storage definition is present in resourceRequirements
 // we need to get rid of it because cache.redis_resource_requirements is a corev1.ResourceRequirements (which can contain storage definition)
 // but storage is not a valid value for container resources
@@ -424,8 +443,12 @@ func (r *RepoManagerReconciler) deprovisionCache(ctx context.Context, pulp *pulp
 		r.Delete(ctx, deploymentFound)
 	}
 
+	// redis StatefulSet and sentinel resources
+	r.removeSentinelCache(ctx, pulp, log)
+
 	// Update managedCache status
 	pulp.Status.ManagedCacheEnabled = pulp.Spec.Cache.Enabled
+	pulp.Status.CacheMode = ""
 	r.Status().Update(ctx, pulp)
 
 	return ctrl.Result{}, nil
@@ -0,0 +1,508 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/settings"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultCacheReplicas          = int32(3)
+	defaultSentinelReplicas       = int32(3)
+	defaultSentinelMasterName     = "pulp"
+	defaultSentinelDownAfterMilis = 5000
+)
+
+// pulpCacheSentinelController provisions and reconciles a replicated Redis (StatefulSet)
+// monitored by Redis Sentinel pods
+func (r *RepoManagerReconciler) pulpCacheSentinelController(ctx context.Context, pulp *pulpv1.Pulp, log logr.Logger) (ctrl.Result, error) {
+
+	// conditionType is used to update .status.conditions with the current resource state
+	conditionType := "Pulp-API-Ready"
+	funcResources := controllers.FunctionResources{Context: ctx, Client: r.Client, Pulp: pulp, Scheme: r.Scheme, Logger: log}
+
+	// remove the standalone Redis resources in case cache.mode has been modified
+	if pulp.Status.CacheMode != controllers.CacheSentinelMode {
+		r.removeStandaloneCache(ctx, pulp, log)
+	}
+
+	statefulSetName := settings.CacheStatefulSet(pulp.Name)
+	headlessSvcName := settings.CacheHeadlessService(pulp.Name)
+	sentinelSvcName := settings.CacheSentinelService(pulp.Name)
+	sentinelDeploymentName := settings.SENTINEL.DeploymentName(pulp.Name)
+
+	// list of redis sentinel resources that should be provisioned
+	resources := []ApiResource{
+		{ResourceDefinition{ctx, &corev1.Service{}, headlessSvcName, "Cache", conditionType, pulp}, redisHeadlessSvc},
+		{ResourceDefinition{ctx, &corev1.Service{}, sentinelSvcName, "CacheSentinel", conditionType, pulp}, redisSentinelSvc},
+		{ResourceDefinition{ctx, &appsv1.StatefulSet{}, statefulSetName, "Cache", conditionType, pulp}, redisStatefulSet},
+		{ResourceDefinition{ctx, &appsv1.Deployment{}, sentinelDeploymentName, "CacheSentinel", conditionType, pulp}, redisSentinelDeployment},
+	}
+
+	for _, resource := range resources {
+		requeue, err := r.createPulpResource(resource.Definition, resource.Function)
+		if err != nil {
+			return ctrl.Result{}, err
+		} else if requeue {
+			return ctrl.Result{Requeue: true}, nil
+		}
+	}
+
+	// Reconcile Services
+	for svcName, svcFunc := range map[string]func(controllers.FunctionResources) client.Object{headlessSvcName: redisHeadlessSvc, sentinelSvcName: redisSentinelSvc} {
+		svc := &corev1.Service{}
+		r.Get(ctx, types.NamespacedName{Name: svcName, Namespace: pulp.Namespace}, svc)
+		expectedSvc := svcFunc(funcResources)
+		if requeue, err := controllers.ReconcileObject(funcResources, expectedSvc, svc, conditionType, controllers.PulpService{}); err != nil || requeue {
+			return ctrl.Result{Requeue: requeue}, err
+		}
+	}
+
+	// Reconcile StatefulSet
+	sts := &appsv1.StatefulSet{}
+	r.Get(ctx, types.NamespacedName{Name: statefulSetName, Namespace: pulp.Namespace}, sts)
+	expectedSts := redisStatefulSet(funcResources).(*appsv1.StatefulSet)
+	if !equality.Semantic.DeepDerivative(expectedSts.Spec, sts.Spec) {
+		log.Info("The " + statefulSetName + " StatefulSet has been modified! Reconciling ...")
+		r.recorder.Event(pulp, corev1.EventTypeNormal, "Updating", "Reconciling "+statefulSetName+" StatefulSet")
+		if err := r.Update(ctx, expectedSts); err != nil {
+			log.Error(err, "Error trying to update the "+statefulSetName+" StatefulSet object ... ")
+			r.recorder.Event(pulp, corev1.EventTypeWarning, "Failed", "Failed to reconcile "+statefulSetName+" StatefulSet")
+			return ctrl.Result{}, err
+		}
+		r.recorder.Event(pulp, corev1.EventTypeNormal, "Updated", statefulSetName+" StatefulSet reconciled")
+		return ctrl.Result{Requeue: true, RequeueAfter: time.Second}, nil
+	}
+
+	// Reconcile Sentinel Deployment
+	sentinelDeployment := &appsv1.Deployment{}
+	r.Get(ctx, types.NamespacedName{Name: sentinelDeploymentName, Namespace: pulp.Namespace}, sentinelDeployment)
+	expectedDeployment := redisSentinelDeployment(funcResources)
+	if requeue, err := controllers.ReconcileObject(funcResources, expectedDeployment, sentinelDeployment, conditionType, controllers.PulpDeployment{}); err != nil || requeue {
+		return ctrl.Result{Requeue: requeue}, err
+	}
+
+	// Update managedCache status
+	pulp.Status.ManagedCacheEnabled = pulp.Spec.Cache.Enabled
+	pulp.Status.CacheMode = controllers.CacheSentinelMode
+	r.Status().Update(ctx, pulp)
+
+	r.recorder.Event(pulp, corev1.EventTypeNormal, "RedisReady", "All Redis tasks ran successfully")
+	return ctrl.Result{}, nil
+}
+
+// redisHeadlessSvc returns the headless Service used to provide a stable network identity to Redis pods
+func redisHeadlessSvc(resources controllers.FunctionResources) client.Object {
+	m := resources.Pulp
+	labels := labelsForCache(m)
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      settings.CacheHeadlessService(m.Name),
+			Namespace: m.Namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Selector:  labels,
+			// Redis replicas and Sentinel need to resolve the pods addresses before they are ready
+			PublishNotReadyAddresses: true,
+			Ports: []corev1.ServicePort{{
+				Port:       6379,
+				Protocol:   corev1.ProtocolTCP,
+				TargetPort: intstr.IntOrString{IntVal: 6379},
+				Name:       "redis-6379",
+			}},
+		},
+	}
+	ctrl.SetControllerReference(m, svc, resources.Scheme)
+	return svc
+}
+
+// redisSentinelSvc returns the Service used to reach the Sentinel pods
+func redisSentinelSvc(resources controllers.FunctionResources) client.Object {
+	m := resources.Pulp
+	labels := labelsForSentinel(m)
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      settings.CacheSentinelService(m.Name),
+			Namespace: m.Namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: labels,
+			Ports: []corev1.ServicePort{{
+				Port:       controllers.SentinelPort,
+				Protocol:   corev1.ProtocolTCP,
+				TargetPort: intstr.IntOrString{IntVal: controllers.SentinelPort},
+				Name:       "sentinel-26379",
+			}},
+		},
+	}
+	ctrl.SetControllerReference(m, svc, resources.Scheme)
+	return svc
+}
+
+// redisStatefulSet returns the Redis StatefulSet deployed in sentinel mode
+func redisStatefulSet(resources controllers.FunctionResources) client.Object {
+	m := resources.Pulp
+	ls := labelsForCache(m)
+	replicas := cacheReplicas(m)
+
+	affinity := &corev1.Affinity{}
+	if m.Spec.Cache.Affinity != nil {
+		affinity = m.Spec.Cache.Affinity
+	}
+	nodeSelector := map[string]string{}
+	if m.Spec.Cache.NodeSelector != nil {
+		nodeSelector = m.Spec.Cache.NodeSelector
+	}
+	toleration := []corev1.Toleration{}
+	if m.Spec.Cache.Tolerations != nil {
+		toleration = m.Spec.Cache.Tolerations
+	}
+
+	// each Redis pod gets its own PVC if a StorageClass is provided,
+	// otherwise the data will be stored in an emptyDir
+	volumeName := m.Name + "-redis-data"
+	volumes := []corev1.Volume{}
+	volumeClaimTemplate := []corev1.PersistentVolumeClaim{}
+	if len(m.Spec.Cache.RedisStorageClass) > 0 {
+		pvc := redisDataPVC(m)
+		volumeClaimTemplate = append(volumeClaimTemplate, corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: volumeName},
+			Spec:       pvc.Spec,
+		})
+	} else {
+		volumes = append(volumes, corev1.Volume{
+			Name:         volumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+	}
+
+	probe := &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"/bin/sh", "-c", "redis-cli -h 127.0.0.1 -p 6379 ping"},
+			},
+		},
+		InitialDelaySeconds: 5,
+		PeriodSeconds:       5,
+		TimeoutSeconds:      5,
+		FailureThreshold:    5,
+		SuccessThreshold:    1,
+	}
+	readinessProbe := m.Spec.Cache.ReadinessProbe
+	if readinessProbe == nil {
+		readinessProbe = probe
+	}
+	livenessProbe := m.Spec.Cache.LivenessProbe
+	if livenessProbe == nil {
+		livenessProbe = probe
+	}
+
+	containerResources := m.Spec.Cache.RedisResourceRequirements
+	removeStorageDefinition(&containerResources)
+
+	// on startup, ask Sentinel for the current master and join it as a replica.
+	// If no master is known yet (first deployment), the first pod will be the master.
+	domain := settings.CacheHeadlessService(m.Name) + "." + m.Namespace + ".svc.cluster.local"
+	args := []string{
+		"-c",
+		`SELF="$(hostname).` + domain + `"
+MASTER=$(redis-cli -h ` + settings.CacheSentinelService(m.Name) + ` -p ` + strconv.Itoa(controllers.SentinelPort) + ` sentinel get-master-addr-by-name ` + sentinelMasterName(m) + ` 2>/dev/null | head -n 1)
+if [ -z "$MASTER" ]; then
+  MASTER="` + controllers.DefaultCacheMaster(*m) + `"
+fi
+if [ "$MASTER" = "$SELF" ]; then
+  exec redis-server --port 6379 --dir /data --replica-announce-ip "$SELF"
+fi
+exec redis-server --port 6379 --dir /data --replica-announce-ip "$SELF" --replicaof "$MASTER" 6379`,
+	}
+
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      settings.CacheStatefulSet(m.Name),
+			Namespace: m.Namespace,
+			Labels:    ls,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:            &replicas,
+			ServiceName:         settings.CacheHeadlessService(m.Name),
+			PodManagementPolicy: appsv1.ParallelPodManagement,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: ls,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: ls,
+				},
+				Spec: corev1.PodSpec{
+					Affinity:           affinity,
+					NodeSelector:       nodeSelector,
+					Tolerations:        toleration,
+					ServiceAccountName: settings.PulpServiceAccount(m.Name),
+					SecurityContext:    redisPodSecurityContext(),
+					Containers: []corev1.Container{{
+						Name:            "redis",
+						Image:           redisImage(m),
+						ImagePullPolicy: corev1.PullPolicy("IfNotPresent"),
+						Command:         []string{"/bin/sh"},
+						Args:            args,
+						VolumeMounts: []corev1.VolumeMount{{
+							MountPath: "/data",
+							Name:      volumeName,
+						}},
+						Ports: []corev1.ContainerPort{{
+							ContainerPort: 6379,
+							Protocol:      "TCP",
+						}},
+						LivenessProbe:   livenessProbe,
+						ReadinessProbe:  readinessProbe,
+						Resources:       containerResources,
+						SecurityContext: controllers.SetDefaultSecurityContext(),
+					}},
+					Volumes: volumes,
+				},
+			},
+			VolumeClaimTemplates: volumeClaimTemplate,
+		},
+	}
+	ctrl.SetControllerReference(m, sts, resources.Scheme)
+	return sts
+}
+
+// redisSentinelDeployment returns the Redis Sentinel Deployment
+func redisSentinelDeployment(resources controllers.FunctionResources) client.Object {
+	m := resources.Pulp
+	ls := labelsForSentinel(m)
+	replicas := sentinelReplicas(m)
+	masterName := sentinelMasterName(m)
+	port := strconv.Itoa(controllers.SentinelPort)
+
+	image := m.Spec.Cache.Sentinel.Image
+	if len(image) == 0 {
+		image = redisImage(m)
+	}
+
+	downAfter := m.Spec.Cache.Sentinel.DownAfterMilliseconds
+	if downAfter == 0 {
+		downAfter = defaultSentinelDownAfterMilis
+	}
+
+	// spread the sentinel pods in the same way as the redis pods
+	affinity := &corev1.Affinity{}
+	if m.Spec.Cache.Affinity != nil {
+		affinity = m.Spec.Cache.Affinity
+	}
+	nodeSelector := map[string]string{}
+	if m.Spec.Cache.NodeSelector != nil {
+		nodeSelector = m.Spec.Cache.NodeSelector
+	}
+	toleration := []corev1.Toleration{}
+	if m.Spec.Cache.Tolerations != nil {
+		toleration = m.Spec.Cache.Tolerations
+	}
+
+	probe := &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"/bin/sh", "-c", "redis-cli -h 127.0.0.1 -p " + port + " ping"},
+			},
+		},
+		InitialDelaySeconds: 5,
+		PeriodSeconds:       5,
+		TimeoutSeconds:      5,
+		FailureThreshold:    5,
+		SuccessThreshold:    1,
+	}
+
+	// sentinel needs a writable configuration file, so we are generating it
+	// in an emptyDir. If other sentinels are already running, we will
+	// monitor the master they agreed on.
+	args := []string{
+		"-c",
+		`MASTER=$(redis-cli -h ` + settings.CacheSentinelService(m.Name) + ` -p ` + port + ` sentinel get-master-addr-by-name ` + masterName + ` 2>/dev/null | head -n 1)
+if [ -z "$MASTER" ]; then
+  MASTER="` + controllers.DefaultCacheMaster(*m) + `"
+fi
+cat > /data/sentinel.conf <<EOF
+port ` + port + `
+dir /data
+sentinel resolve-hostnames yes
+sentinel announce-hostnames yes
+sentinel monitor ` + masterName + ` $MASTER 6379 ` + strconv.Itoa(int(replicas/2+1)) + `
+sentinel down-after-milliseconds ` + masterName + ` ` + strconv.Itoa(downAfter) + `
+sentinel failover-timeout ` + masterName + ` 60000
+sentinel parallel-syncs ` + masterName + ` 1
+EOF
+exec redis-sentinel /data/sentinel.conf`,
+	}
+
+	strategy := appsv1.DeploymentStrategy{Type: appsv1.RollingUpdateDeploymentStrategyType}
+
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      settings.SENTINEL.DeploymentName(m.Name),
+			Namespace: m.Namespace,
+			Labels:    ls,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Strategy: strategy,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: ls,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: ls,
+				},
+				Spec: corev1.PodSpec{
+					Affinity:           affinity,
+					NodeSelector:       nodeSelector,
+					Tolerations:        toleration,
+					ServiceAccountName: settings.PulpServiceAccount(m.Name),
+					SecurityContext:    redisPodSecurityContext(),
+					Containers: []corev1.Container{{
+						Name:            "sentinel",
+						Image:           image,
+						ImagePullPolicy: corev1.PullPolicy("IfNotPresent"),
+						Command:         []string{"/bin/sh"},
+						Args:            args,
+						VolumeMounts: []corev1.VolumeMount{{
+							MountPath: "/data",
+							Name:      "sentinel-data",
+						}},
+						Ports: []corev1.ContainerPort{{
+							ContainerPort: controllers.SentinelPort,
+							Protocol:      "TCP",
+						}},
+						LivenessProbe:   probe,
+						ReadinessProbe:  probe,
+						Resources:       m.Spec.Cache.Sentinel.ResourceRequirements,
+						SecurityContext: controllers.SetDefaultSecurityContext(),
+					}},
+					Volumes: []corev1.Volume{{
+						Name:         "sentinel-data",
+						VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+					}},
+				},
+			},
+		},
+	}
+
+	controllers.AddHashLabel(resources, dep)
+	ctrl.SetControllerReference(m, dep, resources.Scheme)
+	return dep
+}
+
+// removeStandaloneCache removes the Redis Deployment and Service deployed in standalone mode
+func (r *RepoManagerReconciler) removeStandaloneCache(ctx context.Context, pulp *pulpv1.Pulp, log logr.Logger) {
+	objects := map[string]client.Object{
+		settings.CacheService(pulp.Name):         &corev1.Service{},
+		settings.CACHE.DeploymentName(pulp.Name): &appsv1.Deployment{},
+	}
+	r.removeCacheObjects(ctx, pulp, log, objects)
+}
+
+// removeSentinelCache removes the Redis StatefulSet and Sentinel resources deployed in sentinel mode
+func (r *RepoManagerReconciler) removeSentinelCache(ctx context.Context, pulp *pulpv1.Pulp, log logr.Logger) {
+	objects := map[string]client.Object{
+		settings.CacheHeadlessService(pulp.Name):    &corev1.Service{},
+		settings.CacheSentinelService(pulp.Name):    &corev1.Service{},
+		settings.CacheStatefulSet(pulp.Name):        &appsv1.StatefulSet{},
+		settings.SENTINEL.DeploymentName(pulp.Name): &appsv1.Deployment{},
+	}
+	r.removeCacheObjects(ctx, pulp, log, objects)
+}
+
+// removeCacheObjects deletes the cache objects found
+func (r *RepoManagerReconciler) removeCacheObjects(ctx context.Context, pulp *pulpv1.Pulp, log logr.Logger, objects map[string]client.Object) {
+	for name, obj := range objects {
+		err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: pulp.Namespace}, obj)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			log.Error(err, "Failed to get "+name)
+			continue
+		}
+		log.Info("Removing Redis resource", "Namespace", pulp.Namespace, "Name", name)
+		if err := r.Delete(ctx, obj); err != nil {
+			log.Error(err, "Failed to remove "+name)
+		}
+	}
+}
+
+// cachePDB returns the PodDisruptionBudgetSpec for the redis or sentinel pods.
+// If cache is not deployed in sentinel mode it will return nil to remove any
+// PDB previously created.
+func cachePDB(pulp *pulpv1.Pulp, pdb *policy.PodDisruptionBudgetSpec) *policy.PodDisruptionBudgetSpec {
+	if !controllers.CacheSentinelEnabled(*pulp) {
+		return nil
+	}
+	if pdb != nil {
+		return pdb
+	}
+	maxUnavailable := intstr.FromInt32(1)
+	return &policy.PodDisruptionBudgetSpec{MaxUnavailable: &maxUnavailable}
+}
+
+// labelsForSentinel returns the labels for selecting the Sentinel resources
+// belonging to the given pulp CR name.
+func labelsForSentinel(m *pulpv1.Pulp) map[string]string {
+	return settings.PulpcoreLabels(*m, "redis-sentinel")
+}
+
+// cacheReplicas returns the number of Redis pods deployed in sentinel mode
+func cacheReplicas(m *pulpv1.Pulp) int32 {
+	if m.Spec.Cache.Replicas == 0 {
+		return defaultCacheReplicas
+	}
+	return m.Spec.Cache.Replicas
+}
+
+// sentinelReplicas returns the number of Sentinel pods
+func sentinelReplicas(m *pulpv1.Pulp) int32 {
+	if m.Spec.Cache.Sentinel.Replicas == 0 {
+		return defaultSentinelReplicas
+	}
+	return m.Spec.Cache.Sentinel.Replicas
+}
+
+// sentinelMasterName returns the name of the master group monitored by Sentinel
+func sentinelMasterName(m *pulpv1.Pulp) string {
+	if len(m.Spec.Cache.Sentinel.MasterName) == 0 {
+		return defaultSentinelMasterName
+	}
+	return m.Spec.Cache.Sentinel.MasterName
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRedisSentinelObjects(t *testing.T) {
+	scheme := runtime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	pulpv1.AddToScheme(scheme)
+	pulp := &pulpv1.Pulp{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "pulp"},
+		Spec: pulpv1.PulpSpec{Cache: pulpv1.Cache{
+			Enabled:  true,
+			Mode:     controllers.CacheSentinelMode,
+			Sentinel: pulpv1.Sentinel{MasterName: "cache"},
+		}},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pulp).Build()
+	resources := controllers.FunctionResources{Context: context.TODO(), Client: k8sClient, Pulp: pulp, Scheme: scheme}
+
+	// the redis pods join the master known by Sentinel
+	sts := redisStatefulSet(resources).(*appsv1.StatefulSet)
+	script := sts.Spec.Template.Spec.Containers[0].Args[1]
+	if !strings.Contains(script, "redis-cli -h test-redis-sentinel-svc -p 26379 sentinel get-master-addr-by-name cache") {
+		t.Errorf("expected the redis pods to ask Sentinel for the master, got %v", script)
+	}
+	if !strings.Contains(script, `MASTER="test-redis-0.test-redis-headless.pulp.svc.cluster.local"`) {
+		t.Errorf("expected the first redis pod as the initial master, got %v", script)
+	}
+
+	// the sentinel pods only run sentinel
+	dep := redisSentinelDeployment(resources).(*appsv1.Deployment)
+	containers := dep.Spec.Template.Spec.Containers
+	if len(containers) != 1 || containers[0].Name != "sentinel" {
+		t.Fatalf("expected only the sentinel container in the Sentinel pods, got %v", containers)
+	}
+	if !strings.Contains(containers[0].Args[1], "sentinel monitor cache $MASTER 6379 2") {
+		t.Errorf("expected Sentinel to monitor the cache master with a quorum of 2, got %v", containers[0].Args[1])
+	}
+}
@@ -18,13 +18,12 @@ package repo_manager
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 	"strconv"
 	"strings"
 
 	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
 	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/pysettings"
 	"github.com/pulp/pulp-operator/controllers/settings"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -121,40 +120,47 @@ func (r *RepoManagerReconciler) createSecrets(ctx context.Context, pulp *pulpv1.
 func pulpServerSecret(resources controllers.FunctionResources) client.Object {
 
 	pulp := resources.Pulp
-	pulp_settings := controllers.DotNotEditMessage
+	pulpSettings := pysettings.New()
+	pulpSettings.Code(controllers.DotNotEditMessage)
 
 	// add custom settings to the secret
-	customSettings := addCustomPulpSettings(resources, &pulp_settings)
+	customSettings := addCustomPulpSettings(resources, pulpSettings)
 
 	// pulpcore debug log
-	debugLogging(resources, &pulp_settings)
+	debugLogging(resources, pulpSettings)
 
 	// db settings
-	databaseSettings(resources, &pulp_settings, customSettings)
+	databaseSettings(resources, pulpSettings, customSettings)
 
 	// add cache settings
-	cacheSettings(resources, &pulp_settings)
+	cacheSettings(resources, pulpSettings)
 
 	// azure settings
-	azureSettings(resources, &pulp_settings, customSettings)
+	azureSettings(resources, pulpSettings, customSettings)
 
 	// s3 settings
-	s3Settings(resources, &pulp_settings, customSettings)
+	s3Settings(resources, pulpSettings, customSettings)
+
+	// gcs settings
+	gcsSettings(resources, pulpSettings, customSettings)
 
 	// configure settings.py with keycloak integration variables
-	ssoConfig(resources, &pulp_settings)
+	ssoConfig(resources, pulpSettings)
 
 	// configure TOKEN_SERVER based on ingress_type
-	tokenSettings(resources, &pulp_settings, customSettings)
+	tokenSettings(resources, pulpSettings, customSettings)
 
 	// django SECRET_KEY
-	secretKeySettings(resources, &pulp_settings, customSettings)
+	secretKeySettings(resources, pulpSettings, customSettings)
 
 	// allowed content checksum
-	allowedContentChecksumsSettings(resources, &pulp_settings, customSettings)
+	allowedContentChecksumsSettings(resources, pulpSettings, customSettings)
 
 	// ldap auth config
-	ldapSettings(resources, &pulp_settings)
+	ldapSettings(resources, pulpSettings)
+
+	// oidc auth config
+	oidcSettings(resources, pulpSettings)
 
 	sec := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -163,9 +169,12 @@ func pulpServerSecret(resources controllers.FunctionResources) client.Object {
 			Labels:    settings.CommonLabels(*pulp),
 		},
 		StringData: map[string]string{
-			"settings.py": pulp_settings,
+			"settings.py": pulpSettings.Render(),
 		},
 	}
+	for file, content := range oidcModule(pulp) {
+		sec.StringData[file] = content
+	}
 
 	// Set Pulp instance as the owner and controller
 	ctrl.SetControllerReference(pulp, sec, resources.Scheme)
@@ -242,7 +251,7 @@ func pulpContainerAuth(resources controllers.FunctionResources) client.Object {
 }
 
 // cacheSettings appends redis/cache settings into pulpSettings
-func cacheSettings(resources controllers.FunctionResources, pulpSettings *string) {
+func cacheSettings(resources controllers.FunctionResources, pulpSettings *pysettings.Settings) {
 	pulp := resources.Pulp
 	context := resources.Context
 	client := resources.Client
@@ -251,7 +260,8 @@ func cacheSettings(resources controllers.FunctionResources, pulpSettings *string
 		return
 	}
 
-	var cacheHost, cachePort, cachePassword, cacheDB string
+	var cacheHost, cachePort, cacheDB string
+	var cachePassword any = ""
 
 	cachePort = strconv.Itoa(6379)
 	if pulp.Spec.Cache.RedisPort != 0 {
@@ -260,24 +270,62 @@ func cacheSettings(resources controllers.FunctionResources, pulpSettings *string
 	cacheHost = pulp.Name + "-redis-svc." + pulp.Namespace
 	if len(pulp.Spec.Cache.ExternalCacheSecret) > 0 {
 		// retrieve the connection data from ExternalCacheSecret secret
-		externalCacheData := []string{"REDIS_HOST", "REDIS_PORT", "REDIS_PASSWORD", "REDIS_DB"}
+		externalCacheData := []string{"REDIS_HOST", "REDIS_PORT", "REDIS_DB"}
+		// the password provided by a SecretProviderClass is read from the file mounted in the pods
+		secretsStore := len(controllers.SecretsStoreClass(pulp, controllers.SecretsStoreCache)) > 0
+		if !secretsStore {
+			externalCacheData = append(externalCacheData, "REDIS_PASSWORD")
+		}
 		externalCacheConfig, _ := controllers.RetrieveSecretData(context, pulp.Spec.Cache.ExternalCacheSecret, pulp.Namespace, true, client, externalCacheData...)
 		cacheHost = externalCacheConfig["REDIS_HOST"]
 		cachePort = externalCacheConfig["REDIS_PORT"]
 		cachePassword = externalCacheConfig["REDIS_PASSWORD"]
+		if secretsStore {
+			cachePassword = pysettings.FileContent(controllers.SecretsStoreFile(controllers.SecretsStoreCache, "REDIS_PASSWORD"))
+		}
 		cacheDB = externalCacheConfig["REDIS_DB"]
 	}
 
-	*pulpSettings = *pulpSettings + `CACHE_ENABLED = True
-REDIS_HOST =  "` + cacheHost + `"
-REDIS_PORT =  "` + cachePort + `"
-REDIS_PASSWORD = "` + cachePassword + `"
-REDIS_DB = "` + cacheDB + `"
-`
+	pulpSettings.Set("CACHE_ENABLED", true)
+
+	// in sentinel mode, the django cache follows the master through the Sentinel
+	// pods and the pulpcore connection (REDIS_HOST/REDIS_PORT) discovers it during
+	// startup (falling back to the first Redis pod if Sentinel is not reachable)
+	if controllers.CacheSentinelEnabled(*pulp) {
+		sentinels := []any{pysettings.Tuple{settings.CacheSentinelService(pulp.Name) + "." + pulp.Namespace, controllers.SentinelPort}}
+		masterName := sentinelMasterName(pulp)
+		pulpSettings.Set("REDIS_SENTINELS", sentinels)
+		pulpSettings.Set("REDIS_SENTINEL_MASTER", masterName)
+		pulpSettings.Set("DJANGO_REDIS_CONNECTION_FACTORY", "django_redis.pool.SentinelConnectionFactory")
+		pulpSettings.Set("CACHES", pysettings.Dict{
+			{Key: "default", Value: pysettings.Dict{
+				{Key: "BACKEND", Value: "django_redis.cache.RedisCache"},
+				{Key: "LOCATION", Value: "redis://" + masterName + "/0"},
+				{Key: "OPTIONS", Value: pysettings.Dict{
+					{Key: "CLIENT_CLASS", Value: "django_redis.client.SentinelClient"},
+					{Key: "CONNECTION_POOL_CLASS", Value: "redis.sentinel.SentinelConnectionPool"},
+					{Key: "SENTINELS", Value: sentinels},
+				}},
+			}},
+		})
+		pulpSettings.Code(`try:
+    from redis.sentinel import Sentinel
+    REDIS_HOST, REDIS_PORT = Sentinel(REDIS_SENTINELS, socket_timeout=1).discover_master(REDIS_SENTINEL_MASTER)
+except Exception:
+    REDIS_HOST, REDIS_PORT = ` + pysettings.Literal(controllers.DefaultCacheMaster(*pulp)) + `, 6379`)
+		pulpSettings.Set("REDIS_PASSWORD", cachePassword)
+		pulpSettings.Set("REDIS_DB", cacheDB)
+		return
+	}
+
+	pulpSettings.Set("REDIS_HOST", cacheHost)
+	pulpSettings.Set("REDIS_PORT", cachePort)
+	pulpSettings.Set("REDIS_PASSWORD", cachePassword)
+	pulpSettings.Set("REDIS_DB", cacheDB)
 }
 
 // databaseSettings appends postgres settings into pulpSettings
-func databaseSettings(resources controllers.FunctionResources, pulpSettings *string, customSettings map[string]struct{}) {
+func databaseSettings(resources controllers.FunctionResources, pulpSettings *pysettings.Settings, customSettings map[string]struct{}) {
 	if _, exists := customSettings["DATABASES"]; exists {
 		return
 	}
@@ -287,7 +335,8 @@ func databaseSettings(resources controllers.FunctionResources, pulpSettings *str
 	context := resources.Context
 	client := resources.Client
 
-	var dbHost, dbPort, dbUser, dbPass, dbName, dbSSLMode string
+	var dbHost, dbPort, dbUser, dbName, dbSSLMode string
+	var dbPass any
 
 	// if there is no external database configuration get the databaseconfig from pulp-postgres-configuration secret
 	if len(pulp.Spec.Database.ExternalDBSecret) == 0 {
@@ -307,7 +356,12 @@ func databaseSettings(resources controllers.FunctionResources, pulpSettings *str
 		dbSSLMode = pgCredentials["sslmode"]
 	} else {
 		logger.V(1).Info("Retrieving Postgres credentials from "+resources.Pulp.Spec.Database.ExternalDBSecret+" secret", "Secret.Namespace", resources.Pulp.Namespace, "Secret.Name", resources.Pulp.Name)
-		externalPostgresData := []string{"POSTGRES_HOST", "POSTGRES_PORT", "POSTGRES_USERNAME", "POSTGRES_PASSWORD", "POSTGRES_DB_NAME", "POSTGRES_SSLMODE"}
+		externalPostgresData := []string{"POSTGRES_HOST", "POSTGRES_PORT", "POSTGRES_USERNAME", "POSTGRES_DB_NAME", "POSTGRES_SSLMODE"}
+		// the password provided by a SecretProviderClass is read from the file mounted in the pods
+		secretsStore := len(controllers.SecretsStoreClass(pulp, controllers.SecretsStoreDatabase)) > 0
+		if !secretsStore {
+			externalPostgresData = append(externalPostgresData, "POSTGRES_PASSWORD")
+		}
 		pgCredentials, err := controllers.RetrieveSecretData(context, pulp.Spec.Database.ExternalDBSecret, pulp.Namespace, true, client, externalPostgresData...)
 		if err != nil {
 			logger.Error(err, "Secret Not Found!", "Secret.Namespace", pulp.Namespace, "Secret.Name", pulp.Name)
@@ -317,27 +371,42 @@ func databaseSettings(resources controllers.FunctionResources, pulpSettings *str
 		dbPort = pgCredentials["POSTGRES_PORT"]
 		dbUser = pgCredentials["POSTGRES_USERNAME"]
 		dbPass = pgCredentials["POSTGRES_PASSWORD"]
+		if secretsStore {
+			dbPass = pysettings.FileContent(controllers.SecretsStoreFile(controllers.SecretsStoreDatabase, "POSTGRES_PASSWORD"))
+		}
 		dbName = pgCredentials["POSTGRES_DB_NAME"]
 		dbSSLMode = pgCredentials["POSTGRES_SSLMODE"]
 	}
 
-	*pulpSettings = *pulpSettings + `DATABASES = {
-  'default': {
-    'HOST': '` + dbHost + `',
-    'ENGINE': 'django.db.backends.postgresql_psycopg2',
-    'NAME': '` + dbName + `',
-    'USER': '` + dbUser + `',
-    'PASSWORD': '` + dbPass + `',
-    'PORT': '` + dbPort + `',
-    'CONN_MAX_AGE': 0,
-    'OPTIONS': { 'sslmode': '` + dbSSLMode + `' },
-  }
+	pulpSettings.Set("DATABASES", pysettings.Dict{
+		{Key: "default", Value: pysettings.Dict{
+			{Key: "HOST", Value: dbHost},
+			{Key: "ENGINE", Value: "django.db.backends.postgresql_psycopg2"},
+			{Key: "NAME", Value: dbName},
+			{Key: "USER", Value: dbUser},
+			{Key: "PASSWORD", Value: dbPass},
+			{Key: "PORT", Value: dbPort},
+			{Key: "CONN_MAX_AGE", Value: 0},
+			{Key: "OPTIONS", Value: pysettings.Dict{{Key: "sslmode", Value: dbSSLMode}}},
+		}},
+	})
 }
-`
+
+// objectStorageSettings appends the STORAGES definition with the object storage backend into pulpSettings
+func objectStorageSettings(pulpSettings *pysettings.Settings, backend string, options pysettings.Dict) {
+	pulpSettings.Set("REDIRECT_TO_OBJECT_STORAGE", true)
+	pulpSettings.Set("MEDIA_ROOT", "")
+	pulpSettings.Set("STORAGES", pysettings.Dict{
+		{Key: "default", Value: pysettings.Dict{
+			{Key: "BACKEND", Value: backend},
+			{Key: "OPTIONS", Value: options},
+		}},
+		{Key: "staticfiles", Value: pysettings.Dict{{Key: "BACKEND", Value: "django.contrib.staticfiles.storage.StaticFilesStorage"}}},
+	})
 }
 
 // azureSettings appends azure blob object storage settings into pulpSettings
-func azureSettings(resources controllers.FunctionResources, pulpSettings *string, customSettings map[string]struct{}) {
+func azureSettings(resources controllers.FunctionResources, pulpSettings *pysettings.Settings, customSettings map[string]struct{}) {
 	if _, exists := customSettings["STORAGES"]; exists {
 		return
 	}
@@ -353,35 +422,45 @@ func azureSettings(resources controllers.FunctionResources, pulpSettings *string
 	}
 
 	logger.V(1).Info("Retrieving Azure data from " + resources.Pulp.Spec.ObjectStorageAzureSecret)
-	storageData, err := controllers.RetrieveSecretData(context, pulp.Spec.ObjectStorageAzureSecret, pulp.Namespace, true, client, "azure-account-name", "azure-account-key", "azure-container", "azure-container-path", "azure-connection-string")
+	storageData, err := controllers.RetrieveSecretData(context, pulp.Spec.ObjectStorageAzureSecret, pulp.Namespace, true, client, "azure-account-name", "azure-container")
 	if err != nil {
 		logger.Error(err, "Secret Not Found!", "Secret.Namespace", pulp.Namespace, "Secret.Name", pulp.Spec.ObjectStorageAzureSecret)
 		return
 	}
+	optionalKey, _ := controllers.RetrieveSecretData(context, pulp.Spec.ObjectStorageAzureSecret, pulp.Namespace, false, client, "azure-account-key", "azure-container-path", "azure-connection-string")
 
-	*pulpSettings = *pulpSettings + `REDIRECT_TO_OBJECT_STORAGE = True
-MEDIA_ROOT = ""
-STORAGES = {
-    "default": {
-        "BACKEND": "storages.backends.azure_storage.AzureStorage",
-        "OPTIONS": {
-            "connection_string": '` + storageData["azure-connection-string"] + `',
-            "account_name": '` + storageData["azure-account-name"] + `',
-            "azure_container": '` + storageData["azure-container"] + `',
-            "account_key": '` + storageData["azure-account-key"] + `',
-            "expiration_secs": 60,
-            "overwrite_files": 'True',
-            "location": '` + storageData["azure-container-path"] + `'
-        },
-    },
-    "staticfiles": {"BACKEND": "django.contrib.staticfiles.storage.StaticFilesStorage"},
-}
-`
+	// with workload identity there is no static key in the Secret, the credentials
+	// are obtained from the federated token projected in the pods
+	workloadIdentity := controllers.AzureWorkloadIdentityEnabled(context, client, pulp)
 
+	options := pysettings.Dict{}
+	if workloadIdentity {
+		pulpSettings.Code("from azure.identity import WorkloadIdentityCredential")
+	} else {
+		options.Set("connection_string", optionalKey["azure-connection-string"])
+	}
+	options.Set("account_name", storageData["azure-account-name"])
+	options.Set("azure_container", storageData["azure-container"])
+	switch {
+	case workloadIdentity:
+		options.Set("token_credential", pysettings.Expr("WorkloadIdentityCredential()"))
+	case len(controllers.SecretsStoreClass(pulp, controllers.SecretsStoreAzure)) > 0:
+		options.Set("account_key", pysettings.FileContent(controllers.SecretsStoreFile(controllers.SecretsStoreAzure, "azure-account-key")))
+	default:
+		options.Set("account_key", optionalKey["azure-account-key"])
+	}
+
+	extraOptions := extraStorageOptions(resources, pulp.Spec.ObjectStorageAzureSecret, "azure-", azureManagedKeys)
+	options.Set("expiration_secs", extraOptions.pop("expiration_secs", 60))
+	options.Set("overwrite_files", extraOptions.pop("overwrite_files", "True"))
+	options = append(options, extraOptions.items()...)
+	options.Set("location", optionalKey["azure-container-path"])
+
+	objectStorageSettings(pulpSettings, "storages.backends.azure_storage.AzureStorage", options)
 }
 
 // s3Settings appends s3 object storage settings into pulpSettings
-func s3Settings(resources controllers.FunctionResources, pulpSettings *string, customSettings map[string]struct{}) {
+func s3Settings(resources controllers.FunctionResources, pulpSettings *pysettings.Settings, customSettings map[string]struct{}) {
 	if _, exists := customSettings["STORAGES"]; exists {
 		return
 	}
@@ -408,50 +487,83 @@ func s3Settings(resources controllers.FunctionResources, pulpSettings *string, c
 		return
 	}
 
-	var s3SecretKey, s3KeyId, s3Endpoint, s3Region string
-	if len(optionalKey["s3-secret-access-key"]) > 0 {
-		s3SecretKey = fmt.Sprintf("%12s\"secret_key\": \"%v\",\n", "", optionalKey["s3-secret-access-key"])
+	// any other s3-* key from the Secret is passed through as an OPTION
+	extraOptions := extraStorageOptions(resources, pulp.Spec.ObjectStorageS3Secret, "s3-", s3ManagedKeys)
+
+	options := pysettings.Dict{
+		{Key: "signature_version", Value: extraOptions.pop("signature_version", "s3v4")},
+		{Key: "addressing_style", Value: extraOptions.pop("addressing_style", "path")},
+		{Key: "bucket_name", Value: storageData["s3-bucket-name"]},
+	}
+	if len(controllers.SecretsStoreClass(pulp, controllers.SecretsStoreS3)) > 0 {
+		options.Set("secret_key", pysettings.FileContent(controllers.SecretsStoreFile(controllers.SecretsStoreS3, "s3-secret-access-key")))
+		options.Set("access_key", pysettings.FileContent(controllers.SecretsStoreFile(controllers.SecretsStoreS3, "s3-access-key-id")))
+	} else {
+		if len(optionalKey["s3-secret-access-key"]) > 0 {
+			options.Set("secret_key", optionalKey["s3-secret-access-key"])
+		}
+		if len(optionalKey["s3-access-key-id"]) > 0 {
+			options.Set("access_key", optionalKey["s3-access-key-id"])
+		}
+	}
+	if len(optionalKey["s3-endpoint"]) > 0 {
+		options.Set("endpoint_url", optionalKey["s3-endpoint"])
+	}
+	if len(optionalKey["s3-region"]) > 0 {
+		options.Set("region_name", optionalKey["s3-region"])
+	}
+	options = append(options, extraOptions.items()...)
+
+	objectStorageSettings(pulpSettings, "storages.backends.s3boto3.S3Boto3Storage", options)
+}
+
+// gcsSettings appends google cloud storage object storage settings into pulpSettings
+func gcsSettings(resources controllers.FunctionResources, pulpSettings *pysettings.Settings, customSettings map[string]struct{}) {
+	if _, exists := customSettings["STORAGES"]; exists {
+		return
 	}
+	pulp := resources.Pulp
+	logger := resources.Logger
+	context := resources.Context
+	client := resources.Client
 
-	if len(optionalKey["s3-access-key-id"]) > 0 {
-		s3KeyId = fmt.Sprintf("%12s\"access_key\": \"%v\",\n", "", optionalKey["s3-access-key-id"])
+	_, storageType := controllers.MultiStorageConfigured(pulp, "Pulp")
+	if storageType[0] != controllers.GCSObjType {
+		return
 	}
 
-	if len(optionalKey["s3-endpoint"]) > 0 {
-		s3Endpoint = fmt.Sprintf("%12s\"endpoint_url\": \"%v\",\n", "", optionalKey["s3-endpoint"])
+	logger.V(1).Info("Retrieving GCS data from " + resources.Pulp.Spec.ObjectStorageGCSSecret)
+	storageData, err := controllers.RetrieveSecretData(context, pulp.Spec.ObjectStorageGCSSecret, pulp.Namespace, true, client, "gcs-bucket-name")
+	if err != nil {
+		logger.Error(err, "Secret Not Found!", "Secret.Namespace", pulp.Namespace, "Secret.Name", pulp.Spec.ObjectStorageGCSSecret)
+		return
 	}
 
-	if len(optionalKey["s3-region"]) > 0 {
-		s3Region = fmt.Sprintf("%12s\"region_name\": \"%v\",\n", "", optionalKey["s3-region"])
-	}
-
-	s3Options := `        "OPTIONS": {
-            "signature_version": "s3v4",
-            "addressing_style": "path",
-            "bucket_name": '` + storageData["s3-bucket-name"] + `',
-`
-	s3Options += s3SecretKey
-	s3Options += s3KeyId
-	s3Options += s3Endpoint
-	s3Options += s3Region
-	s3Options += fmt.Sprintf("%8s},\n", "")
-
-	*pulpSettings += `REDIRECT_TO_OBJECT_STORAGE = True
-MEDIA_ROOT = ""
-STORAGES = {
-    "default": {
-        "BACKEND": "storages.backends.s3boto3.S3Boto3Storage",
-`
-	*pulpSettings += s3Options
-	*pulpSettings += `    },
-    "staticfiles": {"BACKEND": "django.contrib.staticfiles.storage.StaticFilesStorage"},
-`
-	*pulpSettings += fmt.Sprintln("}")
+	// the gcs-credentials key is not added to settings.py, it is mounted in the pods
+	// and its path is provided through the GOOGLE_APPLICATION_CREDENTIALS env var
+	optionalKey, _ := controllers.RetrieveSecretData(context, pulp.Spec.ObjectStorageGCSSecret, pulp.Namespace, false, client, "gcs-project-id", "gcs-location")
 
+	// any other gcs-* key from the Secret is passed through as an OPTION
+	extraOptions := extraStorageOptions(resources, pulp.Spec.ObjectStorageGCSSecret, "gcs-", gcsManagedKeys)
+
+	options := pysettings.Dict{
+		{Key: "bucket_name", Value: storageData["gcs-bucket-name"]},
+		{Key: "expiration", Value: extraOptions.pop("expiration", 60)},
+		{Key: "file_overwrite", Value: extraOptions.pop("file_overwrite", false)},
+	}
+	if len(optionalKey["gcs-project-id"]) > 0 {
+		options.Set("project_id", optionalKey["gcs-project-id"])
+	}
+	if len(optionalKey["gcs-location"]) > 0 {
+		options.Set("location", optionalKey["gcs-location"])
+	}
+	options = append(options, extraOptions.items()...)
+
+	objectStorageSettings(pulpSettings, "storages.backends.gcloud.GoogleCloudStorage", options)
 }
 
 // tokenSettings appends the TOKEN_SERVER setting into pulpSettings
-func tokenSettings(resources controllers.FunctionResources, pulpSettings *string, customSettings map[string]struct{}) {
+func tokenSettings(resources controllers.FunctionResources, pulpSettings *pysettings.Settings, customSettings map[string]struct{}) {
 	if _, exists := customSettings["TOKEN_SERVER"]; exists {
 		return
 	}
@@ -460,21 +572,15 @@ func tokenSettings(resources controllers.FunctionResources, pulpSettings *string
 	rootUrl := getRootURL(*pulp)
 
 	// configure TOKEN_SERVER based on ingress_type
-	tokenServer := "http://" + pulp.Name + "-api-svc." + pulp.Namespace + ".svc.cluster.local:24817/token/"
-	if isRoute(pulp) {
+	tokenServer := controllers.InternalTLSScheme(pulp) + "://" + pulp.Name + "-api-svc." + pulp.Namespace + ".svc.cluster.local:24817/token/"
+	if isRoute(pulp) || isIngress(pulp) || isGateway(pulp) {
 		tokenServer = rootUrl + "/token/"
-	} else if isIngress(pulp) {
-		proto := "http"
-		if len(pulp.Spec.IngressTLSSecret) > 0 {
-			proto = "https"
-		}
-		tokenServer = proto + "://" + pulp.Spec.IngressHost + "/token/"
 	}
-	*pulpSettings = *pulpSettings + fmt.Sprintln("TOKEN_SERVER = \""+tokenServer+"\"")
+	pulpSettings.Set("TOKEN_SERVER", tokenServer)
 }
 
 // secretKeySettings appends djange SECRET_KEY setting into pulpSettings
-func secretKeySettings(resources controllers.FunctionResources, pulpSettings *string, customSettings map[string]struct{}) {
+func secretKeySettings(resources controllers.FunctionResources, pulpSettings *pysettings.Settings, customSettings map[string]struct{}) {
 	if _, exists := customSettings["SECRET_KEY"]; exists {
 		return
 	}
@@ -490,11 +596,11 @@ func secretKeySettings(resources controllers.FunctionResources, pulpSettings *st
 		return
 	}
 
-	*pulpSettings = *pulpSettings + fmt.Sprintf("SECRET_KEY = \"%v\"\n", secretKey["secret_key"])
+	pulpSettings.Set("SECRET_KEY", secretKey["secret_key"])
 }
 
 // allowedContentChecksumsSettings appends the allowed_content_checksums into pulpSettings
-func allowedContentChecksumsSettings(resources controllers.FunctionResources, pulpSettings *string, customSettings map[string]struct{}) {
+func allowedContentChecksumsSettings(resources controllers.FunctionResources, pulpSettings *pysettings.Settings, customSettings map[string]struct{}) {
 	if _, exists := customSettings["ALLOWED_CONTENT_CHECKSUMS"]; exists {
 		return
 	}
@@ -503,21 +609,20 @@ func allowedContentChecksumsSettings(resources controllers.FunctionResources, pu
 	if len(pulp.Spec.AllowedContentChecksums) == 0 {
 		return
 	}
-	settings, _ := json.Marshal(pulp.Spec.AllowedContentChecksums)
-	*pulpSettings = *pulpSettings + fmt.Sprintln("ALLOWED_CONTENT_CHECKSUMS = ", string(settings))
+	pulpSettings.Set("ALLOWED_CONTENT_CHECKSUMS", pulp.Spec.AllowedContentChecksums)
 }
 
 // addCustomPulpSettings defines settings.py with the configurations defined in custom_pulp_settings configmap
 // and returns a map with all the custom keys defined
-func addCustomPulpSettings(resources controllers.FunctionResources, pulpSettings *string) map[string]struct{} {
+func addCustomPulpSettings(resources controllers.FunctionResources, pulpSettings *pysettings.Settings) map[string]struct{} {
 	pulp := resources.Pulp
 	rootUrl := getRootURL(*pulp)
-	defaultSettings := settings.DefaultPulpSettings(rootUrl)
+	defaultSettings := settings.DefaultPulpSettings(rootUrl, getContentOrigin(*pulp))
 
 	// if custom_pulp_settings is not defined, append the default values and return
 	if pulp.Spec.CustomPulpSettings == "" {
 		for _, k := range sortKeys(defaultSettings) {
-			*pulpSettings = *pulpSettings + fmt.Sprintf("%v = %v\n", k, defaultSettings[k])
+			pulpSettings.Set(k, defaultSettings[k])
 		}
 		return nil
 	}
@@ -528,23 +633,36 @@ func addCustomPulpSettings(resources controllers.FunctionResources, pulpSettings
 	// store the keys found in custom_pulp_settings configmap
 	settings := map[string]struct{}{}
 	for _, k := range sortKeys(settingsCM.Data) {
-		*pulpSettings = *pulpSettings + fmt.Sprintf("%v = %v\n", strings.ToUpper(k), settingsCM.Data[k])
-		settings[strings.ToUpper(k)] = struct{}{}
+		key := strings.ToUpper(k)
+		if !pysettings.IsIdentifier(key) {
+			resources.Logger.Info("The key \"" + k + "\" from ConfigMap \"" + pulp.Spec.CustomPulpSettings + "\" is not a valid setting name, ignoring it ...")
+			continue
+		}
+
+		// the custom_pulp_settings values are python expressions provided by the cluster admin
+		// (for example, a list or a dict), so they are not converted into string literals
+		pulpSettings.Set(key, pysettings.Expr(settingsCM.Data[k]))
+		settings[key] = struct{}{}
 
 		// remove the settings from defaultSettings dict to avoid duplicate config
-		delete(defaultSettings, strings.ToUpper(k))
+		delete(defaultSettings, key)
 	}
 
 	for _, k := range sortKeys(defaultSettings) {
-		*pulpSettings = *pulpSettings + fmt.Sprintf("%v = %v\n", k, defaultSettings[k])
+		pulpSettings.Set(k, defaultSettings[k])
 	}
 	return settings
 }
 
 // debugLogging will set the log level from Pulpcore pods to DEBUG
-func debugLogging(resources controllers.FunctionResources, pulpSettings *string) {
+func debugLogging(resources controllers.FunctionResources, pulpSettings *pysettings.Settings) {
 
 	if resources.Pulp.Spec.EnableDebugging {
-		*pulpSettings = *pulpSettings + fmt.Sprintln("LOGGING = {'dynaconf_merge': True, 'loggers': {'': {'handlers': ['console'], 'level': 'DEBUG'}}}")
+		pulpSettings.Set("LOGGING", pysettings.Dict{
+			{Key: "dynaconf_merge", Value: true},
+			{Key: "loggers", Value: pysettings.Dict{
+				{Key: "", Value: pysettings.Dict{{Key: "handlers", Value: []string{"console"}}, {Key: "level", Value: "DEBUG"}}},
+			}},
+		})
 	}
 }
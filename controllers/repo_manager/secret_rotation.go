@@ -0,0 +1,235 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	// adminPasswordRotationAnnotation requests a new rotation of the admin password whenever its value changes
+	adminPasswordRotationAnnotation = "repo-manager.pulpproject.org/rotate-admin-password"
+	// containerTokenRotationAnnotation requests a new rotation of the container token key pair whenever its value changes
+	containerTokenRotationAnnotation = "repo-manager.pulpproject.org/rotate-container-token"
+)
+
+// secretRotationController rotates the admin password and the container token key pair when
+// their rotation interval has elapsed or when their rotation annotation has been modified.
+// The admin password is reset by the job from updateAdminPasswordJob (the Secret hash changes)
+// and the pulpcore pods are restarted to load the new container token key pair.
+func (r *RepoManagerReconciler) secretRotationController(ctx context.Context, pulp *pulpv1.Pulp, log logr.Logger) (ctrl.Result, error) {
+	adminRotated, err := r.rotateAdminPassword(ctx, pulp, log)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	tokenRotated, err := r.rotateContainerTokenKeys(ctx, pulp, log)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if adminRotated || tokenRotated {
+		return ctrl.Result{Requeue: true}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// rotateAdminPassword stores a new password in the admin_password_secret if its rotation is due
+func (r *RepoManagerReconciler) rotateAdminPassword(ctx context.Context, pulp *pulpv1.Pulp, log logr.Logger) (bool, error) {
+	policy := pulp.Spec.SecretRotation.AdminPassword
+	trigger := pulp.Annotations[adminPasswordRotationAnnotation]
+	if len(policy.Interval) == 0 && len(trigger) == 0 {
+		return false, nil
+	}
+
+	secretName := controllers.GetAdminSecretName(*pulp)
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: secretName, Namespace: pulp.Namespace}, secret); err != nil {
+		log.Error(err, "Failed to get "+secretName+" Secret")
+		return false, err
+	}
+	if !rotationDue(policy.Interval, pulp.Status.AdminPasswordRotation, trigger, secret.CreationTimestamp) {
+		return false, nil
+	}
+
+	log.Info("Rotating the admin password from " + secretName + " Secret")
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data["password"] = []byte(createPwd(32))
+	if err := r.Update(ctx, secret); err != nil {
+		log.Error(err, "Failed to update "+secretName+" Secret")
+		return false, err
+	}
+
+	pulp.Status.AdminPasswordRotation = &pulpv1.SecretRotationStatus{LastRotationTime: &metav1.Time{Time: time.Now()}, Trigger: trigger}
+	if err := r.Status().Update(ctx, pulp); err != nil {
+		log.Error(err, "Failed to update pulp status with the admin password rotation")
+		return false, err
+	}
+	r.recorder.Event(pulp, corev1.EventTypeNormal, "AdminPasswordRotated", "Rotated the admin password from "+secretName+" Secret")
+	return true, nil
+}
+
+// rotateContainerTokenKeys replaces the key pair from the container_token_secret if its rotation is due.
+// pulp_container verifies the tokens with a single public key (PUBLIC_KEY_PATH), so the tokens signed
+// with the previous private key are rejected as soon as the pods load the new key pair and the
+// clients have to request a new token.
+func (r *RepoManagerReconciler) rotateContainerTokenKeys(ctx context.Context, pulp *pulpv1.Pulp, log logr.Logger) (bool, error) {
+	policy := pulp.Spec.SecretRotation.ContainerToken
+	trigger := pulp.Annotations[containerTokenRotationAnnotation]
+	if len(pulp.Spec.ContainerTokenSecret) == 0 || (len(policy.Interval) == 0 && len(trigger) == 0) {
+		return false, nil
+	}
+
+	secretName := pulp.Spec.ContainerTokenSecret
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: secretName, Namespace: pulp.Namespace}, secret); err != nil {
+		log.Error(err, "Failed to get "+secretName+" Secret")
+		return false, err
+	}
+	if !rotationDue(policy.Interval, pulp.Status.ContainerTokenRotation, trigger, secret.CreationTimestamp) {
+		return false, nil
+	}
+
+	log.Info("Rotating the container token key pair from " + secretName + " Secret")
+	privKey, pubKey := genTokenAuthKey()
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data["container_auth_private_key.pem"] = []byte(privKey)
+	secret.Data["container_auth_public_key.pem"] = []byte(pubKey)
+	if err := r.Update(ctx, secret); err != nil {
+		log.Error(err, "Failed to update "+secretName+" Secret")
+		return false, err
+	}
+
+	pulp.Status.ContainerTokenRotation = &pulpv1.SecretRotationStatus{LastRotationTime: &metav1.Time{Time: time.Now()}, Trigger: trigger}
+	if err := r.Status().Update(ctx, pulp); err != nil {
+		log.Error(err, "Failed to update pulp status with the container token rotation")
+		return false, err
+	}
+
+	// the keys are mounted through subPath, so the pods need to be recreated to load them
+	r.restartPulpCorePods(ctx, pulp)
+	r.recorder.Event(pulp, corev1.EventTypeNormal, "ContainerTokenRotated", "Rotated the container token key pair from "+secretName+" Secret")
+	return true, nil
+}
+
+// nextSecretRotation returns the time to wait before the next scheduled rotation
+func (r *RepoManagerReconciler) nextSecretRotation(ctx context.Context, pulp *pulpv1.Pulp) ctrl.Result {
+	next := []time.Time{}
+
+	if interval := pulp.Spec.SecretRotation.AdminPassword.Interval; len(interval) > 0 {
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Name: controllers.GetAdminSecretName(*pulp), Namespace: pulp.Namespace}, secret); err == nil {
+			next = append(next, nextRotation(interval, pulp.Status.AdminPasswordRotation, secret.CreationTimestamp))
+		}
+	}
+	if len(pulp.Spec.ContainerTokenSecret) > 0 {
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Name: pulp.Spec.ContainerTokenSecret, Namespace: pulp.Namespace}, secret); err == nil {
+			next = append(next, nextRotation(pulp.Spec.SecretRotation.ContainerToken.Interval, pulp.Status.ContainerTokenRotation, secret.CreationTimestamp))
+		}
+	}
+
+	if interval := pulp.Spec.SecretRotation.DBFieldsEncryption.Interval; len(interval) > 0 {
+		// a rotation in progress is followed by dbKeyRotationController
+		switch rotation := pulp.Status.DBFieldsEncryptionRotation; {
+		case rotation == nil:
+			secret := &corev1.Secret{}
+			if err := r.Get(ctx, types.NamespacedName{Name: dbFieldsEncryptionSecretName(pulp), Namespace: pulp.Namespace}, secret); err == nil {
+				next = append(next, nextRotation(interval, nil, secret.CreationTimestamp))
+			}
+		case rotation.Phase == dbKeyRotationCompleted:
+			next = append(next, nextRotation(interval, &rotation.SecretRotationStatus, metav1.Time{}))
+		}
+	}
+
+	var result ctrl.Result
+	for _, t := range next {
+		if t.IsZero() {
+			continue
+		}
+		wait := time.Until(t)
+		if wait <= 0 {
+			wait = time.Second
+		}
+		result = earliestResult(result, ctrl.Result{RequeueAfter: wait})
+	}
+	return result
+}
+
+// rotationDue returns true if the rotation annotation (trigger) has not been handled yet
+// or if the rotation interval has elapsed
+func rotationDue(interval string, status *pulpv1.SecretRotationStatus, trigger string, created metav1.Time) bool {
+	if len(trigger) > 0 && (status == nil || status.Trigger != trigger) {
+		return true
+	}
+	next := nextRotation(interval, status, created)
+	return !next.IsZero() && !time.Now().Before(next)
+}
+
+// nextRotation returns the time of the next rotation based on the last rotation (or on the
+// Secret creation if it has never been rotated). It returns the zero time if there is no interval.
+func nextRotation(interval string, status *pulpv1.SecretRotationStatus, created metav1.Time) time.Time {
+	duration, err := time.ParseDuration(interval)
+	if err != nil || duration <= 0 {
+		return time.Time{}
+	}
+	last := created.Time
+	if status != nil && status.LastRotationTime != nil {
+		last = status.LastRotationTime.Time
+	}
+	return last.Add(duration)
+}
+
+// earliestResult returns the result that requeues first
+func earliestResult(a, b ctrl.Result) ctrl.Result {
+	if a.RequeueAfter == 0 {
+		return b
+	}
+	if b.RequeueAfter == 0 || a.RequeueAfter < b.RequeueAfter {
+		return a
+	}
+	return b
+}
+
+// rotationAnnotationsChanged triggers a reconciliation when a rotation annotation is modified
+// (the annotations do not change the generation of Pulp CR)
+func rotationAnnotationsChanged() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			for _, annotation := range []string{adminPasswordRotationAnnotation, containerTokenRotationAnnotation, dbKeyRotationAnnotation} {
+				if e.ObjectOld.GetAnnotations()[annotation] != e.ObjectNew.GetAnnotations()[annotation] {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
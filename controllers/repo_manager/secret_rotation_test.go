@@ -0,0 +1,128 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestNextRotation(t *testing.T) {
+	created := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	rotated := &metav1.Time{Time: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)}
+	tests := []struct {
+		name     string
+		interval string
+		status   *pulpv1.SecretRotationStatus
+		expected time.Time
+	}{
+		{name: "never rotated", interval: "24h", expected: created.Add(24 * time.Hour)},
+		{name: "rotated", interval: "24h", status: &pulpv1.SecretRotationStatus{LastRotationTime: rotated}, expected: rotated.Add(24 * time.Hour)},
+		{name: "rotation status without time", interval: "1h", status: &pulpv1.SecretRotationStatus{Trigger: "1"}, expected: created.Add(time.Hour)},
+		{name: "no interval", interval: ""},
+		{name: "invalid interval", interval: "1d"},
+		{name: "negative interval", interval: "-1h"},
+	}
+	for _, test := range tests {
+		if next := nextRotation(test.interval, test.status, created); !next.Equal(test.expected) {
+			t.Errorf("%v: expected %v, got %v", test.name, test.expected, next)
+		}
+	}
+}
+
+func TestRotationDue(t *testing.T) {
+	now := time.Now()
+	recent := &metav1.Time{Time: now.Add(-time.Hour)}
+	old := &metav1.Time{Time: now.Add(-48 * time.Hour)}
+	created := metav1.NewTime(now.Add(-72 * time.Hour))
+	tests := []struct {
+		name     string
+		interval string
+		status   *pulpv1.SecretRotationStatus
+		trigger  string
+		due      bool
+	}{
+		{name: "interval elapsed since the creation", interval: "24h", due: true},
+		{name: "interval elapsed since the last rotation", interval: "24h", status: &pulpv1.SecretRotationStatus{LastRotationTime: old}, due: true},
+		{name: "interval not elapsed", interval: "24h", status: &pulpv1.SecretRotationStatus{LastRotationTime: recent}},
+		{name: "no interval", status: &pulpv1.SecretRotationStatus{LastRotationTime: old}},
+		{name: "new trigger", trigger: "2", status: &pulpv1.SecretRotationStatus{LastRotationTime: recent, Trigger: "1"}, due: true},
+		{name: "first trigger", trigger: "1", due: true},
+		{name: "trigger already handled", trigger: "1", status: &pulpv1.SecretRotationStatus{LastRotationTime: recent, Trigger: "1"}},
+		{name: "trigger handled and interval elapsed", interval: "24h", trigger: "1", status: &pulpv1.SecretRotationStatus{LastRotationTime: old, Trigger: "1"}, due: true},
+	}
+	for _, test := range tests {
+		if due := rotationDue(test.interval, test.status, test.trigger, created); due != test.due {
+			t.Errorf("%v: expected %v, got %v", test.name, test.due, due)
+		}
+	}
+}
+
+func TestContainerTokenRotation(t *testing.T) {
+	ctx := context.TODO()
+	pulp := settingsTestPulp()
+	pulp.Spec.ContainerTokenSecret = "test-container-auth"
+	pulp.Spec.SecretRotation.ContainerToken.Interval = "720h"
+	pulp.Annotations = map[string]string{containerTokenRotationAnnotation: "1"}
+	r := newTestReconciler(pulp, settingsTestSecret("test-container-auth", map[string]string{
+		"container_auth_private_key.pem": "current-private",
+		"container_auth_public_key.pem":  "current-public",
+	}))
+	secretData := func() map[string][]byte {
+		secret := &corev1.Secret{}
+		r.Get(ctx, types.NamespacedName{Name: "test-container-auth", Namespace: "pulp"}, secret)
+		return secret.Data
+	}
+
+	// the new key pair is used right away
+	if result, err := r.secretRotationController(ctx, pulp, logr.Discard()); err != nil || !result.Requeue {
+		t.Fatalf("expected the rotation to be requeued, got %v (%v)", result, err)
+	}
+	data := secretData()
+	rotatedPrivate, rotatedPublic := string(data["container_auth_private_key.pem"]), string(data["container_auth_public_key.pem"])
+	if rotatedPrivate == "current-private" || rotatedPublic == "current-public" || len(rotatedPrivate) == 0 || len(rotatedPublic) == 0 {
+		t.Errorf("expected a new key pair in the Secret, got %v", data)
+	}
+	if len(data) != 2 {
+		t.Errorf("expected only the key pair in use in the Secret, got %v", data)
+	}
+	if status := pulp.Status.ContainerTokenRotation; status == nil || status.Trigger != "1" || status.LastRotationTime == nil {
+		t.Errorf("expected the rotation status to be updated, got %v", status)
+	}
+	if len(pulp.Status.LastDeploymentUpdate) == 0 {
+		t.Errorf("expected the pulpcore pods to be restarted to load the new key pair")
+	}
+
+	// the trigger is only handled once and the next rotation follows the interval
+	pulp.Status.LastDeploymentUpdate = ""
+	if result, err := r.secretRotationController(ctx, pulp, logr.Discard()); err != nil || result.Requeue {
+		t.Fatalf("expected no new rotation, got %v (%v)", result, err)
+	}
+	if string(secretData()["container_auth_private_key.pem"]) != rotatedPrivate || len(pulp.Status.LastDeploymentUpdate) > 0 {
+		t.Errorf("the key pair should not be rotated again")
+	}
+	if result := r.nextSecretRotation(ctx, pulp); result.RequeueAfter <= 719*time.Hour || result.RequeueAfter > 720*time.Hour {
+		t.Errorf("expected the next rotation after the interval, got %v", result)
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// secretProviderClass returns a SecretProviderClass in the pulp namespace
+func secretProviderClass(name string) client.Object {
+	spc := &unstructured.Unstructured{}
+	spc.SetGroupVersionKind(controllers.SecretProviderClassGVK)
+	spc.SetName(name)
+	spc.SetNamespace("pulp")
+	return spc
+}
+
+func TestCheckSecretsStore(t *testing.T) {
+	oidc := pulpv1.OIDC{IssuerURL: "https://example.okta.com/oauth2/default", ClientSecret: "oidc"}
+	externalCache := pulpv1.Cache{Enabled: true, ExternalCacheSecret: "external-cache"}
+
+	tests := []struct {
+		name         string
+		pulp         func(*pulpv1.Pulp)
+		objects      []client.Object
+		rejected     bool
+		requeueAfter time.Duration
+	}{
+		{
+			name: "gcs",
+			pulp: func(pulp *pulpv1.Pulp) {
+				pulp.Spec.ObjectStorageGCSSecret = "gcs"
+				pulp.Spec.SecretsStore.ObjectStorageGCSSecret = "pulp-gcs"
+			},
+			objects: []client.Object{secretProviderClass("pulp-gcs")},
+		},
+		{
+			name: "cache",
+			pulp: func(pulp *pulpv1.Pulp) {
+				pulp.Spec.Cache = externalCache
+				pulp.Spec.SecretsStore.ExternalCacheSecret = "pulp-cache"
+			},
+			objects: []client.Object{secretProviderClass("pulp-cache")},
+		},
+		{
+			name: "oidc",
+			pulp: func(pulp *pulpv1.Pulp) {
+				pulp.Spec.OIDC = oidc
+				pulp.Spec.SecretsStore.OIDCClientSecret = "pulp-oidc"
+			},
+			objects: []client.Object{secretProviderClass("pulp-oidc")},
+		},
+		{
+			name:     "gcs without object_storage_gcs_secret",
+			pulp:     func(pulp *pulpv1.Pulp) { pulp.Spec.SecretsStore.ObjectStorageGCSSecret = "pulp-gcs" },
+			rejected: true,
+		},
+		{
+			name: "cache without external_cache_secret",
+			pulp: func(pulp *pulpv1.Pulp) {
+				pulp.Spec.Cache = pulpv1.Cache{Enabled: true}
+				pulp.Spec.SecretsStore.ExternalCacheSecret = "pulp-cache"
+			},
+			rejected: true,
+		},
+		{
+			name: "oidc without issuer_url",
+			pulp: func(pulp *pulpv1.Pulp) {
+				pulp.Spec.OIDC = pulpv1.OIDC{ClientSecret: "oidc"}
+				pulp.Spec.SecretsStore.OIDCClientSecret = "pulp-oidc"
+			},
+			rejected: true,
+		},
+		{
+			name: "missing SecretProviderClass",
+			pulp: func(pulp *pulpv1.Pulp) {
+				pulp.Spec.Cache = externalCache
+				pulp.Spec.SecretsStore.ExternalCacheSecret = "pulp-cache"
+			},
+			rejected:     true,
+			requeueAfter: time.Minute,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pulp := settingsTestPulp()
+			test.pulp(pulp)
+			r := newTestReconciler(append([]client.Object{pulp}, test.objects...)...)
+
+			result := checkSecretsStore(context.TODO(), r, pulp)
+			switch {
+			case !test.rejected && result != nil:
+				t.Errorf("expected the secrets store to be accepted, got %+v", result)
+			case test.rejected && result == nil:
+				t.Errorf("expected the secrets store to be rejected")
+			case test.rejected && result.RequeueAfter != test.requeueAfter:
+				t.Errorf("expected a requeue after %v, got %v", test.requeueAfter, result.RequeueAfter)
+			}
+		})
+	}
+}
+
+func TestSecretsStoreDeployment(t *testing.T) {
+	pulp := settingsTestPulp()
+	pulp.Spec.FileStorageClass = ""
+	pulp.Spec.ObjectStorageGCSSecret = "gcs"
+	pulp.Spec.Cache = pulpv1.Cache{Enabled: true, ExternalCacheSecret: "external-cache"}
+	pulp.Spec.SecretsStore = pulpv1.SecretsStore{ObjectStorageGCSSecret: "pulp-gcs", ExternalCacheSecret: "pulp-cache"}
+	r := newTestReconciler(pulp,
+		settingsTestSecret("gcs", map[string]string{"gcs-bucket-name": "pulp"}),
+		settingsTestSecret("external-cache", map[string]string{"REDIS_HOST": "redis.example.com", "REDIS_PORT": "6379", "REDIS_DB": "1"}),
+	)
+	resources := controllers.FunctionResources{Context: context.TODO(), Client: r.Client, Pulp: pulp, Scheme: r.Scheme, Logger: r.RawLogger}
+
+	deployment := controllers.DeploymentAPICommon{}.Deploy(resources).(*appsv1.Deployment)
+	env := map[string]corev1.EnvVar{}
+	for _, envVar := range deployment.Spec.Template.Spec.Containers[0].Env {
+		env[envVar.Name] = envVar
+	}
+	if _, found := env["REDIS_SERVICE_PASSWORD"]; found {
+		t.Errorf("REDIS_SERVICE_PASSWORD should not reference the external_cache_secret, the password is provided by the SecretProviderClass")
+	}
+	if _, found := env["REDIS_SERVICE_HOST"]; !found {
+		t.Errorf("REDIS_SERVICE_HOST should still be read from the external_cache_secret")
+	}
+	if path := controllers.SecretsStoreFile(controllers.SecretsStoreGCS, "gcs-credentials"); env["GOOGLE_APPLICATION_CREDENTIALS"].Value != path {
+		t.Errorf("expected GOOGLE_APPLICATION_CREDENTIALS=%v, got %q", path, env["GOOGLE_APPLICATION_CREDENTIALS"].Value)
+	}
+
+	volumes := map[string]corev1.Volume{}
+	for _, volume := range deployment.Spec.Template.Spec.Volumes {
+		volumes[volume.Name] = volume
+	}
+	if _, found := volumes["gcs-credentials"]; found {
+		t.Errorf("the gcs-credentials volume should not be mounted from the object_storage_gcs_secret")
+	}
+	for source, spc := range map[string]string{controllers.SecretsStoreGCS: "pulp-gcs", controllers.SecretsStoreCache: "pulp-cache"} {
+		volume, found := volumes["secrets-store-"+source]
+		if !found || volume.CSI == nil || volume.CSI.VolumeAttributes["secretProviderClass"] != spc {
+			t.Errorf("expected the %v SecretProviderClass to be mounted, got %+v", spc, volume)
+		}
+	}
+}
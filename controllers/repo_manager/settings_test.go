@@ -0,0 +1,275 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// run "go test ./controllers/repo_manager/ -run TestPulpServerSecret -update" to regenerate the golden files
+var updateGolden = flag.Bool("update", false, "update the golden files")
+
+// injection is a value that would break (or inject code into) settings.py if it is not escaped
+const injection = "p@ss'\"\\\nimport os; os.system(\"id\")\n#"
+
+// settingsTestPulp returns the Pulp CR used by the settings.py golden tests
+func settingsTestPulp() *pulpv1.Pulp {
+	return &pulpv1.Pulp{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "pulp", UID: "test-uid"},
+		Spec: pulpv1.PulpSpec{
+			IngressType:      "nodeport",
+			FileStorageClass: "standard",
+			PulpSecretKey:    "test-secret-key",
+		},
+	}
+}
+
+// settingsTestSecret returns a Secret in the pulp namespace with data
+func settingsTestSecret(name string, data map[string]string) *corev1.Secret {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "pulp"}, Data: map[string][]byte{}}
+	for k, v := range data {
+		secret.Data[k] = []byte(v)
+	}
+	return secret
+}
+
+func TestPulpServerSecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	pulpv1.AddToScheme(scheme)
+
+	postgres := settingsTestSecret("test-postgres-configuration", map[string]string{
+		"username": "pulp", "password": "password", "database": "pulp", "port": "5432", "sslmode": "prefer",
+	})
+	secretKey := settingsTestSecret("test-secret-key", map[string]string{"secret_key": "django-key"})
+
+	tests := []struct {
+		name    string
+		pulp    func(*pulpv1.Pulp)
+		objects []client.Object
+	}{
+		{
+			name: "default",
+		},
+		{
+			name: "escaping",
+			pulp: func(pulp *pulpv1.Pulp) {
+				pulp.Spec.Database.ExternalDBSecret = "external-db"
+				pulp.Spec.Cache = pulpv1.Cache{Enabled: true, ExternalCacheSecret: "external-cache"}
+				pulp.Spec.CustomPulpSettings = "custom-settings"
+				pulp.Spec.SSOSecret = "sso"
+				pulp.Spec.EnableDebugging = true
+				pulp.Spec.AllowedContentChecksums = []string{"sha256", "sha512"}
+			},
+			objects: []client.Object{
+				settingsTestSecret("external-db", map[string]string{
+					"POSTGRES_HOST": "db.example.com", "POSTGRES_PORT": "5432", "POSTGRES_USERNAME": "pulp",
+					"POSTGRES_PASSWORD": injection, "POSTGRES_DB_NAME": "pulp", "POSTGRES_SSLMODE": "require",
+				}),
+				settingsTestSecret("external-cache", map[string]string{
+					"REDIS_HOST": "redis.example.com", "REDIS_PORT": "6379", "REDIS_PASSWORD": injection, "REDIS_DB": "1",
+				}),
+				settingsTestSecret("test-secret-key", map[string]string{"secret_key": injection}),
+				settingsTestSecret("sso", map[string]string{
+					"social_auth_keycloak_key": "pulp", "social_auth_keycloak_secret": injection,
+					"social_auth_keycloak_public_key": "public-key", "keycloak_host": "keycloak.example.com",
+					"keycloak_protocol": "https", "keycloak_port": "443", "keycloak_realm": "pulp",
+				}),
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "custom-settings", Namespace: "pulp"},
+					Data: map[string]string{
+						"api_root":          `"/api/"`,
+						"telemetry":         "False",
+						"invalid-key = 1\n": "ignored",
+					},
+				},
+			},
+		},
+		{
+			name: "s3",
+			pulp: func(pulp *pulpv1.Pulp) {
+				pulp.Spec.FileStorageClass = ""
+				pulp.Spec.ObjectStorageS3Secret = "s3"
+			},
+			objects: []client.Object{
+				settingsTestSecret("s3", map[string]string{
+					"s3-bucket-name": "pulp", "s3-region": "us-east-1", "s3-access-key-id": "key-id",
+					"s3-secret-access-key": injection, "s3-default-acl": "private", "s3-querystring-auth": "false",
+					"s3-object-parameters": `{"CacheControl": "max-age=86400"}`, "s3-max-memory-size": "1024",
+				}),
+			},
+		},
+		{
+			name: "azure",
+			pulp: func(pulp *pulpv1.Pulp) {
+				pulp.Spec.FileStorageClass = ""
+				pulp.Spec.ObjectStorageAzureSecret = "azure"
+			},
+			objects: []client.Object{
+				settingsTestSecret("azure", map[string]string{
+					"azure-account-name": "pulp", "azure-container": "pulp", "azure-account-key": injection,
+					"azure-container-path": "pulp", "azure-overwrite-files": "'False'",
+				}),
+			},
+		},
+		{
+			name: "gcs",
+			pulp: func(pulp *pulpv1.Pulp) {
+				pulp.Spec.FileStorageClass = ""
+				pulp.Spec.ObjectStorageGCSSecret = "gcs"
+			},
+			objects: []client.Object{
+				settingsTestSecret("gcs", map[string]string{
+					"gcs-bucket-name": "pulp", "gcs-project-id": "project", "gcs-expiration": "120",
+				}),
+			},
+		},
+		{
+			name: "secrets-store",
+			pulp: func(pulp *pulpv1.Pulp) {
+				pulp.Spec.IngressType = "ingress"
+				pulp.Spec.IngressHost = "pulp.example.com"
+				pulp.Spec.Database.ExternalDBSecret = "external-db"
+				pulp.Spec.Cache = pulpv1.Cache{Enabled: true, ExternalCacheSecret: "external-cache"}
+				pulp.Spec.OIDC = pulpv1.OIDC{IssuerURL: "https://example.okta.com/oauth2/default", ClientSecret: "oidc"}
+				pulp.Spec.SecretsStore = pulpv1.SecretsStore{
+					ExternalDBSecret:    "pulp-database",
+					ExternalCacheSecret: "pulp-cache",
+					OIDCClientSecret:    "pulp-oidc",
+				}
+			},
+			objects: []client.Object{
+				// the credentials are not in the Secrets, they are provided by the SecretProviderClasses
+				settingsTestSecret("external-db", map[string]string{
+					"POSTGRES_HOST": "db.example.com", "POSTGRES_PORT": "5432", "POSTGRES_USERNAME": "pulp",
+					"POSTGRES_DB_NAME": "pulp", "POSTGRES_SSLMODE": "require",
+				}),
+				settingsTestSecret("external-cache", map[string]string{
+					"REDIS_HOST": "redis.example.com", "REDIS_PORT": "6379", "REDIS_DB": "1",
+				}),
+				settingsTestSecret("oidc", map[string]string{"client_id": "pulp"}),
+			},
+		},
+		{
+			name: "sentinel",
+			pulp: func(pulp *pulpv1.Pulp) {
+				pulp.Spec.Cache = pulpv1.Cache{Enabled: true, Mode: controllers.CacheSentinelMode}
+			},
+		},
+		{
+			name: "ldap-config",
+			pulp: func(pulp *pulpv1.Pulp) {
+				pulp.Spec.LDAP.Config = "ldap"
+			},
+			objects: []client.Object{
+				settingsTestSecret("ldap", map[string]string{
+					"auth_ldap_server_uri":    "ldap://ldap.example.com",
+					"auth_ldap_bind_password": injection,
+					"auth_ldap_user_search":   `LDAPSearch("ou=users,dc=example,dc=com", ldap.SCOPE_SUBTREE, "(uid=%(user)s)")`,
+					"auth_ldap_mirror_groups": "true",
+					"auth_ldap_start_tls":     "False",
+					"invalid_key":             "ignored",
+				}),
+			},
+		},
+		{
+			name: "ldap-oidc",
+			pulp: func(pulp *pulpv1.Pulp) {
+				pulp.Spec.IngressType = "ingress"
+				pulp.Spec.IngressHost = "pulp.example.com"
+				pulp.Spec.LDAP = pulpv1.LDAP{
+					ServerURI:          "ldaps://ldap.example.com",
+					BindDN:             "cn=pulp,dc=example,dc=com",
+					BindPasswordSecret: "ldap-bind",
+					UserSearch:         pulpv1.LDAPSearch{BaseDN: "ou=users,dc=example,dc=com"},
+					GroupSearch:        pulpv1.LDAPSearch{BaseDN: "ou=groups,dc=example,dc=com", Scope: "onelevel", Filter: "(objectClass=groupOfNames)"},
+					UserAttrMap:        map[string]string{"first_name": "givenName", "email": "mail"},
+					MirrorGroups:       true,
+					CA:                 "ldap-ca",
+				}
+				pulp.Spec.OIDC = pulpv1.OIDC{
+					IssuerURL:    "https://example.okta.com/oauth2/default/",
+					ClientSecret: "oidc",
+					ClaimMappings: []pulpv1.OIDCClaimMapping{
+						{Claim: "pulp-admins", Superuser: true},
+						{Claim: "pulp-developers", Groups: []string{"developers"}, Roles: []string{"file.filerepository_creator"}},
+					},
+				}
+			},
+			objects: []client.Object{
+				settingsTestSecret("ldap-bind", map[string]string{"bind_password": injection}),
+				settingsTestSecret("oidc", map[string]string{"client_id": "pulp", "client_secret": injection}),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pulp := settingsTestPulp()
+			if test.pulp != nil {
+				test.pulp(pulp)
+			}
+			objects := []client.Object{pulp, postgres, secretKey}
+			for _, obj := range test.objects {
+				// the objects from the test case replace the default ones
+				for i := range objects {
+					if objects[i].GetName() == obj.GetName() {
+						objects = append(objects[:i], objects[i+1:]...)
+						break
+					}
+				}
+				objects = append(objects, obj)
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+			resources := controllers.FunctionResources{Context: context.TODO(), Client: fakeClient, Pulp: pulp, Scheme: scheme, Logger: logr.Discard()}
+
+			secret := pulpServerSecret(resources).(*corev1.Secret)
+			golden(t, filepath.Join("testdata", "settings", test.name+".py"), secret.StringData["settings.py"])
+		})
+	}
+}
+
+// golden compares got with the content of path
+func golden(t *testing.T, path, got string) {
+	t.Helper()
+	if *updateGolden {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(expected) {
+		t.Errorf("%v does not match the golden file:\n--- got ---\n%v\n--- expected ---\n%v", path, got, string(expected))
+	}
+}
@@ -7,12 +7,13 @@ import (
 	"strings"
 
 	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/pysettings"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
 
 // ssoConfig sets the configurations needed to authenticate pulp through keycloak
-func ssoConfig(resource controllers.FunctionResources, pulpSettings *string) error {
+func ssoConfig(resource controllers.FunctionResources, pulpSettings *pysettings.Settings) error {
 
 	log := resource.Logger
 	client := resource.Client
@@ -45,6 +46,12 @@ func ssoConfig(resource controllers.FunctionResources, pulpSettings *string) err
 		"keycloak_admin_role", "keycloak_group_token_claim", "keycloak_role_token_claim", "keycloak_host_loopback",
 	}
 
+	// the client secret provided by a SecretProviderClass is read from the file mounted in the pods
+	secretsStore := len(controllers.SecretsStoreClass(pulp, controllers.SecretsStoreSSO)) > 0
+	if secretsStore {
+		requiredKeys = slices.DeleteFunc(requiredKeys, func(key string) bool { return key == "social_auth_keycloak_secret" })
+	}
+
 	// retrieve mandatory keys from sso_secret
 	settings, err := controllers.RetrieveSecretData(ctx, pulp.Spec.SSOSecret, pulp.Namespace, true, client, requiredKeys...)
 	if err != nil {
@@ -64,7 +71,10 @@ func ssoConfig(resource controllers.FunctionResources, pulpSettings *string) err
 
 	// Inject SSO settings into pulp_settings
 	for _, key := range slices.Sorted(maps.Keys(settings)) {
-		*pulpSettings = *pulpSettings + fmt.Sprintf("%v = \"%v\"\n", strings.ToUpper(key), settings[key])
+		pulpSettings.Set(strings.ToUpper(key), settings[key])
+	}
+	if secretsStore {
+		pulpSettings.Set("SOCIAL_AUTH_KEYCLOAK_SECRET", pysettings.FileContent(controllers.SecretsStoreFile(controllers.SecretsStoreSSO, "social_auth_keycloak_secret")))
 	}
 
 	return nil
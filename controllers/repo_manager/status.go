@@ -153,7 +153,7 @@ func (r *RepoManagerReconciler) setStatusConditions(ctx context.Context, pulp *p
 // needsIngressStatusUpdate returns false when there is no need to deploy pulp-web, so we will not need to worry about updating .status field with it
 func (r *RepoManagerReconciler) needsIngressStatusUpdate(ctx context.Context, resource pulpResource, pulp *pulpv1.Pulp) bool {
 	if resource.Type == string(settings.WEB) {
-		if isRoute(pulp) || r.isNginxIngress(pulp) {
+		if isRoute(pulp) || isGateway(pulp) || r.isNativeIngress(pulp) {
 			return false
 		}
 		if isIngress(pulp) {
@@ -175,6 +175,7 @@ func setStatusFields(ctx context.Context, pulp *pulpv1.Pulp, r RepoManagerReconc
 	}{
 		{verifyFunc: objAzureSecretCondition(), fieldName: "ObjectStorageAzureSecret"},
 		{verifyFunc: objS3SecretCondition(), fieldName: "ObjectStorageS3Secret"},
+		{verifyFunc: objGCSSecretCondition(), fieldName: "ObjectStorageGCSSecret"},
 		{verifyFunc: dbFieldsEncrSecretCondition(), fieldName: "DBFieldsEncryptionSecret"},
 		{verifyFunc: ingressTypeCondition(), fieldName: "IngressType"},
 		{verifyFunc: containerTokenSecretCondition(), fieldName: "ContainerTokenSecret"},
@@ -233,6 +234,13 @@ func objS3SecretCondition() func(*pulpv1.Pulp) bool {
 	}
 }
 
+// objGCSSecretCondition returns the function to verify if a new pulp.Status.ObjectStorageGCSSecret should be set
+func objGCSSecretCondition() func(*pulpv1.Pulp) bool {
+	return func(pulp *pulpv1.Pulp) bool {
+		return len(pulp.Status.ObjectStorageGCSSecret) == 0 || pulp.Spec.ObjectStorageGCSSecret != pulp.Status.ObjectStorageGCSSecret
+	}
+}
+
 // dbFieldsEncrSecretCondition returns the function to verify if a new pulp.Status.DBFieldsEncryptionSecret should be set
 func dbFieldsEncrSecretCondition() func(*pulpv1.Pulp) bool {
 	return func(pulp *pulpv1.Pulp) bool {
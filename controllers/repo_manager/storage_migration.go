@@ -0,0 +1,546 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/pysettings"
+	"github.com/pulp/pulp-operator/controllers/settings"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	storageMigrationConditionType = "Pulp-Storage-Migration"
+	storageMigrationMountPath     = "/etc/pulp/storage-migration"
+)
+
+// storageMigrationScript copies all the files from the source storage into the destination storage.
+// The storages are loaded from the STORAGES definition in source.py and destination.py (or
+// from /var/lib/pulp/media if there is no STORAGES definition, which means file storage).
+// Files already present in the destination with the same size are skipped, so that an
+// interrupted Job can be resumed. Each copied file is verified with its sha256 checksum.
+const storageMigrationScript = `import hashlib
+import os
+import sys
+
+import django
+
+django.setup()
+
+from django.core.files.storage import FileSystemStorage
+from django.utils.module_loading import import_string
+
+MEDIA_ROOT = "/var/lib/pulp/media"
+
+
+def load_storage(path):
+    namespace = {}
+    with open(path) as f:
+        exec(f.read(), namespace)
+    config = namespace.get("STORAGES", {}).get("default")
+    if not config:
+        os.makedirs(MEDIA_ROOT, exist_ok=True)
+        return FileSystemStorage(location=MEDIA_ROOT)
+    return import_string(config["BACKEND"])(**config.get("OPTIONS", {}))
+
+
+def walk(storage, path=""):
+    dirs, files = storage.listdir(path)
+    for name in files:
+        yield os.path.join(path, name)
+    for name in dirs:
+        yield from walk(storage, os.path.join(path, name))
+
+
+def checksum(storage, name):
+    digest = hashlib.sha256()
+    with storage.open(name, "rb") as f:
+        for chunk in iter(lambda: f.read(1024 * 1024), b""):
+            digest.update(chunk)
+    return digest.hexdigest()
+
+
+source = load_storage("` + storageMigrationMountPath + `/source.py")
+destination = load_storage("` + storageMigrationMountPath + `/destination.py")
+
+copied = skipped = 0
+for name in walk(source):
+    if destination.exists(name):
+        if destination.size(name) == source.size(name):
+            skipped += 1
+            continue
+        destination.delete(name)
+    with source.open(name, "rb") as f:
+        saved = destination.save(name, f)
+    if saved != name or checksum(source, name) != checksum(destination, name):
+        print(f"failed to verify {name}", file=sys.stderr)
+        sys.exit(1)
+    copied += 1
+    if copied % 100 == 0:
+        print(f"copied={copied} skipped={skipped}", flush=True)
+
+summary = f"copied={copied} skipped={skipped}"
+print(summary, flush=True)
+with open("/dev/termination-log", "w") as f:
+    f.write(summary)
+`
+
+// storageMigrationController copies the artifacts to the new storage backend when the storage
+// type changes (for example, from a PVC to S3 or from Azure Blob to a PVC).
+// While the artifacts are copied, pulpcore pods are kept in maintenance (scaled down). After the
+// Job finishes, the settings are updated with the new storage and the pods are redeployed.
+func (r *RepoManagerReconciler) storageMigrationController(ctx context.Context, pulp *pulpv1.Pulp) (*ctrl.Result, error) {
+	migration := pulp.Status.StorageMigration
+
+	if !controllers.StorageMigrationInProgress(pulp) {
+		if !storageMigrationNeeded(pulp) {
+			r.updateFileStoragePVC(ctx, pulp)
+			return nil, nil
+		}
+		return r.startStorageMigration(ctx, pulp)
+	}
+
+	// if the storage type was reverted to the source, there is nothing to copy anymore
+	if destination := controllers.GetStorageType(*pulp)[0]; destination == migration.Source {
+		return r.cancelStorageMigration(ctx, pulp)
+	}
+
+	switch migration.Phase {
+	case controllers.StorageMigrationMaintenance:
+		return r.storageMigrationMaintenance(ctx, pulp)
+	case controllers.StorageMigrationCopying:
+		return r.storageMigrationCopying(ctx, pulp)
+	}
+
+	// the migration failed, it will be retried only after the failed Job is removed
+	job := &batchv1.Job{}
+	if err := r.Get(ctx, types.NamespacedName{Name: migration.Job, Namespace: pulp.Namespace}, job); err != nil && errors.IsNotFound(err) {
+		r.RawLogger.Info("Storage migration Job " + migration.Job + " not found. Retrying the storage migration ...")
+		migration.Phase = controllers.StorageMigrationMaintenance
+		migration.Job = ""
+		r.Status().Update(ctx, pulp)
+		return &ctrl.Result{Requeue: true}, nil
+	}
+	r.RawLogger.Error(nil, "Storage migration failed! Verify the logs from "+migration.Job+" Job and remove it to retry the migration (or revert the storage configuration).")
+	return &ctrl.Result{}, nil
+}
+
+// storageMigrationNeeded returns true if the storage type changed from/to an object storage
+func storageMigrationNeeded(pulp *pulpv1.Pulp) bool {
+	if pulp.Spec.DisableStorageMigration || len(pulp.Status.StorageType) == 0 || !controllers.StorageTypeChanged(pulp) {
+		return false
+	}
+
+	destination := controllers.GetStorageType(*pulp)[0]
+	return controllers.IsObjectStorage(pulp.Status.StorageType) || controllers.IsObjectStorage(destination)
+}
+
+// startStorageMigration records the source and destination storages and puts Pulp in maintenance
+func (r *RepoManagerReconciler) startStorageMigration(ctx context.Context, pulp *pulpv1.Pulp) (*ctrl.Result, error) {
+	source := pulp.Status.StorageType
+	destination := controllers.GetStorageType(*pulp)[0]
+
+	sourceReference := pulp.Status.FileStoragePVC
+	switch source {
+	case controllers.S3ObjType:
+		sourceReference = pulp.Status.ObjectStorageS3Secret
+	case controllers.AzureObjType:
+		sourceReference = pulp.Status.ObjectStorageAzureSecret
+	case controllers.GCSObjType:
+		sourceReference = pulp.Status.ObjectStorageGCSSecret
+	case controllers.SCNameType:
+		if len(sourceReference) == 0 {
+			sourceReference = settings.DefaultPulpFileStorage(pulp.Name)
+		}
+	}
+	if len(sourceReference) == 0 {
+		r.RawLogger.Error(nil, "Could not find the "+source+" storage in use by Pulp to migrate the artifacts from. Set disable_storage_migration to true and copy the artifacts manually.")
+		return &ctrl.Result{}, nil
+	}
+
+	r.RawLogger.Info("Storage type changed from " + source + " to " + destination + ". Starting the migration of the artifacts ...")
+	now := metav1.Now()
+	pulp.Status.StorageMigration = &pulpv1.StorageMigrationStatus{
+		Phase:           controllers.StorageMigrationMaintenance,
+		Source:          source,
+		Destination:     destination,
+		SourceReference: sourceReference,
+		StartTime:       &now,
+	}
+	r.Status().Update(ctx, pulp)
+	controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, storageMigrationConditionType, "Maintenance", "Scaling down pulpcore pods to migrate the artifacts from "+source+" to "+destination)
+	r.recorder.Event(pulp, corev1.EventTypeNormal, "StorageMigrationStarted", "Migrating the artifacts from "+source+" to "+destination)
+	return &ctrl.Result{Requeue: true}, nil
+}
+
+// storageMigrationMaintenance scales down pulpcore pods and, after all of them are
+// terminated, creates the Job to copy the artifacts
+func (r *RepoManagerReconciler) storageMigrationMaintenance(ctx context.Context, pulp *pulpv1.Pulp) (*ctrl.Result, error) {
+	migration := pulp.Status.StorageMigration
+
+	running := false
+	for _, pulpcoreType := range []settings.PulpcoreType{settings.API, settings.CONTENT, settings.WORKER} {
+		deployment := &appsv1.Deployment{}
+		if err := r.Get(ctx, types.NamespacedName{Name: pulpcoreType.DeploymentName(pulp.Name), Namespace: pulp.Namespace}, deployment); err != nil {
+			continue
+		}
+		if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas != 0 {
+			replicas := int32(0)
+			deployment.Spec.Replicas = &replicas
+			if err := r.Update(ctx, deployment); err != nil {
+				r.RawLogger.Error(err, "Failed to scale down "+deployment.Name+" Deployment")
+				return &ctrl.Result{}, err
+			}
+		}
+		if deployment.Status.Replicas != 0 {
+			running = true
+		}
+	}
+	if running {
+		r.RawLogger.Info("Waiting pulpcore pods to be terminated before migrating the artifacts ...")
+		return &ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+
+	if err := r.storageMigrationSecret(ctx, pulp); err != nil {
+		return &ctrl.Result{}, err
+	}
+
+	job := r.storageMigrationJob(ctx, pulp)
+	r.RawLogger.Info("Creating a new " + settings.StorageMigrationJob(pulp.Name) + "* Job")
+	if err := r.Create(ctx, job); err != nil {
+		r.RawLogger.Error(err, "Failed to create "+settings.StorageMigrationJob(pulp.Name)+"* Job!")
+		return &ctrl.Result{}, err
+	}
+
+	migration.Phase = controllers.StorageMigrationCopying
+	migration.Job = job.Name
+	migration.Progress = ""
+	r.Status().Update(ctx, pulp)
+	controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, storageMigrationConditionType, "Copying", "Copying the artifacts from "+migration.Source+" to "+migration.Destination+" (Job "+job.Name+")")
+	return &ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+}
+
+// storageMigrationCopying follows the execution of the storage migration Job
+func (r *RepoManagerReconciler) storageMigrationCopying(ctx context.Context, pulp *pulpv1.Pulp) (*ctrl.Result, error) {
+	migration := pulp.Status.StorageMigration
+
+	job := &batchv1.Job{}
+	if err := r.Get(ctx, types.NamespacedName{Name: migration.Job, Namespace: pulp.Namespace}, job); err != nil {
+		if errors.IsNotFound(err) {
+			r.RawLogger.Info("Storage migration Job " + migration.Job + " not found. Recreating it ...")
+			migration.Phase = controllers.StorageMigrationMaintenance
+			r.Status().Update(ctx, pulp)
+			return &ctrl.Result{Requeue: true}, nil
+		}
+		return &ctrl.Result{}, err
+	}
+
+	if progress := r.storageMigrationProgress(ctx, pulp, job); len(progress) > 0 && progress != migration.Progress {
+		migration.Progress = progress
+		r.Status().Update(ctx, pulp)
+	}
+
+	switch {
+	case job.Status.Succeeded > 0:
+		r.RawLogger.Info("Storage migration finished: " + migration.Progress)
+		now := metav1.Now()
+		migration.Phase = controllers.StorageMigrationCompleted
+		migration.CompletionTime = &now
+		pulp.Status.StorageType = migration.Destination
+		r.Status().Update(ctx, pulp)
+		r.deleteStorageMigrationSecret(ctx, pulp)
+		controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionTrue, storageMigrationConditionType, "Completed", "Artifacts migrated from "+migration.Source+" to "+migration.Destination+": "+migration.Progress)
+		r.recorder.Event(pulp, corev1.EventTypeNormal, "StorageMigrationCompleted", "Artifacts migrated from "+migration.Source+" to "+migration.Destination)
+
+		// redeploy pulpcore pods with the new storage settings
+		r.restartPulpCorePods(ctx, pulp)
+		return &ctrl.Result{Requeue: true}, nil
+	case jobFailed(job):
+		migration.Phase = controllers.StorageMigrationFailed
+		r.Status().Update(ctx, pulp)
+		controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, storageMigrationConditionType, "Failed", "Failed to migrate the artifacts from "+migration.Source+" to "+migration.Destination+". Verify the logs from "+job.Name+" Job.")
+		r.recorder.Event(pulp, corev1.EventTypeWarning, "StorageMigrationFailed", "Failed to migrate the artifacts from "+migration.Source+" to "+migration.Destination)
+		return &ctrl.Result{}, nil
+	}
+
+	return &ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+}
+
+// cancelStorageMigration stops the migration in case the storage configuration was reverted
+func (r *RepoManagerReconciler) cancelStorageMigration(ctx context.Context, pulp *pulpv1.Pulp) (*ctrl.Result, error) {
+	migration := pulp.Status.StorageMigration
+	r.RawLogger.Info("Storage type reverted to " + migration.Source + ". Canceling the storage migration ...")
+
+	if len(migration.Job) > 0 {
+		job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: migration.Job, Namespace: pulp.Namespace}}
+		r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	}
+	r.deleteStorageMigrationSecret(ctx, pulp)
+
+	now := metav1.Now()
+	migration.Phase = controllers.StorageMigrationCanceled
+	migration.CompletionTime = &now
+	r.Status().Update(ctx, pulp)
+	controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionTrue, storageMigrationConditionType, "Canceled", "Storage migration canceled, the storage type was reverted to "+migration.Source)
+	r.recorder.Event(pulp, corev1.EventTypeNormal, "StorageMigrationCanceled", "Storage type reverted to "+migration.Source)
+	return &ctrl.Result{Requeue: true}, nil
+}
+
+// updateFileStoragePVC keeps .status.file_storage_pvc with the PVC in use by pulpcore pods,
+// so that we know where to copy the artifacts from in case the storage type changes
+func (r *RepoManagerReconciler) updateFileStoragePVC(ctx context.Context, pulp *pulpv1.Pulp) {
+	if controllers.StorageTypeChanged(pulp) {
+		return
+	}
+	pvc := fileStorageClaim(pulp)
+	if pulp.Status.FileStoragePVC != pvc {
+		pulp.Status.FileStoragePVC = pvc
+		r.Status().Update(ctx, pulp)
+	}
+}
+
+// fileStorageClaim returns the name of the PVC used by pulpcore pods (if any)
+func fileStorageClaim(pulp *pulpv1.Pulp) string {
+	switch controllers.GetStorageType(*pulp)[0] {
+	case controllers.SCNameType:
+		return settings.DefaultPulpFileStorage(pulp.Name)
+	case controllers.PVCType:
+		return pulp.Spec.PVC
+	}
+	return ""
+}
+
+// storagePulp returns a copy of pulp configured only with the storage provided, so that
+// we can reuse the settings.py functions to get the STORAGES definition of each side of the migration
+func storagePulp(pulp *pulpv1.Pulp, storageType, reference string) *pulpv1.Pulp {
+	view := pulp.DeepCopy()
+	view.Spec.ObjectStorageS3Secret = ""
+	view.Spec.ObjectStorageAzureSecret = ""
+	view.Spec.ObjectStorageGCSSecret = ""
+	view.Spec.FileStorageClass = ""
+	view.Spec.PVC = ""
+
+	switch storageType {
+	case controllers.S3ObjType:
+		view.Spec.ObjectStorageS3Secret = reference
+	case controllers.AzureObjType:
+		view.Spec.ObjectStorageAzureSecret = reference
+	case controllers.GCSObjType:
+		view.Spec.ObjectStorageGCSSecret = reference
+	default:
+		view.Spec.PVC = reference
+	}
+	return view
+}
+
+// storagesDefinition returns the STORAGES definition (settings.py format) of the storage provided
+func (r *RepoManagerReconciler) storagesDefinition(ctx context.Context, pulp *pulpv1.Pulp) string {
+	resources := controllers.FunctionResources{Context: ctx, Client: r.Client, Pulp: pulp, Scheme: r.Scheme, Logger: r.RawLogger}
+	definition := pysettings.New()
+	azureSettings(resources, definition, map[string]struct{}{})
+	s3Settings(resources, definition, map[string]struct{}{})
+	gcsSettings(resources, definition, map[string]struct{}{})
+	return definition.Render()
+}
+
+// storageMigrationSecret creates (or updates) the Secret with the script and the source and
+// destination STORAGES definitions used by the storage migration Job
+func (r *RepoManagerReconciler) storageMigrationSecret(ctx context.Context, pulp *pulpv1.Pulp) error {
+	migration := pulp.Status.StorageMigration
+	source := storagePulp(pulp, migration.Source, migration.SourceReference)
+
+	expected := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      settings.StorageMigrationSecret(pulp.Name),
+			Namespace: pulp.Namespace,
+			Labels:    jobLabels(*pulp),
+		},
+		StringData: map[string]string{
+			"migrate.py":     storageMigrationScript,
+			"source.py":      r.storagesDefinition(ctx, source),
+			"destination.py": r.storagesDefinition(ctx, pulp),
+		},
+	}
+	ctrl.SetControllerReference(pulp, expected, r.Scheme)
+
+	found := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: expected.Name, Namespace: pulp.Namespace}, found); err != nil {
+		if errors.IsNotFound(err) {
+			return r.Create(ctx, expected)
+		}
+		return err
+	}
+	found.Data = nil
+	found.StringData = expected.StringData
+	return r.Update(ctx, found)
+}
+
+// deleteStorageMigrationSecret removes the Secret used by the storage migration Job
+// (it contains a copy of the object storage credentials)
+func (r *RepoManagerReconciler) deleteStorageMigrationSecret(ctx context.Context, pulp *pulpv1.Pulp) {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: settings.StorageMigrationSecret(pulp.Name), Namespace: pulp.Namespace}}
+	if err := r.Delete(ctx, secret); err != nil && !errors.IsNotFound(err) {
+		r.RawLogger.Error(err, "Failed to remove "+secret.Name+" Secret")
+	}
+}
+
+// storageMigrationJob returns the definition of the Job that copies the artifacts
+func (r *RepoManagerReconciler) storageMigrationJob(ctx context.Context, pulp *pulpv1.Pulp) *batchv1.Job {
+	migration := pulp.Status.StorageMigration
+	labels := jobLabels(*pulp)
+	labels["app.kubernetes.io/component"] = "storage-migration"
+
+	volumes := pulpcoreVolumes(pulp, "")
+	volumes = append(volumes, corev1.Volume{
+		Name: "storage-migration",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: settings.StorageMigrationSecret(pulp.Name)},
+		},
+	})
+	container := storageMigrationContainer(pulp)
+
+	// the file storage side of the migration (if any) is mounted in /var/lib/pulp
+	claim := fileStorageClaim(pulp)
+	if !controllers.IsObjectStorage(migration.Source) {
+		claim = migration.SourceReference
+	}
+	if len(claim) > 0 {
+		volumes = append(volumes, corev1.Volume{
+			Name: "file-storage",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+			},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: "file-storage", MountPath: "/var/lib/pulp"})
+	}
+
+	// credentials provided by the cloud provider
+	gcsSecret := pulp.Spec.ObjectStorageGCSSecret
+	if migration.Source == controllers.GCSObjType {
+		gcsSecret = migration.SourceReference
+	}
+	if gcsSecret == pulp.Spec.ObjectStorageGCSSecret && len(controllers.SecretsStoreClass(pulp, controllers.SecretsStoreGCS)) > 0 {
+		// the key of the Pulp CR storage can be provided by a SecretProviderClass
+		volume := controllers.SecretsStoreVolume(pulp, controllers.SecretsStoreGCS)
+		volumes = append(volumes, volume)
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: volume.Name, MountPath: path.Join(controllers.SecretsStorePath, controllers.SecretsStoreGCS), ReadOnly: true})
+		container.Env = append(container.Env, corev1.EnvVar{Name: "GOOGLE_APPLICATION_CREDENTIALS", Value: controllers.SecretsStoreFile(controllers.SecretsStoreGCS, "gcs-credentials")})
+	} else if gcsCredentials, _ := controllers.RetrieveSecretData(ctx, gcsSecret, pulp.Namespace, true, r.Client, "gcs-credentials"); len(gcsCredentials) > 0 {
+		volumes = append(volumes, corev1.Volume{
+			Name: "gcs-credentials",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: gcsSecret,
+					Items:      []corev1.KeyToPath{{Key: "gcs-credentials", Path: "gcs-credentials.json"}},
+				},
+			},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: "gcs-credentials", MountPath: controllers.GCSCredentialsPath, SubPath: "gcs-credentials.json", ReadOnly: true})
+		container.Env = append(container.Env, corev1.EnvVar{Name: "GOOGLE_APPLICATION_CREDENTIALS", Value: controllers.GCSCredentialsPath})
+	}
+
+	backOffLimit := int32(2)
+	job := commonJob(pulpJobConfig{
+		settings.StorageMigrationJob(pulp.Name),
+		pulp.Namespace,
+		settings.PulpServiceAccount(pulp.Name),
+		labels,
+		&backOffLimit,
+		nil, // the Job is kept to track its state (and to allow retrying in case of failure)
+		[]corev1.Container{container},
+		volumes,
+	})
+	if controllers.AzureWorkloadIdentityEnabled(ctx, r.Client, storagePulp(pulp, migration.Source, migration.SourceReference)) || controllers.AzureWorkloadIdentityEnabled(ctx, r.Client, pulp) {
+		job.Spec.Template.Labels = map[string]string{controllers.AzureWIUseLabel: "true"}
+		for k, v := range labels {
+			job.Spec.Template.Labels[k] = v
+		}
+	}
+
+	ctrl.SetControllerReference(pulp, job, r.Scheme)
+	return job
+}
+
+// storageMigrationContainer defines the container spec for the storage migration Job
+func storageMigrationContainer(pulp *pulpv1.Pulp) corev1.Container {
+	envVars := controllers.GetPostgresEnvVars(*pulp)
+	envVars = append(envVars, controllers.SetCustomEnvVars(*pulp, "StorageMigrationJob")...)
+	envVars = append(envVars, corev1.EnvVar{Name: "DJANGO_SETTINGS_MODULE", Value: "pulpcore.app.settings"})
+
+	volumeMounts := pulpcoreVolumeMounts(pulp)
+	volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: "storage-migration", MountPath: storageMigrationMountPath, ReadOnly: true})
+
+	return corev1.Container{
+		Name:            "storage-migration",
+		Image:           pulp.Spec.Image + ":" + pulp.Spec.ImageVersion,
+		ImagePullPolicy: corev1.PullPolicy(pulp.Spec.ImagePullPolicy),
+		Env:             envVars,
+		Command:         []string{"python3"},
+		Args:            []string{storageMigrationMountPath + "/migrate.py"},
+		Resources:       pulp.Spec.StorageMigrationJob.PulpContainer.ResourceRequirements,
+		VolumeMounts:    volumeMounts,
+		SecurityContext: controllers.SetDefaultSecurityContext(),
+	}
+}
+
+// storageMigrationProgress returns the last progress report from the storage migration Job
+// (the termination message after the Job finishes or the last log line while it is running)
+func (r *RepoManagerReconciler) storageMigrationProgress(ctx context.Context, pulp *pulpv1.Pulp, job *batchv1.Job) string {
+	podList := &corev1.PodList{}
+	r.List(ctx, podList, client.InNamespace(pulp.Namespace), client.MatchingLabels{"job-name": job.Name})
+
+	progress := ""
+	for _, pod := range podList.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Terminated != nil && status.State.Terminated.ExitCode == 0 {
+				return status.State.Terminated.Message
+			}
+		}
+		if pod.Status.Phase != corev1.PodRunning || r.RESTClient == nil {
+			continue
+		}
+		logs, err := r.RESTClient.Get().Namespace(pod.Namespace).Resource("pods").Name(pod.Name).SubResource("log").
+			Param("container", "storage-migration").Param("tailLines", "1").Do(ctx).Raw()
+		if err == nil && strings.HasPrefix(string(logs), "copied=") {
+			progress = strings.TrimSpace(string(logs))
+		}
+	}
+	return progress
+}
+
+// jobFailed returns true if the Job has the Failed condition
+func jobFailed(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
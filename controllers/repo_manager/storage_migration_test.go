@@ -0,0 +1,194 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/settings"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newTestReconciler returns a RepoManagerReconciler with a fake client populated with objects
+func newTestReconciler(objects ...client.Object) *RepoManagerReconciler {
+	scheme := runtime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	pulpv1.AddToScheme(scheme)
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).WithStatusSubresource(&pulpv1.Pulp{}).Build()
+	return &RepoManagerReconciler{Client: k8sClient, RawLogger: logr.Discard(), Scheme: scheme, recorder: record.NewFakeRecorder(100)}
+}
+
+// pulpcoreDeployments returns the api, content and worker Deployments with running pods
+func pulpcoreDeployments(pulp *pulpv1.Pulp) []client.Object {
+	objects := []client.Object{}
+	for _, pulpcoreType := range []settings.PulpcoreType{settings.API, settings.CONTENT, settings.WORKER} {
+		replicas := int32(1)
+		objects = append(objects, &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: pulpcoreType.DeploymentName(pulp.Name), Namespace: pulp.Namespace},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{Replicas: 1},
+		})
+	}
+	return objects
+}
+
+func TestStorageMigration(t *testing.T) {
+	ctx := context.TODO()
+	pulp := settingsTestPulp()
+	pulp.Spec.FileStorageClass = ""
+	pulp.Spec.ObjectStorageS3Secret = "s3"
+	pulp.Status.StorageType = controllers.SCNameType
+	s3 := settingsTestSecret("s3", map[string]string{
+		"s3-access-key-id": "key", "s3-secret-access-key": "secret", "s3-bucket-name": "pulp", "s3-region": "us-east-1",
+	})
+	objects := append(pulpcoreDeployments(pulp), pulp, s3)
+	r := newTestReconciler(objects...)
+	migration := func() *pulpv1.StorageMigrationStatus { return pulp.Status.StorageMigration }
+
+	// the storage type changed from a StorageClass to S3
+	if result, _ := r.storageMigrationController(ctx, pulp); result == nil || migration() == nil ||
+		migration().Phase != controllers.StorageMigrationMaintenance || migration().SourceReference != settings.DefaultPulpFileStorage(pulp.Name) {
+		t.Fatalf("expected the migration to start from the default file storage PVC, got %+v", migration())
+	}
+
+	// the pulpcore pods are scaled down before the Job is created
+	r.storageMigrationController(ctx, pulp)
+	if migration().Phase != controllers.StorageMigrationMaintenance {
+		t.Fatalf("expected the migration to wait for the pulpcore pods, got %v", migration().Phase)
+	}
+	for _, pulpcoreType := range []settings.PulpcoreType{settings.API, settings.CONTENT, settings.WORKER} {
+		deployment := &appsv1.Deployment{}
+		r.Get(ctx, types.NamespacedName{Name: pulpcoreType.DeploymentName(pulp.Name), Namespace: pulp.Namespace}, deployment)
+		if *deployment.Spec.Replicas != 0 {
+			t.Errorf("expected %v to be scaled down", deployment.Name)
+		}
+		deployment.Status.Replicas = 0
+		r.Status().Update(ctx, deployment)
+	}
+
+	// the Job is created with the source and destination storages
+	r.storageMigrationController(ctx, pulp)
+	if migration().Phase != controllers.StorageMigrationCopying || len(migration().Job) == 0 {
+		t.Fatalf("expected the migration Job to be created, got %+v", migration())
+	}
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: settings.StorageMigrationSecret(pulp.Name), Namespace: pulp.Namespace}, secret); err != nil {
+		t.Fatal(err)
+	}
+	if destination := secret.StringData["destination.py"]; len(destination) == 0 || secret.StringData["source.py"] != "" {
+		t.Errorf("expected the S3 destination and the file source storages, got %v", secret.StringData)
+	}
+
+	// a failed Job is only retried after it is removed
+	job := &batchv1.Job{}
+	r.Get(ctx, types.NamespacedName{Name: migration().Job, Namespace: pulp.Namespace}, job)
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}
+	r.Status().Update(ctx, job)
+	r.storageMigrationController(ctx, pulp)
+	r.storageMigrationController(ctx, pulp)
+	if migration().Phase != controllers.StorageMigrationFailed || !controllers.StorageMigrationInProgress(pulp) {
+		t.Fatalf("expected the migration to fail and keep pulp in maintenance, got %v", migration().Phase)
+	}
+	r.Delete(ctx, job)
+	r.storageMigrationController(ctx, pulp)
+	if migration().Phase != controllers.StorageMigrationMaintenance || len(migration().Job) != 0 {
+		t.Fatalf("expected the migration to be retried, got %+v", migration())
+	}
+
+	// the storage type is only updated after the Job succeeds
+	r.storageMigrationController(ctx, pulp)
+	r.Get(ctx, types.NamespacedName{Name: migration().Job, Namespace: pulp.Namespace}, job)
+	job.Status.Succeeded = 1
+	r.Status().Update(ctx, job)
+	if pulp.Status.StorageType != controllers.SCNameType {
+		t.Errorf("expected the storage type to be kept while the artifacts are copied")
+	}
+	r.storageMigrationController(ctx, pulp)
+	if migration().Phase != controllers.StorageMigrationCompleted || pulp.Status.StorageType != controllers.S3ObjType || controllers.StorageMigrationInProgress(pulp) {
+		t.Fatalf("expected the migration to complete, got %+v", migration())
+	}
+	if err := r.Get(ctx, types.NamespacedName{Name: settings.StorageMigrationSecret(pulp.Name), Namespace: pulp.Namespace}, secret); !errors.IsNotFound(err) {
+		t.Errorf("expected the migration Secret to be removed, got %v", err)
+	}
+}
+
+func TestStorageMigrationCanceled(t *testing.T) {
+	ctx := context.TODO()
+	pulp := settingsTestPulp()
+	pulp.Status.StorageType = controllers.SCNameType
+	pulp.Status.StorageMigration = &pulpv1.StorageMigrationStatus{
+		Phase:           controllers.StorageMigrationCopying,
+		Source:          controllers.SCNameType,
+		Destination:     controllers.S3ObjType,
+		SourceReference: settings.DefaultPulpFileStorage(pulp.Name),
+		Job:             "test-storage-migration-abcde",
+	}
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "test-storage-migration-abcde", Namespace: pulp.Namespace}}
+	r := newTestReconciler(pulp, job)
+
+	// spec.file_storage_storage_class is still the source storage
+	r.storageMigrationController(ctx, pulp)
+	if pulp.Status.StorageMigration.Phase != controllers.StorageMigrationCanceled || controllers.StorageMigrationInProgress(pulp) {
+		t.Fatalf("expected the migration to be canceled, got %+v", pulp.Status.StorageMigration)
+	}
+	if err := r.Get(ctx, types.NamespacedName{Name: job.Name, Namespace: pulp.Namespace}, job); !errors.IsNotFound(err) {
+		t.Errorf("expected the migration Job to be removed, got %v", err)
+	}
+}
+
+func TestStorageMigrationNeeded(t *testing.T) {
+	tests := []struct {
+		name     string
+		pulp     func(*pulpv1.Pulp)
+		expected bool
+	}{
+		{"first deployment", func(p *pulpv1.Pulp) { p.Status.StorageType = "" }, false},
+		{"unchanged", func(p *pulpv1.Pulp) {}, false},
+		{"file to s3", func(p *pulpv1.Pulp) { p.Spec.FileStorageClass = ""; p.Spec.ObjectStorageS3Secret = "s3" }, true},
+		{"s3 to file", func(p *pulpv1.Pulp) { p.Status.StorageType = controllers.S3ObjType }, true},
+		{"storage class to pvc", func(p *pulpv1.Pulp) { p.Spec.FileStorageClass = ""; p.Spec.PVC = "pulp-file" }, false},
+		{"disabled", func(p *pulpv1.Pulp) {
+			p.Spec.FileStorageClass = ""
+			p.Spec.ObjectStorageS3Secret = "s3"
+			p.Spec.DisableStorageMigration = true
+		}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pulp := settingsTestPulp()
+			pulp.Status.StorageType = controllers.SCNameType
+			test.pulp(pulp)
+			if got := storageMigrationNeeded(pulp); got != test.expected {
+				t.Errorf("storageMigrationNeeded() = %v, expected %v", got, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"encoding/json"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/pysettings"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// keys from the object storage Secrets that are handled by the operator and
+// should not be passed through as django-storages OPTIONS
+var (
+	s3ManagedKeys    = []string{"s3-access-key-id", "s3-secret-access-key", "s3-bucket-name", "s3-endpoint", "s3-region", "s3-role-arn"}
+	azureManagedKeys = []string{"azure-account-name", "azure-account-key", "azure-container", "azure-container-path", "azure-connection-string", "azure-client-id", "azure-tenant-id"}
+	gcsManagedKeys   = []string{"gcs-bucket-name", "gcs-project-id", "gcs-location", "gcs-credentials"}
+)
+
+// pythonNumber matches the integers and floats that are valid python literals
+// (python does not accept leading zeros, inf or nan as literals)
+var pythonNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+
+// storageOptions holds the extra django-storages OPTIONS found in an object storage Secret.
+// The values are already converted into the types rendered by pysettings.
+type storageOptions map[string]any
+
+// extraStorageOptions returns the keys from the object storage Secret with the given prefix
+// that are not managed by the operator as django-storages OPTIONS.
+// For example, the "s3-default-acl" key is rendered as the "default_acl" option.
+func extraStorageOptions(resources controllers.FunctionResources, secretName, prefix string, managedKeys []string) storageOptions {
+	options := storageOptions{}
+	secret := &corev1.Secret{}
+	if err := resources.Client.Get(resources.Context, types.NamespacedName{Name: secretName, Namespace: resources.Pulp.Namespace}, secret); err != nil {
+		return options
+	}
+
+	for key, value := range secret.Data {
+		if !strings.HasPrefix(key, prefix) || slices.Contains(managedKeys, key) {
+			continue
+		}
+		option := strings.ReplaceAll(strings.TrimPrefix(key, prefix), "-", "_")
+		options[option] = storageOptionValue(string(value))
+	}
+	return options
+}
+
+// pop returns the value of option (or defaultValue if it was not provided)
+// and removes it from the list of options, so that it is not rendered twice
+func (o storageOptions) pop(option string, defaultValue any) any {
+	if value, found := o[option]; found {
+		delete(o, option)
+		return value
+	}
+	return defaultValue
+}
+
+// items returns the remaining options (sorted by name) as items of
+// the STORAGES["default"]["OPTIONS"] dict
+func (o storageOptions) items() pysettings.Dict {
+	names := make([]string, 0, len(o))
+	for name := range o {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	options := pysettings.Dict{}
+	for _, name := range names {
+		options = append(options, pysettings.Item{Key: name, Value: o[name]})
+	}
+	return options
+}
+
+// storageOptionValue converts the value from a Secret key into the python type of the option:
+//   - true/false into True/False
+//   - none/null into None
+//   - integers and floats are kept as numbers
+//   - json objects and arrays into python dicts and lists
+//   - quoted values ("..." or '...') and everything else into python strings
+func storageOptionValue(value string) any {
+	value = strings.TrimSpace(value)
+
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+
+	switch strings.ToLower(value) {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "none", "null":
+		return nil
+	}
+
+	if pythonNumber.MatchString(value) {
+		return pysettings.Expr(value)
+	}
+
+	if strings.HasPrefix(value, "{") || strings.HasPrefix(value, "[") {
+		var jsonValue any
+		if err := json.Unmarshal([]byte(value), &jsonValue); err == nil {
+			return jsonValue
+		}
+	}
+
+	return value
+}
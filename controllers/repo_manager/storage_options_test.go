@@ -0,0 +1,100 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/pysettings"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestStorageOptionValue(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected any
+	}{
+		{"true", true},
+		{" False ", false},
+		{"None", nil},
+		{"null", nil},
+		{"3600", pysettings.Expr("3600")},
+		{"-1.5e3", pysettings.Expr("-1.5e3")},
+		{"0755", "0755"},
+		{"inf", "inf"},
+		{`"true"`, "true"},
+		{"'10'", "10"},
+		{`{"CacheControl": "max-age=86400"}`, map[string]any{"CacheControl": "max-age=86400"}},
+		{`["a", 1]`, []any{"a", float64(1)}},
+		{"{not json", "{not json"},
+		{"public-read", "public-read"},
+	}
+	for _, test := range tests {
+		if got := storageOptionValue(test.value); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("storageOptionValue(%q) = %#v, expected %#v", test.value, got, test.expected)
+		}
+	}
+}
+
+func TestExtraStorageOptions(t *testing.T) {
+	pulp := &pulpv1.Pulp{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "pulp"}}
+	secret := settingsTestSecret("s3", map[string]string{
+		"s3-bucket-name":        "pulp",
+		"s3-region":             "us-east-1",
+		"s3-default-acl":        "private",
+		"s3-max-memory-size":    "1048576",
+		"s3-object-parameters":  `{"CacheControl": "max-age=86400"}`,
+		"azure-expiration-secs": "60",
+		"other":                 "value",
+	})
+	resources := controllers.FunctionResources{
+		Context: context.TODO(),
+		Client:  fake.NewClientBuilder().WithObjects(secret).Build(),
+		Pulp:    pulp,
+	}
+
+	options := extraStorageOptions(resources, "s3", "s3-", s3ManagedKeys)
+	expected := storageOptions{
+		"default_acl":       "private",
+		"max_memory_size":   pysettings.Expr("1048576"),
+		"object_parameters": map[string]any{"CacheControl": "max-age=86400"},
+	}
+	if !reflect.DeepEqual(options, expected) {
+		t.Fatalf("unexpected options %#v", options)
+	}
+
+	// the options popped are not rendered again
+	if acl := options.pop("default_acl", "public-read"); acl != "private" {
+		t.Errorf("expected the default_acl from the Secret, got %v", acl)
+	}
+	if signature := options.pop("signature_version", "s3v4"); signature != "s3v4" {
+		t.Errorf("expected the default signature_version, got %v", signature)
+	}
+	items := options.items()
+	if len(items) != 2 || items[0].Key != "max_memory_size" || items[1].Key != "object_parameters" {
+		t.Errorf("expected the remaining options sorted by name, got %v", items)
+	}
+
+	if options := extraStorageOptions(resources, "missing", "s3-", s3ManagedKeys); len(options) != 0 {
+		t.Errorf("expected no options from a missing Secret, got %v", options)
+	}
+}
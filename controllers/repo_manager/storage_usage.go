@@ -0,0 +1,250 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/settings"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	fileStorageUsageCondition        = "Pulp-File-Storage-Usage"
+	defaultFileStorageUsageThreshold = 85
+	defaultFileStorageUsageInterval  = 10 * time.Minute
+	fileStorageUsageJobPoll          = 10 * time.Second
+)
+
+// fileStorageUsageScript reports (in the termination message) the capacity, used and available
+// bytes of the file storage volume
+const fileStorageUsageScript = `import os
+s = os.statvfs("/var/lib/pulp")
+usage = "%d %d %d" % (s.f_blocks * s.f_frsize, (s.f_blocks - s.f_bfree) * s.f_frsize, s.f_bavail * s.f_frsize)
+print(usage)
+with open("/dev/termination-log", "w") as f:
+    f.write(usage)`
+
+// fileStorageUsage samples the usage of the file storage volume (through a Job mounting the
+// file storage PVC) and updates .status.file_storage_usage and the Pulp-File-Storage-Usage
+// condition. It returns the time to wait before the next sample.
+func (r *RepoManagerReconciler) fileStorageUsage(ctx context.Context, pulp *pulpv1.Pulp) ctrl.Result {
+	log := r.RawLogger
+
+	if len(fileStorageClaim(pulp)) == 0 {
+		return ctrl.Result{}
+	}
+
+	threshold := int32(defaultFileStorageUsageThreshold)
+	if pulp.Spec.FileStorageUsageThreshold != nil {
+		threshold = *pulp.Spec.FileStorageUsageThreshold
+	}
+	if threshold == 0 {
+		return ctrl.Result{}
+	}
+
+	interval := defaultFileStorageUsageInterval
+	if duration, err := time.ParseDuration(pulp.Spec.FileStorageUsageInterval); err == nil && duration > 0 {
+		interval = duration
+	}
+
+	// wait for the next sample
+	if usage := pulp.Status.FileStorageUsage; usage != nil && usage.LastSampleTime != nil {
+		if elapsed := time.Since(usage.LastSampleTime.Time); elapsed < interval {
+			return ctrl.Result{RequeueAfter: interval - elapsed}
+		}
+	}
+
+	// the Job from the previous sample (if any)
+	jobList := &batchv1.JobList{}
+	if err := r.List(ctx, jobList, client.InNamespace(pulp.Namespace), client.MatchingLabels(fileStorageUsageJobLabels(pulp))); err != nil {
+		log.Error(err, "Failed to list the file storage usage Jobs")
+		return ctrl.Result{RequeueAfter: interval}
+	}
+	if len(jobList.Items) == 0 {
+		job := r.fileStorageUsageJob(pulp)
+		log.V(1).Info("Creating a new " + settings.FileStorageUsageJob(pulp.Name) + "* Job")
+		if err := r.Create(ctx, job); err != nil {
+			log.Error(err, "Failed to create the file storage usage Job")
+			return ctrl.Result{RequeueAfter: interval}
+		}
+		return ctrl.Result{RequeueAfter: fileStorageUsageJobPoll}
+	}
+	job := &jobList.Items[0]
+	if job.Status.Succeeded == 0 && !jobFailed(job) {
+		// the Job could not be scheduled (for example, no api pod running)
+		if time.Since(job.CreationTimestamp.Time) > interval {
+			log.Info("The " + job.Name + " Job did not finish in " + interval.String() + ". Removing it ...")
+			r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		}
+		return ctrl.Result{RequeueAfter: fileStorageUsageJobPoll}
+	}
+
+	// a failed Job is kept until the next sample, so its logs can be verified
+	if jobFailed(job) {
+		if elapsed := time.Since(job.CreationTimestamp.Time); elapsed < interval {
+			log.Info("Failed to sample the file storage usage. Verify the logs from " + job.Name + " Job.")
+			return ctrl.Result{RequeueAfter: interval - elapsed}
+		}
+		r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		return ctrl.Result{Requeue: true}
+	}
+
+	output := r.jobTerminationMessage(ctx, job, "file-storage-usage")
+	r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	capacity, used, available, err := parseFileStorageUsage(output)
+	if err != nil {
+		log.Error(err, "Failed to parse the file storage usage from "+job.Name+" Job")
+		return ctrl.Result{RequeueAfter: interval}
+	}
+
+	// same rounding as df: the percentage is calculated over the space available
+	// for unprivileged users and rounded up
+	usedPercent := int32(0)
+	if total := used + available; total > 0 {
+		usedPercent = int32((used*100 + total - 1) / total)
+	}
+
+	now := metav1.Now()
+	pulp.Status.FileStorageUsage = &pulpv1.FileStorageUsageStatus{
+		Capacity:       formatStorageSize(capacity),
+		Used:           formatStorageSize(used),
+		Available:      formatStorageSize(available),
+		UsedPercent:    usedPercent,
+		LastSampleTime: &now,
+	}
+
+	condition := metav1.Condition{
+		Type:    fileStorageUsageCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  "UsageBelowThreshold",
+		Message: fmt.Sprintf("%d%% of the file storage in use", usedPercent),
+	}
+	if usedPercent >= threshold {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "UsageAboveThreshold"
+		condition.Message = fmt.Sprintf("%d%% of the file storage in use (threshold: %d%%)", usedPercent, threshold)
+	}
+	v1.SetStatusCondition(&pulp.Status.Conditions, condition)
+
+	if err := r.Status().Update(ctx, pulp); err != nil {
+		log.V(1).Info("Failed to update the file storage usage status", "error", err)
+		return ctrl.Result{Requeue: true}
+	}
+
+	if condition.Status == metav1.ConditionFalse {
+		log.Info("File storage usage above threshold", "used", usedPercent, "threshold", threshold)
+		r.recorder.Event(pulp, corev1.EventTypeWarning, "FileStorageAlmostFull", condition.Message)
+	}
+
+	return ctrl.Result{RequeueAfter: interval}
+}
+
+// fileStorageUsageJobLabels returns the labels of the file storage usage Job
+func fileStorageUsageJobLabels(pulp *pulpv1.Pulp) map[string]string {
+	labels := jobLabels(*pulp)
+	labels["app.kubernetes.io/component"] = "file-storage-usage"
+	return labels
+}
+
+// fileStorageUsageJob returns the Job that samples the usage of the file storage volume.
+// The Job runs in the same node of an api pod, so that RWO volumes can also be mounted.
+func (r *RepoManagerReconciler) fileStorageUsageJob(pulp *pulpv1.Pulp) *batchv1.Job {
+	containers := []corev1.Container{{
+		Name:            "file-storage-usage",
+		Image:           pulpcoreImage(pulp),
+		ImagePullPolicy: corev1.PullPolicy(pulp.Spec.ImagePullPolicy),
+		Command:         []string{"python3", "-c", fileStorageUsageScript},
+		VolumeMounts:    []corev1.VolumeMount{{Name: "file-storage", MountPath: "/var/lib/pulp", ReadOnly: true}},
+		SecurityContext: controllers.SetDefaultSecurityContext(),
+	}}
+	volumes := []corev1.Volume{{
+		Name: "file-storage",
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: fileStorageClaim(pulp), ReadOnly: true},
+		},
+	}}
+	backOffLimit := int32(0)
+
+	job := commonJob(pulpJobConfig{
+		settings.FileStorageUsageJob(pulp.Name),
+		pulp.Namespace,
+		settings.PulpServiceAccount(pulp.Name),
+		fileStorageUsageJobLabels(pulp),
+		&backOffLimit,
+		nil, // the Job is removed after its result is read
+		containers,
+		volumes,
+	})
+	job.Spec.Template.Spec.Affinity = &corev1.Affinity{
+		PodAffinity: &corev1.PodAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: settings.PulpcoreLabels(*pulp, "api")},
+				TopologyKey:   "kubernetes.io/hostname",
+			}},
+		},
+	}
+	ctrl.SetControllerReference(pulp, job, r.Scheme)
+	return job
+}
+
+// jobTerminationMessage returns the termination message of the container from the Job pods
+func (r *RepoManagerReconciler) jobTerminationMessage(ctx context.Context, job *batchv1.Job, container string) string {
+	podList := &corev1.PodList{}
+	r.List(ctx, podList, client.InNamespace(job.Namespace), client.MatchingLabels{"job-name": job.Name})
+	for _, pod := range podList.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name == container && status.State.Terminated != nil && len(status.State.Terminated.Message) > 0 {
+				return status.State.Terminated.Message
+			}
+		}
+	}
+	return ""
+}
+
+// parseFileStorageUsage returns the capacity, used and available bytes from the output of fileStorageUsageScript
+func parseFileStorageUsage(output string) (capacity, used, available int64, err error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) != 3 {
+		return 0, 0, 0, fmt.Errorf("unexpected output: %q", output)
+	}
+	values := make([]int64, 3)
+	for i, field := range fields {
+		if values[i], err = strconv.ParseInt(field, 10, 64); err != nil {
+			return 0, 0, 0, err
+		}
+	}
+	return values[0], values[1], values[2], nil
+}
+
+// formatStorageSize returns the size (rounded down to Mi) in the k8s quantity format
+func formatStorageSize(size int64) string {
+	return resource.NewQuantity(size/(1<<20)*(1<<20), resource.BinarySI).String()
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestParseFileStorageUsage(t *testing.T) {
+	capacity, used, available, err := parseFileStorageUsage("10737418240 8805478400 1931939840")
+	if err != nil || capacity != 10737418240 || used != 8805478400 || available != 1931939840 {
+		t.Errorf("unexpected usage %v %v %v: %v", capacity, used, available, err)
+	}
+	for _, output := range []string{"", "1 2", "1 2 x"} {
+		if _, _, _, err := parseFileStorageUsage(output); err == nil {
+			t.Errorf("expected an error for %q", output)
+		}
+	}
+	if size := formatStorageSize(8805478400); size != "8397Mi" {
+		t.Errorf("unexpected size %v", size)
+	}
+}
+
+func TestFileStorageUsage(t *testing.T) {
+	ctx := context.TODO()
+	pulp := settingsTestPulp()
+	r := newTestReconciler(pulp)
+	jobs := func() []batchv1.Job {
+		jobList := &batchv1.JobList{}
+		r.List(ctx, jobList, client.MatchingLabels(fileStorageUsageJobLabels(pulp)))
+		return jobList.Items
+	}
+
+	// the usage is sampled by a Job mounting the file storage PVC
+	if result := r.fileStorageUsage(ctx, pulp); result.RequeueAfter != fileStorageUsageJobPoll || len(jobs()) != 1 {
+		t.Fatalf("expected the file storage usage Job to be created, got %v", result)
+	}
+	// the fake client does not set the creationTimestamp
+	job := jobs()[0]
+	job.CreationTimestamp = metav1.Now()
+	r.Update(ctx, &job)
+	if claim := job.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim; claim == nil || claim.ClaimName != "test-file-storage" {
+		t.Errorf("expected the file storage PVC to be mounted, got %v", job.Spec.Template.Spec.Volumes)
+	}
+	r.fileStorageUsage(ctx, pulp)
+	if len(jobs()) != 1 || pulp.Status.FileStorageUsage != nil {
+		t.Fatalf("expected to wait for the running Job")
+	}
+
+	// the result is read from the termination message
+	job.Status.Succeeded = 1
+	r.Status().Update(ctx, &job)
+	r.Create(ctx, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: job.Name + "-abcde", Namespace: "pulp", Labels: map[string]string{"job-name": job.Name}},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name:  "file-storage-usage",
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: "10737418240 9663676416 1073741824"}},
+		}}},
+	})
+	if result := r.fileStorageUsage(ctx, pulp); result.RequeueAfter != defaultFileStorageUsageInterval {
+		t.Errorf("expected the next sample in %v, got %v", defaultFileStorageUsageInterval, result)
+	}
+	usage := pulp.Status.FileStorageUsage
+	if usage == nil || usage.Capacity != "10Gi" || usage.Used != "9Gi" || usage.UsedPercent != 90 {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+	if !v1.IsStatusConditionFalse(pulp.Status.Conditions, fileStorageUsageCondition) {
+		t.Errorf("expected the usage to be above the threshold")
+	}
+	if len(jobs()) != 0 {
+		t.Errorf("expected the Job to be removed")
+	}
+
+	// no new Job before the interval
+	r.fileStorageUsage(ctx, pulp)
+	if len(jobs()) != 0 {
+		t.Errorf("expected no Job before the next sample")
+	}
+}
@@ -0,0 +1,455 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/pulpapi"
+	"github.com/pulp/pulp-operator/controllers/settings"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// syncScheduleLabel is the name of the PulpSyncSchedule of the CronJob, Jobs and Pods
+	syncScheduleLabel = "repo-manager.pulpproject.org/sync-schedule"
+	// syncContainerName is the name of the container that dispatches the sync
+	syncContainerName = "sync"
+	// syncCAPath is the path of the internal TLS CA in the sync container
+	syncCAPath = "/etc/pulp/certs/internal-ca.crt"
+)
+
+// syncScript dispatches the sync of the repository through the Pulp REST API and waits for the task.
+// The result is written (as json) in the termination message of the container, from where it is read
+// by the operator.
+const syncScript = `import base64
+import json
+import os
+import ssl
+import sys
+import time
+import urllib.error
+import urllib.parse
+import urllib.request
+
+API_URL = os.environ["PULP_API_URL"]
+with open("/etc/pulp/pulp-admin-password") as f:
+    AUTH = "Basic " + base64.b64encode(("admin:" + f.read().strip()).encode()).decode()
+CONTEXT = ssl.create_default_context(cafile=os.environ.get("PULP_CA_FILE") or None)
+FINISHED = ("completed", "failed", "canceled", "skipped")
+
+
+def request(method, path, body=None):
+    data = json.dumps(body).encode() if body is not None else None
+    req = urllib.request.Request(
+        urllib.parse.urljoin(API_URL, path),
+        data=data,
+        method=method,
+        headers={"Authorization": AUTH, "Content-Type": "application/json"},
+    )
+    with urllib.request.urlopen(req, context=CONTEXT, timeout=30) as response:
+        return json.load(response)
+
+
+def find(kind, name):
+    query = urllib.parse.urlencode({"name": name})
+    results = request("GET", "%s/%s/?%s" % (kind, os.environ["PULP_TYPE"], query))["results"]
+    if not results:
+        raise Exception("%s %s not found" % (kind[:-1], name))
+    return results[0]
+
+
+result = {"state": "failed"}
+try:
+    repository = find("repositories", os.environ["PULP_REPOSITORY"])
+    body = json.loads(os.environ.get("PULP_SYNC_OPTIONS") or "null") or {}
+    body["mirror"] = os.environ.get("PULP_MIRROR") == "true"
+    if os.environ.get("PULP_REMOTE"):
+        body["remote"] = find("remotes", os.environ["PULP_REMOTE"])["pulp_href"]
+    elif not repository.get("remote"):
+        raise Exception("repository %s has no remote" % repository["name"])
+    result["href"] = request("POST", repository["pulp_href"] + "sync/", body)["task"]
+    print("sync of repository %s dispatched: %s" % (repository["name"], result["href"]))
+    task = request("GET", result["href"])
+    while task["state"] not in FINISHED:
+        time.sleep(10)
+        task = request("GET", result["href"])
+    result.update(state=task["state"], started_at=task.get("started_at"), finished_at=task.get("finished_at"))
+    if task.get("error"):
+        result["error"] = task["error"].get("description") or json.dumps(task["error"])
+except urllib.error.HTTPError as e:
+    result["error"] = "%s %s: %s" % (e.url, e, e.read().decode(errors="replace"))
+except Exception as e:
+    result["error"] = str(e)
+
+if "error" in result:
+    # the termination message is limited to 4096 bytes
+    result["error"] = result["error"][:2048]
+print(json.dumps(result))
+with open("/dev/termination-log", "w") as f:
+    json.dump(result, f)
+sys.exit(0 if result["state"] == "completed" else 1)`
+
+// PulpSyncScheduleReconciler reconciles a PulpSyncSchedule object
+type PulpSyncScheduleReconciler struct {
+	client.Client
+	RawLogger logr.Logger
+	Scheme    *runtime.Scheme
+	recorder  record.EventRecorder
+}
+
+// syncResult is the result of the sync written by syncScript in the termination message
+type syncResult struct {
+	Href       string     `json:"href"`
+	State      string     `json:"state"`
+	Error      string     `json:"error"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+//+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulpsyncschedules,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulpsyncschedules/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=repo-manager.pulpproject.org,namespace=pulp-operator-system,resources=pulpsyncschedules/finalizers,verbs=update
+
+// Reconcile renders the PulpSyncSchedule into a CronJob that syncs the repository and keeps the
+// result of the last sync in the status
+func (r *PulpSyncScheduleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.RawLogger.WithValues("PulpSyncSchedule", req.NamespacedName)
+
+	schedule := &pulpv1.PulpSyncSchedule{}
+	if err := r.Get(ctx, req.NamespacedName, schedule); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get PulpSyncSchedule")
+		return ctrl.Result{}, err
+	}
+	status := schedule.Status.DeepCopy()
+
+	pulp := &pulpv1.Pulp{}
+	if err := r.Get(ctx, types.NamespacedName{Name: schedule.Spec.DeploymentName, Namespace: schedule.Namespace}, pulp); err != nil {
+		if !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		setSyncScheduleCondition(schedule, metav1.ConditionFalse, "PulpNotFound", "Pulp "+schedule.Spec.DeploymentName+" not found")
+		return ctrl.Result{RequeueAfter: time.Minute}, r.updateSyncScheduleStatus(ctx, schedule, status)
+	}
+
+	cronJob, err := r.syncScheduleCronJob(ctx, schedule, pulp)
+	if err != nil {
+		log.Error(err, "Failed to render the sync CronJob")
+		return ctrl.Result{}, err
+	}
+	current := &batchv1.CronJob{}
+	err = r.Get(ctx, types.NamespacedName{Name: cronJob.Name, Namespace: cronJob.Namespace}, current)
+	switch {
+	case errors.IsNotFound(err):
+		log.Info("Creating " + cronJob.Name + " CronJob")
+		if err := r.Create(ctx, cronJob); err != nil {
+			log.Error(err, "Failed to create "+cronJob.Name+" CronJob")
+			return ctrl.Result{}, err
+		}
+		current = cronJob
+	case err != nil:
+		log.Error(err, "Failed to get "+cronJob.Name+" CronJob")
+		return ctrl.Result{}, err
+	case controllers.GetCurrentHash(current) != controllers.GetCurrentHash(cronJob):
+		log.Info("Updating " + cronJob.Name + " CronJob")
+		current.Labels = cronJob.Labels
+		current.Spec = cronJob.Spec
+		if err := r.Update(ctx, current); err != nil {
+			log.Error(err, "Failed to update "+cronJob.Name+" CronJob")
+			return ctrl.Result{}, err
+		}
+	}
+	schedule.Status.CronJob = current.Name
+	schedule.Status.LastScheduleTime = current.Status.LastScheduleTime
+
+	if err := r.updateLastSync(ctx, schedule, log); err != nil {
+		log.Error(err, "Failed to get the result of the last sync")
+		return ctrl.Result{}, err
+	}
+
+	switch lastSync := schedule.Status.LastSync; {
+	case lastSync != nil && lastSync.State != pulpapi.TaskCompleted:
+		setSyncScheduleCondition(schedule, metav1.ConditionFalse, "SyncFailed", "Sync from "+lastSync.Job+" Job "+lastSync.State+": "+lastSync.Error)
+	case schedule.Spec.Suspend:
+		setSyncScheduleCondition(schedule, metav1.ConditionTrue, "Suspended", "Syncs are suspended")
+	default:
+		setSyncScheduleCondition(schedule, metav1.ConditionTrue, "Scheduled", "Syncs are scheduled by "+current.Name+" CronJob")
+	}
+	return ctrl.Result{}, r.updateSyncScheduleStatus(ctx, schedule, status)
+}
+
+// updateSyncScheduleStatus updates the status of the PulpSyncSchedule if it was modified
+func (r *PulpSyncScheduleReconciler) updateSyncScheduleStatus(ctx context.Context, schedule *pulpv1.PulpSyncSchedule, previous *pulpv1.PulpSyncScheduleStatus) error {
+	if reflect.DeepEqual(&schedule.Status, previous) {
+		return nil
+	}
+	return r.Status().Update(ctx, schedule)
+}
+
+// setSyncScheduleCondition sets the Ready condition of the PulpSyncSchedule
+func setSyncScheduleCondition(schedule *pulpv1.PulpSyncSchedule, status metav1.ConditionStatus, reason, message string) {
+	v1.SetStatusCondition(&schedule.Status.Conditions, metav1.Condition{
+		Type:               "Ready",
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: schedule.Generation,
+	})
+}
+
+// updateLastSync keeps the result of the newest finished Job in .status.last_sync and records an
+// event with it
+func (r *PulpSyncScheduleReconciler) updateLastSync(ctx context.Context, schedule *pulpv1.PulpSyncSchedule, log logr.Logger) error {
+	jobList := &batchv1.JobList{}
+	if err := r.List(ctx, jobList, client.InNamespace(schedule.Namespace), client.MatchingLabels{syncScheduleLabel: schedule.Name}); err != nil {
+		return err
+	}
+	jobs := []batchv1.Job{}
+	for _, job := range jobList.Items {
+		if job.Status.Succeeded > 0 || jobFailed(&job) {
+			jobs = append(jobs, job)
+		}
+	}
+	if len(jobs) == 0 {
+		return nil
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[j].CreationTimestamp.Before(&jobs[i].CreationTimestamp)
+	})
+	job := &jobs[0]
+	if lastSync := schedule.Status.LastSync; lastSync != nil && lastSync.Job == job.Name {
+		return nil
+	}
+
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(job.Namespace), client.MatchingLabels{"job-name": job.Name}); err != nil {
+		return err
+	}
+	message := ""
+	for _, pod := range podList.Items {
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			if containerStatus.Name == syncContainerName && containerStatus.State.Terminated != nil {
+				message = containerStatus.State.Terminated.Message
+			}
+		}
+	}
+	schedule.Status.LastSync = lastSyncStatus(job, message)
+
+	lastSync := schedule.Status.LastSync
+	if lastSync.State == pulpapi.TaskCompleted {
+		log.Info("Sync from " + job.Name + " Job completed")
+		r.recorder.Event(schedule, corev1.EventTypeNormal, "SyncCompleted", "Sync of repository "+schedule.Spec.Repository+" completed")
+	} else {
+		log.Info("Sync from "+job.Name+" Job failed", "state", lastSync.State, "error", lastSync.Error)
+		r.recorder.Event(schedule, corev1.EventTypeWarning, "SyncFailed", "Sync of repository "+schedule.Spec.Repository+" "+lastSync.State+": "+lastSync.Error)
+	}
+	return nil
+}
+
+// lastSyncStatus returns the status of the sync run by job from the termination message of the sync
+// container
+func lastSyncStatus(job *batchv1.Job, message string) *pulpv1.PulpScheduledSyncStatus {
+	result := syncResult{}
+	if err := json.Unmarshal([]byte(message), &result); err != nil || len(result.State) == 0 {
+		// the Pod was removed or the container did not finish the script
+		result = syncResult{State: pulpapi.TaskFailed, Error: "the result of the sync was not found, verify the logs from " + job.Name + " Job"}
+		if job.Status.Succeeded > 0 {
+			result = syncResult{State: pulpapi.TaskCompleted}
+		}
+	}
+
+	status := &pulpv1.PulpScheduledSyncStatus{
+		PulpTaskStatus: pulpv1.PulpTaskStatus{Href: result.Href, State: result.State, Error: result.Error},
+		Job:            job.Name,
+	}
+	if result.StartedAt != nil {
+		status.StartedAt = &metav1.Time{Time: *result.StartedAt}
+	}
+	if result.FinishedAt != nil {
+		status.FinishedAt = &metav1.Time{Time: *result.FinishedAt}
+	}
+	if result.StartedAt != nil && result.FinishedAt != nil {
+		status.Duration = &metav1.Duration{Duration: result.FinishedAt.Sub(*result.StartedAt).Round(time.Second)}
+	}
+	return status
+}
+
+// syncScheduleLabels returns the labels of the sync CronJob, Jobs and Pods
+func syncScheduleLabels(schedule *pulpv1.PulpSyncSchedule, pulp *pulpv1.Pulp) map[string]string {
+	labels := jobLabels(*pulp)
+	labels["app.kubernetes.io/component"] = "sync-schedule"
+	labels[syncScheduleLabel] = schedule.Name
+	return labels
+}
+
+// syncScheduleCronJob returns the CronJob that syncs the repository of the PulpSyncSchedule
+func (r *PulpSyncScheduleReconciler) syncScheduleCronJob(ctx context.Context, schedule *pulpv1.PulpSyncSchedule, pulp *pulpv1.Pulp) (*batchv1.CronJob, error) {
+	syncOptions, err := json.Marshal(schedule.Spec.SyncOptions)
+	if err != nil {
+		return nil, err
+	}
+	envVars := []corev1.EnvVar{
+		{Name: "PULP_API_URL", Value: pulpapi.APIURL(ctx, r.Client, pulp)},
+		{Name: "PULP_TYPE", Value: schedule.Spec.Type},
+		{Name: "PULP_REPOSITORY", Value: schedule.Spec.Repository},
+		{Name: "PULP_REMOTE", Value: schedule.Spec.Remote},
+		{Name: "PULP_MIRROR", Value: strconv.FormatBool(schedule.Spec.Mirror)},
+		{Name: "PULP_SYNC_OPTIONS", Value: string(syncOptions)},
+	}
+
+	adminSecretName := pulp.Status.AdminPasswordSecret
+	if len(adminSecretName) == 0 {
+		adminSecretName = settings.DefaultAdminPassword(pulp.Name)
+	}
+	volumes := []corev1.Volume{{
+		Name: adminSecretName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: adminSecretName,
+				Items:      []corev1.KeyToPath{{Key: "password", Path: "admin-password"}},
+			},
+		},
+	}}
+	volumeMounts := []corev1.VolumeMount{{
+		Name:      adminSecretName,
+		MountPath: "/etc/pulp/pulp-admin-password",
+		SubPath:   "admin-password",
+		ReadOnly:  true,
+	}}
+	if controllers.InternalTLSEnabled(pulp) {
+		envVars = append(envVars, corev1.EnvVar{Name: "PULP_CA_FILE", Value: syncCAPath})
+		volumes = append(volumes, corev1.Volume{
+			Name: "internal-tls",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: settings.InternalTLSSecret(pulp.Name),
+					Items:      []corev1.KeyToPath{{Key: "ca.crt", Path: "ca.crt"}},
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: "internal-tls", MountPath: syncCAPath, SubPath: "ca.crt", ReadOnly: true})
+	}
+
+	containers := []corev1.Container{{
+		Name:                     syncContainerName,
+		Image:                    pulpcoreImage(pulp),
+		ImagePullPolicy:          corev1.PullPolicy(pulp.Spec.ImagePullPolicy),
+		Env:                      envVars,
+		Command:                  []string{"python3", "-c", syncScript},
+		Resources:                schedule.Spec.ResourceRequirements,
+		VolumeMounts:             volumeMounts,
+		TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		SecurityContext:          controllers.SetDefaultSecurityContext(),
+	}}
+	labels := syncScheduleLabels(schedule, pulp)
+	// a failed sync is not retried (the Job is kept until it is removed by the CronJob history limit)
+	backOffLimit := int32(0)
+
+	job := commonJob(pulpJobConfig{
+		settings.SyncScheduleCronJob(schedule.Name),
+		schedule.Namespace,
+		settings.PulpServiceAccount(pulp.Name),
+		labels,
+		&backOffLimit,
+		nil,
+		containers,
+		volumes,
+	})
+
+	cronJob := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      settings.SyncScheduleCronJob(schedule.Name),
+			Namespace: schedule.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:          schedule.Spec.Schedule,
+			TimeZone:          schedule.Spec.TimeZone,
+			Suspend:           &schedule.Spec.Suspend,
+			ConcurrencyPolicy: batchv1.ForbidConcurrent,
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       job.Spec,
+			},
+		},
+	}
+	controllers.SetHashLabel(controllers.CalculateHash(cronJob.Spec), cronJob)
+	if err := ctrl.SetControllerReference(schedule, cronJob, r.Scheme); err != nil {
+		return nil, err
+	}
+	return cronJob, nil
+}
+
+// findSyncScheduleForJob returns the PulpSyncSchedule of the sync Job
+func findSyncScheduleForJob(ctx context.Context, job client.Object) []reconcile.Request {
+	name, ok := job.GetLabels()[syncScheduleLabel]
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name, Namespace: job.GetNamespace()}}}
+}
+
+// findSyncSchedulesForPulp returns the PulpSyncSchedules of the Pulp CR
+func (r *PulpSyncScheduleReconciler) findSyncSchedulesForPulp(ctx context.Context, pulp client.Object) []reconcile.Request {
+	scheduleList := &pulpv1.PulpSyncScheduleList{}
+	if err := r.List(ctx, scheduleList, client.InNamespace(pulp.GetNamespace())); err != nil {
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, schedule := range scheduleList.Items {
+		if schedule.Spec.DeploymentName == pulp.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: schedule.Name, Namespace: schedule.Namespace}})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PulpSyncScheduleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("PulpSyncSchedule")
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&pulpv1.PulpSyncSchedule{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&batchv1.CronJob{}).
+		Watches(&batchv1.Job{}, handler.EnqueueRequestsFromMapFunc(findSyncScheduleForJob)).
+		// the status of the Pulp CR is also watched (.status.admin_password_secret)
+		Watches(&pulpv1.Pulp{}, handler.EnqueueRequestsFromMapFunc(r.findSyncSchedulesForPulp), builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
+		Complete(r)
+}
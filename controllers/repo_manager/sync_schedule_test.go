@@ -0,0 +1,202 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// syncJob returns a finished sync Job of the nightly PulpSyncSchedule and its Pod with the termination
+// message
+func syncJob(name string, created time.Time, succeeded bool, message string) (*batchv1.Job, *corev1.Pod) {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "pulp",
+			Labels:            map[string]string{syncScheduleLabel: "nightly"},
+			CreationTimestamp: metav1.NewTime(created),
+		},
+	}
+	if succeeded {
+		job.Status.Succeeded = 1
+	} else {
+		job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name + "-abcde", Namespace: "pulp", Labels: map[string]string{"job-name": name}},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  syncContainerName,
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: message}},
+			}},
+		},
+	}
+	return job, pod
+}
+
+func TestPulpSyncSchedule(t *testing.T) {
+	scheme := runtime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	pulpv1.AddToScheme(scheme)
+
+	pulp := &pulpv1.Pulp{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-pulp", Namespace: "pulp"},
+		Status:     pulpv1.PulpStatus{AdminPasswordSecret: "admin-password"},
+	}
+	schedule := &pulpv1.PulpSyncSchedule{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "pulp"},
+		Spec: pulpv1.PulpSyncScheduleSpec{
+			DeploymentName: "example-pulp",
+			Type:           "rpm/rpm",
+			Repository:     "baseos",
+			Schedule:       "0 2 * * *",
+			Mirror:         true,
+		},
+	}
+	k8sClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(pulp, schedule).
+		WithStatusSubresource(&pulpv1.PulpSyncSchedule{}).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &PulpSyncScheduleReconciler{Client: k8sClient, RawLogger: logr.Discard(), Scheme: scheme, recorder: recorder}
+	ctx := context.TODO()
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "nightly", Namespace: "pulp"}}
+
+	reconcile := func() *pulpv1.PulpSyncSchedule {
+		t.Helper()
+		if _, err := r.Reconcile(ctx, request); err != nil {
+			t.Fatal(err)
+		}
+		schedule := &pulpv1.PulpSyncSchedule{}
+		k8sClient.Get(ctx, request.NamespacedName, schedule)
+		return schedule
+	}
+	readyReason := func(schedule *pulpv1.PulpSyncSchedule) string {
+		if condition := v1.FindStatusCondition(schedule.Status.Conditions, "Ready"); condition != nil {
+			return condition.Reason
+		}
+		return ""
+	}
+
+	// the CronJob is created with the schedule and the sync container
+	current := reconcile()
+	cronJob := &batchv1.CronJob{}
+	if err := k8sClient.Get(ctx, types.NamespacedName{Name: "nightly-sync", Namespace: "pulp"}, cronJob); err != nil {
+		t.Fatal(err)
+	}
+	if cronJob.Spec.Schedule != "0 2 * * *" || cronJob.Spec.ConcurrencyPolicy != batchv1.ForbidConcurrent {
+		t.Errorf("unexpected CronJob spec %+v", cronJob.Spec)
+	}
+	if len(cronJob.OwnerReferences) != 1 || cronJob.OwnerReferences[0].Name != "nightly" {
+		t.Errorf("expected the CronJob to be owned by the PulpSyncSchedule, got %+v", cronJob.OwnerReferences)
+	}
+	podSpec := cronJob.Spec.JobTemplate.Spec.Template.Spec
+	env := map[string]string{}
+	for _, envVar := range podSpec.Containers[0].Env {
+		env[envVar.Name] = envVar.Value
+	}
+	if env["PULP_API_URL"] != "http://example-pulp-api-svc.pulp.svc:24817/pulp/api/v3/" || env["PULP_TYPE"] != "rpm/rpm" || env["PULP_REPOSITORY"] != "baseos" || env["PULP_MIRROR"] != "true" {
+		t.Errorf("unexpected sync container env %v", env)
+	}
+	if podSpec.Volumes[0].Secret == nil || podSpec.Volumes[0].Secret.SecretName != "admin-password" {
+		t.Errorf("expected the admin password Secret to be mounted, got %+v", podSpec.Volumes)
+	}
+	if cronJob.Spec.JobTemplate.Labels[syncScheduleLabel] != "nightly" || cronJob.Spec.JobTemplate.Labels["app.kubernetes.io/component"] != "sync-schedule" {
+		t.Errorf("unexpected Job labels %v", cronJob.Spec.JobTemplate.Labels)
+	}
+	if current.Status.CronJob != "nightly-sync" || readyReason(current) != "Scheduled" {
+		t.Errorf("unexpected status %+v", current.Status)
+	}
+
+	// the CronJob is updated with the spec
+	current.Spec.Suspend = true
+	current.Spec.Schedule = "0 3 * * *"
+	k8sClient.Update(ctx, current)
+	current = reconcile()
+	k8sClient.Get(ctx, types.NamespacedName{Name: "nightly-sync", Namespace: "pulp"}, cronJob)
+	if cronJob.Spec.Schedule != "0 3 * * *" || !*cronJob.Spec.Suspend || readyReason(current) != "Suspended" {
+		t.Errorf("expected the CronJob to be updated, got %+v", cronJob.Spec)
+	}
+
+	// a failed sync is kept in the status and reported in an event
+	now := time.Now()
+	job, pod := syncJob("nightly-sync-1", now, false, `{"href": "/pulp/api/v3/tasks/1/", "state": "failed", "error": "connection refused", "started_at": "2024-01-01T02:00:00Z", "finished_at": "2024-01-01T02:01:30.123Z"}`)
+	k8sClient.Create(ctx, job)
+	k8sClient.Create(ctx, pod)
+	current = reconcile()
+	lastSync := current.Status.LastSync
+	if lastSync == nil || lastSync.Job != "nightly-sync-1" || lastSync.State != "failed" || lastSync.Href != "/pulp/api/v3/tasks/1/" || lastSync.Duration.Duration != 90*time.Second {
+		t.Errorf("unexpected last sync %+v", lastSync)
+	}
+	if readyReason(current) != "SyncFailed" {
+		t.Errorf("expected the SyncFailed reason, got %v", current.Status.Conditions)
+	}
+	if event := <-recorder.Events; !strings.HasPrefix(event, "Warning SyncFailed") || !strings.Contains(event, "connection refused") {
+		t.Errorf("unexpected event %v", event)
+	}
+	reconcile()
+	if len(recorder.Events) > 0 {
+		t.Errorf("expected a single event for each sync, got %v", <-recorder.Events)
+	}
+
+	// the newest Job is reported (also without the result, if the Pod was removed)
+	job, _ = syncJob("nightly-sync-2", now.Add(time.Hour), true, "")
+	k8sClient.Create(ctx, job)
+	current = reconcile()
+	if lastSync := current.Status.LastSync; lastSync.Job != "nightly-sync-2" || lastSync.State != "completed" || lastSync.Duration != nil {
+		t.Errorf("unexpected last sync %+v", lastSync)
+	}
+	if event := <-recorder.Events; !strings.HasPrefix(event, "Normal SyncCompleted") {
+		t.Errorf("unexpected event %v", event)
+	}
+	if readyReason(current) != "Suspended" {
+		t.Errorf("expected the Suspended reason, got %v", current.Status.Conditions)
+	}
+
+	// the syncs are retried while the Pulp CR is not found
+	k8sClient.Delete(ctx, pulp)
+	result, err := r.Reconcile(ctx, request)
+	k8sClient.Get(ctx, request.NamespacedName, current)
+	if err != nil || result.RequeueAfter != time.Minute || readyReason(current) != "PulpNotFound" {
+		t.Errorf("expected to requeue until the Pulp CR is created, got %v %v %v", result, err, current.Status.Conditions)
+	}
+}
+
+func TestLastSyncStatus(t *testing.T) {
+	job, _ := syncJob("nightly-sync-1", time.Now(), false, "")
+	status := lastSyncStatus(job, "Error: the container was killed")
+	if status.State != "failed" || !strings.Contains(status.Error, "nightly-sync-1") {
+		t.Errorf("expected a failed sync without the result, got %+v", status)
+	}
+}
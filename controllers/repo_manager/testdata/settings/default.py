@@ -0,0 +1,31 @@
+
+# This file is managed by Pulp operator.
+# DO NOT EDIT IT.
+#
+# To modify custom fields, use the custom_pulp_settings from Pulp CR, for example:
+# spec:
+#   custom_pulp_settings: <configmap name>
+
+ANSIBLE_API_HOSTNAME = "http://test-web-svc.pulp.svc.cluster.local:24880"
+ANSIBLE_CERTS_DIR = "/etc/pulp/keys/"
+CONTENT_ORIGIN = "http://test-web-svc.pulp.svc.cluster.local:24880"
+DB_ENCRYPTION_KEY = "/etc/pulp/keys/database_fields.symmetric.key"
+PRIVATE_KEY_PATH = "/etc/pulp/keys/container_auth_private_key.pem"
+PUBLIC_KEY_PATH = "/etc/pulp/keys/container_auth_public_key.pem"
+STATIC_ROOT = "/var/lib/operator/static/"
+TOKEN_AUTH_DISABLED = False
+TOKEN_SIGNATURE_ALGORITHM = "ES256"
+DATABASES = {
+    "default": {
+        "HOST": "test-database-svc",
+        "ENGINE": "django.db.backends.postgresql_psycopg2",
+        "NAME": "pulp",
+        "USER": "pulp",
+        "PASSWORD": "password",
+        "PORT": "5432",
+        "CONN_MAX_AGE": 0,
+        "OPTIONS": {"sslmode": "prefer"},
+    },
+}
+TOKEN_SERVER = "http://test-api-svc.pulp.svc.cluster.local:24817/token/"
+SECRET_KEY = "django-key"
@@ -0,0 +1,50 @@
+
+# This file is managed by Pulp operator.
+# DO NOT EDIT IT.
+#
+# To modify custom fields, use the custom_pulp_settings from Pulp CR, for example:
+# spec:
+#   custom_pulp_settings: <configmap name>
+
+API_ROOT = "/api/"
+TELEMETRY = False
+ANSIBLE_API_HOSTNAME = "http://test-web-svc.pulp.svc.cluster.local:24880"
+ANSIBLE_CERTS_DIR = "/etc/pulp/keys/"
+CONTENT_ORIGIN = "http://test-web-svc.pulp.svc.cluster.local:24880"
+DB_ENCRYPTION_KEY = "/etc/pulp/keys/database_fields.symmetric.key"
+PRIVATE_KEY_PATH = "/etc/pulp/keys/container_auth_private_key.pem"
+PUBLIC_KEY_PATH = "/etc/pulp/keys/container_auth_public_key.pem"
+STATIC_ROOT = "/var/lib/operator/static/"
+TOKEN_AUTH_DISABLED = False
+TOKEN_SIGNATURE_ALGORITHM = "ES256"
+LOGGING = {
+    "dynaconf_merge": True,
+    "loggers": {"": {"handlers": ["console"], "level": "DEBUG"}},
+}
+DATABASES = {
+    "default": {
+        "HOST": "db.example.com",
+        "ENGINE": "django.db.backends.postgresql_psycopg2",
+        "NAME": "pulp",
+        "USER": "pulp",
+        "PASSWORD": "p@ss'\"\\\nimport os; os.system(\"id\")\n#",
+        "PORT": "5432",
+        "CONN_MAX_AGE": 0,
+        "OPTIONS": {"sslmode": "require"},
+    },
+}
+CACHE_ENABLED = True
+REDIS_HOST = "redis.example.com"
+REDIS_PORT = "6379"
+REDIS_PASSWORD = "p@ss'\"\\\nimport os; os.system(\"id\")\n#"
+REDIS_DB = "1"
+KEYCLOAK_HOST = "keycloak.example.com"
+KEYCLOAK_PORT = "443"
+KEYCLOAK_PROTOCOL = "https"
+KEYCLOAK_REALM = "pulp"
+SOCIAL_AUTH_KEYCLOAK_KEY = "pulp"
+SOCIAL_AUTH_KEYCLOAK_PUBLIC_KEY = "public-key"
+SOCIAL_AUTH_KEYCLOAK_SECRET = "p@ss'\"\\\nimport os; os.system(\"id\")\n#"
+TOKEN_SERVER = "http://test-api-svc.pulp.svc.cluster.local:24817/token/"
+SECRET_KEY = "p@ss'\"\\\nimport os; os.system(\"id\")\n#"
+ALLOWED_CONTENT_CHECKSUMS = ["sha256", "sha512"]
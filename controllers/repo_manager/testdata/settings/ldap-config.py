@@ -0,0 +1,46 @@
+
+# This file is managed by Pulp operator.
+# DO NOT EDIT IT.
+#
+# To modify custom fields, use the custom_pulp_settings from Pulp CR, for example:
+# spec:
+#   custom_pulp_settings: <configmap name>
+
+ANSIBLE_API_HOSTNAME = "http://test-web-svc.pulp.svc.cluster.local:24880"
+ANSIBLE_CERTS_DIR = "/etc/pulp/keys/"
+CONTENT_ORIGIN = "http://test-web-svc.pulp.svc.cluster.local:24880"
+DB_ENCRYPTION_KEY = "/etc/pulp/keys/database_fields.symmetric.key"
+PRIVATE_KEY_PATH = "/etc/pulp/keys/container_auth_private_key.pem"
+PUBLIC_KEY_PATH = "/etc/pulp/keys/container_auth_public_key.pem"
+STATIC_ROOT = "/var/lib/operator/static/"
+TOKEN_AUTH_DISABLED = False
+TOKEN_SIGNATURE_ALGORITHM = "ES256"
+DATABASES = {
+    "default": {
+        "HOST": "test-database-svc",
+        "ENGINE": "django.db.backends.postgresql_psycopg2",
+        "NAME": "pulp",
+        "USER": "pulp",
+        "PASSWORD": "password",
+        "PORT": "5432",
+        "CONN_MAX_AGE": 0,
+        "OPTIONS": {"sslmode": "prefer"},
+    },
+}
+TOKEN_SERVER = "http://test-api-svc.pulp.svc.cluster.local:24817/token/"
+SECRET_KEY = "django-key"
+
+#### LDAP SETTINGS ####
+import ldap
+from django_auth_ldap.config import *
+AUTHENTICATION_BACKENDS = [
+    "django_auth_ldap.backend.LDAPBackend",
+    "django.contrib.auth.backends.ModelBackend",
+    "pulpcore.backends.ObjectRolePermissionBackend",
+]
+AUTH_LDAP_BIND_PASSWORD = "p@ss'\"\\\nimport os; os.system(\"id\")\n#"
+AUTH_LDAP_MIRROR_GROUPS = True
+AUTH_LDAP_SERVER_URI = "ldap://ldap.example.com"
+AUTH_LDAP_START_TLS = False
+AUTH_LDAP_USER_SEARCH = LDAPSearch("ou=users,dc=example,dc=com", ldap.SCOPE_SUBTREE, "(uid=%(user)s)")
+#### END OF LDAP SETTINGS ####
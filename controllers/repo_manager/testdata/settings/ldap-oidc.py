@@ -0,0 +1,98 @@
+
+# This file is managed by Pulp operator.
+# DO NOT EDIT IT.
+#
+# To modify custom fields, use the custom_pulp_settings from Pulp CR, for example:
+# spec:
+#   custom_pulp_settings: <configmap name>
+
+ANSIBLE_API_HOSTNAME = "http://pulp.example.com"
+ANSIBLE_CERTS_DIR = "/etc/pulp/keys/"
+CONTENT_ORIGIN = "http://pulp.example.com"
+DB_ENCRYPTION_KEY = "/etc/pulp/keys/database_fields.symmetric.key"
+PRIVATE_KEY_PATH = "/etc/pulp/keys/container_auth_private_key.pem"
+PUBLIC_KEY_PATH = "/etc/pulp/keys/container_auth_public_key.pem"
+STATIC_ROOT = "/var/lib/operator/static/"
+TOKEN_AUTH_DISABLED = False
+TOKEN_SIGNATURE_ALGORITHM = "ES256"
+DATABASES = {
+    "default": {
+        "HOST": "test-database-svc",
+        "ENGINE": "django.db.backends.postgresql_psycopg2",
+        "NAME": "pulp",
+        "USER": "pulp",
+        "PASSWORD": "password",
+        "PORT": "5432",
+        "CONN_MAX_AGE": 0,
+        "OPTIONS": {"sslmode": "prefer"},
+    },
+}
+TOKEN_SERVER = "http://pulp.example.com/token/"
+SECRET_KEY = "django-key"
+
+#### LDAP SETTINGS ####
+import ldap
+from django_auth_ldap.config import *
+AUTHENTICATION_BACKENDS = [
+    "social_core.backends.open_id_connect.OpenIdConnectAuth",
+    "django_auth_ldap.backend.LDAPBackend",
+    "django.contrib.auth.backends.ModelBackend",
+    "pulpcore.backends.ObjectRolePermissionBackend",
+]
+AUTH_LDAP_SERVER_URI = "ldaps://ldap.example.com"
+AUTH_LDAP_BIND_DN = "cn=pulp,dc=example,dc=com"
+AUTH_LDAP_BIND_PASSWORD = "p@ss'\"\\\nimport os; os.system(\"id\")\n#"
+AUTH_LDAP_USER_SEARCH = LDAPSearch(
+    "ou=users,dc=example,dc=com",
+    ldap.SCOPE_SUBTREE,
+    "(uid=%(user)s)",
+)
+AUTH_LDAP_GROUP_SEARCH = LDAPSearch(
+    "ou=groups,dc=example,dc=com",
+    ldap.SCOPE_ONELEVEL,
+    "(objectClass=groupOfNames)",
+)
+AUTH_LDAP_GROUP_TYPE = GroupOfNamesType(name_attr="cn")
+AUTH_LDAP_USER_ATTR_MAP = {"email": "mail", "first_name": "givenName"}
+AUTH_LDAP_MIRROR_GROUPS = True
+AUTH_LDAP_CONNECTION_OPTIONS = {
+    ldap.OPT_X_TLS_CACERTFILE: "/etc/pulp/ldap/ca.crt",
+    ldap.OPT_X_TLS_NEWCTX: 0,
+}
+#### END OF LDAP SETTINGS ####
+
+#### OIDC SETTINGS ####
+import sys
+sys.path.insert(0, "/etc/pulp/oidc")
+INSTALLED_APPS = ["dynaconf_merge", "social_django"]
+ROOT_URLCONF = "pulp_oidc.urls"
+SOCIAL_AUTH_OIDC_OIDC_ENDPOINT = "https://example.okta.com/oauth2/default"
+SOCIAL_AUTH_OIDC_KEY = "pulp"
+SOCIAL_AUTH_OIDC_SECRET = "p@ss'\"\\\nimport os; os.system(\"id\")\n#"
+SOCIAL_AUTH_OIDC_SCOPE = ["openid", "profile", "email"]
+SOCIAL_AUTH_OIDC_IGNORE_DEFAULT_SCOPE = True
+SOCIAL_AUTH_OIDC_USERNAME_KEY = "preferred_username"
+SOCIAL_AUTH_LOGIN_REDIRECT_URL = "/pulp/api/v3/"
+SOCIAL_AUTH_REDIRECT_IS_HTTPS = False
+SOCIAL_AUTH_PIPELINE = [
+    "social_core.pipeline.social_auth.social_details",
+    "social_core.pipeline.social_auth.social_uid",
+    "social_core.pipeline.social_auth.auth_allowed",
+    "social_core.pipeline.social_auth.social_user",
+    "social_core.pipeline.user.get_username",
+    "social_core.pipeline.user.create_user",
+    "social_core.pipeline.social_auth.associate_user",
+    "social_core.pipeline.social_auth.load_extra_data",
+    "social_core.pipeline.user.user_details",
+    "pulp_oidc.pipeline.map_claims",
+]
+PULP_OIDC_GROUPS_CLAIM = "groups"
+PULP_OIDC_CLAIM_MAPPINGS = [
+    {"claim": "pulp-admins", "groups": [], "roles": [], "superuser": True},
+    {
+        "claim": "pulp-developers",
+        "groups": ["developers"],
+        "roles": ["file.filerepository_creator"],
+        "superuser": False,
+    },
+]
@@ -0,0 +1,136 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/settings"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The resources from CRDs that are not always installed in the cluster (Gateway API, Traefik
+// and Contour) are handled as unstructured objects, so the operator does not require them
+// in clusters that are not using them.
+
+// unstructuredObjects groups the expected objects of a kind
+type unstructuredObjects struct {
+	gvk     schema.GroupVersionKind
+	objects []*unstructured.Unstructured
+}
+
+// reconcileUnstructuredObjects creates or updates the expected objects and removes the objects
+// of the same kind (provisioned by the operator) that are not expected anymore.
+// It returns true if any object has been modified.
+func (r *RepoManagerReconciler) reconcileUnstructuredObjects(ctx context.Context, pulp *pulpv1.Pulp, log logr.Logger, conditionType string, gvk schema.GroupVersionKind, expectedObjects []*unstructured.Unstructured) (bool, error) {
+	kind := gvk.Kind
+	requeue := false
+	expectedNames := map[string]bool{}
+	for _, expected := range expectedObjects {
+		name := expected.GetName()
+		expectedNames[name] = true
+		current := &unstructured.Unstructured{}
+		current.SetGroupVersionKind(gvk)
+		err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: pulp.Namespace}, current)
+
+		// Create the object in case it is not found
+		if err != nil && errors.IsNotFound(err) {
+			log.Info("Creating a new "+kind, kind+".Namespace", pulp.Namespace, kind+".Name", name)
+			controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, conditionType, "Creating"+kind, "Creating "+name+" "+kind)
+			if err := r.Create(ctx, expected); err != nil {
+				log.Error(err, "Failed to create new "+kind, kind+".Namespace", pulp.Namespace, kind+".Name", name)
+				controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, conditionType, "ErrorCreating"+kind, "Failed to create "+name+" "+kind+": "+err.Error())
+				r.recorder.Event(pulp, corev1.EventTypeWarning, "Failed", "Failed to create new "+kind)
+				return false, err
+			}
+			requeue = true
+			continue
+		} else if err != nil {
+			log.Error(err, "Failed to get "+kind)
+			return false, err
+		}
+
+		// Ensure the object spec, labels and annotations are as expected
+		if unstructuredModified(expected, current) {
+			log.Info("The " + name + " " + kind + " has been modified! Reconciling ...")
+			controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, conditionType, "Updating"+kind, "Reconciling "+name+" "+kind)
+			current.Object["spec"] = expected.Object["spec"]
+			current.SetLabels(expected.GetLabels())
+			current.SetAnnotations(expected.GetAnnotations())
+			if err := r.Update(ctx, current); err != nil {
+				log.Error(err, "Failed to reconcile "+name+" "+kind)
+				controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, conditionType, "ErrorUpdating"+kind, "Failed to reconcile "+name+" "+kind+": "+err.Error())
+				r.recorder.Event(pulp, corev1.EventTypeWarning, "Failed", "Failed to reconcile "+name+" "+kind)
+				return false, err
+			}
+			r.recorder.Event(pulp, corev1.EventTypeNormal, "Updated", name+" "+kind+" reconciled")
+			requeue = true
+		}
+	}
+
+	// remove the objects that are not expected anymore (for example, from a plugin that has been uninstalled)
+	currentObjects := &unstructured.UnstructuredList{}
+	currentObjects.SetGroupVersionKind(gvk)
+	if err := r.List(ctx, currentObjects, client.InNamespace(pulp.Namespace), client.MatchingLabels(settings.CommonLabels(*pulp))); err != nil {
+		log.Error(err, "Failed to list "+kind+"s")
+		return false, err
+	}
+	for i := range currentObjects.Items {
+		current := &currentObjects.Items[i]
+		if expectedNames[current.GetName()] || !metav1.IsControlledBy(current, pulp) {
+			continue
+		}
+		log.Info("Removing " + current.GetName() + " " + kind)
+		if err := r.Delete(ctx, current); err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "Failed to remove "+current.GetName()+" "+kind)
+			return false, err
+		}
+		requeue = true
+	}
+	return requeue, nil
+}
+
+// unstructuredModified returns true if the spec, labels or annotations from the current object
+// are not the expected ones.
+// The spec is compared with DeepDerivative because the API server sets default values
+// (like HTTPRoute parentRefs group/kind and backendRefs weight) that are not defined by the operator.
+func unstructuredModified(expected, current *unstructured.Unstructured) bool {
+	return !equality.Semantic.DeepDerivative(expected.Object["spec"], current.Object["spec"]) ||
+		!equality.Semantic.DeepEqual(expected.GetLabels(), current.GetLabels()) ||
+		!equality.Semantic.DeepEqual(expected.GetAnnotations(), current.GetAnnotations())
+}
+
+// removeUnstructuredObjects deletes all objects of the kind provisioned by the operator
+func (r *RepoManagerReconciler) removeUnstructuredObjects(ctx context.Context, pulp *pulpv1.Pulp, gvk schema.GroupVersionKind) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	listOpts := []client.DeleteAllOfOption{
+		client.InNamespace(pulp.Namespace),
+		client.MatchingLabels(settings.CommonLabels(*pulp)),
+	}
+	r.DeleteAllOf(ctx, obj, listOpts...)
+}
@@ -103,7 +103,7 @@ func checkSecretsAvailable(funcResources controllers.FunctionResources) error {
 	ctx := funcResources.Context
 	pulp := funcResources.Pulp
 
-	secrets := []string{"ObjectStorageAzureSecret", "ObjectStorageS3Secret", "SSOSecret"}
+	secrets := []string{"ObjectStorageAzureSecret", "ObjectStorageS3Secret", "ObjectStorageGCSSecret", "SSOSecret"}
 	for _, secretField := range secrets {
 		structField := reflect.Indirect(reflect.ValueOf(pulp)).FieldByName("Spec").FieldByName(secretField)
 		if structField.IsValid() && len(structField.Interface().(string)) != 0 {
@@ -141,6 +141,20 @@ func checkSecretsAvailable(funcResources controllers.FunctionResources) error {
 			return err
 		}
 	}
+	if len(pulp.Spec.LDAP.BindPasswordSecret) != 0 {
+		secret := &corev1.Secret{}
+		if err := funcResources.Get(ctx, types.NamespacedName{Name: pulp.Spec.LDAP.BindPasswordSecret, Namespace: pulp.Namespace}, secret); err != nil {
+			return err
+		}
+	}
+
+	// OIDC Secret
+	if len(pulp.Spec.OIDC.ClientSecret) != 0 {
+		secret := &corev1.Secret{}
+		if err := funcResources.Get(ctx, types.NamespacedName{Name: pulp.Spec.OIDC.ClientSecret, Namespace: pulp.Namespace}, secret); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -193,6 +207,10 @@ func (r *RepoManagerReconciler) updateIngressType(ctx context.Context, pulp *pul
 			pulp.Status.IngressClassName = ""
 		}
 
+		// remove the traefik/contour resources
+		r.removeIngressControllerObjects(ctx, pulp, pulp.Status.IngressController)
+		pulp.Status.IngressController = ""
+
 		// remove pulp-web components
 		controllers.RemovePulpWebResources(controllers.FunctionResources{Context: ctx, Client: r.Client, Pulp: pulp, Scheme: nil, Logger: logr.Logger{}})
 
@@ -202,6 +220,21 @@ func (r *RepoManagerReconciler) updateIngressType(ctx context.Context, pulp *pul
 		return
 	}
 
+	// if pulp CR was defined with gateway and user modified it to anything else
+	// delete all httproutes with operator's labels
+	// remove gateway .status.conditions
+	if strings.ToLower(pulp.Status.IngressType) == "gateway" && !isGateway(pulp) {
+		r.removeHTTPRoutes(ctx, pulp)
+		gatewayConditionType := "Pulp-Gateway-Ready"
+		v1.RemoveStatusCondition(&pulp.Status.Conditions, gatewayConditionType)
+
+		pulp.Status.IngressType = pulp.Spec.IngressType
+		r.Status().Update(ctx, pulp)
+
+		// nothing else to do (the controller will be responsible for setting up the other resources)
+		return
+	}
+
 	// if pulp CR was defined with nodeport or loadbalancer and user modified it to anything else
 	// delete all pulp-web resources
 	// remove pulp-web .status.conditions
@@ -225,8 +258,8 @@ func (r *RepoManagerReconciler) updateIngressType(ctx context.Context, pulp *pul
 // modification scenarios
 func (r *RepoManagerReconciler) updateIngressClass(ctx context.Context, pulp *pulpv1.Pulp) {
 
-	// if the new one uses nginx controller
-	if r.isNginxIngress(pulp) {
+	// if the new one uses nginx (or another supported) controller
+	if r.isNativeIngress(pulp) {
 
 		// remove pulp-web components
 		webDeployment := &appsv1.Deployment{}
@@ -241,7 +274,7 @@ func (r *RepoManagerReconciler) updateIngressClass(ctx context.Context, pulp *pu
 		webConditionType := "Pulp-Web-Ready"
 		v1.RemoveStatusCondition(&pulp.Status.Conditions, webConditionType)
 
-		// or the new one does not use nginx controller anymore
+		// or the new one does not use a supported controller anymore
 	} else {
 		// remove ingress resource
 		ingress := &netv1.Ingress{}
@@ -259,6 +292,32 @@ func (r *RepoManagerReconciler) updateIngressClass(ctx context.Context, pulp *pu
 	r.Status().Update(ctx, pulp)
 }
 
+// updateIngressController will check the current definition of ingress_controller and will remove
+// the resources provisioned for the previous controller
+func (r *RepoManagerReconciler) updateIngressController(ctx context.Context, pulp *pulpv1.Pulp) {
+	ingressController := controllers.IngressController(pulp)
+
+	// remove the traefik/contour resources from the previous controller
+	r.removeIngressControllerObjects(ctx, pulp, pulp.Status.IngressController)
+
+	// traefik and contour do not use the Ingress
+	if _, found := ingressControllerObjects[ingressController]; found {
+		ingress := &netv1.Ingress{}
+		if err := r.Get(ctx, types.NamespacedName{Name: pulp.Name, Namespace: pulp.Namespace}, ingress); err == nil {
+			r.Delete(ctx, ingress)
+		}
+	}
+
+	// the new controller can forward the traffic to api and content pods without pulp-web
+	if controllers.IsNativeIngressSupported(pulp) {
+		controllers.RemovePulpWebResources(controllers.FunctionResources{Context: ctx, Client: r.Client, Pulp: pulp, Scheme: nil, Logger: logr.Logger{}})
+	}
+	v1.RemoveStatusCondition(&pulp.Status.Conditions, "Pulp-Ingress-Ready")
+
+	pulp.Status.IngressController = ingressController
+	r.Status().Update(ctx, pulp)
+}
+
 // ResourceDefinition has the attributes of a Pulp Resource
 type ResourceDefinition struct {
 	// A Context carries a deadline, a cancellation signal, and other values across
@@ -290,6 +349,9 @@ func (r *RepoManagerReconciler) createPulpResource(resource ResourceDefinition,
 	case *appsv1.Deployment:
 		object = resourceType
 		objKind = "Deployment"
+	case *appsv1.StatefulSet:
+		object = resourceType
+		objKind = "StatefulSet"
 	case *corev1.Service:
 		object = resourceType
 		objKind = "Service"
@@ -349,10 +411,10 @@ func needsRequeue(err error, pulpController ctrl.Result) bool {
 	return err != nil || !reflect.DeepEqual(pulpController, ctrl.Result{})
 }
 
-// needsPulpWeb will return true if ingress_type is not route and the ingress_type provided does not
+// needsPulpWeb will return true if ingress_type is not route nor gateway and the ingress_type provided does not
 // support nginx controller, which is a scenario where pulp-web should be deployed
 func (r *RepoManagerReconciler) needsPulpWeb(pulp *pulpv1.Pulp) bool {
-	return !isRoute(pulp) && !controllers.IsNginxIngressSupported(pulp)
+	return !isRoute(pulp) && !isGateway(pulp) && !controllers.IsNativeIngressSupported(pulp)
 }
 
 // isNginxIngress will check if ingress_type is defined as "ingress"
@@ -365,16 +427,30 @@ func isRoute(pulp *pulpv1.Pulp) bool {
 	return strings.ToLower(pulp.Spec.IngressType) == "route"
 }
 
-// isNginxIngress returns true if pulp is defined with ingress_type==ingress and the controller of the ingresclass provided is a nginx
-func (r *RepoManagerReconciler) isNginxIngress(pulp *pulpv1.Pulp) bool {
-	return isIngress(pulp) && controllers.IsNginxIngressSupported(pulp)
+// isGateway will check if ingress_type is defined as "gateway"
+func isGateway(pulp *pulpv1.Pulp) bool {
+	return strings.ToLower(pulp.Spec.IngressType) == "gateway"
+}
+
+// gatewayScheme returns the scheme used by clients to reach the Gateway listener
+func gatewayScheme(pulp *pulpv1.Pulp) string {
+	if len(pulp.Spec.Gateway.Scheme) == 0 {
+		return "https"
+	}
+	return pulp.Spec.Gateway.Scheme
+}
+
+// isNativeIngress returns true if pulp is defined with ingress_type==ingress and the controller of the ingresclass provided
+// can forward the traffic to api and content pods (nginx, traefik, haproxy or contour)
+func (r *RepoManagerReconciler) isNativeIngress(pulp *pulpv1.Pulp) bool {
+	return isIngress(pulp) && controllers.IsNativeIngressSupported(pulp)
 }
 
 // getRootURL handles user facing URLs
 func getRootURL(pulp pulpv1.Pulp) string {
 	scheme := "https"
 	if isIngress(&pulp) {
-		if pulp.Spec.IngressTLSSecret == "" {
+		if controllers.IngressTLSSecret(&pulp) == "" {
 			scheme = "http"
 		}
 		hostname := pulp.Spec.IngressHost
@@ -383,10 +459,39 @@ func getRootURL(pulp pulpv1.Pulp) string {
 	if isRoute(&pulp) {
 		return "https://" + pulp_ocp.GetRouteHost(&pulp)
 	}
+	if isGateway(&pulp) {
+		return gatewayScheme(&pulp) + "://" + pulp.Spec.IngressHost
+	}
 
 	return "http://" + settings.PulpWebService(pulp.Name) + "." + pulp.Namespace + ".svc.cluster.local:24880"
 }
 
+// getContentOrigin returns the URL used by the clients to reach the content app (CONTENT_ORIGIN).
+// If no content_host is defined, the content is served from the same URL as the API.
+func getContentOrigin(pulp pulpv1.Pulp) string {
+	var hosts []controllers.PulpHost
+	scheme := "https"
+	switch {
+	case isIngress(&pulp):
+		hosts = controllers.IngressHosts(&pulp)
+	case isRoute(&pulp):
+		hosts = pulp_ocp.RouteHosts(&pulp)
+	case isGateway(&pulp):
+		hosts = controllers.IngressHosts(&pulp)
+		scheme = gatewayScheme(&pulp)
+	}
+	for _, host := range hosts {
+		if !host.ContentOnly {
+			continue
+		}
+		if isIngress(&pulp) && len(host.TLSSecret) == 0 {
+			scheme = "http"
+		}
+		return scheme + "://" + host.Host
+	}
+	return getRootURL(pulp)
+}
+
 // ignoreUpdateCRStatusPredicate filters update events on pulpbackup CR status
 func ignoreCronjobStatus() predicate.Predicate {
 	return predicate.Funcs{
@@ -425,6 +530,17 @@ func (r *RepoManagerReconciler) findPulpDependentObjects(ctx context.Context, ob
 	return []reconcile.Request{}
 }
 
+// findPulpForDomain enqueues the Pulp CR of the PulpDomain, so that the domain content path is
+// exposed by the Ingress/Route
+func findPulpForDomain(ctx context.Context, domain client.Object) []reconcile.Request {
+	return []reconcile.Request{{
+		NamespacedName: types.NamespacedName{
+			Name:      domain.(*pulpv1.PulpDomain).Spec.DeploymentName,
+			Namespace: domain.GetNamespace(),
+		},
+	}}
+}
+
 // restartPulpCorePods will redeploy all pulpcore (API,content,worker) pods.
 func (r *RepoManagerReconciler) restartPulpCorePods(ctx context.Context, pulp *pulpv1.Pulp) {
 	r.RawLogger.Info("Reprovisioning pulpcore pods to get the new settings ...")
@@ -19,6 +19,9 @@ package repo_manager
 import (
 	"context"
 	"os"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -41,11 +44,17 @@ func (r *RepoManagerReconciler) pulpWebController(ctx context.Context, pulp *pul
 	// conditionType is used to update .status.conditions with the current resource state
 	conditionType := "Pulp-Web-Ready"
 
+	// the nginx locations are generated from the same paths used by the Ingress/Routes
+	pulpPlugins, reconcile := r.ingressPlugins(ctx, pulp, log, conditionType)
+	if reconcile != nil {
+		return *reconcile, nil
+	}
+
 	// pulp-web Configmap
 	configMapName := settings.PulpWebConfigMapName(pulp.Name)
 	webConfigMap := &corev1.ConfigMap{}
 	err := r.Get(ctx, types.NamespacedName{Name: configMapName, Namespace: pulp.Namespace}, webConfigMap)
-	newWebConfigMap := r.pulpWebConfigMap(ctx, pulp)
+	newWebConfigMap := r.pulpWebConfigMap(pulp, pulpPlugins)
 	if err != nil && errors.IsNotFound(err) {
 		log.Info("Creating a new Pulp Web ConfigMap", "ConfigMap.Namespace", newWebConfigMap.Namespace, "ConfigMap.Name", newWebConfigMap.Name)
 		controllers.UpdateStatus(ctx, r.Client, pulp, metav1.ConditionFalse, conditionType, "CreatingWebConfigmap", "Creating "+pulp.Name+"-web configmap resource")
@@ -64,6 +73,11 @@ func (r *RepoManagerReconciler) pulpWebController(ctx context.Context, pulp *pul
 		return ctrl.Result{}, err
 	}
 
+	// Reconcile ConfigMap
+	if requeue, err := controllers.ReconcileObject(funcResources, newWebConfigMap, webConfigMap, conditionType, controllers.PulpConfigMap{}); err != nil || requeue {
+		return ctrl.Result{Requeue: requeue}, err
+	}
+
 	// pulp-web Deployment
 	deploymentName := settings.WEB.DeploymentName(pulp.Name)
 	webDeployment := &appsv1.Deployment{}
@@ -203,6 +217,94 @@ func (r *RepoManagerReconciler) deploymentForPulpWeb(m *pulpv1.Pulp, funcResourc
 		podSecurityContext = &corev1.PodSecurityContext{}
 	}
 
+	ports := []corev1.ContainerPort{{
+		ContainerPort: 8080,
+		Protocol:      "TCP",
+	}}
+	volumeMounts := []corev1.VolumeMount{
+		{
+			Name:      m.Name + "-nginx-conf",
+			MountPath: "/etc/nginx/nginx.conf",
+			SubPath:   "nginx.conf",
+			ReadOnly:  true,
+		},
+	}
+	volumes := []corev1.Volume{
+		{
+			Name: m.Name + "-nginx-conf",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: settings.PulpWebConfigMapName(m.Name),
+					},
+					Items: []corev1.KeyToPath{
+						{Key: "nginx.conf", Path: "nginx.conf"},
+					},
+				},
+			},
+		},
+	}
+
+	// mount the certificate issued by cert-manager in the path expected by nginx.conf
+	podAnnotations := map[string]string{}
+	if controllers.WebTLSPassthrough(m) {
+		ports = append(ports, corev1.ContainerPort{ContainerPort: 8443, Protocol: "TCP"})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      m.Name + "-web-tls",
+			MountPath: "/etc/nginx/pki",
+			ReadOnly:  true,
+		})
+		volumes = append(volumes, corev1.Volume{
+			Name: m.Name + "-web-tls",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: settings.WebCertificate(m.Name),
+					Items: []corev1.KeyToPath{
+						{Key: corev1.TLSCertKey, Path: "web.crt"},
+						{Key: corev1.TLSPrivateKeyKey, Path: "web.key"},
+					},
+				},
+			},
+		})
+
+		// nginx does not reload the certificate, so we are keeping a hash of it
+		// in the pod template to roll out the pods when cert-manager renews it
+		certSecret := &corev1.Secret{}
+		if err := funcResources.Client.Get(ctx, types.NamespacedName{Name: settings.WebCertificate(m.Name), Namespace: m.Namespace}, certSecret); err == nil {
+			podAnnotations["repo-manager.pulpproject.org/web-certificate-hash"] = controllers.CalculateHash(certSecret.Data[corev1.TLSCertKey])
+		}
+	}
+
+	// mount the CA used to verify pulpcore-api and pulpcore-content certificates
+	if controllers.InternalTLSEnabled(m) {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      m.Name + "-internal-tls",
+			MountPath: "/etc/nginx/internal-tls",
+			ReadOnly:  true,
+		})
+		volumes = append(volumes, corev1.Volume{
+			Name: m.Name + "-internal-tls",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: settings.InternalTLSSecret(m.Name),
+					Items:      []corev1.KeyToPath{{Key: "ca.crt", Path: "ca.crt"}},
+				},
+			},
+		})
+
+		caSecret := &corev1.Secret{}
+		if err := funcResources.Client.Get(ctx, types.NamespacedName{Name: settings.InternalTLSSecret(m.Name), Namespace: m.Namespace}, caSecret); err == nil {
+			podAnnotations["repo-manager.pulpproject.org/internal-ca-hash"] = controllers.CalculateHash(caSecret.Data["ca.crt"])
+		}
+	}
+
+	// nginx does not reload its configuration, so we are keeping a hash of nginx.conf
+	// in the pod template to roll out the pods when the plugin paths change
+	webConfigMap := &corev1.ConfigMap{}
+	if err := funcResources.Client.Get(ctx, types.NamespacedName{Name: settings.PulpWebConfigMapName(m.Name), Namespace: m.Namespace}, webConfigMap); err == nil {
+		podAnnotations["repo-manager.pulpproject.org/nginx-conf-hash"] = controllers.CalculateHash(webConfigMap.Data["nginx.conf"])
+	}
+
 	dep := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        settings.WEB.DeploymentName(m.Name),
@@ -218,48 +320,25 @@ func (r *RepoManagerReconciler) deploymentForPulpWeb(m *pulpv1.Pulp, funcResourc
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: ls,
+					Labels:      ls,
+					Annotations: podAnnotations,
 				},
 				Spec: corev1.PodSpec{
 					NodeSelector:       nodeSelector,
 					ServiceAccountName: settings.PulpServiceAccount(m.Name),
 					Containers: []corev1.Container{{
-						Image:     ImageWeb,
-						Name:      "web",
-						Resources: resources,
-						Env:       envVars,
-						Ports: []corev1.ContainerPort{{
-							ContainerPort: 8080,
-							Protocol:      "TCP",
-						}},
-						LivenessProbe:  livenessProbe,
-						ReadinessProbe: readinessProbe,
-						VolumeMounts: []corev1.VolumeMount{
-							{
-								Name:      m.Name + "-nginx-conf",
-								MountPath: "/etc/nginx/nginx.conf",
-								SubPath:   "nginx.conf",
-								ReadOnly:  true,
-							},
-						},
+						Image:           ImageWeb,
+						Name:            "web",
+						Resources:       resources,
+						Env:             envVars,
+						Ports:           ports,
+						LivenessProbe:   livenessProbe,
+						ReadinessProbe:  readinessProbe,
+						VolumeMounts:    volumeMounts,
 						SecurityContext: controllers.SetDefaultSecurityContext(),
 					}},
 					SecurityContext: podSecurityContext,
-					Volumes: []corev1.Volume{
-						{
-							Name: m.Name + "-nginx-conf",
-							VolumeSource: corev1.VolumeSource{
-								ConfigMap: &corev1.ConfigMapVolumeSource{
-									LocalObjectReference: corev1.LocalObjectReference{
-										Name: settings.PulpWebConfigMapName(m.Name),
-									},
-									Items: []corev1.KeyToPath{
-										{Key: "nginx.conf", Path: "nginx.conf"},
-									},
-								},
-							},
-						},
-					},
+					Volumes:         volumes,
 				},
 			},
 		},
@@ -305,10 +384,15 @@ func serviceForPulpWeb(m *pulpv1.Pulp) *corev1.Service {
 		if m.Spec.LoadbalancerPort != 0 {
 			lbPort = m.Spec.LoadbalancerPort
 		}
+		// with passthrough the TLS connection is terminated by pulp-web
+		targetPort := int32(8080)
+		if controllers.WebTLSPassthrough(m) {
+			targetPort = 8443
+		}
 		port := corev1.ServicePort{
 			Port:       lbPort,
 			Protocol:   corev1.ProtocolTCP,
-			TargetPort: intstr.IntOrString{IntVal: 8080},
+			TargetPort: intstr.IntOrString{IntVal: targetPort},
 			Name:       "web-8443",
 		}
 		servicePort = append(servicePort, port)
@@ -352,7 +436,7 @@ func serviceForPulpWeb(m *pulpv1.Pulp) *corev1.Service {
 }
 
 // wouldn't it be better to handle the configmap content by loading it from a file?
-func (r *RepoManagerReconciler) pulpWebConfigMap(ctx context.Context, m *pulpv1.Pulp) *corev1.ConfigMap {
+func (r *RepoManagerReconciler) pulpWebConfigMap(m *pulpv1.Pulp, plugins []controllers.IngressPlugin) *corev1.ConfigMap {
 
 	// Nginx default values
 	nginxProxyReadTimeout := m.Spec.NginxProxyReadTimeout
@@ -427,6 +511,41 @@ func (r *RepoManagerReconciler) pulpWebConfigMap(ctx context.Context, m *pulpv1.
     	` + listenHTTPIpv6
 	}
 
+	// with internal TLS, the upstreams point to local servers that re-encrypt the requests
+	// to pulpcore-api and pulpcore-content
+	contentUpstream := settings.ContentService(m.Name) + ":24816"
+	apiUpstream := settings.ApiService(m.Name) + ":24817"
+	internalTLSConfig := ""
+	if controllers.InternalTLSEnabled(m) {
+		contentUpstream = "127.0.0.1:24816"
+		apiUpstream = "127.0.0.1:24817"
+		for _, upstream := range []struct {
+			service string
+			port    string
+		}{{settings.ContentService(m.Name), "24816"}, {settings.ApiService(m.Name), "24817"}} {
+			internalTLSConfig += `
+		server {
+			listen 127.0.0.1:` + upstream.port + `;
+
+			proxy_read_timeout ` + nginxProxyReadTimeout + `;
+			proxy_connect_timeout ` + nginxProxyConnectTimeout + `;
+			proxy_send_timeout ` + nginxProxySendTimeout + `;
+			client_max_body_size ` + nginxMaxBodySize + `;
+
+			location / {
+				proxy_set_header Host $http_host;
+				proxy_redirect off;
+				proxy_pass https://` + upstream.service + `.` + m.Namespace + `.svc:` + upstream.port + `;
+				proxy_ssl_verify on;
+				proxy_ssl_trusted_certificate /etc/nginx/internal-tls/ca.crt;
+				proxy_ssl_server_name on;
+				proxy_ssl_name ` + upstream.service + `.` + m.Namespace + `.svc;
+			}
+		}
+`
+		}
+	}
+
 	data := map[string]string{
 		"nginx.conf": `
 	error_log /dev/stdout info;
@@ -446,15 +565,15 @@ func (r *RepoManagerReconciler) pulpWebConfigMap(ctx context.Context, m *pulpv1.
 		# If left at the default of 1024, nginx emits a warning about being unable
 		# to build optimal hash types.
 		types_hash_max_size 4096;
-
+` + nginxHTTPDirectives(m) + `
 		upstream pulp-content {
-			server ` + settings.ContentService(m.Name) + `:24816;
+			server ` + contentUpstream + `;
 		}
 
 		upstream pulp-api {
-			server ` + settings.ApiService(m.Name) + `:24817;
+			server ` + apiUpstream + `;
 		}
-` + serverConfig + `
+` + internalTLSConfig + serverConfig + `
 
 			# If you have a domain name, this is where to add it
 			server_name $hostname;
@@ -473,50 +592,7 @@ func (r *RepoManagerReconciler) pulpWebConfigMap(ctx context.Context, m *pulpv1.
 			# static files that can change dynamically, or are needed for TLS
 			# purposes are served through the webserver.
 			root "/opt/app-root/src";
-
-			location /pulp/content/ {
-				proxy_set_header X-Forwarded-For $proxy_add_x_forwarded_for;
-				proxy_set_header X-Forwarded-Proto $scheme;
-				proxy_set_header Host $http_host;
-				# we don't want nginx trying to do something clever with
-				# redirects, we set the Host: header above already.
-				proxy_redirect off;
-				proxy_pass http://pulp-content;
-			}
-
-			location ` + controllers.GetAPIRoot(ctx, r.Client, m) + `api/v3/ {
-				proxy_set_header X-Forwarded-For $proxy_add_x_forwarded_for;
-				proxy_set_header X-Forwarded-Proto $scheme;
-				proxy_set_header Host $http_host;
-				# we don't want nginx trying to do something clever with
-				# redirects, we set the Host: header above already.
-				proxy_redirect off;
-				proxy_pass http://pulp-api;
-			}
-
-			location /auth/login/ {
-				proxy_set_header X-Forwarded-For $proxy_add_x_forwarded_for;
-				proxy_set_header X-Forwarded-Proto $scheme;
-				proxy_set_header Host $http_host;
-				# we don't want nginx trying to do something clever with
-				# redirects, we set the Host: header above already.
-				proxy_redirect off;
-				proxy_pass http://pulp-api;
-			}
-
-			include /opt/app-root/etc/nginx.default.d/*.conf;
-			include /etc/nginx/pulp/*.conf;
-
-			location / {
-				proxy_set_header X-Forwarded-For $proxy_add_x_forwarded_for;
-				proxy_set_header X-Forwarded-Proto $scheme;
-				proxy_set_header Host $http_host;
-				# we don't want nginx trying to do something clever with
-				# redirects, we set the Host: header above already.
-				proxy_redirect off;
-				proxy_pass http://pulp-api;
-				# static files are served through whitenoise - http://whitenoise.evans.io/en/stable/
-			}
+` + nginxServerDirectives(m) + nginxLocations(m, plugins) + `
 		}
 	}
 `,
@@ -535,3 +611,188 @@ func (r *RepoManagerReconciler) pulpWebConfigMap(ctx context.Context, m *pulpv1.
 	ctrl.SetControllerReference(m, sec, r.Scheme)
 	return sec
 }
+
+// nginxLocation represents a location block from the pulp-web nginx.conf
+type nginxLocation struct {
+	path     string
+	upstream string
+	// prefix is the exposed path replaced by rewrite
+	prefix  string
+	rewrite string
+}
+
+// nginxPluginDirectives are the directives, other than the proxy ones, from the location blocks of
+// the plugin snippets shipped in the pulp-web image (/etc/nginx/pulp/*.conf).
+// The snippets are not included because nginx does not accept duplicate locations.
+var nginxPluginDirectives = map[string][]string{
+	// pulp_container: the blobs are pushed in a single request
+	"/v2/": {"client_max_body_size 0;"},
+}
+
+// nginxLocations returns a location block for each path exposed by pulpcore and its plugins.
+// The locations are generated from the plugin paths (instead of including the snippets shipped
+// in the pulp-web image) so that pulp-web forwards the same paths as the Ingress/Routes.
+// The "/" location is kept as the last one.
+func nginxLocations(pulp *pulpv1.Pulp, plugins []controllers.IngressPlugin) string {
+	locations := []nginxLocation{}
+	seen := map[string]bool{}
+	for _, plugin := range plugins {
+		if seen[plugin.Path] {
+			continue
+		}
+		seen[plugin.Path] = true
+		upstream := "pulp-api"
+		if plugin.ServiceName == settings.ContentService(pulp.Name) {
+			upstream = "pulp-content"
+		}
+		locations = append(locations, nginxLocation{path: plugin.Path, upstream: upstream, prefix: plugin.Path, rewrite: plugin.Rewrite})
+	}
+
+	// the rate limited paths that are not exposed by pulpcore are forwarded in the same way
+	// as the longest exposed path matching them
+	for _, rateLimit := range pulp.Spec.Web.Nginx.RateLimits {
+		if seen[rateLimit.Path] {
+			continue
+		}
+		seen[rateLimit.Path] = true
+		parent := nginxLocation{upstream: "pulp-api", prefix: "/"}
+		for _, location := range locations {
+			if strings.HasPrefix(rateLimit.Path, location.path) && len(location.path) > len(parent.prefix) {
+				parent = location
+			}
+		}
+		parent.path = rateLimit.Path
+		locations = append(locations, parent)
+	}
+
+	config := ""
+	rootLocation := ""
+	for _, location := range locations {
+		directives := ""
+		if location.upstream == "pulp-api" {
+			directives += nginxAPIAccess(pulp)
+		}
+		for i, rateLimit := range pulp.Spec.Web.Nginx.RateLimits {
+			if strings.HasPrefix(location.path, rateLimit.Path) {
+				directives += nginxLimitReq(i, rateLimit)
+			}
+		}
+		for _, directive := range nginxPluginDirectives[location.prefix] {
+			directives += `
+				` + directive
+		}
+		if len(location.rewrite) > 0 {
+			directives += `
+				rewrite ^` + regexp.QuoteMeta(location.prefix) + `(.*)$ ` + location.rewrite + `$1 break;`
+		}
+		block := `
+			location ` + location.path + ` {
+				proxy_set_header X-Forwarded-For $proxy_add_x_forwarded_for;
+				proxy_set_header X-Forwarded-Proto $scheme;
+				proxy_set_header Host $http_host;
+				# we don't want nginx trying to do something clever with
+				# redirects, we set the Host: header above already.
+				proxy_redirect off;` + directives + `
+				proxy_pass http://` + location.upstream + `;
+			}
+`
+		if location.path == "/" {
+			rootLocation = block
+			continue
+		}
+		config += block
+	}
+	return config + rootLocation
+}
+
+// nginxRateLimitZone returns the name of the shared memory zone of the i-th rate limit
+func nginxRateLimitZone(i int) string {
+	return "pulp_rate_limit_" + strconv.Itoa(i)
+}
+
+// nginxLimitReq returns the limit_req directive of the i-th rate limit
+func nginxLimitReq(i int, rateLimit pulpv1.NginxRateLimit) string {
+	limitReq := `
+				limit_req zone=` + nginxRateLimitZone(i)
+	if rateLimit.Burst > 0 {
+		limitReq += ` burst=` + strconv.Itoa(int(rateLimit.Burst))
+	}
+	if rateLimit.NoDelay {
+		limitReq += ` nodelay`
+	}
+	return limitReq + ";"
+}
+
+// nginxAPIAccess returns the allow/deny directives from web.nginx.api_{deny,allow}_list.
+// nginx checks the rules in order, so the deny list is evaluated first and, if there is an
+// allow list, all the other addresses are denied.
+func nginxAPIAccess(pulp *pulpv1.Pulp) string {
+	access := ""
+	for _, address := range pulp.Spec.Web.Nginx.APIDenyList {
+		access += `
+				deny ` + address + `;`
+	}
+	for _, address := range pulp.Spec.Web.Nginx.APIAllowList {
+		access += `
+				allow ` + address + `;`
+	}
+	if len(pulp.Spec.Web.Nginx.APIAllowList) > 0 {
+		access += `
+				deny all;`
+	}
+	return access
+}
+
+// nginxHTTPDirectives returns the rate limit zones and the web.nginx.http_snippet
+// added to the http block
+func nginxHTTPDirectives(pulp *pulpv1.Pulp) string {
+	directives := ""
+	for i, rateLimit := range pulp.Spec.Web.Nginx.RateLimits {
+		directives += `
+		limit_req_zone $binary_remote_addr zone=` + nginxRateLimitZone(i) + `:10m rate=` + rateLimit.Rate + `;`
+	}
+	if len(pulp.Spec.Web.Nginx.HTTPSnippet) > 0 {
+		directives += "\n" + indentNginxSnippet(pulp.Spec.Web.Nginx.HTTPSnippet, "\t\t")
+	}
+	if len(directives) > 0 {
+		directives += "\n"
+	}
+	return directives
+}
+
+// nginxServerDirectives returns the response headers and the web.nginx.server_snippet
+// added to the server block
+func nginxServerDirectives(pulp *pulpv1.Pulp) string {
+	directives := ""
+	headers := make([]string, 0, len(pulp.Spec.Web.Nginx.Headers))
+	for name := range pulp.Spec.Web.Nginx.Headers {
+		headers = append(headers, name)
+	}
+	sort.Strings(headers)
+	for _, name := range headers {
+		directives += `
+			add_header ` + name + ` "` + pulp.Spec.Web.Nginx.Headers[name] + `" always;`
+	}
+	if len(pulp.Spec.Web.Nginx.RateLimits) > 0 {
+		directives += `
+			limit_req_status 429;`
+	}
+	if len(pulp.Spec.Web.Nginx.ServerSnippet) > 0 {
+		directives += "\n" + indentNginxSnippet(pulp.Spec.Web.Nginx.ServerSnippet, "\t\t\t")
+	}
+	if len(directives) > 0 {
+		directives += "\n"
+	}
+	return directives
+}
+
+// indentNginxSnippet indents each line of a raw snippet to keep the nginx.conf readable
+func indentNginxSnippet(snippet, indent string) string {
+	lines := strings.Split(strings.TrimRight(snippet, "\n"), "\n")
+	for i, line := range lines {
+		if len(strings.TrimSpace(line)) > 0 {
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
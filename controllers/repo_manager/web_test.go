@@ -0,0 +1,130 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo_manager
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	"github.com/pulp/pulp-operator/controllers"
+	"github.com/pulp/pulp-operator/controllers/settings"
+)
+
+// webTestPlugins returns the default paths and the paths from pulp_container and pulp_ansible
+func webTestPlugins(pulp *pulpv1.Pulp) []controllers.IngressPlugin {
+	api, content := settings.ApiService(pulp.Name), settings.ContentService(pulp.Name)
+	return []controllers.IngressPlugin{
+		{Name: "test-content", Path: "/pulp/content/", ServiceName: content},
+		{Name: "test-api-v3", Path: "/pulp/api/v3/", ServiceName: api},
+		{Name: "test-auth", Path: "/auth/login/", ServiceName: api},
+		{Name: "test", Path: "/", ServiceName: api},
+		{Name: "test-container-v2", Path: "/v2/", ServiceName: api},
+		{Name: "test-container-content", Path: "/pulp/container/", ServiceName: content},
+		{Name: "test-ansible", Path: "/pulp_ansible/galaxy/", ServiceName: api, Rewrite: "/api/galaxy/"},
+		{Name: "test-api-v3-duplicated", Path: "/pulp/api/v3/", ServiceName: api},
+	}
+}
+
+// nginxLocationBlocks returns the location blocks (by path) from the nginx config
+func nginxLocationBlocks(config string) (map[string]string, []string) {
+	blocks := map[string]string{}
+	paths := []string{}
+	for _, match := range regexp.MustCompile(`(?s)location (\S+) \{(.*?)\n\t\t\t\}`).FindAllStringSubmatch(config, -1) {
+		blocks[match[1]] = match[2]
+		paths = append(paths, match[1])
+	}
+	return blocks, paths
+}
+
+func TestNginxLocations(t *testing.T) {
+	pulp := settingsTestPulp()
+	blocks, paths := nginxLocationBlocks(nginxLocations(pulp, webTestPlugins(pulp)))
+
+	expectedPaths := []string{"/pulp/content/", "/pulp/api/v3/", "/auth/login/", "/v2/", "/pulp/container/", "/pulp_ansible/galaxy/", "/"}
+	if strings.Join(paths, " ") != strings.Join(expectedPaths, " ") {
+		t.Fatalf("expected the locations %v (with / as the last one and no duplicates), got %v", expectedPaths, paths)
+	}
+
+	tests := []struct {
+		path     string
+		contains []string
+		excludes []string
+	}{
+		{path: "/pulp/content/", contains: []string{"proxy_pass http://pulp-content;"}, excludes: []string{"rewrite", "client_max_body_size"}},
+		{path: "/pulp/container/", contains: []string{"proxy_pass http://pulp-content;"}},
+		{path: "/pulp/api/v3/", contains: []string{"proxy_pass http://pulp-api;", "proxy_set_header Host $http_host;", "proxy_redirect off;"}},
+		{path: "/v2/", contains: []string{"proxy_pass http://pulp-api;", "client_max_body_size 0;"}},
+		{path: "/pulp_ansible/galaxy/", contains: []string{`rewrite ^/pulp_ansible/galaxy/(.*)$ /api/galaxy/$1 break;`}},
+		{path: "/", contains: []string{"proxy_pass http://pulp-api;"}, excludes: []string{"client_max_body_size"}},
+	}
+	for _, test := range tests {
+		for _, directive := range test.contains {
+			if !strings.Contains(blocks[test.path], directive) {
+				t.Errorf("expected %q in the %v location, got %v", directive, test.path, blocks[test.path])
+			}
+		}
+		for _, directive := range test.excludes {
+			if strings.Contains(blocks[test.path], directive) {
+				t.Errorf("unexpected %q in the %v location", directive, test.path)
+			}
+		}
+	}
+}
+
+func TestNginxLocationsAccessAndRateLimits(t *testing.T) {
+	pulp := settingsTestPulp()
+	pulp.Spec.Web.Nginx = pulpv1.Nginx{
+		APIAllowList: []string{"10.0.0.0/8"},
+		APIDenyList:  []string{"10.1.2.3"},
+		RateLimits: []pulpv1.NginxRateLimit{
+			{Path: "/pulp/api/v3/", Rate: "10r/s", Burst: 20, NoDelay: true},
+			{Path: "/v2/token/", Rate: "1r/s"},
+		},
+	}
+	blocks, paths := nginxLocationBlocks(nginxLocations(pulp, webTestPlugins(pulp)))
+	if paths[len(paths)-1] != "/" {
+		t.Errorf("expected / as the last location, got %v", paths)
+	}
+
+	// the access lists are only applied to the api
+	access := "deny 10.1.2.3;\n\t\t\t\tallow 10.0.0.0/8;\n\t\t\t\tdeny all;"
+	for _, path := range []string{"/pulp/api/v3/", "/v2/", "/"} {
+		if !strings.Contains(blocks[path], access) {
+			t.Errorf("expected the access lists in the %v location, got %v", path, blocks[path])
+		}
+	}
+	if strings.Contains(blocks["/pulp/content/"], "deny") {
+		t.Errorf("unexpected access lists in the content location")
+	}
+
+	if !strings.Contains(blocks["/pulp/api/v3/"], "limit_req zone=pulp_rate_limit_0 burst=20 nodelay;") {
+		t.Errorf("expected the rate limit in the api location, got %v", blocks["/pulp/api/v3/"])
+	}
+
+	// the rate limited path not exposed by the plugins is forwarded as its parent location
+	token := blocks["/v2/token/"]
+	for _, directive := range []string{"limit_req zone=pulp_rate_limit_1;", "proxy_pass http://pulp-api;", "client_max_body_size 0;"} {
+		if !strings.Contains(token, directive) {
+			t.Errorf("expected %q in the /v2/token/ location, got %v", directive, token)
+		}
+	}
+	if strings.Contains(blocks["/v2/"], "pulp_rate_limit_1") {
+		t.Errorf("unexpected /v2/token/ rate limit in the /v2/ location")
+	}
+}
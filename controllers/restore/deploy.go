@@ -98,8 +98,8 @@ func (r *RepoManagerRestoreReconciler) scaleDeployments(ctx context.Context, pul
 		pulp.Spec.Api.Replicas = 1
 		pulp.Spec.Content.Replicas = 1
 		pulp.Spec.Worker.Replicas = 1
-		isNginxIngress := strings.ToLower(pulp.Spec.IngressType) == "ingress" && !controllers.IsNginxIngressSupported(pulp)
-		if strings.ToLower(pulp.Spec.IngressType) != "route" && !isNginxIngress {
+		isNativeIngress := strings.ToLower(pulp.Spec.IngressType) == "ingress" && controllers.IsNativeIngressSupported(pulp)
+		if strings.ToLower(pulp.Spec.IngressType) != "route" && strings.ToLower(pulp.Spec.IngressType) != "gateway" && !isNativeIngress {
 			pulp.Spec.Web.Replicas = 1
 		}
 	}
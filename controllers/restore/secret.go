@@ -47,12 +47,19 @@ type storageObjectSecret struct {
 	S3Region              string `json:"s3-region"`
 	S3Endpoint            string `json:"s3-endpoint"`
 	S3SecretAccessKey     string `json:"s3-secret-access-key"`
+	S3RoleArn             string `json:"s3-role-arn"`
 	StorageSecret         string `json:"storage_secret"`
 	AzureAccountName      string `json:"azure-account-name"`
 	AzureAccountKey       string `json:"azure-account-key"`
 	AzureContainer        string `json:"azure-container"`
 	AzureContainerPath    string `json:"azure-container-path"`
 	AzureConnectionString string `json:"azure-connection-string"`
+	AzureClientId         string `json:"azure-client-id"`
+	AzureTenantId         string `json:"azure-tenant-id"`
+	GCSBucketName         string `json:"gcs-bucket-name"`
+	GCSProjectId          string `json:"gcs-project-id"`
+	GCSLocation           string `json:"gcs-location"`
+	GCSCredentials        string `json:"gcs-credentials"`
 }
 
 type signingSecret struct {
@@ -155,6 +162,9 @@ func (r *RepoManagerRestoreReconciler) restoreSecret(ctx context.Context, pulpRe
 	if found, err := r.restoreSecretFromYaml(ctx, resourceTypeLDAP, backupDir, "ldap_ca_secret.yaml", pod, pulpRestore); found && err != nil {
 		return err
 	}
+	if found, err := r.restoreSecretFromYaml(ctx, resourceTypeLDAP, backupDir, "ldap_bind_password_secret.yaml", pod, pulpRestore); found && err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -247,6 +257,18 @@ func (r *RepoManagerRestoreReconciler) secret(ctx context.Context, resourceType,
 			}
 		}
 
+		// the storage Secret can also provide extra django-storages OPTIONS
+		// (s3-*, azure-* and gcs-* keys) that are not part of storageObjectSecret
+		if secretNameKey == "storage_secret" {
+			extraKeys := map[string]string{}
+			yaml.Unmarshal([]byte(cmdOutput), &extraKeys)
+			for key, value := range extraKeys {
+				if _, found := secretData[key]; !found && key != secretNameKey && value != "" {
+					secretData[key] = value
+				}
+			}
+		}
+
 		secret := &corev1.Secret{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      secretNameData,
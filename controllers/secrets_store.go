@@ -0,0 +1,155 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"path"
+
+	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// SecretsStoreDriver is the name of the Secrets Store CSI driver
+	SecretsStoreDriver = "secrets-store.csi.k8s.io"
+	// SecretsStorePath is the directory in which the SecretProviderClasses are mounted
+	// (each one in a subdirectory named after its source)
+	SecretsStorePath = "/etc/pulp/secrets-store"
+
+	// the sources of the credentials that can be provided by a SecretProviderClass
+	SecretsStoreDatabase = "database"
+	SecretsStoreS3       = "object-storage-s3"
+	SecretsStoreAzure    = "object-storage-azure"
+	SecretsStoreGCS      = "object-storage-gcs"
+	SecretsStoreCache    = "cache"
+	SecretsStoreSSO      = "sso"
+	SecretsStoreLDAP     = "ldap"
+	SecretsStoreOIDC     = "oidc"
+	SecretsStoreSigning  = "signing"
+)
+
+// SecretProviderClassGVK is the kind of the objects provided by the Secrets Store CSI driver
+var SecretProviderClassGVK = schema.GroupVersionKind{Group: "secrets-store.csi.x-k8s.io", Version: "v1", Kind: "SecretProviderClass"}
+
+// secretsStoreSources are the sources mounted in the pulpcore pods (the signing key is
+// mounted only in the containers that import it into the keyring)
+var secretsStoreSources = []string{SecretsStoreDatabase, SecretsStoreS3, SecretsStoreAzure, SecretsStoreGCS, SecretsStoreCache, SecretsStoreSSO, SecretsStoreLDAP, SecretsStoreOIDC}
+
+// SecretsStoreClass returns the SecretProviderClass that provides the credentials of source
+// (or an empty string if the credentials are read from the Secret)
+func SecretsStoreClass(pulp *pulpv1.Pulp, source string) string {
+	store := pulp.Spec.SecretsStore
+	switch source {
+	case SecretsStoreDatabase:
+		return store.ExternalDBSecret
+	case SecretsStoreS3:
+		return store.ObjectStorageS3Secret
+	case SecretsStoreAzure:
+		return store.ObjectStorageAzureSecret
+	case SecretsStoreGCS:
+		return store.ObjectStorageGCSSecret
+	case SecretsStoreCache:
+		return store.ExternalCacheSecret
+	case SecretsStoreSSO:
+		return store.SSOSecret
+	case SecretsStoreLDAP:
+		return store.LDAPBindPasswordSecret
+	case SecretsStoreOIDC:
+		return store.OIDCClientSecret
+	case SecretsStoreSigning:
+		return store.SigningSecret
+	}
+	return ""
+}
+
+// SecretsStoreFile returns the path of the object mounted from the SecretProviderClass of source
+func SecretsStoreFile(source, object string) string {
+	return path.Join(SecretsStorePath, source, object)
+}
+
+// SecretsStoreVolume returns the CSI volume with the SecretProviderClass of source
+func SecretsStoreVolume(pulp *pulpv1.Pulp, source string) corev1.Volume {
+	readOnly := true
+	return corev1.Volume{
+		Name: "secrets-store-" + source,
+		VolumeSource: corev1.VolumeSource{
+			CSI: &corev1.CSIVolumeSource{
+				Driver:           SecretsStoreDriver,
+				ReadOnly:         &readOnly,
+				VolumeAttributes: map[string]string{"secretProviderClass": SecretsStoreClass(pulp, source)},
+			},
+		},
+	}
+}
+
+// SecretsStoreVolumes returns the CSI volumes with the SecretProviderClasses used by pulpcore
+func SecretsStoreVolumes(pulp *pulpv1.Pulp) []corev1.Volume {
+	volumes := []corev1.Volume{}
+	for _, source := range secretsStoreSources {
+		if len(SecretsStoreClass(pulp, source)) > 0 {
+			volumes = append(volumes, SecretsStoreVolume(pulp, source))
+		}
+	}
+	return volumes
+}
+
+// SecretsStoreVolumeMounts returns the mount points of the SecretsStoreVolumes
+func SecretsStoreVolumeMounts(pulp *pulpv1.Pulp) []corev1.VolumeMount {
+	volumeMounts := []corev1.VolumeMount{}
+	for _, source := range secretsStoreSources {
+		if len(SecretsStoreClass(pulp, source)) > 0 {
+			volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: "secrets-store-" + source, MountPath: path.Join(SecretsStorePath, source), ReadOnly: true})
+		}
+	}
+	return volumeMounts
+}
+
+// SigningEnabled returns true if a signing key is provided (through signing_secret or
+// through a SecretProviderClass)
+func SigningEnabled(pulp *pulpv1.Pulp) bool {
+	return len(pulp.Spec.SigningSecret) > 0 || len(pulp.Spec.SecretsStore.SigningSecret) > 0
+}
+
+// SigningKeyVolume returns the gpg-keys volume with the signing_service.gpg key
+func SigningKeyVolume(pulp *pulpv1.Pulp) corev1.Volume {
+	if len(pulp.Spec.SecretsStore.SigningSecret) > 0 {
+		volume := SecretsStoreVolume(pulp, SecretsStoreSigning)
+		volume.Name = "gpg-keys"
+		return volume
+	}
+	return corev1.Volume{
+		Name: "gpg-keys",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: pulp.Spec.SigningSecret,
+				Items:      []corev1.KeyToPath{{Key: "signing_service.gpg", Path: "signing_service.gpg"}},
+			},
+		},
+	}
+}
+
+// SigningKeyFingerprint returns the fingerprint of the signing key. The key from a SecretProviderClass
+// is not available to the operator, so its fingerprint is provided in Pulp CR.
+func SigningKeyFingerprint(ctx context.Context, r client.Client, pulp *pulpv1.Pulp) (string, error) {
+	if len(pulp.Spec.SecretsStore.SigningSecret) > 0 {
+		return pulp.Spec.SecretsStore.SigningKeyFingerprint, nil
+	}
+	return GetSigningKeyFingerprint(ctx, r, pulp.Spec.SigningSecret, pulp.Namespace)
+}
@@ -10,6 +10,7 @@ package settings
 
 const (
 	caConfigMapName = "user-ca-bundle"
+	pluginPaths     = "plugin-paths"
 )
 
 func EmptyCAConfigMapName(pulpName string) string {
@@ -23,3 +24,7 @@ func PulpWebConfigMapName(pulpName string) string {
 func PulpWorkerProbe(pulpName string) string {
 	return pulpName + "-worker-probe"
 }
+
+func PluginPathsConfigMap(pulpName string) string {
+	return pulpName + "-" + pluginPaths
+}
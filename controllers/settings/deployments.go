@@ -18,6 +18,8 @@ const (
 	WORKER  PulpcoreType = "Worker"
 	WEB     PulpcoreType = "Web"
 	CACHE   PulpcoreType = "Redis"
+	// SENTINEL is the Redis Sentinel component deployed when cache.mode is "sentinel"
+	SENTINEL PulpcoreType = "Redis-Sentinel"
 )
 
 func (t PulpcoreType) DeploymentName(pulpName string) string {
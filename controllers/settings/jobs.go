@@ -13,6 +13,12 @@ const (
 	resetAdminPwdJob            = "reset-admin-password-"
 	updateChecksumsJob          = "update-content-checksums-"
 	signingScriptJob            = "signing-metadata-"
+	storageMigrationJob         = "storage-migration-"
+	pluginPathsJob              = "plugin-paths-"
+	ldapCheckJob                = "ldap-check-"
+	rotateDBKeyJob              = "rotate-db-key-"
+	fileStorageUsageJob         = "file-storage-usage-"
+	syncScheduleCronJob         = "sync"
 	SigningScriptPath           = "/var/lib/pulp/scripts/"
 	ContainerSigningScriptName  = "container_script.sh"
 	CollectionSigningScriptName = "collection_script.sh"
@@ -32,3 +38,21 @@ func UpdateChecksumsJob(pulpName string) string {
 func SigningScriptJob(pulpName string) string {
 	return pulpName + "-" + signingScriptJob
 }
+func StorageMigrationJob(pulpName string) string {
+	return pulpName + "-" + storageMigrationJob
+}
+func PluginPathsJob(pulpName string) string {
+	return pulpName + "-" + pluginPathsJob
+}
+func LDAPCheckJob(pulpName string) string {
+	return pulpName + "-" + ldapCheckJob
+}
+func RotateDBKeyJob(pulpName string) string {
+	return pulpName + "-" + rotateDBKeyJob
+}
+func FileStorageUsageJob(pulpName string) string {
+	return pulpName + "-" + fileStorageUsageJob
+}
+func SyncScheduleCronJob(scheduleName string) string {
+	return scheduleName + "-" + syncScheduleCronJob
+}
@@ -23,3 +23,8 @@ func DefaultDBPVC(pulpName string) string {
 func DefaultCachePVC(pulpName string) string {
 	return pulpName + "-" + cacheVolumeName
 }
+
+// DBStatefulSetPVC returns the name of the PVC created by the database StatefulSet volumeClaimTemplate
+func DBStatefulSetPVC(pulpName string) string {
+	return DefaultDBPVC(pulpName) + "-" + DefaultDBStatefulSet(pulpName) + "-0"
+}
@@ -16,6 +16,12 @@ const (
 	dBFieldsEncryptionSecret = "db-fields-encryption"
 	rhOperatorPullSecretName = "redhat-operators-pull-secret"
 	postgresConfiguration    = "postgres-configuration"
+	storageMigration         = "storage-migration"
+	ingressCertificate       = "ingress-tls"
+	routeCertificate         = "route-tls"
+	webCertificate           = "web-tls"
+	internalCA               = "internal-ca"
+	internalTLS              = "internal-tls"
 )
 
 func DefaultAdminPassword(pulpName string) string {
@@ -39,18 +45,36 @@ func RedHatOperatorPullSecret(pulpName string) string {
 func DefaultDBSecret(pulpName string) string {
 	return pulpName + "-" + postgresConfiguration
 }
+func StorageMigrationSecret(pulpName string) string {
+	return pulpName + "-" + storageMigration
+}
+func IngressCertificate(pulpName string) string {
+	return pulpName + "-" + ingressCertificate
+}
+func RouteCertificate(pulpName string) string {
+	return pulpName + "-" + routeCertificate
+}
+func WebCertificate(pulpName string) string {
+	return pulpName + "-" + webCertificate
+}
+func InternalCASecret(pulpName string) string {
+	return pulpName + "-" + internalCA
+}
+func InternalTLSSecret(pulpName string) string {
+	return pulpName + "-" + internalTLS
+}
 
 // Default configurations for settings.py
-func DefaultPulpSettings(rootUrl string) map[string]string {
-	return map[string]string{
-		"DB_ENCRYPTION_KEY":         `"/etc/pulp/keys/database_fields.symmetric.key"`,
-		"ANSIBLE_CERTS_DIR":         `"/etc/pulp/keys/"`,
-		"PRIVATE_KEY_PATH":          `"/etc/pulp/keys/container_auth_private_key.pem"`,
-		"PUBLIC_KEY_PATH":           `"/etc/pulp/keys/container_auth_public_key.pem"`,
-		"STATIC_ROOT":               `"/var/lib/operator/static/"`,
-		"TOKEN_AUTH_DISABLED":       "False",
-		"TOKEN_SIGNATURE_ALGORITHM": `"ES256"`,
-		"ANSIBLE_API_HOSTNAME":      `"` + rootUrl + `"`,
-		"CONTENT_ORIGIN":            `"` + rootUrl + `"`,
+func DefaultPulpSettings(rootUrl, contentOrigin string) map[string]any {
+	return map[string]any{
+		"DB_ENCRYPTION_KEY":         "/etc/pulp/keys/database_fields.symmetric.key",
+		"ANSIBLE_CERTS_DIR":         "/etc/pulp/keys/",
+		"PRIVATE_KEY_PATH":          "/etc/pulp/keys/container_auth_private_key.pem",
+		"PUBLIC_KEY_PATH":           "/etc/pulp/keys/container_auth_public_key.pem",
+		"STATIC_ROOT":               "/var/lib/operator/static/",
+		"TOKEN_AUTH_DISABLED":       false,
+		"TOKEN_SIGNATURE_ALGORITHM": "ES256",
+		"ANSIBLE_API_HOSTNAME":      rootUrl,
+		"CONTENT_ORIGIN":            contentOrigin,
 	}
 }
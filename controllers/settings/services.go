@@ -26,3 +26,9 @@ func DBService(pulpName string) string {
 func CacheService(pulpName string) string {
 	return pulpName + "-redis-svc"
 }
+func CacheHeadlessService(pulpName string) string {
+	return pulpName + "-redis-headless"
+}
+func CacheSentinelService(pulpName string) string {
+	return pulpName + "-redis-sentinel-svc"
+}
@@ -11,3 +11,6 @@ package settings
 func DefaultDBStatefulSet(pulpName string) string {
 	return pulpName + "-database"
 }
+func CacheStatefulSet(pulpName string) string {
+	return pulpName + "-redis"
+}
@@ -33,18 +33,22 @@ func telemetryConfig(resources any, envVars []corev1.EnvVar, containers []corev1
 
 	// when telemetry is enabled we need to modify the entrypoint from container image
 	containers[0].Command = []string{"/bin/sh", "-c"}
-	containers[0].Args = []string{
-		`if which pulpcore-api
+	entrypoint := `if which pulpcore-api
 then
   PULP_API_ENTRYPOINT=("pulpcore-api")
 else
   PULP_API_ENTRYPOINT=("gunicorn" "pulpcore.app.wsgi:application" "--bind" "[::]:24817" "--name" "pulp-api" "--access-logformat" "pulp [%({correlation-id}o)s]: %(h)s %(l)s %(u)s %(t)s \"%(r)s\" %(s)s %(b)s \"%(f)s\" \"%(a)s\"")
-fi
+fi`
+	if InternalTLSEnabled(pulp) {
+		entrypoint = `PULP_API_ENTRYPOINT=("gunicorn" "pulpcore.app.wsgi:application" "--bind" "[::]:24817" "--name" "pulp-api" "--access-logformat" "pulp [%({correlation-id}o)s]: %(h)s %(l)s %(u)s %(t)s \"%(r)s\" %(s)s %(b)s \"%(f)s\" \"%(a)s\"")`
+	}
+	containers[0].Args = []string{
+		entrypoint + `
 
 exec "${PULP_API_ENTRYPOINT[@]}" \
 --timeout "${PULP_GUNICORN_TIMEOUT}" \
 --workers "${PULP_API_WORKERS}" \
---access-logfile -`,
+--access-logfile -` + gunicornTLSArgs(*pulp),
 	}
 
 	volumeName := "otel-collector-config"
@@ -57,6 +57,7 @@ import (
 const (
 	AzureObjType = "azure blob"
 	S3ObjType    = "s3"
+	GCSObjType   = "gcs"
 	SCNameType   = "StorageClass"
 	PVCType      = "PVC"
 	EmptyDirType = "emptyDir"
@@ -65,6 +66,31 @@ const (
 	CacheResource    = "Cache"
 	DatabaseResource = "Database"
 
+	CacheStandaloneMode = "standalone"
+	CacheSentinelMode   = "sentinel"
+	SentinelPort        = 26379
+
+	GCSCredentialsPath = "/etc/pulp/keys/gcs-credentials.json"
+	InternalTLSPath    = "/etc/pulp/internal-tls"
+	OIDCPath           = "/etc/pulp/oidc"
+	LDAPCAPath         = "/etc/pulp/ldap/ca.crt"
+
+	StorageMigrationMaintenance = "Maintenance"
+	StorageMigrationCopying     = "Copying"
+	StorageMigrationCompleted   = "Completed"
+	StorageMigrationFailed      = "Failed"
+	StorageMigrationCanceled    = "Canceled"
+
+	IRSARoleAnnotation        = "eks.amazonaws.com/role-arn"
+	AzureWIClientIdAnnotation = "azure.workload.identity/client-id"
+	AzureWITenantIdAnnotation = "azure.workload.identity/tenant-id"
+	AzureWIUseLabel           = "azure.workload.identity/use"
+
+	NginxIngressController   = "nginx"
+	TraefikIngressController = "traefik"
+	HAProxyIngressController = "haproxy"
+	ContourIngressController = "contour"
+
 	DotNotEditMessage = `
 # This file is managed by Pulp operator.
 # DO NOT EDIT IT.
@@ -134,6 +160,10 @@ func MultiStorageConfigured(pulp *pulpv1.Pulp, resource string) (bool, []string)
 			names = append(names, S3ObjType)
 		}
 
+		if len(pulp.Spec.ObjectStorageGCSSecret) > 0 {
+			names = append(names, GCSObjType)
+		}
+
 		if len(pulp.Spec.FileStorageClass) > 0 {
 			names = append(names, SCNameType)
 		}
@@ -239,7 +269,25 @@ func ContainerExec[T any](ctx context.Context, client T, pod *corev1.Pod, comman
 
 // IsNginxIngressSupported returns true if the operator was instructed that this is a nginx ingress controller
 func IsNginxIngressSupported(pulp *pulpv1.Pulp) bool {
-	return pulp.Spec.IsNginxIngress
+	return IngressController(pulp) == NginxIngressController
+}
+
+// IngressController returns the ingress controller defined in ingress_controller (or nginx, if is_nginx_ingress is true).
+// An empty string means that the ingress controller is unknown (pulp-web will forward the traffic).
+func IngressController(pulp *pulpv1.Pulp) string {
+	if len(pulp.Spec.IngressController) > 0 {
+		return strings.ToLower(pulp.Spec.IngressController)
+	}
+	if pulp.Spec.IsNginxIngress {
+		return NginxIngressController
+	}
+	return ""
+}
+
+// IsNativeIngressSupported returns true if the operator knows how to configure the ingress controller
+// to forward the traffic to api and content pods (without pulp-web)
+func IsNativeIngressSupported(pulp *pulpv1.Pulp) bool {
+	return len(IngressController(pulp)) > 0
 }
 
 // CustomZapLogger should be used only for warn messages
@@ -318,8 +366,8 @@ func getCMData(cm *corev1.ConfigMap, key string) *string {
 	return nil
 }
 
-// domainEnabled returns the definition of DOMAIN_ENABLED in settings.py
-func domainEnabled(ctx context.Context, r client.Client, pulp *pulpv1.Pulp) bool {
+// DomainEnabled returns the definition of DOMAIN_ENABLED in settings.py
+func DomainEnabled(ctx context.Context, r client.Client, pulp *pulpv1.Pulp) bool {
 	if domainEnabled := getPulpSetting(ctx, r, pulp, "domain_enabled"); domainEnabled != "" {
 		enabled, _ := strconv.ParseBool(domainEnabled)
 		return enabled
@@ -343,12 +391,22 @@ func GetContentPathPrefix(ctx context.Context, r client.Client, pulp *pulpv1.Pul
 		return strings.Replace(contentPath, "\"", "", -1)
 	}
 
-	if domainEnabled(ctx, r, pulp) {
+	if DomainEnabled(ctx, r, pulp) {
 		return "/pulp/content/default/"
 	}
 	return "/pulp/content/"
 }
 
+// GetDomainContentPath returns the path of the content from domain, which is the CONTENT_PATH_PREFIX
+// from settings.py (or /pulp/content/) followed by the domain name
+func GetDomainContentPath(ctx context.Context, r client.Client, pulp *pulpv1.Pulp, domain string) string {
+	contentPath := "/pulp/content/"
+	if prefix := getPulpSetting(ctx, r, pulp, "content_path_prefix"); prefix != "" {
+		contentPath = strings.Replace(prefix, "\"", "", -1)
+	}
+	return contentPath + domain + "/"
+}
+
 // getSigningKeyFingerprint returns the signing key fingerprint from secret object
 func GetSigningKeyFingerprint(ctx context.Context, r client.Client, secretName, secretNamespace string) (string, error) {
 
@@ -489,6 +547,7 @@ type PulpService struct{}
 type PulpConfigMap struct{}
 type PulpIngress struct{}
 type PulpRoute struct{}
+type PulpNetworkPolicy struct{}
 type PulpObjectMetadata struct{}
 
 // GetFields expects 3 arguments:
@@ -613,6 +672,26 @@ func (PulpRoute) GetFieldAndKind() (string, string) {
 	return "Spec", "Route"
 }
 
+// GetFields expects 2 arguments:
+// * the current NetworkPolicy spec field
+// * the expected NetworkPolicy spec field
+func (PulpNetworkPolicy) GetFields(obj ...interface{}) []interface{} {
+	var fieldsState []interface{}
+	expectedSpec := append(fieldsState, reflect.Indirect(reflect.ValueOf(obj[0].(client.Object))).FieldByName("Spec").Interface())
+	currentSpec := append(fieldsState, reflect.Indirect(reflect.ValueOf(obj[1].(client.Object))).FieldByName("Spec").Interface())
+	return append(fieldsState, expectedSpec, currentSpec)
+}
+
+// GetModifiedFunc returns the function used to check the NetworkPolicy modification
+func (PulpNetworkPolicy) GetModifiedFunc() func(...interface{}) bool {
+	return checkSpecModification
+}
+
+// GetFieldAndKind returns the field being checked and the object kind
+func (PulpNetworkPolicy) GetFieldAndKind() (string, string) {
+	return "Spec", "NetworkPolicy"
+}
+
 // GetFields expects 2 arguments:
 // * the current Object definition
 // * the expected Object definition
@@ -851,12 +930,226 @@ func SetPulpcoreCustomEnvVars(pulp pulpv1.Pulp, pulpcoreType settings.PulpcoreTy
 	return SetCustomEnvVars(pulp, string(pulpcoreType))
 }
 
+// CacheSentinelEnabled returns true if the cache managed by the operator
+// should be deployed as a replicated Redis monitored by Sentinel
+func CacheSentinelEnabled(pulp pulpv1.Pulp) bool {
+	return pulp.Spec.Cache.Enabled && len(pulp.Spec.Cache.ExternalCacheSecret) == 0 && pulp.Spec.Cache.Mode == CacheSentinelMode
+}
+
+// DefaultCacheMaster returns the address of the first Redis pod from the StatefulSet
+// deployed in sentinel mode. It is the master elected during the first deployment.
+func DefaultCacheMaster(pulp pulpv1.Pulp) string {
+	return settings.CacheStatefulSet(pulp.Name) + "-0." + settings.CacheHeadlessService(pulp.Name) + "." + pulp.Namespace + ".svc.cluster.local"
+}
+
 // GetStorageType retrieves the storage type defined in pulp CR
 func GetStorageType(pulp pulpv1.Pulp) []string {
 	_, storageType := MultiStorageConfigured(&pulp, "Pulp")
 	return storageType
 }
 
+// IsObjectStorage returns true if storageType is one of the object storage types
+func IsObjectStorage(storageType string) bool {
+	return storageType == S3ObjType || storageType == AzureObjType || storageType == GCSObjType
+}
+
+// StorageMigrationInProgress returns true while pulpcore pods should be kept in
+// maintenance (scaled down) because the artifacts are being copied to a new storage backend
+func StorageMigrationInProgress(pulp *pulpv1.Pulp) bool {
+	migration := pulp.Status.StorageMigration
+	if migration == nil {
+		return false
+	}
+	return migration.Phase == StorageMigrationMaintenance || migration.Phase == StorageMigrationCopying || migration.Phase == StorageMigrationFailed
+}
+
+// CertManagerEnabled returns true if the certificates should be provisioned by cert-manager
+func CertManagerEnabled(pulp *pulpv1.Pulp) bool {
+	return pulp.Spec.TLS.IssuerRef != nil && len(pulp.Spec.TLS.IssuerRef.Name) > 0
+}
+
+// IngressTLSSecret returns the name of the Secret with the ingress certificate.
+// The Secret provided through ingress_tls_secret takes precedence over the one
+// issued by cert-manager.
+func IngressTLSSecret(pulp *pulpv1.Pulp) string {
+	if len(pulp.Spec.IngressTLSSecret) > 0 || !CertManagerEnabled(pulp) {
+		return pulp.Spec.IngressTLSSecret
+	}
+	return settings.IngressCertificate(pulp.Name)
+}
+
+// RouteTLSSecret returns the name of the Secret with the route certificate.
+// The Secret provided through route_tls_secret takes precedence over the one
+// issued by cert-manager.
+func RouteTLSSecret(pulp *pulpv1.Pulp) string {
+	if len(pulp.Spec.RouteTLSSecret) > 0 || !CertManagerEnabled(pulp) {
+		return pulp.Spec.RouteTLSSecret
+	}
+	return settings.RouteCertificate(pulp.Name)
+}
+
+// PulpHost is a DNS host used to expose Pulp and the Secret with its certificate
+type PulpHost struct {
+	Host      string
+	TLSSecret string
+	// ContentOnly is true for the content_host, which should only expose the content paths
+	ContentOnly bool
+}
+
+// PulpHosts returns the main host (ingress_host or route_host), the host_aliases and the
+// content_host. The hosts without a tls_secret share the certificate from the main host.
+func PulpHosts(pulp *pulpv1.Pulp, mainHost, mainTLSSecret string) []PulpHost {
+	hosts := []PulpHost{{Host: mainHost, TLSSecret: mainTLSSecret}}
+	hostTLSSecret := func(hostname pulpv1.Hostname) string {
+		if len(hostname.TLSSecret) > 0 {
+			return hostname.TLSSecret
+		}
+		return mainTLSSecret
+	}
+	for _, alias := range pulp.Spec.HostAliases {
+		hosts = append(hosts, PulpHost{Host: alias.Host, TLSSecret: hostTLSSecret(alias)})
+	}
+	if contentHost := pulp.Spec.ContentHost; contentHost != nil && len(contentHost.Host) > 0 {
+		hosts = append(hosts, PulpHost{Host: contentHost.Host, TLSSecret: hostTLSSecret(*contentHost), ContentOnly: true})
+	}
+	return hosts
+}
+
+// IngressHosts returns the hosts exposed through the Ingress
+func IngressHosts(pulp *pulpv1.Pulp) []PulpHost {
+	return PulpHosts(pulp, pulp.Spec.IngressHost, IngressTLSSecret(pulp))
+}
+
+// WebTLSPassthrough returns true if pulp-web should terminate the TLS connections
+// with the certificate issued by cert-manager
+func WebTLSPassthrough(pulp *pulpv1.Pulp) bool {
+	return strings.ToLower(pulp.Spec.Web.TLSTerminationMechanism) == "passthrough" && CertManagerEnabled(pulp)
+}
+
+// InternalTLSEnabled returns true if pulpcore-api and pulpcore-content should serve HTTPS
+func InternalTLSEnabled(pulp *pulpv1.Pulp) bool {
+	return pulp.Spec.InternalTLS.Enabled
+}
+
+// LDAPEnabled returns true if Pulp users can be authenticated through ldap
+func LDAPEnabled(pulp *pulpv1.Pulp) bool {
+	return len(pulp.Spec.LDAP.Config) > 0 || LDAPStructured(pulp)
+}
+
+// LDAPStructured returns true if the ldap settings should be generated from the ldap fields
+// instead of the ldap.config Secret
+func LDAPStructured(pulp *pulpv1.Pulp) bool {
+	return len(pulp.Spec.LDAP.ServerURI) > 0
+}
+
+// OIDCEnabled returns true if Pulp users should be authenticated through an OpenID provider
+func OIDCEnabled(pulp *pulpv1.Pulp) bool {
+	return len(pulp.Spec.OIDC.IssuerURL) > 0
+}
+
+// OIDCModuleVolume returns the volume with the pulp_oidc python module (social-auth pipeline
+// and urls) stored in the pulp-server Secret
+func OIDCModuleVolume(pulp *pulpv1.Pulp) corev1.Volume {
+	return corev1.Volume{
+		Name: "oidc",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: settings.PulpServerSecret(pulp.Name),
+				Items: []corev1.KeyToPath{
+					{Key: "oidc_init.py", Path: "pulp_oidc/__init__.py"},
+					{Key: "oidc_pipeline.py", Path: "pulp_oidc/pipeline.py"},
+					{Key: "oidc_urls.py", Path: "pulp_oidc/urls.py"},
+				},
+			},
+		},
+	}
+}
+
+// OIDCModuleVolumeMount returns the mount point of the pulp_oidc python module.
+// OIDCPath is added to sys.path by settings.py.
+func OIDCModuleVolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{Name: "oidc", MountPath: OIDCPath, ReadOnly: true}
+}
+
+// InternalTLSCertManager returns true if the services certificate should be issued by cert-manager.
+// A CA provided through internal_tls.ca_secret takes precedence over tls.issuer_ref.
+func InternalTLSCertManager(pulp *pulpv1.Pulp) bool {
+	return InternalTLSEnabled(pulp) && len(pulp.Spec.InternalTLS.CASecret) == 0 && CertManagerEnabled(pulp)
+}
+
+// InternalTLSCASecret returns the name of the Secret with the CA used to sign the services certificate
+func InternalTLSCASecret(pulp *pulpv1.Pulp) string {
+	if len(pulp.Spec.InternalTLS.CASecret) > 0 {
+		return pulp.Spec.InternalTLS.CASecret
+	}
+	return settings.InternalCASecret(pulp.Name)
+}
+
+// InternalTLSDNSNames returns the hostnames of the services certificate
+func InternalTLSDNSNames(pulp *pulpv1.Pulp) []string {
+	dnsNames := []string{}
+	for _, service := range []string{settings.ApiService(pulp.Name), settings.ContentService(pulp.Name)} {
+		dnsNames = append(dnsNames,
+			service,
+			service+"."+pulp.Namespace,
+			service+"."+pulp.Namespace+".svc",
+			service+"."+pulp.Namespace+".svc.cluster.local",
+		)
+	}
+	return dnsNames
+}
+
+// InternalTLSScheme returns the scheme used to reach pulpcore-api and pulpcore-content
+func InternalTLSScheme(pulp *pulpv1.Pulp) string {
+	if InternalTLSEnabled(pulp) {
+		return "https"
+	}
+	return "http"
+}
+
+// WorkloadIdentityAnnotations returns the annotations that should be added to pulp SA
+// so that the pods can get the object storage credentials from the cloud provider
+// (IRSA for S3 or Azure Workload Identity) instead of static keys from the Secret
+func WorkloadIdentityAnnotations(ctx context.Context, c client.Client, pulp *pulpv1.Pulp) map[string]string {
+	annotations := map[string]string{}
+	storageType := GetStorageType(*pulp)
+	if len(storageType) == 0 {
+		return annotations
+	}
+
+	switch storageType[0] {
+	case S3ObjType:
+		storageData, _ := RetrieveSecretData(ctx, pulp.Spec.ObjectStorageS3Secret, pulp.Namespace, false, c, "s3-role-arn")
+		if len(storageData["s3-role-arn"]) > 0 {
+			annotations[IRSARoleAnnotation] = storageData["s3-role-arn"]
+		}
+	case AzureObjType:
+		storageData, _ := RetrieveSecretData(ctx, pulp.Spec.ObjectStorageAzureSecret, pulp.Namespace, false, c, "azure-client-id", "azure-tenant-id")
+		if len(storageData["azure-client-id"]) > 0 {
+			annotations[AzureWIClientIdAnnotation] = storageData["azure-client-id"]
+		}
+		if len(storageData["azure-tenant-id"]) > 0 {
+			annotations[AzureWITenantIdAnnotation] = storageData["azure-tenant-id"]
+		}
+	}
+
+	return annotations
+}
+
+// AzureWorkloadIdentityEnabled returns true if the Azure object storage Secret does not
+// provide an account key nor a connection string but provides the azure-client-id of
+// the managed identity federated with pulp SA
+func AzureWorkloadIdentityEnabled(ctx context.Context, c client.Client, pulp *pulpv1.Pulp) bool {
+	if len(pulp.Spec.ObjectStorageAzureSecret) == 0 {
+		return false
+	}
+	storageData, err := RetrieveSecretData(ctx, pulp.Spec.ObjectStorageAzureSecret, pulp.Namespace, false, c, "azure-account-key", "azure-connection-string", "azure-client-id")
+	if err != nil {
+		return false
+	}
+	return len(storageData["azure-account-key"]) == 0 && len(storageData["azure-connection-string"]) == 0 && len(storageData["azure-client-id"]) > 0
+}
+
 // DeployCollectionSign returns true if signingScript secret is defined with a collection script
 func DeployCollectionSign(secret corev1.Secret) bool {
 	_, contains := secret.Data[settings.CollectionSigningScriptName]
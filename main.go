@@ -44,7 +44,9 @@ import (
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	pulpv1 "github.com/pulp/pulp-operator/apis/repo-manager.pulpproject.org/v1"
+	repo_manager_access "github.com/pulp/pulp-operator/controllers/access"
 	repo_manager_backup "github.com/pulp/pulp-operator/controllers/backup"
+	repo_manager_content "github.com/pulp/pulp-operator/controllers/content"
 	repo_manager "github.com/pulp/pulp-operator/controllers/repo_manager"
 	repo_manager_restore "github.com/pulp/pulp-operator/controllers/restore"
 	//+kubebuilder:scaffold:imports
@@ -192,6 +194,70 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "PulpRestore")
 		os.Exit(1)
 	}
+	if err = (&repo_manager_access.PulpUserReconciler{
+		Client:    mgr.GetClient(),
+		RawLogger: mgr.GetLogger(),
+		Scheme:    mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PulpUser")
+		os.Exit(1)
+	}
+	if err = (&repo_manager_access.PulpGroupReconciler{
+		Client:    mgr.GetClient(),
+		RawLogger: mgr.GetLogger(),
+		Scheme:    mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PulpGroup")
+		os.Exit(1)
+	}
+	if err = (&repo_manager_access.PulpRoleBindingReconciler{
+		Client:    mgr.GetClient(),
+		RawLogger: mgr.GetLogger(),
+		Scheme:    mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PulpRoleBinding")
+		os.Exit(1)
+	}
+	if err = (&repo_manager_content.PulpRemoteReconciler{
+		Client:    mgr.GetClient(),
+		RawLogger: mgr.GetLogger(),
+		Scheme:    mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PulpRemote")
+		os.Exit(1)
+	}
+	if err = (&repo_manager_content.PulpRepositoryReconciler{
+		Client:    mgr.GetClient(),
+		RawLogger: mgr.GetLogger(),
+		Scheme:    mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PulpRepository")
+		os.Exit(1)
+	}
+	if err = (&repo_manager_content.PulpDistributionReconciler{
+		Client:    mgr.GetClient(),
+		RawLogger: mgr.GetLogger(),
+		Scheme:    mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PulpDistribution")
+		os.Exit(1)
+	}
+	if err = (&repo_manager_content.PulpDomainReconciler{
+		Client:    mgr.GetClient(),
+		RawLogger: mgr.GetLogger(),
+		Scheme:    mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PulpDomain")
+		os.Exit(1)
+	}
+	if err = (&repo_manager.PulpSyncScheduleReconciler{
+		Client:    mgr.GetClient(),
+		RawLogger: mgr.GetLogger(),
+		Scheme:    mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PulpSyncSchedule")
+		os.Exit(1)
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {